@@ -0,0 +1,432 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/LFroesch/scout/internal/archive"
+	"github.com/LFroesch/scout/internal/fileops"
+	"github.com/LFroesch/scout/internal/jobs"
+	"github.com/LFroesch/scout/internal/utils"
+)
+
+// Archives: the preview lists what's inside a zip or tar (plain, gz, bz2 or xz), and enter
+// opens it in a read-only browser that navigates it like a directory. Z extracts the
+// archives under the cursor or selected, z compresses the selection into a new zip or
+// tar.gz; both run as background jobs (see jobs.go) and can be undone.
+
+// archivePreviewBudget caps how much of a compressed tar the preview reads, so a huge
+// tarball doesn't stall the cursor; the browser always reads the whole thing
+const archivePreviewBudget = 8 << 20
+
+// archiveListedMsg delivers an archive's listing, read in the background
+type archiveListedMsg struct {
+	path     string
+	entries  []archive.Entry
+	complete bool
+	err      error
+}
+
+// listArchive reads the whole listing of the archive at path
+func listArchive(path string) tea.Cmd {
+	return func() tea.Msg {
+		entries, complete, err := archive.List(path, 0)
+		return archiveListedMsg{path: path, entries: entries, complete: complete, err: err}
+	}
+}
+
+// archiveBrowser is the read-only view into an archive
+type archiveBrowser struct {
+	path    string
+	entries []archive.Entry
+	loading bool
+	err     error
+	dir     string          // Directory shown, as a path inside the archive ("" at the top)
+	rows    []archive.Entry // dir's children
+	cursor  int
+	cursors map[string]int // Cursor left behind in each directory, restored on the way back up
+}
+
+// show lists dir, putting the cursor back where it was when dir was last left
+func (b *archiveBrowser) show(dir string) {
+	b.cursors[b.dir] = b.cursor
+	b.dir = dir
+	b.rows = archive.Children(b.entries, dir)
+	b.cursor = min(b.cursors[dir], max(len(b.rows)-1, 0))
+}
+
+// openArchive opens the browser on the archive at path and starts reading it
+func (m *model) openArchive(path string) tea.Cmd {
+	m.archiveBrowser = &archiveBrowser{path: path, loading: true, cursors: make(map[string]int)}
+	m.mode = modeArchive
+	return listArchive(path)
+}
+
+// handleArchiveListed fills the browser once its listing arrives
+func (m *model) handleArchiveListed(msg archiveListedMsg) {
+	b := m.archiveBrowser
+	if b == nil || b.path != msg.path {
+		return // Closed, or another archive opened since
+	}
+	b.loading = false
+	b.err = msg.err
+	b.entries = msg.entries
+	b.show("")
+}
+
+// closeArchive leaves the browser
+func (m *model) closeArchive() {
+	m.mode = modeNormal
+	m.archiveBrowser = nil
+}
+
+// handleArchiveKey handles keys while the archive browser is open
+func (m *model) handleArchiveKey(msg tea.KeyMsg) tea.Cmd {
+	b := m.archiveBrowser
+	switch msg.String() {
+	case "esc", "q":
+		m.closeArchive()
+	case "j", "down":
+		if b.cursor < len(b.rows)-1 {
+			b.cursor++
+		}
+	case "k", "up":
+		if b.cursor > 0 {
+			b.cursor--
+		}
+	case "g":
+		b.cursor = 0
+	case "G":
+		b.cursor = max(len(b.rows)-1, 0)
+	case "enter", "l", "right":
+		if b.cursor < len(b.rows) {
+			if e := b.rows[b.cursor]; e.IsDir {
+				b.show(e.Name)
+			} else {
+				m.statusMsg = "archives are read-only here: x to extract"
+				m.statusExpiry = time.Now().Add(2 * time.Second)
+			}
+		}
+	case "h", "left", "backspace":
+		if b.dir == "" {
+			m.closeArchive()
+			break
+		}
+		parent := path.Dir(b.dir)
+		if parent == "." {
+			parent = ""
+		}
+		left := b.dir
+		b.show(parent)
+		// Land on the directory just left
+		for i, e := range b.rows {
+			if e.Name == left {
+				b.cursor = i
+			}
+		}
+	case "x", "Z":
+		archivePath := b.path
+		m.closeArchive()
+		return m.openExtractDialog([]string{archivePath})
+	}
+	return nil
+}
+
+// archivePreview lists an archive's entries with their sizes for the preview pane
+func (m *model) archivePreview(path string, info os.FileInfo) string {
+	if cached, ok := m.previewCache[path]; ok && cached.modTime.Equal(info.ModTime()) {
+		return cached.content
+	}
+
+	var b strings.Builder
+	format := archive.Detect(path)
+	b.WriteString(fmt.Sprintf("─── Archive (%s) ───\n\n", format))
+	entries, complete, err := archive.List(path, archivePreviewBudget)
+	if err != nil {
+		b.WriteString(fmt.Sprintf("Cannot read archive: %v", err))
+		return b.String()
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+
+	files, dirs := 0, 0
+	var total int64
+	for _, e := range entries {
+		if e.IsDir {
+			dirs++
+		} else {
+			files++
+			total += max(e.Size, 0)
+		}
+	}
+	more := ""
+	if !complete {
+		more = " so far"
+	}
+	b.WriteString(fmt.Sprintf("%d file(s), %d folder(s), %s unpacked%s\n\n", files, dirs, utils.FormatFileSize(total), more))
+
+	for i, e := range entries {
+		if i >= maxPreviewItems {
+			b.WriteString(fmt.Sprintf("\n... and %d more entries\n", len(entries)-maxPreviewItems))
+			break
+		}
+		switch {
+		case e.IsDir:
+			b.WriteString(fmt.Sprintf("📁 %s/\n", e.Name))
+		case e.Link != "":
+			b.WriteString(fmt.Sprintf("🔗 %s → %s\n", e.Name, e.Link))
+		case e.Size < 0:
+			b.WriteString(fmt.Sprintf("📄 %s\n", e.Name))
+		default:
+			b.WriteString(fmt.Sprintf("📄 %s  %s\n", e.Name, utils.FormatFileSize(e.Size)))
+		}
+	}
+	if !complete {
+		b.WriteString(fmt.Sprintf("\n(listing stopped after the first %s: enter to browse it all)", utils.FormatFileSize(archivePreviewBudget)))
+	} else {
+		b.WriteString("\n(enter to browse, Z to extract)")
+	}
+
+	m.addToPreviewCache(path, previewCacheEntry{content: b.String(), modTime: info.ModTime()})
+	return b.String()
+}
+
+// extractState is the extract dialog: where the archives go, typed or picked from presets
+type extractState struct {
+	archives  []string
+	subfolder bool // Each archive goes into a new folder named after it, inside the destination
+	preset    int  // Last preset tab picked
+	prevMode  mode
+}
+
+// extractPreset is a destination the extract dialog offers on tab
+type extractPreset struct {
+	dir       string
+	subfolder bool
+}
+
+// extractPresets lists the dialog's tab choices: a new folder here, straight here, and
+// the same two in the other pane when it's open
+func (m *model) extractPresets() []extractPreset {
+	presets := []extractPreset{{m.currentDir, true}, {m.currentDir, false}}
+	if m.dualPane && m.otherPane.dir != m.currentDir {
+		presets = append(presets, extractPreset{m.otherPane.dir, true}, extractPreset{m.otherPane.dir, false})
+	}
+	return presets
+}
+
+// openExtractDialog asks where to extract archives, starting on "a new folder here"
+func (m *model) openExtractDialog(archives []string) tea.Cmd {
+	if len(archives) == 0 {
+		m.statusMsg = "no archive here: Z extracts zip and tar files"
+		m.statusExpiry = time.Now().Add(2 * time.Second)
+		return nil
+	}
+	m.commitVisual()
+	m.extract = &extractState{archives: archives, prevMode: m.mode}
+	if m.mode == modeArchive {
+		m.extract.prevMode = modeNormal
+	}
+	m.applyExtractPreset(0)
+	m.mode = modeExtract
+	m.textInput.Placeholder = "destination directory"
+	m.textInput.Focus()
+	return textinput.Blink
+}
+
+// applyExtractPreset puts preset i in the dialog
+func (m *model) applyExtractPreset(i int) {
+	p := m.extractPresets()[i]
+	m.extract.preset = i
+	m.extract.subfolder = p.subfolder
+	m.textInput.SetValue(p.dir)
+	m.textInput.CursorEnd()
+}
+
+// extractTargets returns the archives under the cursor or selected
+func (m *model) extractTargets() []string {
+	var archives []string
+	for _, p := range m.targetPaths() {
+		if archive.Detect(p) != archive.Unknown {
+			archives = append(archives, p)
+		}
+	}
+	return archives
+}
+
+// handleExtractKey handles keys while the extract dialog is open
+func (m *model) handleExtractKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc", "ctrl+c":
+		m.mode = m.extract.prevMode
+		m.extract = nil
+		m.textInput.SetValue("")
+		return nil
+	case "tab":
+		m.applyExtractPreset((m.extract.preset + 1) % len(m.extractPresets()))
+		return nil
+	case "ctrl+n":
+		// Toggle the per-archive folder without touching the path
+		m.extract.subfolder = !m.extract.subfolder
+		return nil
+	case "enter":
+		return m.submitExtract(m.textInput.Value())
+	}
+	var cmd tea.Cmd
+	m.textInput, cmd = m.textInput.Update(msg)
+	return cmd
+}
+
+// submitExtract queues extracting the dialog's archives into dest
+func (m *model) submitExtract(dest string) tea.Cmd {
+	dest = strings.TrimSpace(dest)
+	if dest == "" {
+		return nil
+	}
+	if strings.HasPrefix(dest, "~") {
+		if home, err := os.UserHomeDir(); err == nil {
+			dest = filepath.Join(home, dest[1:])
+		}
+	}
+	if !filepath.IsAbs(dest) {
+		dest = filepath.Join(m.currentDir, dest)
+	}
+	dest = filepath.Clean(dest)
+
+	st := m.extract
+	reserved := make(map[string]bool)
+	var items []jobs.Item
+	for _, a := range st.archives {
+		dst := dest
+		if st.subfolder {
+			dst = filepath.Join(dest, archive.Stem(filepath.Base(a)))
+			if _, err := os.Lstat(dst); err == nil || reserved[dst] {
+				free, err := fileops.KeepBothPath(dst, true, reserved)
+				if err != nil {
+					m.extract = nil
+					m.textInput.SetValue("")
+					m.showError("EXTRACT FAILED", fmt.Sprintf("%s: %v", filepath.Base(a), err))
+					return nil
+				}
+				dst = free
+			}
+			reserved[dst] = true
+		}
+		items = append(items, jobs.Item{Src: a, Dst: dst})
+	}
+
+	m.mode = st.prevMode
+	m.extract = nil
+	m.textInput.SetValue("")
+	m.jobs.Submit(jobs.Extract, items, dest, fileops.CopyOptions{})
+	m.statusMsg = fmt.Sprintf("extracting %d archive(s) to %s in the background (J: jobs)", len(items), filepath.Base(dest))
+	m.statusExpiry = time.Now().Add(2 * time.Second)
+	return m.startJobPolling()
+}
+
+// compressFormats are what the compress dialog can write, in tab order
+var compressFormats = []string{".zip", ".tar.gz"}
+
+// compressState is the compress dialog: what goes in the new archive
+type compressState struct {
+	sources  []string
+	prevMode mode
+}
+
+// openCompressDialog asks for the name of an archive holding the selection (or the
+// cursor item), suggesting one after it
+func (m *model) openCompressDialog() tea.Cmd {
+	sources := m.targetPaths()
+	if len(sources) == 0 {
+		return nil
+	}
+	m.commitVisual()
+	name := filepath.Base(sources[0])
+	if len(sources) > 1 {
+		name = filepath.Base(m.currentDir)
+	}
+	if info, err := os.Lstat(sources[0]); err == nil && !info.IsDir() && len(sources) == 1 {
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+
+	m.compress = &compressState{sources: sources, prevMode: m.mode}
+	m.mode = modeCompress
+	m.textInput.Placeholder = "archive name (.zip or .tar.gz)"
+	m.textInput.SetValue(name + compressFormats[0])
+	m.textInput.CursorEnd()
+	m.textInput.Focus()
+	return textinput.Blink
+}
+
+// handleCompressKey handles keys while the compress dialog is open
+func (m *model) handleCompressKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc", "ctrl+c":
+		m.mode = m.compress.prevMode
+		m.compress = nil
+		m.textInput.SetValue("")
+		return nil
+	case "tab":
+		// Swap the extension for the next format
+		name := m.textInput.Value()
+		next := compressFormats[0]
+		for i, ext := range compressFormats {
+			if strings.HasSuffix(strings.ToLower(name), ext) {
+				name = name[:len(name)-len(ext)]
+				next = compressFormats[(i+1)%len(compressFormats)]
+				break
+			}
+		}
+		m.textInput.SetValue(name + next)
+		m.textInput.CursorEnd()
+		return nil
+	case "enter":
+		return m.submitCompress(m.textInput.Value())
+	}
+	var cmd tea.Cmd
+	m.textInput, cmd = m.textInput.Update(msg)
+	return cmd
+}
+
+// submitCompress queues writing the dialog's sources into a new archive called name
+func (m *model) submitCompress(name string) tea.Cmd {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil
+	}
+	if !archive.CanCreate(archive.Detect(name)) {
+		m.statusMsg = "archive name must end in .zip or .tar.gz"
+		m.statusExpiry = time.Now().Add(3 * time.Second)
+		return nil
+	}
+	dst := name
+	if !filepath.IsAbs(dst) {
+		dst = filepath.Join(m.currentDir, name)
+	}
+	if _, err := os.Lstat(dst); err == nil {
+		m.statusMsg = fmt.Sprintf("%s already exists", filepath.Base(dst))
+		m.statusExpiry = time.Now().Add(3 * time.Second)
+		return nil
+	}
+
+	st := m.compress
+	items := make([]jobs.Item, len(st.sources))
+	for i, src := range st.sources {
+		items[i] = jobs.Item{Src: src, Dst: dst}
+	}
+	m.mode = st.prevMode
+	m.compress = nil
+	m.textInput.SetValue("")
+	m.clearSelection()
+	m.jobs.Submit(jobs.Compress, items, filepath.Dir(dst), fileops.CopyOptions{})
+	m.statusMsg = fmt.Sprintf("compressing %d item(s) into %s in the background (J: jobs)", len(items), filepath.Base(dst))
+	m.statusExpiry = time.Now().Add(2 * time.Second)
+	return m.startJobPolling()
+}
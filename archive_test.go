@@ -0,0 +1,121 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestCompressExtractAndUndo(t *testing.T) {
+	m := selectionTestModel(t, "a.txt", "b.txt")
+	dir := m.currentDir
+
+	// z on a selection suggests a name after the directory; tab switches to tar.gz
+	m.selectByGlob("*.txt", true)
+	m = pressKeys(m, runeKey('z'))
+	if m.mode != modeCompress {
+		t.Fatalf("z opened mode %v", m.mode)
+	}
+	want := filepath.Base(dir) + ".zip"
+	if got := m.textInput.Value(); got != want {
+		t.Errorf("suggested %q, want %q", got, want)
+	}
+	m = pressKeys(m, tea.KeyMsg{Type: tea.KeyTab})
+	if got := m.textInput.Value(); got != filepath.Base(dir)+".tar.gz" {
+		t.Errorf("after tab: %q", got)
+	}
+	m.textInput.SetValue("both.tar.gz")
+	m = pressKeys(m, tea.KeyMsg{Type: tea.KeyEnter})
+	finishJobs(&m)
+	archivePath := filepath.Join(dir, "both.tar.gz")
+	if _, err := os.Stat(archivePath); err != nil {
+		t.Fatalf("archive not created: %v", err)
+	}
+
+	// Z on the archive, first preset: a new folder named after it
+	m.loadFiles()
+	for i, f := range m.filteredFiles {
+		if f.name == "both.tar.gz" {
+			m.cursor = i
+		}
+	}
+	m = pressKeys(m, runeKey('Z'))
+	if m.mode != modeExtract || !m.extract.subfolder || m.textInput.Value() != dir {
+		t.Fatalf("extract dialog: mode %v, value %q", m.mode, m.textInput.Value())
+	}
+	m = pressKeys(m, tea.KeyMsg{Type: tea.KeyEnter})
+	finishJobs(&m)
+	out := filepath.Join(dir, "both")
+	if data, _ := os.ReadFile(filepath.Join(out, "b.txt")); string(data) != "x" {
+		t.Fatalf("extracted b.txt = %q", data)
+	}
+
+	// Extracting again doesn't touch the first copy
+	m = pressKeys(m, runeKey('Z'), tea.KeyMsg{Type: tea.KeyEnter})
+	finishJobs(&m)
+	if _, err := os.Stat(filepath.Join(dir, "both (1)", "a.txt")); err != nil {
+		t.Errorf("second extraction: %v", err)
+	}
+
+	// Undo takes back the extractions, then the archive
+	m = pressKeys(m, runeKey('u'), runeKey('u'))
+	if _, err := os.Lstat(out); !os.IsNotExist(err) {
+		t.Error("undo left the extracted folder")
+	}
+	m = pressKeys(m, runeKey('u'))
+	if _, err := os.Lstat(archivePath); !os.IsNotExist(err) {
+		t.Error("undo left the archive")
+	}
+}
+
+func TestArchiveBrowser(t *testing.T) {
+	m := selectionTestModel(t, "a.txt")
+	dir := m.currentDir
+	sub := filepath.Join(dir, "docs", "img")
+	os.MkdirAll(sub, 0o755)
+	os.WriteFile(filepath.Join(sub, "logo.png"), []byte("png"), 0o644)
+	m.loadFiles()
+	m.selectByGlob("*", true)
+	m = pressKeys(m, runeKey('z'))
+	m.textInput.SetValue("all.zip")
+	m = pressKeys(m, tea.KeyMsg{Type: tea.KeyEnter})
+	finishJobs(&m)
+
+	m.loadFiles()
+	for i, f := range m.filteredFiles {
+		if f.name == "all.zip" {
+			m.cursor = i
+		}
+	}
+	if got := m.previewFile(filepath.Join(dir, "all.zip")); !strings.Contains(got, "docs/img/logo.png  3 B") {
+		t.Errorf("preview doesn't list the entries:\n%s", got)
+	}
+
+	gotModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = *gotModel.(*model)
+	if m.mode != modeArchive || cmd == nil {
+		t.Fatalf("enter on an archive: mode %v", m.mode)
+	}
+	gotModel, _ = m.Update(cmd())
+	m = *gotModel.(*model)
+	b := m.archiveBrowser
+	if len(b.rows) != 2 || b.rows[0].Name != "docs" || b.rows[1].Name != "a.txt" {
+		t.Fatalf("top level = %+v", b.rows)
+	}
+
+	m = pressKeys(m, tea.KeyMsg{Type: tea.KeyEnter}, tea.KeyMsg{Type: tea.KeyEnter})
+	if b.dir != "docs/img" || len(b.rows) != 1 {
+		t.Fatalf("in %q: %+v", b.dir, b.rows)
+	}
+	m = pressKeys(m, runeKey('h'))
+	if b.dir != "docs" || b.rows[b.cursor].Name != "docs/img" {
+		t.Errorf("h went to %q with the cursor on %q", b.dir, b.rows[b.cursor].Name)
+	}
+	m = pressKeys(m, runeKey('h'), runeKey('h'))
+	if m.mode != modeNormal {
+		t.Errorf("h at the top should close the browser, mode %v", m.mode)
+	}
+}
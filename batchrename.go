@@ -0,0 +1,181 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/LFroesch/scout/internal/fileops"
+)
+
+// Pattern rename: an in-TUI dialog that applies a find/replace rule (fileops.RenamePattern)
+// to the selection or every listed item, previewing the result as it's typed.
+
+// Batch rename dialog fields, in tab order
+const (
+	batchFieldFind = iota
+	batchFieldReplace
+	batchFieldExt
+	batchFieldCount
+)
+
+// batchRenameState is the pattern rename dialog
+type batchRenameState struct {
+	inputs   [batchFieldCount]textinput.Model
+	focus    int
+	caseMode fileops.CaseMode
+	items    []fileops.RenameSource  // Everything the pattern is tried on, in listing order
+	rows     []fileops.RenamePreview // Items the pattern changes
+	err      string                  // Why the pattern doesn't compile
+	scroll   int                     // First preview row shown
+}
+
+// conflicts counts preview rows that block applying the rename
+func (br *batchRenameState) conflicts() int {
+	n := 0
+	for _, r := range br.rows {
+		if r.Conflict != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// startBatchRename opens the pattern rename dialog for the selection, or everything listed
+func (m *model) startBatchRename() tea.Cmd {
+	var items []fileItem
+	if m.hasSelection() {
+		items = m.targetItems()
+	} else {
+		for _, f := range m.filteredFiles {
+			if f.name != ".." {
+				items = append(items, f)
+			}
+		}
+	}
+	if len(items) == 0 {
+		return nil
+	}
+
+	br := &batchRenameState{}
+	for _, item := range items {
+		br.items = append(br.items, fileops.RenameSource{Path: item.path, IsDir: item.isDir, ModTime: item.modTime})
+	}
+	placeholders := [batchFieldCount]string{"regexp on the name minus extension (empty: all)", "e.g. photo_{n:3} or $1-{date}", "new extension (. to remove)"}
+	for i := range br.inputs {
+		ti := textinput.New()
+		ti.CharLimit = 256
+		ti.Width = 50
+		ti.Placeholder = placeholders[i]
+		br.inputs[i] = ti
+	}
+	br.inputs[batchFieldFind].Focus()
+
+	m.commitVisual()
+	m.batchRename = br
+	m.mode = modeBatchRename
+	m.updateBatchPreview()
+	return textinput.Blink
+}
+
+// updateBatchPreview recomputes the preview from the dialog's fields
+func (m *model) updateBatchPreview() {
+	br := m.batchRename
+	pattern := fileops.RenamePattern{
+		Find:    br.inputs[batchFieldFind].Value(),
+		Replace: br.inputs[batchFieldReplace].Value(),
+		Ext:     br.inputs[batchFieldExt].Value(),
+		Case:    br.caseMode,
+	}
+	rows, err := fileops.PreviewPattern(pattern, br.items)
+	if err != nil {
+		br.err = err.Error() // Keep the last good preview on screen while the pattern is mid-edit
+		return
+	}
+	br.err = ""
+	br.rows = rows
+	if br.scroll >= len(rows) {
+		br.scroll = 0
+	}
+}
+
+// handleBatchRenameKey handles keys while the pattern rename dialog is open
+func (m *model) handleBatchRenameKey(msg tea.KeyMsg) tea.Cmd {
+	br := m.batchRename
+	switch msg.String() {
+	case "esc", "ctrl+c":
+		m.batchRename = nil
+		m.mode = modeNormal
+		return nil
+	case "tab", "shift+tab":
+		br.inputs[br.focus].Blur()
+		if msg.String() == "tab" {
+			br.focus = (br.focus + 1) % batchFieldCount
+		} else {
+			br.focus = (br.focus + batchFieldCount - 1) % batchFieldCount
+		}
+		br.inputs[br.focus].Focus()
+		return textinput.Blink
+	case "ctrl+t":
+		br.caseMode = (br.caseMode + 1) % (fileops.CaseTitle + 1)
+		m.updateBatchPreview()
+		return nil
+	case "down", "ctrl+n":
+		if br.scroll < len(br.rows)-1 {
+			br.scroll++
+		}
+		return nil
+	case "up", "ctrl+p":
+		if br.scroll > 0 {
+			br.scroll--
+		}
+		return nil
+	case "enter":
+		m.applyBatchRename()
+		return nil
+	}
+	var cmd tea.Cmd
+	br.inputs[br.focus], cmd = br.inputs[br.focus].Update(msg)
+	m.updateBatchPreview()
+	return cmd
+}
+
+// applyBatchRename renames everything in the preview as one undoable step. Nothing
+// happens while the pattern is invalid or any row conflicts.
+func (m *model) applyBatchRename() {
+	br := m.batchRename
+	switch {
+	case br.err != "":
+		m.statusMsg = "fix the pattern first: " + br.err
+	case len(br.rows) == 0:
+		m.statusMsg = "nothing to rename"
+	case br.conflicts() > 0:
+		m.statusMsg = fmt.Sprintf("%d conflict(s), nothing renamed", br.conflicts())
+	default:
+		ops := make([]fileops.RenameOp, len(br.rows))
+		for i, r := range br.rows {
+			ops[i] = r.RenameOp
+		}
+		// The planner orders renames so counters can shift names along (2→1, 3→2) safely
+		plan, err := fileops.PlanRenames(ops, nil)
+		if err == nil {
+			err = fileops.ApplyRenames(plan.Steps)
+		}
+		m.batchRename = nil
+		if err != nil {
+			m.refreshPanes()
+			m.showError("RENAME FAILED", fmt.Sprintf("nothing was renamed.\n\n%v", err))
+			return
+		}
+		m.mode = modeNormal
+		m.record(fileops.Op{Kind: fileops.OpRename, Moves: plan.Renames})
+		m.clearSelection()
+		m.refreshPanes()
+		m.statusMsg = fmt.Sprintf("renamed %d item(s) (press 'u' to undo)", len(plan.Renames))
+		m.statusExpiry = time.Now().Add(3 * time.Second)
+		return
+	}
+	m.statusExpiry = time.Now().Add(3 * time.Second)
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func typeText(m model, text string) model {
+	for _, r := range text {
+		m = pressKeys(m, runeKey(r))
+	}
+	return m
+}
+
+func TestBatchRenameCounterAndUndo(t *testing.T) {
+	m := selectionTestModel(t, "b.jpg", "a.jpg", "notes.txt")
+	dir := m.currentDir
+	m.selectByGlob("*.jpg", true)
+
+	m = pressKeys(m, tea.KeyMsg{Type: tea.KeyCtrlE})
+	if m.mode != modeBatchRename || len(m.batchRename.items) != 2 {
+		t.Fatalf("expected the dialog on the 2 selected items, got mode %d", m.mode)
+	}
+
+	// Whole-stem replacement with a counter; the preview updates as the fields are typed
+	m = pressKeys(m, tea.KeyMsg{Type: tea.KeyTab})
+	m = typeText(m, "img_{n:2}")
+	if len(m.batchRename.rows) != 2 || m.batchRename.conflicts() != 0 {
+		t.Fatalf("unexpected preview: %+v", m.batchRename.rows)
+	}
+
+	// A broken token is reported and blocks enter
+	m = typeText(m, "{")
+	if m.batchRename.err == "" {
+		t.Fatal("expected a pattern error")
+	}
+	m = pressKeys(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.mode != modeBatchRename {
+		t.Fatal("enter must not rename while the pattern is invalid")
+	}
+	m = pressKeys(m, tea.KeyMsg{Type: tea.KeyBackspace}, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.mode != modeNormal {
+		t.Fatalf("expected normal mode after renaming, got %d (%s)", m.mode, m.errorDetails)
+	}
+	for _, name := range []string{"img_01.jpg", "img_02.jpg", "notes.txt"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("%s should exist: %v", name, err)
+		}
+	}
+
+	// One undo entry reverses the whole batch
+	m = pressKeys(m, runeKey('u'))
+	for _, name := range []string{"a.jpg", "b.jpg"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("%s should be back after undo: %v", name, err)
+		}
+	}
+}
+
+func TestBatchRenameConflictsBlockApply(t *testing.T) {
+	m := selectionTestModel(t, "a.txt", "b.txt")
+	m = pressKeys(m, tea.KeyMsg{Type: tea.KeyCtrlE}, tea.KeyMsg{Type: tea.KeyTab})
+	m = typeText(m, "same")
+	if m.batchRename.conflicts() != 2 {
+		t.Fatalf("expected both rows to conflict, got %+v", m.batchRename.rows)
+	}
+	m = pressKeys(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.mode != modeBatchRename {
+		t.Fatal("enter must not rename while there are conflicts")
+	}
+	m = pressKeys(m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.mode != modeNormal || m.batchRename != nil {
+		t.Fatal("esc should close the dialog")
+	}
+	if _, err := os.Stat(filepath.Join(m.currentDir, "a.txt")); err != nil {
+		t.Error("a.txt should be untouched")
+	}
+}
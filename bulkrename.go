@@ -0,0 +1,202 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/LFroesch/scout/internal/fileops"
+	"github.com/LFroesch/scout/internal/logger"
+)
+
+// Bulk rename, vidir-style: the names go to a temp file, the user edits it in their editor,
+// and the edited list becomes a rename plan that's confirmed before anything moves.
+
+// bulkRenameState tracks one bulk rename from opening the editor to applying the plan
+type bulkRenameState struct {
+	file    string              // Temp file holding the editable list
+	baseDir string              // Directory names in the list are relative to
+	paths   []string            // Entry n in the list is paths[n-1]
+	plan    *fileops.RenamePlan // Set once the edited list has been read back
+	scroll  int                 // First plan line shown in the confirmation dialog
+}
+
+// bulkRenameEditedMsg arrives when the editor exits
+type bulkRenameEditedMsg struct{ err error }
+
+// startBulkRename writes the selection (or every listed item) to a temp file and opens it
+// in the user's editor, waiting for it to close
+func (m *model) startBulkRename() tea.Cmd {
+	var paths []string
+	if m.hasSelection() {
+		paths = m.targetPaths()
+	} else {
+		for _, f := range m.filteredFiles {
+			if f.name != ".." {
+				paths = append(paths, f.path)
+			}
+		}
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+	editor, found := m.findEditor()
+	if !found {
+		m.statusMsg = "no editor found in path"
+		m.statusExpiry = time.Now().Add(3 * time.Second)
+		return nil
+	}
+
+	f, err := os.CreateTemp("", "scout-rename-*.txt")
+	if err != nil {
+		m.showError("BULK RENAME FAILED", fmt.Sprintf("cannot create temp file: %v", err))
+		return nil
+	}
+	_, err = f.WriteString(fileops.FormatRenameList(m.currentDir, paths))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		m.showError("BULK RENAME FAILED", fmt.Sprintf("cannot write temp file: %v", err))
+		return nil
+	}
+
+	m.commitVisual()
+	m.bulkRename = &bulkRenameState{file: f.Name(), baseDir: m.currentDir, paths: paths}
+	return tea.ExecProcess(waitEditorCommand(editor, f.Name()), func(err error) tea.Msg {
+		return bulkRenameEditedMsg{err: err}
+	})
+}
+
+// finishBulkRename reads back the edited list and, if anything changed, shows the plan
+// for confirmation
+func (m *model) finishBulkRename(msg bulkRenameEditedMsg) {
+	br := m.bulkRename
+	if br == nil {
+		return
+	}
+	data, err := os.ReadFile(br.file)
+	if rmErr := os.Remove(br.file); rmErr != nil && !os.IsNotExist(rmErr) {
+		logger.Warn("Failed to remove bulk rename list %s: %v", br.file, rmErr)
+	}
+	if msg.err != nil || err != nil {
+		m.bulkRename = nil
+		if msg.err == nil {
+			msg.err = err
+		}
+		m.showError("BULK RENAME FAILED", fmt.Sprintf("editor error: %v", msg.err))
+		return
+	}
+
+	renames, deletes, err := fileops.ParseRenameList(br.baseDir, br.paths, string(data))
+	if err == nil {
+		br.plan, err = fileops.PlanRenames(renames, deletes)
+	}
+	if err != nil {
+		m.bulkRename = nil
+		m.showError("BULK RENAME FAILED", fmt.Sprintf("nothing was renamed.\n\n%v", err))
+		return
+	}
+	if br.plan.Empty() {
+		m.bulkRename = nil
+		m.statusMsg = "bulk rename: no changes"
+		m.statusExpiry = time.Now().Add(2 * time.Second)
+		return
+	}
+	m.mode = modeConfirmBulkRename
+}
+
+// applyBulkRename carries out the confirmed plan: deletes go to the trash first (freeing
+// their names), then the renames run as one unit that's rolled back if any step fails
+func (m *model) applyBulkRename() {
+	br := m.bulkRename
+	m.bulkRename = nil
+	m.mode = modeNormal
+	if br == nil || br.plan == nil {
+		return
+	}
+	plan := br.plan
+
+	var trashed []fileops.TrashedItem
+	for _, path := range plan.Deletes {
+		info, err := os.Lstat(path)
+		if err != nil {
+			m.restoreTrashed(trashed)
+			m.showError("BULK RENAME FAILED", fmt.Sprintf("nothing was renamed.\n\n%s: %v", br.displayName(path), err))
+			return
+		}
+		trashPath, err := fileops.DeleteWithUndo(path, info.IsDir())
+		if err != nil {
+			m.restoreTrashed(trashed)
+			m.showError("BULK RENAME FAILED", fmt.Sprintf("nothing was renamed.\n\n%v", err))
+			return
+		}
+		trashed = append(trashed, fileops.TrashedItem{Path: path, IsDir: info.IsDir(), UndoInfo: trashPath})
+	}
+
+	if err := fileops.ApplyRenames(plan.Steps); err != nil {
+		m.restoreTrashed(trashed)
+		m.refreshPanes()
+		m.showError("BULK RENAME FAILED", fmt.Sprintf("all changes were rolled back.\n\n%v", err))
+		return
+	}
+
+	op := fileops.Op{Kind: fileops.OpRename, Trashed: trashed, Moves: plan.Renames}
+	if len(plan.Renames) == 0 {
+		op.Kind = fileops.OpTrash
+	}
+	m.record(op)
+	m.clearSelection()
+	m.refreshPanes()
+
+	var parts []string
+	if n := len(plan.Renames); n > 0 {
+		parts = append(parts, fmt.Sprintf("renamed %d", n))
+	}
+	if n := len(plan.Deletes); n > 0 {
+		parts = append(parts, fmt.Sprintf("deleted %d", n))
+	}
+	m.statusMsg = strings.Join(parts, ", ") + " (press 'u' to undo)"
+	m.statusExpiry = time.Now().Add(3 * time.Second)
+}
+
+// cancelBulkRename drops a plan without applying it
+func (m *model) cancelBulkRename() {
+	m.bulkRename = nil
+	m.mode = modeNormal
+	m.statusMsg = "bulk rename cancelled"
+	m.statusExpiry = time.Now().Add(2 * time.Second)
+}
+
+// restoreTrashed puts back files a failed bulk rename already sent to the trash
+func (m *model) restoreTrashed(items []fileops.TrashedItem) {
+	for i := len(items) - 1; i >= 0; i-- {
+		if err := fileops.RestoreFromTrash(items[i].UndoInfo, items[i].Path); err != nil {
+			logger.Error("Failed to restore %s after bulk rename error: %v", items[i].Path, err)
+		}
+	}
+}
+
+// planLines renders the plan as the confirmation dialog's diff, one line per change
+func (br *bulkRenameState) planLines() []string {
+	var lines []string
+	for _, op := range br.plan.Renames {
+		lines = append(lines, fmt.Sprintf("  %s → %s", br.displayName(op.From), br.displayName(op.To)))
+	}
+	for _, path := range br.plan.Deletes {
+		lines = append(lines, fmt.Sprintf("✗ %s", br.displayName(path)))
+	}
+	return lines
+}
+
+// displayName shows path the way the edited list did
+func (br *bulkRenameState) displayName(path string) string {
+	if rel, ok := strings.CutPrefix(path, br.baseDir+string(os.PathSeparator)); ok {
+		return rel
+	}
+	return path
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// editRenameList stands in for the user's editor: it rewrites the temp list and reports the editor closing
+func editRenameList(t *testing.T, m model, edited string) model {
+	t.Helper()
+	if m.bulkRename == nil {
+		t.Fatal("no bulk rename in progress")
+	}
+	if err := os.WriteFile(m.bulkRename.file, []byte(edited), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	gotModel, _ := m.Update(bulkRenameEditedMsg{})
+	return *gotModel.(*model)
+}
+
+func TestBulkRenameSwapAndUndo(t *testing.T) {
+	t.Setenv("VISUAL", "true")
+	m := selectionTestModel(t, "one.txt", "two.txt", "three.txt")
+	dir := m.currentDir
+
+	m = pressKeys(m, runeKey('E'))
+	// Listed by name: one.txt, three.txt, two.txt. Swap one/two and drop three.
+	m = editRenameList(t, m, "1\ttwo.txt\n3\tone.txt\n")
+	if m.mode != modeConfirmBulkRename {
+		t.Fatalf("expected confirmation, got mode %d", m.mode)
+	}
+	if m.bulkRename.plan.Cycles != 1 || len(m.bulkRename.plan.Deletes) != 1 {
+		t.Fatalf("unexpected plan: %+v", m.bulkRename.plan)
+	}
+
+	m = pressKeys(m, runeKey('y'))
+	if m.mode != modeNormal {
+		t.Fatalf("expected normal mode, got %d (%s)", m.mode, m.errorDetails)
+	}
+	for _, name := range []string{"one.txt", "two.txt"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("%s should exist after the swap", name)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "three.txt")); !os.IsNotExist(err) {
+		t.Error("three.txt should have been deleted")
+	}
+
+	m = pressKeys(m, runeKey('u'))
+	if m.journal.CanUndo() || m.mode != modeNormal {
+		t.Fatalf("undo should reverse the rename as one step (mode %d: %s)", m.mode, m.errorDetails)
+	}
+}
+
+func TestBulkRenameRejectsCollisions(t *testing.T) {
+	t.Setenv("VISUAL", "true")
+	m := selectionTestModel(t, "a.txt", "b.txt")
+	m.selectByGlob("a.txt", true)
+
+	m = pressKeys(m, runeKey('E'))
+	if len(m.bulkRename.paths) != 1 {
+		t.Fatalf("only the selection should be listed, got %v", m.bulkRename.paths)
+	}
+	m = editRenameList(t, m, "1\tb.txt\n")
+	if m.mode != modeErrorDialog {
+		t.Fatalf("expected an error dialog, got mode %d", m.mode)
+	}
+	if _, err := os.Stat(filepath.Join(m.currentDir, "a.txt")); err != nil {
+		t.Error("a.txt should be untouched")
+	}
+}
+
+func TestBulkRenameNoChanges(t *testing.T) {
+	t.Setenv("VISUAL", "true")
+	m := selectionTestModel(t, "a.txt")
+
+	m = pressKeys(m, runeKey('E'))
+	list, err := os.ReadFile(m.bulkRename.file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmp := m.bulkRename.file
+	m = editRenameList(t, m, string(list))
+	if m.mode != modeNormal || m.bulkRename != nil {
+		t.Fatal("an unchanged list should do nothing")
+	}
+	if _, err := os.Stat(tmp); !os.IsNotExist(err) {
+		t.Error("the temp list should be removed")
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/LFroesch/scout/internal/dataview"
+	"github.com/LFroesch/scout/internal/logger"
+)
+
+// Data files: JSON, CSV/TSV, YAML and TOML preview through the renderer registered for
+// their name or type in internal/dataview, under a line summing the file up. One the
+// renderer can't parse previews as plain or highlighted text. m shows the source
+// instead, and < and > fold a JSON tree or YAML/TOML outline a level at a time; the depth
+// carries over to the next file. Renderings aren't cached: they follow the pane's width
+// and the depth, and are quick to redo.
+
+// dataPreview adds path rendered by p to the preview. It adds nothing and reports false
+// if p can't make sense of the file, which then previews like any other.
+func (m *model) dataPreview(p dataview.Previewer, path string, preview *strings.Builder) bool {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	res, err := p.Render(content, dataview.Options{Width: m.width/2 - 6, Depth: m.previewDepth})
+	if err != nil {
+		logger.Debug("Previewing %s as plain text, not valid %s: %v", path, p.Name(), err)
+		return false
+	}
+
+	m.previewLevels = res.Depth
+	header := res.Summary
+	if res.Depth > 1 {
+		header += " · <>: fold"
+	}
+	preview.WriteString(lineNumberStyle.Render("─── "+header+" · m: source ───") + "\n")
+	preview.WriteString(res.Text)
+	return true
+}
+
+// foldPreview shows a level less (delta -1) or more (+1) of the tree or outline
+func (m *model) foldPreview(delta int) {
+	if !m.showPreview || m.previewLevels < 2 {
+		m.statusMsg = "nothing to fold"
+		m.statusExpiry = time.Now().Add(2 * time.Second)
+		return
+	}
+	depth := m.previewDepth
+	if depth == 0 {
+		depth = m.previewLevels
+	}
+	depth = max(depth+delta, 1)
+	if depth >= m.previewLevels {
+		depth = 0
+	}
+	m.previewDepth = depth
+
+	if depth == 0 {
+		m.statusMsg = "fold: all levels"
+	} else {
+		m.statusMsg = fmt.Sprintf("fold: %d of %d levels", depth, m.previewLevels)
+	}
+	m.statusExpiry = time.Now().Add(2 * time.Second)
+	m.updatePreview()
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	xansi "github.com/charmbracelet/x/ansi"
+)
+
+func TestDataFilesPreviewRenderedFoldedAndAsSource(t *testing.T) {
+	m := selectionTestModel(t)
+	os.WriteFile(filepath.Join(m.currentDir, "app.json"), []byte(`{"name":"scout","deps":{"tea":{"v":1}}}`), 0o644)
+	m.loadFiles()
+	for i, f := range m.filteredFiles {
+		if f.name == "app.json" {
+			m.cursor = i
+		}
+	}
+	preview := func() string {
+		t.Helper()
+		m.updatePreview()
+		return xansi.Strip(m.previewContent)
+	}
+
+	got := preview()
+	if !strings.Contains(got, "─── JSON · object, 2 keys · <>: fold · m: source ───\n{\n  \"name\": \"scout\",") {
+		t.Fatalf("JSON preview:\n%s", got)
+	}
+	if m.previewLevels != 3 {
+		t.Errorf("%d levels", m.previewLevels)
+	}
+
+	m = pressKeys(m, runeKey('<'))
+	if got := preview(); !strings.Contains(got, `"tea": {…}  1 key`) || m.statusMsg != "fold: 2 of 3 levels" {
+		t.Errorf("after <, %q:\n%s", m.statusMsg, got)
+	}
+	m = pressKeys(m, runeKey('<'), runeKey('<'))
+	if got := preview(); !strings.Contains(got, `"deps": {…}  1 key`) || m.previewDepth != 1 {
+		t.Errorf("folded to the top (depth %d):\n%s", m.previewDepth, got)
+	}
+	m = pressKeys(m, runeKey('>'), runeKey('>'))
+	if m.previewDepth != 0 || !strings.Contains(preview(), `"v": 1`) {
+		t.Errorf("> didn't unfold everything: depth %d", m.previewDepth)
+	}
+
+	m = pressKeys(m, runeKey('m'))
+	if got := preview(); !strings.HasSuffix(got, `{"name":"scout","deps":{"tea":{"v":1}}}`) {
+		t.Errorf("source:\n%s", got)
+	}
+	m = pressKeys(m, runeKey('m'))
+
+	// Broken files preview like any other text file
+	os.WriteFile(filepath.Join(m.currentDir, "app.json"), []byte(`{"name":`), 0o644)
+	if got := preview(); !strings.HasSuffix(got, "\n\n{\"name\":") || strings.Contains(got, "───") {
+		t.Errorf("broken JSON:\n%s", got)
+	}
+	m = pressKeys(m, runeKey('<'))
+	if m.statusMsg != "nothing to fold" {
+		t.Errorf("< on a broken file: %q", m.statusMsg)
+	}
+}
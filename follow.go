@@ -0,0 +1,281 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/LFroesch/scout/internal/bigfile"
+	"github.com/LFroesch/scout/internal/search"
+	"github.com/LFroesch/scout/internal/utils"
+)
+
+// Follow: F puts the preview of a text file into follow mode, like tail -f. It shows the
+// file's last followBacklog lines and, every followInterval, reads whatever was appended
+// since, pinned to the end unless scrolled up. A truncated file is read again from the
+// top and a replaced one (log rotation) is reopened by name. Lines matching the pattern
+// given when starting are highlighted. Moving off the file, or F again, stops following.
+
+const (
+	followInterval = 500 * time.Millisecond
+	followBacklog  = 200     // Lines shown from before following started
+	followKeep     = 2000    // Lines kept; older ones scroll away
+	followReadMax  = 1 << 20 // Most bytes read per tick: beyond that the start is skipped
+)
+
+var followMarkStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Bold(true)
+
+// followTickMsg asks the follow with the same id to check its file for new lines
+type followTickMsg struct{ id int }
+
+func followTick(id int) tea.Cmd {
+	return tea.Tick(followInterval, func(t time.Time) tea.Msg {
+		return followTickMsg{id: id}
+	})
+}
+
+// followLine is a line of the followed file, or a note about what happened to it
+type followLine struct {
+	text string
+	note bool
+}
+
+// followState is the file the preview follows
+type followState struct {
+	id      int
+	path    string
+	file    *os.File
+	offset  int64 // Read up to here
+	lines   []followLine
+	partial []byte         // Last line, until its newline is written
+	matcher search.Matcher // Highlights matching lines, nil for none
+	query   string
+	pinned  bool // Scrolled to the end: new lines keep it there
+}
+
+// openFollowDialog asks for the pattern to highlight before following the file under
+// the cursor, or stops following if the preview already is (F)
+func (m *model) openFollowDialog() tea.Cmd {
+	if m.follow != nil {
+		m.stopFollow()
+		m.updatePreview()
+		m.statusMsg = "stopped following"
+		m.statusExpiry = time.Now().Add(2 * time.Second)
+		return nil
+	}
+	if !m.showPreview || len(m.filteredFiles) == 0 || m.cursor >= len(m.filteredFiles) {
+		return nil
+	}
+	selected := m.filteredFiles[m.cursor]
+	if selected.isDir || selected.name == ".." || !utils.IsTextPreviewable(selected.path) || showsHex(selected.path) {
+		m.statusMsg = "follow needs a text file"
+		m.statusExpiry = time.Now().Add(2 * time.Second)
+		return nil
+	}
+	m.previousMode = m.mode
+	m.mode = modeFollowPattern
+	m.textInput.SetValue("")
+	m.textInput.Placeholder = "pattern to highlight, or empty"
+	m.textInput.Focus()
+	return textinput.Blink
+}
+
+// startFollow follows the file under the cursor, highlighting lines that match pattern
+func (m *model) startFollow(pattern string) tea.Cmd {
+	if len(m.filteredFiles) == 0 || m.cursor >= len(m.filteredFiles) {
+		return nil
+	}
+	path := m.filteredFiles[m.cursor].path
+
+	var matcher search.Matcher
+	if pattern = strings.TrimSpace(pattern); pattern != "" {
+		var err error
+		if matcher, err = search.NewMatcher(search.MatchRegex, pattern); err != nil {
+			m.statusMsg = fmt.Sprintf("invalid pattern: %v", err)
+			m.statusExpiry = time.Now().Add(3 * time.Second)
+			return nil
+		}
+	}
+
+	// Start followBacklog lines before the end
+	b, err := bigfile.Open(path)
+	if err != nil {
+		m.showError("CANNOT FOLLOW FILE", err.Error())
+		return nil
+	}
+	size := b.Size()
+	_, start, err := b.ReadBefore(size, followBacklog)
+	b.Close()
+	if err != nil {
+		m.showError("CANNOT FOLLOW FILE", err.Error())
+		return nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		m.showError("CANNOT FOLLOW FILE", err.Error())
+		return nil
+	}
+
+	m.stopFollow()
+	m.closeLargePreview()
+	m.followTicks++
+	fs := &followState{id: m.followTicks, path: path, file: f, offset: start, matcher: matcher, query: pattern, pinned: true}
+	fs.read(size)
+	m.follow = fs
+	m.updatePreview()
+	return followTick(fs.id)
+}
+
+// stopFollow closes the followed file; its tick loop ends at the next tick
+func (m *model) stopFollow() {
+	if m.follow != nil {
+		m.follow.file.Close()
+		m.follow = nil
+	}
+}
+
+// handleFollowTick reads what was written to the followed file since the last tick,
+// reopening it if it was replaced and starting over if it was truncated
+func (m *model) handleFollowTick(msg followTickMsg) tea.Cmd {
+	fs := m.follow
+	if fs == nil || fs.id != msg.id {
+		return nil // Stopped, or superseded by another follow
+	}
+	if m.cursor >= len(m.filteredFiles) || m.filteredFiles[m.cursor].path != fs.path {
+		m.stopFollow()
+		return nil
+	}
+
+	info, err := os.Stat(fs.path)
+	if err != nil {
+		return followTick(fs.id) // Gone for now: rotation may be halfway through
+	}
+	from := fs.offset
+	changed := false
+	if cur, statErr := fs.file.Stat(); statErr != nil || !os.SameFile(cur, info) {
+		f, err := os.Open(fs.path)
+		if err != nil {
+			return followTick(fs.id)
+		}
+		// Take what was written to the old file before it was replaced
+		if statErr == nil {
+			fs.read(cur.Size())
+		}
+		fs.file.Close()
+		fs.file, fs.offset = f, 0
+		fs.note("file replaced")
+		changed = true
+	} else if info.Size() < fs.offset {
+		fs.offset = 0
+		fs.note("file truncated")
+		changed = true
+	}
+	if info.Size() > fs.offset {
+		fs.read(info.Size())
+	}
+
+	if changed || fs.offset != from {
+		m.updatePreview()
+	}
+	return followTick(fs.id)
+}
+
+// read takes the bytes written up to size, splitting off the lines they finish
+func (fs *followState) read(size int64) {
+	skipped := false
+	if size-fs.offset > followReadMax {
+		fs.note(fmt.Sprintf("skipped %s", utils.FormatFileSize(size-followReadMax-fs.offset)))
+		fs.offset = size - followReadMax
+		skipped = true
+	}
+	buf := make([]byte, max(size-fs.offset, 0))
+	n, _ := fs.file.ReadAt(buf, fs.offset)
+	fs.offset += int64(n)
+	data := buf[:n]
+	if skipped {
+		// The first line read is missing its start
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			return
+		}
+		data = data[i+1:]
+	}
+
+	data = append(fs.partial, data...)
+	for i := bytes.IndexByte(data, '\n'); i >= 0; i = bytes.IndexByte(data, '\n') {
+		fs.lines = append(fs.lines, followLine{text: followText(data[:i])})
+		data = data[i+1:]
+	}
+	// A line that never ends is kept just long enough to be shown cut
+	fs.partial = append([]byte(nil), data[:min(len(data), bigfile.MaxLine+1)]...)
+
+	if len(fs.lines) > followKeep {
+		fs.lines = append([]followLine(nil), fs.lines[len(fs.lines)-followKeep:]...)
+	}
+}
+
+// note ends the line being written and adds a note about the file after it
+func (fs *followState) note(text string) {
+	if len(fs.partial) > 0 {
+		fs.lines = append(fs.lines, followLine{text: followText(fs.partial)})
+		fs.partial = nil
+	}
+	fs.lines = append(fs.lines, followLine{text: text, note: true})
+}
+
+// followText turns a line's bytes into text the way large file previews do
+func followText(line []byte) string {
+	cut := len(line) > bigfile.MaxLine
+	s := strings.ToValidUTF8(strings.TrimSuffix(string(line[:min(len(line), bigfile.MaxLine)]), "\r"), "�")
+	if cut {
+		s += "…"
+	}
+	return s
+}
+
+// followPreview adds the followed lines to the preview, highlighting the matches
+func (m *model) followPreview(preview *strings.Builder) {
+	fs := m.follow
+	lines := make([]string, 0, len(fs.lines)+1)
+	matches := 0
+	for _, line := range fs.lines {
+		if line.note {
+			lines = append(lines, lineNumberStyle.Render("── "+line.text+" ──"))
+			continue
+		}
+		text, ok := fs.highlight(line.text)
+		if ok {
+			matches++
+		}
+		lines = append(lines, text)
+	}
+	if len(fs.partial) > 0 {
+		text, _ := fs.highlight(followText(fs.partial))
+		lines = append(lines, text)
+	}
+
+	header := "following"
+	if fs.matcher != nil {
+		header += fmt.Sprintf(" · %d matching %q", matches, fs.query)
+	}
+	preview.WriteString(lineNumberStyle.Render("─── "+header+" · F: stop ───") + "\n")
+	preview.WriteString(strings.Join(lines, "\n"))
+}
+
+// highlight marks the pattern's matches in line, and whether there were any
+func (fs *followState) highlight(line string) (string, bool) {
+	if fs.matcher == nil {
+		return line, false
+	}
+	_, positions, ok := fs.matcher.Match(line)
+	if !ok {
+		return "  " + line, false
+	}
+	return followMarkStyle.Render("▌") + " " + utils.HighlightMatches(line, positions), true
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	xansi "github.com/charmbracelet/x/ansi"
+)
+
+func TestFollowTailsGrowingFile(t *testing.T) {
+	m := selectionTestModel(t, "other.txt")
+	path := filepath.Join(m.currentDir, "app.log")
+	var log strings.Builder
+	for i := 1; i <= 300; i++ {
+		fmt.Fprintf(&log, "INFO boot %d\n", i)
+	}
+	os.WriteFile(path, []byte(log.String()), 0o644)
+	m.loadFiles()
+	for i, f := range m.filteredFiles {
+		if f.name == "app.log" {
+			m.cursor = i
+		}
+	}
+	m.updatePreview()
+
+	m = pressKeys(m, runeKey('F'))
+	if m.mode != modeFollowPattern {
+		t.Fatalf("F opened mode %v", m.mode)
+	}
+	m.textInput.SetValue("ERROR")
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = *updated.(*model)
+	fs := m.follow
+	if m.mode != modeNormal || fs == nil || cmd == nil {
+		t.Fatalf("enter didn't start following (mode %v)", m.mode)
+	}
+	got := xansi.Strip(m.previewContent)
+	if !strings.Contains(got, `following · 0 matching "ERROR"`) || strings.Contains(got, "boot 100\n") || !strings.HasSuffix(got, "boot 300") {
+		t.Fatalf("start of the follow:\n%s", got[len(got)-300:])
+	}
+	if m.previewScroll != m.previewMaxScroll() {
+		t.Errorf("not pinned to the end: scroll %d of %d", m.previewScroll, m.previewMaxScroll())
+	}
+
+	tick := func() {
+		t.Helper()
+		updated, cmd := m.Update(followTickMsg{id: fs.id})
+		m = *updated.(*model)
+		if cmd == nil {
+			t.Fatal("follow stopped ticking")
+		}
+	}
+
+	// Appended lines show up at the end, matches highlighted
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	f.WriteString("ERROR disk full\nINFO half a li")
+	f.Close()
+	tick()
+	got = xansi.Strip(m.previewContent)
+	if !strings.Contains(got, `1 matching "ERROR"`) || !strings.HasSuffix(got, "▌ ERROR disk full\n  INFO half a li") {
+		t.Errorf("after appending:\n%s", got[len(got)-200:])
+	}
+	if m.previewScroll != m.previewMaxScroll() {
+		t.Error("new lines didn't keep the preview at the end")
+	}
+
+	// Scrolled up, it stays put as lines come in
+	m = pressKeys(m, runeKey('w'))
+	scroll := m.previewScroll
+	f, _ = os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	f.WriteString("ne\nINFO more\n")
+	f.Close()
+	tick()
+	if m.previewScroll != scroll || fs.pinned {
+		t.Errorf("scrolled-up preview moved from %d to %d", scroll, m.previewScroll)
+	}
+	if got := xansi.Strip(m.previewContent); !strings.Contains(got, "INFO half a line\n  INFO more") {
+		t.Errorf("finished line:\n%s", got[len(got)-200:])
+	}
+	m = pressKeys(m, runeKey('t'))
+	if !fs.pinned {
+		t.Error("t didn't pin the preview to the end again")
+	}
+
+	// Truncated: read again from the top
+	os.WriteFile(path, []byte("ERROR fresh\n"), 0o644)
+	tick()
+	if got := xansi.Strip(m.previewContent); !strings.HasSuffix(got, "── file truncated ──\n▌ ERROR fresh") {
+		t.Errorf("after truncating:\n%s", got[len(got)-200:])
+	}
+
+	// Rotated: the rest of the old file, then the new one
+	f, _ = os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	f.WriteString("INFO last words\n")
+	f.Close()
+	os.Rename(path, path+".1")
+	os.WriteFile(path, []byte("INFO new file\n"), 0o644)
+	tick()
+	if got := xansi.Strip(m.previewContent); !strings.HasSuffix(got, "INFO last words\n── file replaced ──\n  INFO new file") {
+		t.Errorf("after rotating:\n%s", got[len(got)-200:])
+	}
+
+	// Moving off the file stops following
+	m.loadFiles()
+	for i, f := range m.filteredFiles {
+		if f.name == "other.txt" {
+			m.cursor = i
+		}
+	}
+	m.updatePreview()
+	if m.follow != nil || fs.file.Close() == nil {
+		t.Error("still following after moving off the file")
+	}
+	if _, cmd := m.Update(followTickMsg{id: fs.id}); cmd != nil {
+		t.Error("stale tick kept ticking")
+	}
+}
+
+func TestFollowNeedsTextAndValidPattern(t *testing.T) {
+	m := selectionTestModel(t, "notes.txt")
+	os.WriteFile(filepath.Join(m.currentDir, "blob.bin"), []byte("\x7fELF\x00\x00\x01"), 0o644)
+	m.loadFiles()
+	pick := func(name string) {
+		for i, f := range m.filteredFiles {
+			if f.name == name {
+				m.cursor = i
+			}
+		}
+	}
+
+	pick("blob.bin")
+	m = pressKeys(m, runeKey('F'))
+	if m.mode != modeNormal || m.statusMsg != "follow needs a text file" {
+		t.Errorf("F on a binary: mode %v, status %q", m.mode, m.statusMsg)
+	}
+
+	pick("notes.txt")
+	m = pressKeys(m, runeKey('F'))
+	m.textInput.SetValue("(")
+	m = pressKeys(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.follow != nil || !strings.HasPrefix(m.statusMsg, "invalid pattern") {
+		t.Errorf("bad pattern: following %v, status %q", m.follow != nil, m.statusMsg)
+	}
+
+	// F while following stops
+	m = pressKeys(m, runeKey('F'))
+	m = pressKeys(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.follow == nil {
+		t.Fatal("empty pattern didn't follow")
+	}
+	m = pressKeys(m, runeKey('F'))
+	if m.follow != nil || m.mode != modeNormal {
+		t.Errorf("F again: following %v, mode %v", m.follow != nil, m.mode)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	xansi "github.com/charmbracelet/x/ansi"
@@ -42,6 +43,18 @@ func editorCommand(editor, path string, line int) *exec.Cmd {
 	return exec.Command(editor, path)
 }
 
+// waitEditorCommand builds an editor command that doesn't return until the file is closed,
+// for edits scout reads back (bulk rename). GUI editors need a flag to wait for the window.
+func waitEditorCommand(editor, path string) *exec.Cmd {
+	switch editor {
+	case "code", "cursor", "codium", "zed":
+		return exec.Command(editor, "--wait", path)
+	case "subl":
+		return exec.Command(editor, "-w", path)
+	}
+	return exec.Command(editor, path)
+}
+
 func (m *model) editorList() []string {
 	var editors []string
 	if v := os.Getenv("VISUAL"); v != "" {
@@ -72,10 +85,23 @@ func (m *model) execEditor(path string, line int) tea.Cmd {
 		m.statusExpiry = time.Now().Add(3 * time.Second)
 		return nil
 	}
+	return m.runEditor(editor, editorCommand(editor, path, line), path, filepath.Base(path))
+}
 
-	cmd := editorCommand(editor, path, line)
-	filename := filepath.Base(path)
+// execEditorFiles opens several files in one editor invocation (e.g. vim's buffer list)
+func (m *model) execEditorFiles(paths []string) tea.Cmd {
+	editor, found := m.findEditor()
+	if !found {
+		m.statusMsg = "no editor found in path"
+		m.statusExpiry = time.Now().Add(3 * time.Second)
+		return nil
+	}
+	return m.runEditor(editor, exec.Command(editor, paths...), paths[0], fmt.Sprintf("%d files", len(paths)))
+}
 
+// runEditor runs an editor command built by execEditor/execEditorFiles; label names what's
+// being opened in status messages
+func (m *model) runEditor(editor string, cmd *exec.Cmd, path, label string) tea.Cmd {
 	if isTerminalEditor(editor) {
 		return tea.ExecProcess(cmd, func(err error) tea.Msg {
 			if err != nil {
@@ -96,11 +122,11 @@ func (m *model) execEditor(path string, line int) tea.Cmd {
 	// GUI editor — launch in background
 	err := cmd.Start()
 	if err != nil {
-		m.statusMsg = fmt.Sprintf("Can't open %s via scout", filename)
+		m.statusMsg = fmt.Sprintf("Can't open %s via scout", label)
 		m.statusExpiry = time.Now().Add(3 * time.Second)
 		return nil
 	}
-	m.statusMsg = fmt.Sprintf("opening %s in %s", filename, editor)
+	m.statusMsg = fmt.Sprintf("opening %s in %s", label, editor)
 	m.statusExpiry = time.Now().Add(2 * time.Second)
 	return nil
 }
@@ -158,6 +184,23 @@ func (m *model) ctrlGTargetDir(selected fileItem) string {
 	return filepath.Dir(selected.path)
 }
 
+// enterSearchMode switches to search mode in current-directory filename mode with a fresh query
+func (m *model) enterSearchMode() tea.Cmd {
+	m.mode = modeSearch
+	m.currentSearchType = searchFilename
+	m.recursiveSearch = false     // Always start in current directory mode
+	m.searchResultsLocked = false // Clear any locked results
+	m.searchInput.SetValue("")
+	m.searchInput.Placeholder = "Search..."
+	m.searchInput.Focus()
+	// Clear any previous search results
+	m.filteredFiles = m.files
+	m.searchMatches = [][]int{}
+	m.cursor = 0
+	m.scrollOffset = 0
+	return textinput.Blink
+}
+
 // ensureCursorInBounds ensures cursor is within valid range and adjusts scroll to keep it visible
 func (m *model) ensureCursorInBounds() {
 	// Early return if no files
@@ -267,3 +310,31 @@ func placeOverlay(bg, fg string) string {
 
 	return strings.Join(result, "\n")
 }
+
+// formatCount renders a count compactly: 950, 12.3k, 1.2M
+func formatCount(n int) string {
+	switch {
+	case n >= 1000000:
+		return fmt.Sprintf("%.1fM", float64(n)/1000000)
+	case n >= 1000:
+		return fmt.Sprintf("%.1fk", float64(n)/1000)
+	default:
+		return fmt.Sprintf("%d", n)
+	}
+}
+
+// formatAge renders a duration as a short age: now, 45s, 3m, 2h, 5d
+func formatAge(d time.Duration) string {
+	switch {
+	case d < 5*time.Second:
+		return "now"
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
@@ -1,6 +1,9 @@
 package main
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 func TestCtrlGTargetDir(t *testing.T) {
 	m := model{currentDir: "/tmp/project"}
@@ -35,3 +38,25 @@ func TestCtrlGTargetDir(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatCountAndAge(t *testing.T) {
+	counts := map[int]string{950: "950", 12345: "12.3k", 1200000: "1.2M"}
+	for n, want := range counts {
+		if got := formatCount(n); got != want {
+			t.Errorf("formatCount(%d) = %q, want %q", n, got, want)
+		}
+	}
+
+	ages := map[time.Duration]string{
+		2 * time.Second:  "now",
+		45 * time.Second: "45s",
+		3 * time.Minute:  "3m",
+		2 * time.Hour:    "2h",
+		72 * time.Hour:   "3d",
+	}
+	for d, want := range ages {
+		if got := formatAge(d); got != want {
+			t.Errorf("formatAge(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
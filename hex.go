@@ -0,0 +1,308 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/LFroesch/scout/internal/hexview"
+	"github.com/LFroesch/scout/internal/utils"
+)
+
+// Hex: binaries (executables, databases, anything with unknown bytes) preview as a hex
+// dump of their first hexPreviewBytes, and H opens any file in a full-screen hex viewer.
+// The viewer keeps the file open and reads just the rows on screen with ReadAt, so it
+// pages through files of any size; : jumps to an offset and / searches for bytes in the
+// background.
+
+// hexPreviewBytes is how much of a binary the preview dumps
+const hexPreviewBytes = 4 << 10
+
+// hexFoundMsg delivers the result of a byte search, run in the background
+type hexFoundMsg struct {
+	cancel chan struct{} // Identifies the search: the viewer's cancel while it runs
+	at     int64         // Offset of the match, -1 for none
+	err    error
+}
+
+// findHex searches f for pattern from an offset, forward or backward
+func findHex(f *os.File, size int64, pattern []byte, from int64, backward bool, cancel chan struct{}) tea.Cmd {
+	return func() tea.Msg {
+		at, err := hexview.Find(f, size, pattern, from, backward, cancel)
+		return hexFoundMsg{cancel: cancel, at: at, err: err}
+	}
+}
+
+// hexViewer is the full-screen hex view of one file
+type hexViewer struct {
+	path    string
+	file    *os.File
+	size    int64
+	offset  int64  // First byte on screen, a multiple of the row width
+	prompt  string // "goto" or "search" while one is being typed
+	pattern []byte // Last search, for n and N
+	query   string // pattern as typed, for messages
+	mark    int64  // First highlighted byte: the last match, or the byte jumped to (-1: none)
+	markLen int
+	cancel  chan struct{} // Closed to stop the search running, nil when there's none
+}
+
+// showsHex reports whether path previews as a hex dump: executables, databases, and files
+// of unknown type that turn out to be binary
+func showsHex(path string) bool {
+	switch utils.GetFileType(path) {
+	case utils.FileTypeExecutable, utils.FileTypeDatabase:
+		return true
+	case utils.FileTypeUnknown:
+		return !utils.IsTextPreviewable(path)
+	}
+	return false
+}
+
+// hexPreview adds the file's type and a dump of its first bytes to the preview
+func (m *model) hexPreview(path string, info os.FileInfo, preview *strings.Builder) {
+	ext := strings.ToUpper(strings.TrimPrefix(filepath.Ext(path), "."))
+	preview.WriteString("─── File Metadata ───\n\n")
+	switch utils.GetFileType(path) {
+	case utils.FileTypeExecutable:
+		preview.WriteString("Type: Executable/Compiled Binary\n")
+	case utils.FileTypeDatabase:
+		preview.WriteString(fmt.Sprintf("Type: Database (%s)\n", ext))
+	case utils.FileTypeUnknown:
+		if ext == "" {
+			preview.WriteString("Type: Binary\n")
+		} else {
+			preview.WriteString(fmt.Sprintf("Type: Binary (%s)\n", ext))
+		}
+	}
+	preview.WriteString(fmt.Sprintf("Full Path: %s\n\n", path))
+
+	f, err := os.Open(path)
+	if err != nil {
+		preview.WriteString(fmt.Sprintf("Error reading file: %v", err))
+		return
+	}
+	defer f.Close()
+	buf := make([]byte, min(info.Size(), hexPreviewBytes))
+	n, err := f.ReadAt(buf, 0)
+	if n == 0 && err != nil {
+		preview.WriteString(fmt.Sprintf("Error reading file: %v", err))
+		return
+	}
+
+	preview.WriteString("─── Hex ───\n\n")
+	preview.WriteString(strings.Join(hexview.Dump(buf[:n], 0, hexview.PerRow(m.width/2-6)), "\n"))
+	if int64(n) < info.Size() {
+		preview.WriteString(fmt.Sprintf("\n\n(First %s shown - H opens the hex viewer)", utils.FormatFileSize(int64(n))))
+	}
+}
+
+// openHexViewer opens the full-screen hex view on the file under the cursor
+func (m *model) openHexViewer() {
+	if len(m.filteredFiles) == 0 || m.cursor >= len(m.filteredFiles) {
+		return
+	}
+	selected := m.filteredFiles[m.cursor]
+	if selected.isDir {
+		m.statusMsg = "hex view needs a file"
+		m.statusExpiry = time.Now().Add(2 * time.Second)
+		return
+	}
+	f, err := os.Open(selected.path)
+	if err != nil {
+		m.showError("CANNOT OPEN FILE", err.Error())
+		return
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		m.showError("CANNOT OPEN FILE", err.Error())
+		return
+	}
+	m.commitVisual()
+	m.hexViewer = &hexViewer{path: selected.path, file: f, size: info.Size(), mark: -1}
+	m.mode = modeHex
+}
+
+// closeHexViewer leaves the viewer, stopping any search and closing the file
+func (m *model) closeHexViewer() {
+	if v := m.hexViewer; v != nil {
+		v.stopSearch()
+		v.file.Close()
+	}
+	m.hexViewer = nil
+	m.mode = modeNormal
+	m.textInput.SetValue("")
+}
+
+// hexLayout is the viewer's bytes per row and rows on screen
+func (m *model) hexLayout() (perRow, rows int) {
+	availableHeight := max(m.height-uiOverhead, 3)
+	return hexview.PerRow(m.width - 6), max(availableHeight-1, 1)
+}
+
+// scrollTo moves the view to offset, keeping it on a row boundary and the last page full
+func (v *hexViewer) scrollTo(offset int64, perRow, rows int) {
+	row := int64(perRow)
+	last := max((v.size+row-1)/row-int64(rows), 0) * row
+	v.offset = max(0, min(offset/row*row, last))
+}
+
+// stopSearch cancels the search running, if any
+func (v *hexViewer) stopSearch() {
+	if v.cancel != nil {
+		close(v.cancel)
+		v.cancel = nil
+	}
+}
+
+// search starts looking for the last pattern after the mark (before it, backward), or
+// from the top of the screen when nothing's marked
+func (v *hexViewer) search(backward bool) tea.Cmd {
+	from := v.offset
+	if v.mark >= 0 {
+		from = v.mark
+		if !backward {
+			from++
+		}
+	}
+	v.stopSearch()
+	v.cancel = make(chan struct{})
+	return findHex(v.file, v.size, v.pattern, from, backward, v.cancel)
+}
+
+// handleHexKey handles keys while the hex viewer is open
+func (m *model) handleHexKey(msg tea.KeyMsg) tea.Cmd {
+	v := m.hexViewer
+	perRow, rows := m.hexLayout()
+
+	if v.prompt != "" {
+		switch msg.String() {
+		case "esc", "ctrl+c":
+			v.prompt = ""
+			m.textInput.SetValue("")
+		case "enter":
+			return m.submitHexPrompt(perRow, rows)
+		default:
+			var cmd tea.Cmd
+			m.textInput, cmd = m.textInput.Update(msg)
+			return cmd
+		}
+		return nil
+	}
+
+	switch msg.String() {
+	case "esc":
+		if v.cancel != nil {
+			v.stopSearch()
+			m.statusMsg = "search canceled"
+			m.statusExpiry = time.Now().Add(2 * time.Second)
+			break
+		}
+		m.closeHexViewer()
+	case "q":
+		m.closeHexViewer()
+	case "j", "down":
+		v.scrollTo(v.offset+int64(perRow), perRow, rows)
+	case "k", "up":
+		v.scrollTo(v.offset-int64(perRow), perRow, rows)
+	case "ctrl+d":
+		v.scrollTo(v.offset+int64(perRow*(rows/2)), perRow, rows)
+	case "ctrl+u":
+		v.scrollTo(v.offset-int64(perRow*(rows/2)), perRow, rows)
+	case "ctrl+f", "pgdown", " ":
+		v.scrollTo(v.offset+int64(perRow*rows), perRow, rows)
+	case "ctrl+b", "pgup":
+		v.scrollTo(v.offset-int64(perRow*rows), perRow, rows)
+	case "g", "home":
+		v.scrollTo(0, perRow, rows)
+	case "G", "end":
+		v.scrollTo(v.size, perRow, rows)
+	case ":":
+		v.prompt = "goto"
+		m.textInput.SetValue("")
+		m.textInput.Placeholder = "offset: 0x1f40, 8000, 50%, +0x100"
+		m.textInput.Focus()
+		return textinput.Blink
+	case "/":
+		v.prompt = "search"
+		m.textInput.SetValue("")
+		m.textInput.Placeholder = `bytes: 7f 45 4c 46, or "text"`
+		m.textInput.Focus()
+		return textinput.Blink
+	case "n", "N":
+		if v.pattern == nil {
+			m.statusMsg = "no search yet: / to search"
+			m.statusExpiry = time.Now().Add(2 * time.Second)
+			return nil
+		}
+		return v.search(msg.String() == "N")
+	}
+	return nil
+}
+
+// submitHexPrompt jumps to the offset or starts the search typed in the prompt
+func (m *model) submitHexPrompt(perRow, rows int) tea.Cmd {
+	v := m.hexViewer
+	input := strings.TrimSpace(m.textInput.Value())
+	prompt := v.prompt
+	v.prompt = ""
+	m.textInput.SetValue("")
+	if input == "" {
+		return nil
+	}
+
+	if prompt == "goto" {
+		offset, err := hexview.ParseOffset(input, v.offset, v.size)
+		if err != nil {
+			m.statusMsg = err.Error()
+			m.statusExpiry = time.Now().Add(3 * time.Second)
+			return nil
+		}
+		v.mark, v.markLen = offset, 1
+		v.scrollTo(offset, perRow, rows)
+		return nil
+	}
+
+	pattern, err := hexview.ParsePattern(input)
+	if err != nil {
+		m.statusMsg = err.Error()
+		m.statusExpiry = time.Now().Add(3 * time.Second)
+		return nil
+	}
+	v.pattern, v.query = pattern, input
+	v.mark = -1 // A new search starts from the top of the screen
+	return v.search(false)
+}
+
+// handleHexFound shows a finished search's match, if it's still the viewer's search
+func (m *model) handleHexFound(msg hexFoundMsg) {
+	v := m.hexViewer
+	if v == nil || v.cancel != msg.cancel {
+		return // Closed, canceled, or superseded by another search
+	}
+	v.cancel = nil
+	switch {
+	case msg.err != nil:
+		m.statusMsg = fmt.Sprintf("search failed: %v", msg.err)
+		m.statusExpiry = time.Now().Add(3 * time.Second)
+	case msg.at < 0:
+		m.statusMsg = "not found: " + v.query
+		m.statusExpiry = time.Now().Add(2 * time.Second)
+	default:
+		v.mark, v.markLen = msg.at, len(v.pattern)
+		perRow, rows := m.hexLayout()
+		if msg.at < v.offset || msg.at >= v.offset+int64(perRow*rows) {
+			// Off screen: bring it a third of the way down
+			v.scrollTo(msg.at-int64(perRow*(rows/3)), perRow, rows)
+		}
+		m.statusMsg = "found at 0x" + strconv.FormatInt(msg.at, 16)
+		m.statusExpiry = time.Now().Add(2 * time.Second)
+	}
+}
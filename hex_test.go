@@ -0,0 +1,122 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	xansi "github.com/charmbracelet/x/ansi"
+)
+
+func TestPreviewDumpsBinariesInHex(t *testing.T) {
+	dir := t.TempDir()
+	m := testModelForUpdate(t, dir)
+
+	// Big binaries are dumped too: only the start is read
+	data := make([]byte, 2<<20)
+	copy(data, "\x7fELF")
+	path := filepath.Join(dir, "blob.bin")
+	os.WriteFile(path, data, 0o644)
+
+	got := m.previewFile(path)
+	if !strings.Contains(got, "00000000  7f 45 4c 46 00 00 00 00  |.ELF....|") {
+		t.Fatalf("no hex dump in the preview:\n%s", got)
+	}
+	if !strings.Contains(got, "00000ff8  ") || strings.Contains(got, "00001000  ") {
+		t.Error("preview didn't stop after the first 4 KB")
+	}
+	if !strings.Contains(got, "H opens the hex viewer") || strings.Contains(got, "too large") {
+		t.Errorf("preview footer:\n%s", got[len(got)-200:])
+	}
+
+	// Text with an unknown extension still previews as text
+	os.WriteFile(filepath.Join(dir, "notes.xyz"), []byte("plain words\n"), 0o644)
+	if got := m.previewFile(filepath.Join(dir, "notes.xyz")); strings.Contains(got, "00000000") {
+		t.Errorf("text dumped as hex:\n%s", got)
+	}
+}
+
+func TestHexViewerPagesJumpsAndSearches(t *testing.T) {
+	m := selectionTestModel(t)
+	data := make([]byte, 1<<20)
+	copy(data[0x80000:], "needle")
+	copy(data[0x90000:], "needle")
+	path := filepath.Join(m.currentDir, "big.dat")
+	os.WriteFile(path, data, 0o644)
+	m.loadFiles()
+	for i, f := range m.filteredFiles {
+		if f.name == "big.dat" {
+			m.cursor = i
+		}
+	}
+
+	m = pressKeys(m, runeKey('H'))
+	if m.mode != modeHex || m.hexViewer == nil {
+		t.Fatalf("H opened mode %v", m.mode)
+	}
+	v := m.hexViewer
+	perRow, rows := m.hexLayout()
+
+	m = pressKeys(m, runeKey('j'), runeKey(' '))
+	if want := int64(perRow * (rows + 1)); v.offset != want {
+		t.Errorf("after j and space, offset %#x, want %#x", v.offset, want)
+	}
+	m = pressKeys(m, runeKey('G'))
+	if want := int64(len(data) - perRow*rows); v.offset != want {
+		t.Errorf("G went to %#x, want %#x", v.offset, want)
+	}
+
+	// : jumps to an offset and highlights the byte there
+	m = pressKeys(m, runeKey(':'))
+	m.textInput.SetValue("0x40005")
+	m = pressKeys(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if v.offset != 0x40000 || v.mark != 0x40005 {
+		t.Errorf("goto: offset %#x, mark %#x", v.offset, v.mark)
+	}
+	if view := xansi.Strip(m.renderHexView()); !strings.Contains(view, "00040000  00 00") {
+		t.Errorf("view doesn't show the jump target:\n%s", view)
+	}
+
+	// / searches in the background; n finds the next match after the last
+	search := func(key tea.KeyMsg) {
+		t.Helper()
+		updated, cmd := m.Update(key)
+		m = *updated.(*model)
+		if cmd == nil {
+			t.Fatal("no search started")
+		}
+		updated, _ = m.Update(cmd())
+		m = *updated.(*model)
+	}
+	m = pressKeys(m, runeKey('/'))
+	m.textInput.SetValue(`"needle"`)
+	search(tea.KeyMsg{Type: tea.KeyEnter})
+	if v.mark != 0x80000 || v.markLen != 6 || v.cancel != nil {
+		t.Fatalf("search: mark %#x, len %d", v.mark, v.markLen)
+	}
+	if v.mark < v.offset || v.mark >= v.offset+int64(perRow*rows) {
+		t.Errorf("match %#x not on screen at %#x", v.mark, v.offset)
+	}
+	search(runeKey('n'))
+	if v.mark != 0x90000 {
+		t.Errorf("n found %#x", v.mark)
+	}
+	search(runeKey('n'))
+	if v.mark != 0x90000 || !strings.HasPrefix(m.statusMsg, "not found") {
+		t.Errorf("past the last match: mark %#x, status %q", v.mark, m.statusMsg)
+	}
+	search(runeKey('N'))
+	if v.mark != 0x80000 {
+		t.Errorf("N found %#x", v.mark)
+	}
+
+	m = pressKeys(m, runeKey('q'))
+	if m.mode != modeNormal || m.hexViewer != nil {
+		t.Errorf("q left mode %v", m.mode)
+	}
+	if _, err := v.file.Stat(); err == nil {
+		t.Error("file left open")
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/LFroesch/scout/internal/fileops"
+	"github.com/LFroesch/scout/internal/jobs"
+	"github.com/LFroesch/scout/internal/logger"
+)
+
+// Undo/redo: every file operation is recorded in the fileops.Journal, which stores how
+// to reverse it and is saved to ~/.config/scout/history.json, so u can still take back
+// yesterday's move. U shows the history; ctrl+r redoes what was undone.
+
+// record adds a finished operation to the journal
+func (m *model) record(op fileops.Op) {
+	if err := m.journal.Record(op); err != nil {
+		logger.Warn("Cannot save history: %v", err)
+	}
+}
+
+// recordJob journals what a job created: new copies, the items it moved, what an archive
+// was unpacked into or the new archive. Items pasted over something that was already
+// there can't be taken back and are left out.
+func (m *model) recordJob(job jobs.Snapshot) {
+	if len(job.Created) == 0 {
+		return
+	}
+	op := fileops.Op{Kind: fileops.OpCopy}
+	switch job.Kind {
+	case jobs.Move:
+		op.Kind = fileops.OpMove
+	case jobs.Extract:
+		op.Kind = fileops.OpExtract
+	case jobs.Compress:
+		op.Kind = fileops.OpCompress
+	}
+	for _, it := range job.Created {
+		if job.Kind == jobs.Move {
+			op.Moves = append(op.Moves, fileops.RenameOp{From: it.Src, To: it.Dst})
+		} else {
+			op.Created = append(op.Created, it.Dst)
+		}
+	}
+	m.record(op)
+}
+
+// undoLast reverses the most recent operation in the journal
+func (m *model) undoLast() {
+	if !m.journal.CanUndo() {
+		m.statusMsg = "nothing to undo"
+		m.statusExpiry = time.Now().Add(2 * time.Second)
+		return
+	}
+	op, err := m.journal.Undo()
+	if err != nil {
+		m.showError("UNDO FAILED", fmt.Sprintf("could not undo %s: %v\n\nWhat was undone so far stays undone; u tries the rest again.", op.Describe(), err))
+		return
+	}
+	m.statusMsg = "undone: " + op.Describe() + " (ctrl+r to redo)"
+	m.statusExpiry = time.Now().Add(3 * time.Second)
+}
+
+// redoLast carries out the most recently undone operation again
+func (m *model) redoLast() {
+	if !m.journal.CanRedo() {
+		m.statusMsg = "nothing to redo"
+		m.statusExpiry = time.Now().Add(2 * time.Second)
+		return
+	}
+	op, err := m.journal.Redo()
+	if err != nil {
+		m.showError("REDO FAILED", fmt.Sprintf("could not redo %s: %v", op.Describe(), err))
+		return
+	}
+	m.statusMsg = "redone: " + op.Describe()
+	m.statusExpiry = time.Now().Add(3 * time.Second)
+}
+
+// openHistoryPanel shows the journal, newest first, with the cursor on the op u would undo
+func (m *model) openHistoryPanel() {
+	m.mode = modeHistory
+	m.historyCursorAt(m.journal.Applied - 1)
+}
+
+// historyCursorAt puts the history cursor on journal entry i (rows run newest first)
+func (m *model) historyCursorAt(i int) {
+	row := len(m.journal.Ops) - 1 - i
+	m.historyCursor = max(min(row, len(m.journal.Ops)-1), 0)
+}
+
+// handleHistoryKey handles keys while the history panel is open. u and ctrl+r work as
+// usual and move the cursor along with the applied/undone boundary.
+func (m *model) handleHistoryKey(msg tea.KeyMsg) {
+	switch msg.String() {
+	case "esc", "U", "q":
+		m.mode = modeNormal
+		m.refreshPanes()
+	case "j", "down":
+		if m.historyCursor < len(m.journal.Ops)-1 {
+			m.historyCursor++
+		}
+	case "k", "up":
+		if m.historyCursor > 0 {
+			m.historyCursor--
+		}
+	case "u":
+		m.undoLast()
+		m.historyCursorAt(m.journal.Applied) // The op just undone
+	case "ctrl+r":
+		m.redoLast()
+		m.historyCursorAt(m.journal.Applied - 1) // The op just redone
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestUndoRedoPastedMove(t *testing.T) {
+	src := t.TempDir()
+	dest := t.TempDir()
+	writeFiles(t, src, map[string]string{"a.txt": "a"})
+	m := pasteTestModel(t, dest, opCut, filepath.Join(src, "a.txt"))
+
+	m.Update(runeKey('p'))
+	finishJobs(&m)
+	if !m.journal.CanUndo() {
+		t.Fatal("finished move wasn't recorded")
+	}
+
+	m.Update(runeKey('u'))
+	if m.mode != modeNormal {
+		t.Fatalf("undo failed: %s", m.errorDetails)
+	}
+	if got := readFile(t, filepath.Join(src, "a.txt")); got != "a" {
+		t.Errorf("a.txt back in source = %q", got)
+	}
+	if _, err := os.Lstat(filepath.Join(dest, "a.txt")); !os.IsNotExist(err) {
+		t.Error("a.txt still in dest after undo")
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	if m.mode != modeNormal {
+		t.Fatalf("redo failed: %s", m.errorDetails)
+	}
+	if got := readFile(t, filepath.Join(dest, "a.txt")); got != "a" {
+		t.Errorf("a.txt in dest after redo = %q", got)
+	}
+}
+
+func TestUndoRenameFromHistoryPanel(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"old.txt": "x"})
+	m := testModelForUpdate(t, dir)
+	m.mode = modeNormal
+	m.loadFiles()
+
+	m.cursor = 1 // Past ".."
+	m.Update(runeKey('R'))
+	m.textInput.SetValue("new.txt")
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if _, err := os.Stat(filepath.Join(dir, "new.txt")); err != nil {
+		t.Fatalf("rename didn't happen: %v", err)
+	}
+
+	m.Update(runeKey('U'))
+	if m.mode != modeHistory || m.historyCursor != 0 {
+		t.Fatalf("history panel: mode %v, cursor %d", m.mode, m.historyCursor)
+	}
+	m.Update(runeKey('u'))
+	if _, err := os.Stat(filepath.Join(dir, "old.txt")); err != nil {
+		t.Errorf("old.txt not back after undo: %v", err)
+	}
+	if m.mode != modeHistory || m.journal.CanUndo() {
+		t.Errorf("after undo: mode %v, CanUndo %v", m.mode, m.journal.CanUndo())
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.mode != modeNormal {
+		t.Errorf("esc left mode %v", m.mode)
+	}
+}
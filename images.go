@@ -0,0 +1,215 @@
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	xansi "github.com/charmbracelet/x/ansi"
+
+	"github.com/LFroesch/scout/internal/graphics"
+	"github.com/LFroesch/scout/internal/logger"
+)
+
+// imageMarker stands in the preview text where a kitty, iTerm2 or sixel picture goes.
+// Being an escape sequence it takes no room and survives styling and wrapping; View
+// swaps it for the picture (see placeImage).
+const imageMarker = "\x1b_scout-image\x1b\\"
+
+// imagePreview is the picture last rendered for the preview pane
+type imagePreview struct {
+	path    string
+	modTime time.Time
+	box     graphics.Box
+	loading bool // Until its imageLoadedMsg arrives
+	pic     graphics.Picture
+	err     error
+}
+
+// imageLoadedMsg delivers a picture decoded and rendered in the background
+type imageLoadedMsg struct {
+	path    string
+	modTime time.Time
+	box     graphics.Box
+	pic     graphics.Picture
+	err     error
+}
+
+// imageDraw is what placeImage last returned, so an unchanged frame gets the same back
+type imageDraw struct {
+	frame string        // The frame it was given
+	pic   *imagePreview // The picture it drew into it
+	out   string        // The frame with the picture
+	flip  bool          // Which of two equivalent endings the drawing line got
+}
+
+// imageProtocol picks how pictures are drawn: the config's image_protocol, or whatever
+// the terminal looks like it supports
+func imageProtocol(setting string) graphics.Protocol {
+	if p, ok := graphics.ParseProtocol(setting); ok {
+		return p
+	}
+	if setting != "" && setting != "auto" {
+		logger.Warn("Unknown image_protocol %q, detecting the terminal's instead", setting)
+	}
+	p := graphics.Detect(os.Getenv)
+	logger.Info("Image previews use %s", p)
+	return p
+}
+
+// previewImage finishes path's preview header with the image's size and colour model,
+// then adds the picture: half blocks as text, anything else as the marker plus blank
+// rows for it to cover
+func (m *model) previewImage(path string, fi os.FileInfo, preview *strings.Builder) {
+	info, err := graphics.Stat(path)
+	if err != nil {
+		preview.WriteString(fmt.Sprintf("\n(Cannot read image: %v)", err))
+		return
+	}
+	preview.WriteString(fmt.Sprintf("Image: %s, %d × %d px\n", strings.ToUpper(info.Format), info.Width, info.Height))
+	preview.WriteString(fmt.Sprintf("Color model: %s\n", info.ColorModel))
+	preview.WriteString("\n")
+	if m.imageProtocol == graphics.None {
+		return
+	}
+
+	used := len(m.wrapTextToLines(preview.String(), m.width/2-6)) - 1
+	box := m.imageBox(used)
+	if box.Rows < 3 || box.Cols < 4 {
+		preview.WriteString("(Preview too small for the image)")
+		return
+	}
+
+	img := m.imagePreview
+	if img == nil || img.path != path || !img.modTime.Equal(fi.ModTime()) || img.box != box {
+		img = &imagePreview{path: path, modTime: fi.ModTime(), box: box, loading: true}
+		m.imagePreview = img
+		m.imageLoad = loadImage(path, fi.ModTime(), box, m.imageProtocol)
+	}
+
+	switch {
+	case img.loading:
+		preview.WriteString("(Loading image…)")
+	case img.err != nil:
+		preview.WriteString(fmt.Sprintf("(Cannot render image: %v)", img.err))
+	case img.pic.Lines != nil:
+		preview.WriteString(strings.Join(img.pic.Lines, "\n"))
+	case img.pic.Draw != "":
+		preview.WriteString(imageMarker + strings.Repeat("\n", img.pic.Rows-1))
+	default:
+		preview.WriteString("(Terminal has no colours to draw the image with)")
+	}
+}
+
+// loadImage decodes and renders path in the background: a big photo can take long
+// enough to stall the cursor
+func loadImage(path string, modTime time.Time, box graphics.Box, p graphics.Protocol) tea.Cmd {
+	profile := lipgloss.ColorProfile()
+	return func() tea.Msg {
+		msg := imageLoadedMsg{path: path, modTime: modTime, box: box}
+		if decoded, err := graphics.Load(path); err != nil {
+			msg.err = err
+		} else {
+			msg.pic = graphics.Render(decoded, p, box, imageID(path, modTime), profile)
+		}
+		return msg
+	}
+}
+
+// handleImageLoaded shows a picture that finished loading. One for a file (or version
+// of it, or pane size) the preview has since moved on from is dropped.
+func (m *model) handleImageLoaded(msg imageLoadedMsg) {
+	img := m.imagePreview
+	if img == nil || !img.loading || img.path != msg.path || !img.modTime.Equal(msg.modTime) || img.box != msg.box {
+		return
+	}
+	img.loading, img.pic, img.err = false, msg.pic, msg.err
+	if m.showPreview && m.cursor < len(m.filteredFiles) && m.filteredFiles[m.cursor].path == msg.path {
+		m.updatePreview()
+	}
+}
+
+// imageBox is the room left for a picture in the preview pane once used lines are
+// taken above it
+func (m *model) imageBox(used int) graphics.Box {
+	availableHeight := max(m.height-uiOverhead, 3)
+	cellW, cellH := graphics.CellSize()
+	return graphics.Box{
+		Cols:  m.width/2 - 6,                  // renderPreview's line width
+		Rows:  availableHeight - 1 - used - 1, // Its content height, with a line spare for wrapping slop
+		CellW: cellW,
+		CellH: cellH,
+	}
+}
+
+// imageID names a picture for kitty, which keeps uploaded pixels by id
+func imageID(path string, modTime time.Time) uint32 {
+	h := fnv.New32a()
+	fmt.Fprintf(h, "%s\x00%d", path, modTime.UnixNano())
+	return h.Sum32() | 1
+}
+
+// placeImage draws the preview's picture into frame for kitty, iTerm2 and sixel: at the
+// marker's cell, from the end of the last line, with the cursor saved and restored
+// around it. Half blocks are plain text and need nothing here.
+//
+// The renderer only rewrites lines that changed, and sixel and iTerm2 pixels go when text
+// is written over them. So a frame that differs from the last one is dirty: the picture
+// is drawn again from the last line, which then ends differently from last time (with
+// or without a no-op attribute reset) so the renderer rewrites it after every other line
+// that changed. An unchanged frame gets back exactly what it did before, which the
+// renderer skips. Kitty pictures outlive the text around them instead, so they're
+// cleared and placed again on each redraw, and not shown while a dialog is open.
+func (m *model) placeImage(frame string) string {
+	img := m.imagePreview
+	switch m.imageProtocol {
+	case graphics.Kitty, graphics.ITerm2, graphics.Sixel:
+	default:
+		return frame
+	}
+	if img == nil {
+		return frame
+	}
+	last := m.imageDraw
+	if last != nil && last.frame == frame && last.pic == img {
+		return last.out
+	}
+	draw := &imageDraw{frame: frame, pic: img, flip: last == nil || !last.flip}
+
+	lines := strings.Split(frame, "\n")
+	row, col := -1, 0
+	for i, line := range lines {
+		if j := strings.Index(line, imageMarker); j >= 0 {
+			row, col = i, xansi.StringWidth(line[:j])
+			lines[i] = line[:j] + line[j+len(imageMarker):]
+			break
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("\x1b7")
+	if m.imageProtocol == graphics.Kitty {
+		b.WriteString(graphics.KittyClear)
+	}
+	if row >= 0 && m.previewScroll == 0 && (m.mode == modeNormal || m.mode == modeSearch) {
+		fmt.Fprintf(&b, "\x1b[%d;%dH", row+1, col+1)
+		b.WriteString(img.pic.Draw)
+	}
+	b.WriteString("\x1b8")
+	if draw.flip {
+		b.WriteString("\x1b[m")
+	}
+
+	if row >= 0 && img.pic.Upload != "" {
+		// Uploaded with the top line, which changes least, so it's sent again rarely
+		lines[0] += img.pic.Upload
+	}
+	lines[len(lines)-1] += b.String()
+	draw.out = strings.Join(lines, "\n")
+	m.imageDraw = draw
+	return draw.out
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	xansi "github.com/charmbracelet/x/ansi"
+	"github.com/muesli/termenv"
+
+	"github.com/LFroesch/scout/internal/graphics"
+)
+
+// imageTestModel has photo.png (40×20, solid red) under the cursor
+func imageTestModel(t *testing.T, p graphics.Protocol) model {
+	t.Helper()
+	m := selectionTestModel(t)
+	img := image.NewNRGBA(image.Rect(0, 0, 40, 20))
+	for i := 0; i < len(img.Pix); i += 4 {
+		copy(img.Pix[i:], []byte{255, 0, 0, 255})
+	}
+	f, _ := os.Create(filepath.Join(m.currentDir, "photo.png"))
+	png.Encode(f, img)
+	f.Close()
+
+	m.imageProtocol = p
+	m.loadFiles()
+	for i, f := range m.filteredFiles {
+		if f.name == "photo.png" {
+			m.cursor = i
+		}
+	}
+	m.updatePreview()
+	loadTestImage(t, &m)
+	return m
+}
+
+// loadTestImage runs the picture load the last preview started, as Update would
+func loadTestImage(t *testing.T, m *model) {
+	t.Helper()
+	load := m.imageLoad
+	if load == nil {
+		t.Fatal("preview started no image load")
+	}
+	m.imageLoad = nil
+	m.Update(load())
+}
+
+func TestImagePreviewHalfBlocks(t *testing.T) {
+	profile := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.TrueColor)
+	t.Cleanup(func() { lipgloss.SetColorProfile(profile) })
+
+	m := imageTestModel(t, graphics.Blocks)
+	if !strings.Contains(m.previewContent, "Image: PNG, 40 × 20 px\nColor model: RGBA\n") {
+		t.Errorf("no image details in\n%s", m.previewContent)
+	}
+	// The pane has room, so it's drawn full size: 10 rows of 40 half blocks
+	var rows int
+	for _, line := range m.previewLines {
+		if strings.Contains(line, "▀") {
+			rows++
+			if w := xansi.StringWidth(line); w != 40 || !strings.Contains(line, "38;2;255;0;0") {
+				t.Errorf("row %q is %d wide", line, w)
+			}
+		}
+	}
+	if rows != 10 {
+		t.Errorf("%d rows of blocks", rows)
+	}
+	if frame := m.View(); strings.Contains(frame, "\x1b7") {
+		t.Error("half blocks drew an overlay")
+	}
+}
+
+func TestImagePreviewPlacesKittyPicture(t *testing.T) {
+	m := imageTestModel(t, graphics.Kitty)
+	frame := m.View()
+	if strings.Contains(frame, imageMarker) {
+		t.Fatal("marker left in the frame")
+	}
+	lines := strings.Split(frame, "\n")
+	if !strings.Contains(lines[0], "\x1b_Ga=t,f=100,") {
+		t.Error("picture not uploaded with the first line")
+	}
+
+	// Drawn where the preview text leaves room for it: the row after "Color model"
+	var want string
+	for i, line := range lines {
+		plain := xansi.Strip(line)
+		if j := strings.Index(plain, "Color model: RGBA"); j >= 0 {
+			want = fmt.Sprintf("\x1b[%d;%dH\x1b_Ga=p,", i+3, xansi.StringWidth(plain[:j])+1)
+		}
+	}
+	last := lines[len(lines)-1]
+	if want == "" || !strings.Contains(last, want) || !strings.Contains(last, "\x1b8") {
+		t.Errorf("last line %q doesn't place the picture with %q", last, want)
+	}
+
+	// The same frame again is left as it was, for the renderer to skip
+	if m.View() != frame {
+		t.Error("unchanged frame drawn differently")
+	}
+
+	// A change anywhere (here a name in the list) changes the line that draws it, and a
+	// dialog hides it
+	m.filteredFiles[0].name = "renamed"
+	changed := strings.Split(m.View(), "\n")
+	if frame := strings.Join(changed, "\n"); !strings.Contains(frame, "renamed") {
+		t.Fatal("list didn't change")
+	}
+	if changed[len(changed)-1] == last || !strings.Contains(changed[len(changed)-1], want) {
+		t.Errorf("drawing line %q didn't change with the frame", changed[len(changed)-1])
+	}
+	m.mode = modeHelp
+	if strings.Contains(m.View(), "a=p,") {
+		t.Error("picture drawn over a dialog")
+	}
+}
+
+func TestImageLoadsInBackground(t *testing.T) {
+	m := imageTestModel(t, graphics.Kitty)
+	m.imagePreview = nil
+	m.updatePreview()
+	if !strings.Contains(m.previewContent, "(Loading image…)") || strings.Contains(m.View(), "a=p,") {
+		t.Fatalf("picture shown before it loaded:\n%s", m.previewContent)
+	}
+	load := m.imageLoad
+	_, cmd := m.Update(previewUpdateMsg{})
+	if cmd == nil || m.imageLoad != nil {
+		t.Fatal("Update didn't start the load")
+	}
+
+	// A result for an older version of the file is dropped
+	msg := load().(imageLoadedMsg)
+	stale := msg
+	stale.modTime = stale.modTime.Add(-time.Hour)
+	m.Update(stale)
+	if !m.imagePreview.loading {
+		t.Fatal("stale picture shown")
+	}
+	m.Update(msg)
+	if m.imagePreview.loading || !strings.Contains(m.View(), "a=p,") {
+		t.Errorf("loaded picture not shown:\n%s", m.previewContent)
+	}
+}
+
+func TestImagePreviewErrors(t *testing.T) {
+	m := imageTestModel(t, graphics.Sixel)
+	if !strings.Contains(m.View(), "\x1bP0;1;0q") {
+		t.Error("no sixel drawn")
+	}
+
+	os.WriteFile(filepath.Join(m.currentDir, "photo.png"), []byte("not a png"), 0o644)
+	m.updatePreview()
+	if !strings.Contains(m.previewContent, "Cannot read image") || strings.Contains(m.View(), "\x1bP0;1;0q") {
+		t.Errorf("broken image preview:\n%s", m.previewContent)
+	}
+}
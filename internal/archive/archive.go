@@ -0,0 +1,334 @@
+// Package archive lists, extracts and creates zip and tar archives (plain, gzip, bzip2
+// or xz compressed), plus single gzip/bzip2/xz compressed files
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/bzip2"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ulikunitz/xz"
+)
+
+// Format is an archive or compression format, recognised by file name
+type Format int
+
+const (
+	Unknown Format = iota
+	Zip
+	Tar
+	TarGz
+	TarBz2
+	TarXz
+	Gz  // A single gzip-compressed file
+	Bz2 // A single bzip2-compressed file
+	Xz  // A single xz-compressed file
+)
+
+// suffixes maps name endings to formats, longest first so .tar.gz wins over .gz
+var suffixes = []struct {
+	ext    string
+	format Format
+}{
+	{".tar.bz2", TarBz2}, {".tar.gz", TarGz}, {".tar.xz", TarXz},
+	{".tbz2", TarBz2}, {".tgz", TarGz}, {".txz", TarXz}, {".tbz", TarBz2},
+	{".zip", Zip}, {".jar", Zip}, {".tar", Tar},
+	{".bz2", Bz2}, {".gz", Gz}, {".xz", Xz},
+}
+
+// Detect returns the format of the archive called name (Unknown if it isn't one)
+func Detect(name string) Format {
+	lower := strings.ToLower(name)
+	for _, s := range suffixes {
+		if strings.HasSuffix(lower, s.ext) && len(lower) > len(s.ext) {
+			return s.format
+		}
+	}
+	return Unknown
+}
+
+func (f Format) String() string {
+	switch f {
+	case Zip:
+		return "zip"
+	case Tar:
+		return "tar"
+	case TarGz:
+		return "tar.gz"
+	case TarBz2:
+		return "tar.bz2"
+	case TarXz:
+		return "tar.xz"
+	case Gz:
+		return "gzip"
+	case Bz2:
+		return "bzip2"
+	case Xz:
+		return "xz"
+	}
+	return "unknown"
+}
+
+// Stem is name without its archive extension ("photos.tar.gz" → "photos")
+func Stem(name string) string {
+	lower := strings.ToLower(name)
+	for _, s := range suffixes {
+		if strings.HasSuffix(lower, s.ext) && len(lower) > len(s.ext) {
+			return name[:len(name)-len(s.ext)]
+		}
+	}
+	return name
+}
+
+// Entry is one file, directory or link inside an archive
+type Entry struct {
+	Name    string // Slash-separated path inside the archive, without a trailing slash
+	Size    int64  // Uncompressed size; -1 when unknown
+	Mode    fs.FileMode
+	ModTime time.Time
+	IsDir   bool
+	Link    string // Symlink target (or, for a tar hard link, the entry linked to)
+}
+
+// errBudget stops a listing that has read as much of the archive as it was allowed
+var errBudget = errors.New("read budget exhausted")
+
+// List returns every entry in the archive at path, with the directories they imply.
+// budget > 0 caps how many bytes of a compressed stream are read (zip directories are
+// always read whole); complete is false when the listing stopped there.
+func List(archivePath string, budget int64) (entries []Entry, complete bool, err error) {
+	format := Detect(archivePath)
+	switch format {
+	case Unknown:
+		return nil, false, fmt.Errorf("%s is not a supported archive", filepath.Base(archivePath))
+	case Zip:
+		entries, err = listZip(archivePath)
+		return fillDirs(entries), err == nil, err
+	}
+
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return nil, false, err
+	}
+	defer f.Close()
+	var in io.Reader = f
+	if budget > 0 {
+		in = &budgetReader{r: f, left: budget}
+	}
+	stream, err := decompress(in, format)
+	if err != nil {
+		return nil, false, err
+	}
+
+	switch format {
+	case Gz, Bz2, Xz:
+		// One file: its size is only known once it's all been read
+		e := Entry{Name: singleName(archivePath, stream), Size: -1, Mode: 0644}
+		if info, err := f.Stat(); err == nil {
+			e.ModTime = info.ModTime()
+		}
+		n, err := io.Copy(io.Discard, stream)
+		if err == nil {
+			e.Size = n
+		} else if !errors.Is(err, errBudget) {
+			return nil, false, err
+		}
+		return []Entry{e}, err == nil, nil
+	}
+
+	tr := tar.NewReader(stream)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return fillDirs(entries), true, nil
+		}
+		if errors.Is(err, errBudget) {
+			return fillDirs(entries), false, nil
+		}
+		if err != nil {
+			return fillDirs(entries), false, err
+		}
+		if e, ok := tarEntry(hdr); ok {
+			entries = append(entries, e)
+		}
+	}
+}
+
+// singleName is the name of the file a single-file format holds: the one gzip recorded,
+// or the archive's own name without its extension
+func singleName(archivePath string, stream io.Reader) string {
+	if gz, ok := stream.(*gzip.Reader); ok && gz.Name != "" {
+		if name := path.Base(cleanName(gz.Name)); name != "" && name != "." {
+			return name
+		}
+	}
+	return Stem(filepath.Base(archivePath))
+}
+
+// listZip reads a zip's central directory
+func listZip(archivePath string) ([]Entry, error) {
+	zr, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	var entries []Entry
+	for _, zf := range zr.File {
+		name := cleanName(zf.Name)
+		if name == "" {
+			continue
+		}
+		mode := zf.Mode()
+		e := Entry{Name: name, Size: int64(zf.UncompressedSize64), Mode: mode, ModTime: zf.Modified, IsDir: mode.IsDir()}
+		if mode&fs.ModeSymlink != 0 {
+			e.Link = readZipLink(zf)
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// readZipLink returns a zip symlink's target, which is stored as the entry's contents
+func readZipLink(zf *zip.File) string {
+	rc, err := zf.Open()
+	if err != nil {
+		return ""
+	}
+	defer rc.Close()
+	target, _ := io.ReadAll(io.LimitReader(rc, 4096))
+	return string(target)
+}
+
+// tarEntry converts a tar header; false for entries that aren't files, dirs or links
+func tarEntry(hdr *tar.Header) (Entry, bool) {
+	name := cleanName(hdr.Name)
+	if name == "" {
+		return Entry{}, false
+	}
+	e := Entry{Name: name, Size: hdr.Size, Mode: hdr.FileInfo().Mode(), ModTime: hdr.ModTime}
+	switch hdr.Typeflag {
+	case tar.TypeDir:
+		e.IsDir, e.Size = true, 0
+	case tar.TypeReg, tar.TypeRegA:
+	case tar.TypeSymlink:
+		e.Link, e.Size = hdr.Linkname, 0
+	case tar.TypeLink:
+		e.Link = cleanName(hdr.Linkname)
+	default:
+		return Entry{}, false
+	}
+	return e, true
+}
+
+// cleanName normalises an entry name: slashes, no leading "./" or "/", no trailing "/"
+func cleanName(name string) string {
+	name = path.Clean("/" + strings.ReplaceAll(name, `\`, "/"))
+	return strings.TrimPrefix(name, "/")
+}
+
+// fillDirs adds the directories an archive implies but doesn't list (many zips only
+// list files)
+func fillDirs(entries []Entry) []Entry {
+	seen := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		if e.IsDir {
+			seen[e.Name] = true
+		}
+	}
+	for _, e := range entries {
+		for dir := path.Dir(e.Name); dir != "." && !seen[dir]; dir = path.Dir(dir) {
+			seen[dir] = true
+			entries = append(entries, Entry{Name: dir, IsDir: true, Mode: fs.ModeDir | 0755})
+		}
+	}
+	return entries
+}
+
+// Children returns the entries directly inside dir ("" for the top level), directories
+// first, then by name
+func Children(entries []Entry, dir string) []Entry {
+	parent := dir
+	if parent == "" {
+		parent = "."
+	}
+	var children []Entry
+	for _, e := range entries {
+		if path.Dir(e.Name) == parent {
+			children = append(children, e)
+		}
+	}
+	sort.Slice(children, func(i, j int) bool {
+		if children[i].IsDir != children[j].IsDir {
+			return children[i].IsDir
+		}
+		return strings.ToLower(children[i].Name) < strings.ToLower(children[j].Name)
+	})
+	return children
+}
+
+// Measure returns what extracting the archive reports through OnFile and OnBytes: for a
+// zip its entries and unpacked size, for anything else no count (only a full read would
+// tell) and its size on disk
+func Measure(archivePath string) (int, int64, error) {
+	info, err := os.Stat(archivePath)
+	if err != nil {
+		return 0, 0, err
+	}
+	if Detect(archivePath) != Zip {
+		return 0, info.Size(), nil
+	}
+	zr, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer zr.Close()
+	var bytes int64
+	for _, zf := range zr.File {
+		if zf.Mode().IsRegular() {
+			bytes += int64(zf.UncompressedSize64)
+		}
+	}
+	return len(zr.File), bytes, nil
+}
+
+// decompress wraps r in the decoder for format (tar and single-file formats alike)
+func decompress(r io.Reader, format Format) (io.Reader, error) {
+	switch format {
+	case TarGz, Gz:
+		return gzip.NewReader(r)
+	case TarBz2, Bz2:
+		return bzip2.NewReader(r), nil
+	case TarXz, Xz:
+		return xz.NewReader(r)
+	}
+	return r, nil
+}
+
+// budgetReader fails with errBudget once it has read its allowance
+type budgetReader struct {
+	r    io.Reader
+	left int64
+}
+
+func (b *budgetReader) Read(p []byte) (int, error) {
+	if b.left <= 0 {
+		return 0, errBudget
+	}
+	if int64(len(p)) > b.left {
+		p = p[:b.left]
+	}
+	n, err := b.r.Read(p)
+	b.left -= int64(n)
+	return n, err
+}
@@ -0,0 +1,305 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/LFroesch/scout/internal/fileops"
+)
+
+// writeTree creates files (relative path → contents) under root
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, data := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(p), 0755)
+		if err := os.WriteFile(p, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// names lists the entries' names, in order
+func names(entries []Entry) string {
+	var out []string
+	for _, e := range entries {
+		out = append(out, e.Name)
+	}
+	return strings.Join(out, ",")
+}
+
+func TestDetectAndStem(t *testing.T) {
+	cases := []struct {
+		name   string
+		format Format
+		stem   string
+	}{
+		{"photos.tar.gz", TarGz, "photos"},
+		{"src.TGZ", TarGz, "src"},
+		{"backup.tar.xz", TarXz, "backup"},
+		{"a.tar.bz2", TarBz2, "a"},
+		{"docs.zip", Zip, "docs"},
+		{"notes.txt.gz", Gz, "notes.txt"},
+		{"plain.tar", Tar, "plain"},
+		{"readme.md", Unknown, "readme.md"},
+		{".zip", Unknown, ".zip"},
+	}
+	for _, c := range cases {
+		if got := Detect(c.name); got != c.format {
+			t.Errorf("Detect(%q) = %v, want %v", c.name, got, c.format)
+		}
+		if got := Stem(c.name); got != c.stem {
+			t.Errorf("Stem(%q) = %q, want %q", c.name, got, c.stem)
+		}
+	}
+}
+
+func TestCreateListExtractRoundTrip(t *testing.T) {
+	for _, format := range []Format{Zip, TarGz} {
+		t.Run(format.String(), func(t *testing.T) {
+			root := t.TempDir()
+			src := filepath.Join(root, "project")
+			writeTree(t, src, map[string]string{
+				"main.go":        "package main",
+				"docs/guide.md":  "# Guide",
+				"docs/img/a.png": "png",
+				"empty/.keep":    "",
+			})
+			os.Symlink("main.go", filepath.Join(src, "link"))
+			os.WriteFile(filepath.Join(root, "top.txt"), []byte("top"), 0644)
+
+			dst := filepath.Join(root, "out."+format.String())
+			var files int
+			var read int64
+			opts := fileops.CopyOptions{
+				OnFile:  func(string) { files++ },
+				OnBytes: func(n int64) { read += n },
+			}
+			if err := Create(dst, format, []string{src, filepath.Join(root, "top.txt")}, opts); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			// 4 dirs, 4 files, 1 link, plus top.txt
+			if files != 10 || read != int64(len("package main# Guidepngtop")) {
+				t.Errorf("progress reported %d files, %d bytes", files, read)
+			}
+
+			entries, complete, err := List(dst, 0)
+			if err != nil || !complete {
+				t.Fatalf("List: %v (complete %v)", err, complete)
+			}
+			if got := names(Children(entries, "")); got != "project,top.txt" {
+				t.Errorf("top level = %s", got)
+			}
+			if got := names(Children(entries, "project/docs")); got != "project/docs/img,project/docs/guide.md" {
+				t.Errorf("project/docs = %s", got)
+			}
+			for _, e := range entries {
+				if e.Name == "project/link" && e.Link != "main.go" {
+					t.Errorf("link entry = %+v", e)
+				}
+			}
+
+			dest := filepath.Join(root, "unpacked")
+			created, err := Extract(dst, dest, fileops.CopyOptions{})
+			if err != nil {
+				t.Fatalf("Extract: %v", err)
+			}
+			if len(created) != 1 || created[0] != dest {
+				t.Errorf("created = %v, want just %s", created, dest)
+			}
+			for name, want := range map[string]string{"project/docs/img/a.png": "png", "top.txt": "top", "project/link": "package main"} {
+				if data, _ := os.ReadFile(filepath.Join(dest, filepath.FromSlash(name))); string(data) != want {
+					t.Errorf("%s = %q, want %q", name, data, want)
+				}
+			}
+			if target, _ := os.Readlink(filepath.Join(dest, "project", "link")); target != "main.go" {
+				t.Errorf("link points at %q", target)
+			}
+		})
+	}
+}
+
+func TestCreateRefusesExistingArchive(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{"a.txt": "a", "out.zip": "keep me"})
+	if err := Create(filepath.Join(root, "out.zip"), Zip, []string{filepath.Join(root, "a.txt")}, fileops.CopyOptions{}); err == nil {
+		t.Fatal("Create overwrote an existing file")
+	}
+	if data, _ := os.ReadFile(filepath.Join(root, "out.zip")); string(data) != "keep me" {
+		t.Errorf("out.zip = %q", data)
+	}
+}
+
+func TestCreateCancelRemovesArchive(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{"a.txt": "a"})
+	cancel := make(chan struct{})
+	close(cancel)
+	dst := filepath.Join(root, "out.tar.gz")
+	err := Create(dst, TarGz, []string{filepath.Join(root, "a.txt")}, fileops.CopyOptions{Cancel: cancel})
+	if err != fileops.ErrCancelled {
+		t.Fatalf("err = %v, want ErrCancelled", err)
+	}
+	if _, err := os.Lstat(dst); !os.IsNotExist(err) {
+		t.Error("cancelled archive left behind")
+	}
+}
+
+// tarGz builds a tar.gz from headers, each followed by its contents
+func tarGz(t *testing.T, path string, entries []*tar.Header, contents []string) {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for i, hdr := range entries {
+		if hdr.Typeflag == tar.TypeReg {
+			hdr.Size = int64(len(contents[i]))
+		}
+		if hdr.Mode == 0 {
+			hdr.Mode = 0644
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write([]byte(contents[i]))
+	}
+	tw.Close()
+	gz.Close()
+	os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+func TestExtractRefusesEscapes(t *testing.T) {
+	root := t.TempDir()
+	dest := filepath.Join(root, "dest")
+	outside := filepath.Join(root, "outside")
+	os.Mkdir(outside, 0755)
+
+	cases := map[string]struct {
+		headers  []*tar.Header
+		contents []string
+	}{
+		"dotdot": {
+			[]*tar.Header{{Name: "ok.txt", Typeflag: tar.TypeReg}, {Name: "../outside/evil.txt", Typeflag: tar.TypeReg}},
+			[]string{"ok", "evil"},
+		},
+		"through symlink": {
+			[]*tar.Header{{Name: "ok.txt", Typeflag: tar.TypeReg}, {Name: "out", Typeflag: tar.TypeSymlink, Linkname: outside}, {Name: "out/evil.txt", Typeflag: tar.TypeReg}},
+			[]string{"ok", "", "evil"},
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(root, name+".tar.gz")
+			tarGz(t, path, c.headers, c.contents)
+			if _, err := Extract(path, dest, fileops.CopyOptions{}); err == nil {
+				t.Fatal("Extract succeeded")
+			}
+			if _, err := os.Lstat(filepath.Join(outside, "evil.txt")); !os.IsNotExist(err) {
+				t.Error("wrote outside the destination")
+			}
+			// What it had extracted is gone again, including the directory it made
+			if _, err := os.Lstat(dest); !os.IsNotExist(err) {
+				t.Error("partial extraction left behind")
+			}
+		})
+	}
+}
+
+func TestExtractNeverOverwrites(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "a.zip")
+	f, _ := os.Create(path)
+	zw := zip.NewWriter(f)
+	for _, name := range []string{"new.txt", "clash.txt"} {
+		w, _ := zw.Create(name)
+		w.Write([]byte("from zip"))
+	}
+	zw.Close()
+	f.Close()
+	os.WriteFile(filepath.Join(root, "clash.txt"), []byte("mine"), 0644)
+
+	if _, err := Extract(path, root, fileops.CopyOptions{}); err == nil {
+		t.Fatal("Extract replaced clash.txt")
+	}
+	if data, _ := os.ReadFile(filepath.Join(root, "clash.txt")); string(data) != "mine" {
+		t.Errorf("clash.txt = %q", data)
+	}
+	if _, err := os.Lstat(filepath.Join(root, "new.txt")); !os.IsNotExist(err) {
+		t.Error("new.txt left behind by the failed extraction")
+	}
+}
+
+func TestFailedExtractCleansUpInsideExistingDirectories(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "a.zip")
+	f, _ := os.Create(path)
+	zw := zip.NewWriter(f)
+	for _, name := range []string{"docs/new.txt", "docs/deep/more.txt", "docs/clash.txt"} {
+		w, _ := zw.Create(name)
+		w.Write([]byte("from zip"))
+	}
+	zw.Close()
+	f.Close()
+	dest := filepath.Join(root, "out")
+	writeTree(t, dest, map[string]string{"docs/clash.txt": "mine", "docs/old.txt": "mine"})
+
+	if _, err := Extract(path, dest, fileops.CopyOptions{}); err == nil {
+		t.Fatal("Extract replaced docs/clash.txt")
+	}
+	entries, _ := os.ReadDir(filepath.Join(dest, "docs"))
+	var left []string
+	for _, e := range entries {
+		left = append(left, e.Name())
+	}
+	if got := strings.Join(left, ","); got != "clash.txt,old.txt" {
+		t.Errorf("docs/ holds %s after the failed extraction, want only what was there", got)
+	}
+}
+
+func TestSingleFileGzip(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "notes.txt.gz")
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write([]byte("hello"))
+	gz.Close()
+	os.WriteFile(path, buf.Bytes(), 0644)
+
+	entries, complete, err := List(path, 0)
+	if err != nil || !complete || len(entries) != 1 || entries[0].Name != "notes.txt" || entries[0].Size != 5 {
+		t.Fatalf("List = %+v, %v, %v", entries, complete, err)
+	}
+	created, err := Extract(path, root, fileops.CopyOptions{})
+	if err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	if len(created) != 1 || filepath.Base(created[0]) != "notes.txt" {
+		t.Errorf("created = %v", created)
+	}
+}
+
+func TestListStopsAtBudget(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{}
+	for i := range 50 {
+		files[filepath.Join("d", strings.Repeat("x", i+1))] = strings.Repeat("data", 1000)
+	}
+	writeTree(t, root, files)
+	dst := filepath.Join(root, "big.tar.gz")
+	if err := Create(dst, TarGz, []string{filepath.Join(root, "d")}, fileops.CopyOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	entries, complete, err := List(dst, 512)
+	if err != nil || complete {
+		t.Fatalf("List over budget: complete %v, err %v", complete, err)
+	}
+	if len(entries) >= 51 {
+		t.Errorf("listed all %d entries within the budget", len(entries))
+	}
+}
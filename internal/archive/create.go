@@ -0,0 +1,183 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/LFroesch/scout/internal/fileops"
+	"github.com/LFroesch/scout/internal/logger"
+)
+
+// CanCreate reports whether Create can write format
+func CanCreate(format Format) bool {
+	return format == Zip || format == TarGz
+}
+
+// Create writes a new zip or tar.gz archive at dst holding sources, each stored under its
+// own name (a directory with everything in it). dst must not exist yet; if the archive
+// can't be finished it's removed. Special files, and dst itself should it be inside a
+// source, are left out. Only opts' hooks are used: OnBytes and OnFile report what's read
+// from the sources, so they match a walk of them.
+func Create(dst string, format Format, sources []string, opts fileops.CopyOptions) (err error) {
+	if !CanCreate(format) {
+		return fmt.Errorf("cannot create %s archives", format)
+	}
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return existsErr(err, filepath.Base(dst))
+	}
+	defer func() {
+		if err != nil {
+			out.Close()
+			if rmErr := os.Remove(dst); rmErr != nil {
+				logger.Warn("Failed to remove unfinished archive %s: %v", dst, rmErr)
+			}
+		}
+	}()
+
+	var w archiveWriter
+	if format == Zip {
+		w = &zipWriter{zw: zip.NewWriter(out)}
+	} else {
+		gz := gzip.NewWriter(out)
+		w = &tarWriter{gz: gz, tw: tar.NewWriter(gz)}
+	}
+	for _, src := range sources {
+		if err := addTree(w, src, dst, opts); err != nil {
+			return err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return out.Close()
+}
+
+// archiveWriter adds entries to a zip or tar being written
+type archiveWriter interface {
+	// Add stores an entry called name (slash-separated), reading a regular file's
+	// contents from r and a symlink's target from link
+	Add(name string, info fs.FileInfo, link string, r io.Reader) error
+	Close() error
+}
+
+// addTree adds root and, for a directory, everything under it, named relative to root's
+// parent
+func addTree(w archiveWriter, root, dst string, opts fileops.CopyOptions) error {
+	base := filepath.Dir(root)
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if opts.Cancel != nil && cancelled(opts.Cancel) {
+			return fileops.ErrCancelled
+		}
+		if path == dst {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(base, path)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+
+		switch mode := info.Mode(); {
+		case mode.IsDir():
+			err = w.Add(name, info, "", nil)
+		case mode&fs.ModeSymlink != 0:
+			var link string
+			if link, err = os.Readlink(path); err == nil {
+				err = w.Add(name, info, link, nil)
+			}
+		case mode.IsRegular():
+			var in *os.File
+			if in, err = os.Open(path); err == nil {
+				err = w.Add(name, info, "", &progressReader{r: in, opts: opts})
+				in.Close()
+			}
+		default:
+			logger.Warn("Skipping special file %s (%s)", path, mode.Type())
+		}
+		if err != nil {
+			return err
+		}
+		if opts.OnFile != nil {
+			opts.OnFile(path)
+		}
+		return nil
+	})
+}
+
+type zipWriter struct {
+	zw *zip.Writer
+}
+
+func (w *zipWriter) Add(name string, info fs.FileInfo, link string, r io.Reader) error {
+	hdr, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+	hdr.Name = name
+	switch {
+	case info.IsDir():
+		hdr.Name += "/"
+	case r != nil:
+		hdr.Method = zip.Deflate
+	}
+	fw, err := w.zw.CreateHeader(hdr)
+	if err != nil {
+		return err
+	}
+	if link != "" {
+		_, err = io.WriteString(fw, link)
+		return err
+	}
+	if r != nil {
+		_, err = io.Copy(fw, r)
+	}
+	return err
+}
+
+func (w *zipWriter) Close() error {
+	return w.zw.Close()
+}
+
+type tarWriter struct {
+	gz *gzip.Writer
+	tw *tar.Writer
+}
+
+func (w *tarWriter) Add(name string, info fs.FileInfo, link string, r io.Reader) error {
+	hdr, err := tar.FileInfoHeader(info, link)
+	if err != nil {
+		return err
+	}
+	hdr.Name = name
+	if info.IsDir() {
+		hdr.Name += "/"
+	}
+	if err := w.tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	if r != nil {
+		_, err = io.Copy(w.tw, r)
+	}
+	return err
+}
+
+func (w *tarWriter) Close() error {
+	if err := w.tw.Close(); err != nil {
+		return err
+	}
+	return w.gz.Close()
+}
@@ -0,0 +1,384 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/LFroesch/scout/internal/fileops"
+	"github.com/LFroesch/scout/internal/logger"
+)
+
+// Extract unpacks the archive at archivePath into destDir (created if missing) and
+// returns the top-level paths it created there. It never overwrites: an entry whose path
+// is taken fails the extraction. Entries that would land outside destDir, or be written
+// through a symlink, are refused. On failure or cancellation everything it created is
+// removed again.
+//
+// Only opts' hooks are used. OnBytes follows the archive file as it's read for tar and
+// single-file formats, and the unpacked bytes for zip, matching Measure.
+func Extract(archivePath, destDir string, opts fileops.CopyOptions) ([]string, error) {
+	format := Detect(archivePath)
+	if format == Unknown {
+		return nil, fmt.Errorf("%s is not a supported archive", filepath.Base(archivePath))
+	}
+	destExisted := true
+	if _, err := os.Lstat(destDir); os.IsNotExist(err) {
+		destExisted = false
+	}
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return nil, err
+	}
+
+	x := &extractor{dest: destDir, opts: opts}
+	var err error
+	switch format {
+	case Zip:
+		err = x.zip(archivePath)
+	default:
+		err = x.stream(archivePath, format)
+	}
+	if err == nil {
+		err = x.finishDirs()
+	}
+	if err != nil {
+		x.cleanup()
+		if !destExisted {
+			os.Remove(destDir)
+		}
+		return nil, err
+	}
+	if !destExisted {
+		return []string{destDir}, nil
+	}
+	return x.created, nil
+}
+
+// extractor is the state of one extraction
+type extractor struct {
+	dest    string
+	opts    fileops.CopyOptions
+	created []string  // Top-level paths made in dest, in order
+	made    []string  // Every file, link and directory made, in order, for cleanup
+	dirs    []dirMeta // Directory metadata, applied once their contents are written
+}
+
+type dirMeta struct {
+	path    string
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+// stream extracts a tar (compressed or not) or a single compressed file
+func (x *extractor) stream(archivePath string, format Format) error {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	stream, err := decompress(&progressReader{r: f, opts: x.opts}, format)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case Gz, Bz2, Xz:
+		info, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		name := singleName(archivePath, stream)
+		if err := x.writeFile(name, stream, 0644, info.ModTime()); err != nil {
+			return err
+		}
+		x.fileDone(name)
+		return nil
+	}
+
+	tr := tar.NewReader(stream)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if x.opts.Cancel != nil && cancelled(x.opts.Cancel) {
+			return fileops.ErrCancelled
+		}
+		if err := x.tarEntry(hdr, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// tarEntry extracts one tar header and its contents
+func (x *extractor) tarEntry(hdr *tar.Header, r io.Reader) error {
+	name, err := safeName(hdr.Name)
+	if err != nil || name == "" {
+		return err
+	}
+	mode := hdr.FileInfo().Mode()
+	switch hdr.Typeflag {
+	case tar.TypeDir:
+		err = x.dir(name, mode, hdr.ModTime)
+	case tar.TypeReg, tar.TypeRegA:
+		err = x.writeFile(name, r, mode, hdr.ModTime)
+	case tar.TypeSymlink:
+		err = x.symlink(name, hdr.Linkname)
+	case tar.TypeLink:
+		err = x.hardlink(name, hdr.Linkname)
+	default:
+		// Devices, FIFOs and the like aren't recreated
+		logger.Warn("Skipping special entry %s in archive", hdr.Name)
+	}
+	if err == nil {
+		x.fileDone(name)
+	}
+	return err
+}
+
+// zip extracts every entry of a zip archive
+func (x *extractor) zip(archivePath string) error {
+	zr, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+	for _, zf := range zr.File {
+		if x.opts.Cancel != nil && cancelled(x.opts.Cancel) {
+			return fileops.ErrCancelled
+		}
+		name, err := safeName(zf.Name)
+		if err != nil {
+			return err
+		}
+		if name == "" {
+			continue
+		}
+		mode := zf.Mode()
+		switch {
+		case mode.IsDir():
+			err = x.dir(name, mode, zf.Modified)
+		case mode&fs.ModeSymlink != 0:
+			err = x.symlink(name, readZipLink(zf))
+		default:
+			var rc io.ReadCloser
+			if rc, err = zf.Open(); err == nil {
+				err = x.writeFile(name, &progressReader{r: rc, opts: x.opts}, mode, zf.Modified)
+				rc.Close()
+			}
+		}
+		if err != nil {
+			return err
+		}
+		x.fileDone(name)
+	}
+	return nil
+}
+
+// dir creates a directory entry; its mode and time are applied at the end
+func (x *extractor) dir(name string, mode fs.FileMode, modTime time.Time) error {
+	path, err := x.mkdirs(strings.Split(name, "/"))
+	if err != nil {
+		return err
+	}
+	x.dirs = append(x.dirs, dirMeta{path: path, mode: mode, modTime: modTime})
+	return nil
+}
+
+// writeFile creates a regular file from r, failing if anything is already at its path
+func (x *extractor) writeFile(name string, r io.Reader, mode fs.FileMode, modTime time.Time) error {
+	target, err := x.prepare(name)
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return existsErr(err, name)
+	}
+	x.track(target)
+	if _, err := io.Copy(out, r); err != nil {
+		out.Close()
+		if errors.Is(err, fileops.ErrCancelled) {
+			return err
+		}
+		return fmt.Errorf("extract %s: %w", name, err)
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(target, mode.Perm()); err != nil {
+		return err
+	}
+	if !modTime.IsZero() {
+		os.Chtimes(target, modTime, modTime)
+	}
+	return nil
+}
+
+// symlink recreates a link as stored; its target isn't checked since nothing is ever
+// written through it
+func (x *extractor) symlink(name, linkTarget string) error {
+	target, err := x.prepare(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Symlink(linkTarget, target); err != nil {
+		return existsErr(err, name)
+	}
+	x.track(target)
+	return nil
+}
+
+// hardlink links name to an entry extracted earlier
+func (x *extractor) hardlink(name, linkName string) error {
+	from, err := safeName(linkName)
+	if err != nil || from == "" {
+		return fmt.Errorf("bad hard link %s → %s", name, linkName)
+	}
+	source, err := x.prepare(from)
+	if err != nil {
+		return err
+	}
+	if info, err := os.Lstat(source); err != nil || !info.Mode().IsRegular() {
+		return fmt.Errorf("bad hard link %s → %s", name, linkName)
+	}
+	target, err := x.prepare(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Link(source, target); err != nil {
+		return existsErr(err, name)
+	}
+	x.track(target)
+	return nil
+}
+
+// prepare returns where name goes under dest, creating its parent directories
+func (x *extractor) prepare(name string) (string, error) {
+	parts := strings.Split(name, "/")
+	dir, err := x.mkdirs(parts[:len(parts)-1])
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, parts[len(parts)-1]), nil
+}
+
+// mkdirs creates the directories parts leads through under dest and returns the last.
+// Each must be a real directory: a symlink on the way (say, one the archive itself
+// created) is refused rather than followed out of dest.
+func (x *extractor) mkdirs(parts []string) (string, error) {
+	dir := x.dest
+	for _, part := range parts {
+		dir = filepath.Join(dir, part)
+		info, err := os.Lstat(dir)
+		switch {
+		case os.IsNotExist(err):
+			if err := os.Mkdir(dir, 0755); err != nil {
+				return "", err
+			}
+			x.track(dir)
+		case err != nil:
+			return "", err
+		case !info.IsDir():
+			return "", fmt.Errorf("cannot extract into %s: not a directory", strings.Join(parts, "/"))
+		}
+	}
+	return dir, nil
+}
+
+// track remembers that path was made, and reports it if it's directly in dest
+func (x *extractor) track(path string) {
+	x.made = append(x.made, path)
+	if filepath.Dir(path) == x.dest {
+		x.created = append(x.created, path)
+	}
+}
+
+// finishDirs applies directory modes and times, deepest first so setting a parent's
+// time isn't undone by writing into it
+func (x *extractor) finishDirs() error {
+	for i := len(x.dirs) - 1; i >= 0; i-- {
+		d := x.dirs[i]
+		// Keep directories writable by their owner, or nothing could be cleaned up later
+		if err := os.Chmod(d.path, d.mode.Perm()|0700); err != nil {
+			return err
+		}
+		if !d.modTime.IsZero() {
+			os.Chtimes(d.path, d.modTime, d.modTime)
+		}
+	}
+	return nil
+}
+
+// cleanup removes everything the extraction created, newest first so each directory is
+// empty by the time its turn comes. Entries unpacked into directories that were already
+// there go too, and nothing else in those directories is touched.
+func (x *extractor) cleanup() {
+	for i := len(x.made) - 1; i >= 0; i-- {
+		if err := os.Remove(x.made[i]); err != nil && !os.IsNotExist(err) {
+			logger.Warn("Failed to clean up partial extraction %s: %v", x.made[i], err)
+		}
+	}
+}
+
+func (x *extractor) fileDone(name string) {
+	if x.opts.OnFile != nil {
+		x.opts.OnFile(name)
+	}
+}
+
+// safeName cleans an entry name, refusing any that climbs out with ".." (a leading "/"
+// is dropped, as tar does)
+func safeName(name string) (string, error) {
+	slashed := strings.ReplaceAll(name, `\`, "/")
+	for _, part := range strings.Split(slashed, "/") {
+		if part == ".." {
+			return "", fmt.Errorf("refusing unsafe path in archive: %s", name)
+		}
+	}
+	return cleanName(slashed), nil
+}
+
+// existsErr explains a failed create the way the rest of scout does
+func existsErr(err error, name string) error {
+	if os.IsExist(err) {
+		return fmt.Errorf("%s already exists", name)
+	}
+	return err
+}
+
+func cancelled(cancel <-chan struct{}) bool {
+	select {
+	case <-cancel:
+		return true
+	default:
+		return false
+	}
+}
+
+// progressReader reports what's read through opts.OnBytes and fails once opts.Cancel closes
+type progressReader struct {
+	r    io.Reader
+	opts fileops.CopyOptions
+}
+
+func (pr *progressReader) Read(p []byte) (int, error) {
+	if pr.opts.Cancel != nil && cancelled(pr.opts.Cancel) {
+		return 0, fileops.ErrCancelled
+	}
+	n, err := pr.r.Read(p)
+	if n > 0 && pr.opts.OnBytes != nil {
+		pr.opts.OnBytes(int64(n))
+	}
+	return n, err
+}
@@ -0,0 +1,252 @@
+// Package bigfile reads windows of lines from text files too big to load whole. Lines are
+// counted lazily, as far as the furthest line asked for, and only every checkpointEvery'th
+// line's offset is kept, so even a multi-GB log costs little memory.
+package bigfile
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+)
+
+// MaxLine caps how much of one line is returned; the rest is cut and marked with "…"
+const MaxLine = 4096
+
+const (
+	checkpointEvery = 1024     // Lines between recorded offsets
+	chunkSize       = 64 << 10 // Bytes read at a time
+)
+
+// File is an open text file with a partial index of where its lines start
+type File struct {
+	f           *os.File
+	size        int64
+	checkpoints []int64 // checkpoints[i] is where line i*checkpointEvery starts
+	starts      int     // Line starts found so far
+	scanned     int64   // Offset counting has reached
+	atStart     bool    // scanned is just past a final newline: if the file grows, a line starts there
+}
+
+// Open opens the file at path
+func Open(path string) (*File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	b := &File{f: f}
+	b.Resize(info.Size())
+	return b, nil
+}
+
+// Close closes the file
+func (b *File) Close() error {
+	return b.f.Close()
+}
+
+// Size is the file's size as of Open or the last Resize
+func (b *File) Size() int64 {
+	return b.size
+}
+
+// Resize tells the file it's now size bytes. Growth keeps the lines counted so far;
+// shrinking (a truncated or rewritten log) starts the count again.
+func (b *File) Resize(size int64) {
+	if size < b.scanned || b.scanned == 0 {
+		b.checkpoints, b.starts, b.scanned, b.atStart = nil, 0, 0, false
+		if size > 0 {
+			b.addStart(0)
+		}
+	}
+	b.size = size
+}
+
+// Lines returns how many lines have been counted, and whether that's all of them
+func (b *File) Lines() (n int, complete bool) {
+	return b.starts, b.scanned >= b.size
+}
+
+// count reads on until line n's start is found or the file ends
+func (b *File) count(n int) error {
+	buf := make([]byte, chunkSize)
+	for b.starts <= n && b.scanned < b.size {
+		if b.atStart {
+			b.atStart = false
+			b.addStart(b.scanned)
+			continue
+		}
+		got, err := b.f.ReadAt(buf[:min(int64(chunkSize), b.size-b.scanned)], b.scanned)
+		if got == 0 {
+			return err
+		}
+		chunk := buf[:got]
+		for i := bytes.IndexByte(chunk, '\n'); i >= 0; i = bytes.IndexByte(chunk, '\n') {
+			start := b.scanned + int64(i) + 1
+			chunk = chunk[i+1:]
+			b.scanned = start
+			if start >= b.size {
+				b.atStart = true // A final newline doesn't start another line, yet
+				break
+			}
+			b.addStart(start)
+			if b.starts > n {
+				return nil
+			}
+		}
+		b.scanned += int64(len(chunk))
+	}
+	return nil
+}
+
+// addStart records that a line starts at off
+func (b *File) addStart(off int64) {
+	if b.starts%checkpointEvery == 0 {
+		b.checkpoints = append(b.checkpoints, off)
+	}
+	b.starts++
+}
+
+// Offset returns where line n (from 0) starts, counting lines up to it first if need be.
+// Past the end it gives the last line; the line actually found is returned with it.
+func (b *File) Offset(n int) (int64, int, error) {
+	if err := b.count(max(n, 0)); err != nil {
+		return 0, 0, err
+	}
+	n = max(0, min(n, b.starts-1))
+	if b.starts == 0 {
+		return 0, 0, nil
+	}
+	off := b.checkpoints[n/checkpointEvery]
+	for skip := n % checkpointEvery; skip > 0; skip-- {
+		next, err := b.nextLine(off)
+		if err != nil {
+			return 0, 0, err
+		}
+		off = next
+	}
+	return off, n, nil
+}
+
+// nextLine returns where the line after the one starting at off starts (or the size)
+func (b *File) nextLine(off int64) (int64, error) {
+	buf := make([]byte, chunkSize)
+	for off < b.size {
+		got, err := b.f.ReadAt(buf[:min(int64(chunkSize), b.size-off)], off)
+		if got == 0 {
+			return 0, err
+		}
+		if i := bytes.IndexByte(buf[:got], '\n'); i >= 0 {
+			return off + int64(i) + 1, nil
+		}
+		off += int64(got)
+	}
+	return b.size, nil
+}
+
+// ReadFrom returns up to n lines starting at off (a line's start), and where the line
+// after them starts, or the size once the file runs out
+func (b *File) ReadFrom(off int64, n int) ([]string, int64, error) {
+	var lines []string
+	var line []byte
+	cut := false
+	buf := make([]byte, chunkSize)
+	for off < b.size && len(lines) < n {
+		got, err := b.f.ReadAt(buf[:min(int64(chunkSize), b.size-off)], off)
+		if got == 0 {
+			if err == io.EOF {
+				break
+			}
+			return lines, off, err
+		}
+		chunk := buf[:got]
+		for len(chunk) > 0 && len(lines) < n {
+			i := bytes.IndexByte(chunk, '\n')
+			part := chunk
+			if i >= 0 {
+				part = chunk[:i]
+			}
+			if room := MaxLine - len(line); len(part) > room {
+				part, cut = part[:max(room, 0)], true
+			}
+			line = append(line, part...)
+			if i < 0 {
+				off += int64(len(chunk))
+				break
+			}
+			off += int64(i) + 1
+			chunk = chunk[i+1:]
+			lines = append(lines, finish(line, cut))
+			line, cut = line[:0], false
+		}
+	}
+	if off >= b.size && (len(line) > 0 || cut) && len(lines) < n {
+		lines = append(lines, finish(line, cut)) // Last line, with no newline after it
+	}
+	return lines, min(off, b.size), nil
+}
+
+// ReadBefore returns up to n lines that end before off (a line's start, or the size), and
+// where the first of them starts
+func (b *File) ReadBefore(off int64, n int) ([]string, int64, error) {
+	off = min(off, b.size)
+	if off <= 0 || n <= 0 {
+		return nil, 0, nil
+	}
+	// The newline ending the line before off isn't a boundary to stop at
+	end := off
+	if end == b.size {
+		if last, err := b.byteAt(end - 1); err != nil {
+			return nil, 0, err
+		} else if last == '\n' {
+			end--
+		}
+	} else {
+		end--
+	}
+
+	start, found := int64(0), 0
+	buf := make([]byte, chunkSize)
+scan:
+	for end > 0 {
+		from := max(end-chunkSize, 0)
+		got, err := b.f.ReadAt(buf[:end-from], from)
+		if int64(got) < end-from {
+			return nil, 0, err
+		}
+		chunk := buf[:got]
+		for i := bytes.LastIndexByte(chunk, '\n'); i >= 0; i = bytes.LastIndexByte(chunk, '\n') {
+			found++
+			if found == n {
+				start = from + int64(i) + 1
+				break scan
+			}
+			chunk = chunk[:i]
+		}
+		end = from
+	}
+	if found < n {
+		found++ // The first line in the file
+	}
+	lines, _, err := b.ReadFrom(start, found)
+	return lines, start, err
+}
+
+func (b *File) byteAt(off int64) (byte, error) {
+	var one [1]byte
+	_, err := b.f.ReadAt(one[:], off)
+	return one[0], err
+}
+
+// finish turns a line's bytes into text: no carriage return, valid UTF-8, cut ones marked
+func finish(line []byte, cut bool) string {
+	s := strings.ToValidUTF8(strings.TrimSuffix(string(line), "\r"), "�")
+	if cut {
+		s += "…"
+	}
+	return s
+}
@@ -0,0 +1,140 @@
+package bigfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// numbered writes a file of n lines, "line 0" to "line n-1", with or without a final newline
+func numbered(t *testing.T, n int, final bool) (string, []string) {
+	t.Helper()
+	want := make([]string, n)
+	for i := range want {
+		want[i] = fmt.Sprintf("line %d", i)
+	}
+	text := strings.Join(want, "\n")
+	if final {
+		text += "\n"
+	}
+	path := filepath.Join(t.TempDir(), "log.txt")
+	if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path, want
+}
+
+func open(t *testing.T, path string) *File {
+	t.Helper()
+	f, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+	return f
+}
+
+func TestOffsetCountsLazily(t *testing.T) {
+	path, want := numbered(t, 5000, true)
+	f := open(t, path)
+
+	off, n, err := f.Offset(3000)
+	if err != nil || n != 3000 {
+		t.Fatalf("Offset(3000) = %d, %d, %v", off, n, err)
+	}
+	if lines, _, _ := f.ReadFrom(off, 2); strings.Join(lines, ",") != "line 3000,line 3001" {
+		t.Errorf("read at line 3000: %q", lines)
+	}
+	if counted, complete := f.Lines(); complete || counted > 3001+checkpointEvery {
+		t.Errorf("counted %d lines (complete %v) to find line 3000", counted, complete)
+	}
+
+	// Past the end gives the last line, and the whole count
+	off, n, _ = f.Offset(1 << 30)
+	if lines, _, _ := f.ReadFrom(off, 5); n != 4999 || len(lines) != 1 || lines[0] != want[4999] {
+		t.Errorf("Offset past the end: line %d, %q", n, lines)
+	}
+	if counted, complete := f.Lines(); counted != 5000 || !complete {
+		t.Errorf("Lines() = %d, %v", counted, complete)
+	}
+}
+
+func TestReadFromAndBefore(t *testing.T) {
+	for _, final := range []bool{true, false} {
+		path, want := numbered(t, 100, final)
+		f := open(t, path)
+
+		lines, next, err := f.ReadFrom(0, 10)
+		if err != nil || strings.Join(lines, "\n") != strings.Join(want[:10], "\n") {
+			t.Fatalf("ReadFrom(0, 10) = %q, %v", lines, err)
+		}
+		if more, _, _ := f.ReadFrom(next, 1); more[0] != "line 10" {
+			t.Errorf("next offset reads %q", more)
+		}
+
+		// The tail, then the lines before it
+		tail, start, err := f.ReadBefore(f.Size(), 3)
+		if err != nil || strings.Join(tail, ",") != "line 97,line 98,line 99" {
+			t.Fatalf("final %v: tail %q, %v", final, tail, err)
+		}
+		before, start, _ := f.ReadBefore(start, 2)
+		if strings.Join(before, ",") != "line 95,line 96" {
+			t.Errorf("final %v: before tail %q", final, before)
+		}
+		if all, first, _ := f.ReadBefore(start, 1000); len(all) != 95 || first != 0 || all[0] != "line 0" {
+			t.Errorf("final %v: back to the start: %d lines from %d", final, len(all), first)
+		}
+		if lines, end, _ := f.ReadFrom(next, 1000); len(lines) != 90 || end != f.Size() {
+			t.Errorf("final %v: to the end: %d lines, stopped at %d of %d", final, len(lines), end, f.Size())
+		}
+	}
+}
+
+func TestLongLinesAreCut(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wide.txt")
+	long := strings.Repeat("x", 3*chunkSize)
+	os.WriteFile(path, []byte("a\r\n"+long+"\nb"), 0o644)
+	f := open(t, path)
+
+	lines, _, err := f.ReadFrom(0, 10)
+	if err != nil || len(lines) != 3 || lines[0] != "a" || lines[2] != "b" {
+		t.Fatalf("lines %d, %v", len(lines), err)
+	}
+	if lines[1] != strings.Repeat("x", MaxLine)+"…" {
+		t.Errorf("long line is %d bytes", len(lines[1]))
+	}
+	if off, _, _ := f.Offset(2); off != int64(3+len(long)+1) {
+		t.Errorf("line 2 at %d", off)
+	}
+}
+
+func TestResizeFollowsGrowthAndTruncation(t *testing.T) {
+	path, _ := numbered(t, 3, true)
+	f := open(t, path)
+	if _, n, _ := f.Offset(10); n != 2 {
+		t.Fatalf("last line %d", n)
+	}
+
+	// Appended lines are counted on from where counting stopped
+	af, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	af.WriteString("line 3\nline 4\n")
+	af.Close()
+	info, _ := os.Stat(path)
+	f.Resize(info.Size())
+	off, n, _ := f.Offset(10)
+	if lines, _, _ := f.ReadFrom(off, 1); n != 4 || lines[0] != "line 4" {
+		t.Errorf("after growth: line %d is %q", n, lines)
+	}
+
+	// Truncation starts over
+	os.WriteFile(path, []byte("new\n"), 0o644)
+	f.Resize(4)
+	if _, n, _ := f.Offset(10); n != 0 {
+		t.Errorf("after truncation: last line %d", n)
+	}
+	if lines, _, _ := f.ReadBefore(f.Size(), 5); len(lines) != 1 || lines[0] != "new" {
+		t.Errorf("after truncation: %q", lines)
+	}
+}
@@ -20,7 +20,12 @@ type Config struct {
 	ShowHidden      bool              `json:"show_hidden"`
 	PreviewEnabled  bool              `json:"preview_enabled"`
 	Frecency        map[string]int    `json:"frecency"`
-	LastVisited     map[string]string `json:"last_visited"` // path -> timestamp
+	LastVisited     map[string]string `json:"last_visited"`   // path -> timestamp
+	DisableIndex    bool              `json:"disable_index"`  // Turn off the persistent filename index for recursive/ultra search
+	NoIgnore        bool              `json:"no_ignore"`      // Include files excluded by .gitignore/.ignore in searches by default
+	CopyOwnership   bool              `json:"copy_ownership"` // Keep uid/gid when copying (needs root for other users' files)
+	CopyXattrs      bool              `json:"copy_xattrs"`    // Keep extended attributes when copying
+	ImageProtocol   string            `json:"image_protocol"` // Image previews: "auto" (detect), "kitty", "iterm2", "sixel", "blocks" or "none"
 }
 
 // Load reads config from ~/.config/scout/scout-config.json
@@ -244,3 +249,21 @@ func GetConfigPath() (string, error) {
 	}
 	return filepath.Join(homeDir, ".config", "scout", "scout-config.json"), nil
 }
+
+// GetIndexDir returns the directory holding the persistent filename indexes
+func GetIndexDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".config", "scout", "index"), nil
+}
+
+// GetHistoryPath returns the file holding the undo/redo journal of file operations
+func GetHistoryPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".config", "scout", "history.json"), nil
+}
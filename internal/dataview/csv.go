@@ -0,0 +1,211 @@
+package dataview
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	xansi "github.com/charmbracelet/x/ansi"
+)
+
+const (
+	maxTableRows  = 500 // Rows shown; the rest are only counted
+	maxCellWidth  = 30  // Widest a column gets; longer cells are cut with an ellipsis
+	headerSamples = 20  // Rows the header guess looks at
+)
+
+// CSV previews delimited values as a table with aligned columns, numeric ones aligned
+// right. A first row that looks like a header is set apart, and columns that don't fit
+// the preview's width are left off.
+type CSV struct {
+	Comma rune // ',' or '\t'; a .csv really separated by ';' or tabs is detected
+}
+
+func (c CSV) Name() string {
+	if c.Comma == '\t' {
+		return "TSV"
+	}
+	return "CSV"
+}
+
+func (c CSV) Render(src []byte, opts Options) (Result, error) {
+	r := csv.NewReader(bytes.NewReader(src))
+	r.Comma = c.sniff(src)
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+
+	var rows [][]string
+	total, columns := 0, 0
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Result{}, err
+		}
+		total++
+		columns = max(columns, len(record))
+		if len(rows) < maxTableRows+1 { // Room for a header
+			rows = append(rows, record)
+		}
+	}
+	if total == 0 {
+		return Result{}, errors.New("no rows")
+	}
+
+	header, first := hasHeader(rows), 0
+	if header {
+		first = 1
+	}
+	dataRows := total - first
+	rows = rows[:min(len(rows), maxTableRows+first)]
+
+	// Size the columns, and keep as many as fit the width
+	cells := make([][]string, len(rows))
+	widths := make([]int, columns)
+	for i, record := range rows {
+		cells[i] = make([]string, columns)
+		for j, field := range record {
+			cell := xansi.Truncate(strings.Join(strings.Fields(field), " "), maxCellWidth, "…")
+			cells[i][j] = cell
+			widths[j] = max(widths[j], lipgloss.Width(cell))
+		}
+	}
+	shown, used := 0, 0
+	for shown < columns && (shown == 0 || used+3+widths[shown] <= opts.Width || opts.Width <= 0) {
+		if shown > 0 {
+			used += 3
+		}
+		used += widths[shown]
+		shown++
+	}
+
+	numeric := make([]bool, shown)
+	for j := range numeric {
+		numeric[j] = numericColumn(cells[first:], j)
+	}
+
+	sep := ruleStyle.Render(" │ ")
+	var lines []string
+	for i, row := range cells {
+		padded := make([]string, shown)
+		for j := range padded {
+			gap := strings.Repeat(" ", widths[j]-lipgloss.Width(row[j]))
+			switch {
+			case header && i == 0:
+				padded[j] = headerStyle.Render(row[j]) + gap
+			case numeric[j]:
+				padded[j] = gap + row[j]
+			default:
+				padded[j] = row[j] + gap
+			}
+		}
+		lines = append(lines, strings.TrimRight(strings.Join(padded, sep), " "))
+		if header && i == 0 {
+			rule := make([]string, shown)
+			for j := range rule {
+				rule[j] = strings.Repeat("─", widths[j])
+			}
+			lines = append(lines, ruleStyle.Render(strings.Join(rule, "─┼─")))
+		}
+	}
+	if listed := len(cells) - first; listed < dataRows {
+		lines = append(lines, dimStyle.Render(fmt.Sprintf("… %d more rows", dataRows-listed)))
+	}
+
+	summary := fmt.Sprintf("%s · %s × %s", c.Name(), plural(dataRows, "row"), plural(columns, "column"))
+	if shown < columns {
+		summary += fmt.Sprintf(" (%d shown)", shown)
+	}
+	if header {
+		summary += " · header"
+	}
+	return Result{Summary: summary, Text: strings.Join(lines, "\n")}, nil
+}
+
+// sniff picks the delimiter of a .csv: whichever of commas, semicolons and tabs its first
+// line has most of
+func (c CSV) sniff(src []byte) rune {
+	if c.Comma != ',' {
+		return c.Comma
+	}
+	line, _, _ := bytes.Cut(src, []byte("\n"))
+	comma, most := ',', bytes.Count(line, []byte(","))
+	for _, d := range []rune{';', '\t'} {
+		if n := bytes.Count(line, []byte(string(d))); n > most {
+			comma, most = d, n
+		}
+	}
+	return comma
+}
+
+// hasHeader guesses whether the first row names the columns, column by column: a column
+// of numbers under a word votes for a header, as does a column of same-length values
+// under a different length; the first row fitting in with the rest votes against
+func hasHeader(rows [][]string) bool {
+	if len(rows) < 2 {
+		return false
+	}
+	seen := map[string]bool{}
+	for _, name := range rows[0] {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] || isNumber(name) {
+			return false // Headers name every column once, and not with a number
+		}
+		seen[name] = true
+	}
+
+	sample := rows[1:min(len(rows), headerSamples+1)]
+	votes := 0
+	for j, name := range rows[0] {
+		numbers, length, sameLength, filled := true, -1, true, 0
+		for _, row := range sample {
+			if j >= len(row) || strings.TrimSpace(row[j]) == "" {
+				continue
+			}
+			filled++
+			numbers = numbers && isNumber(row[j])
+			if length < 0 {
+				length = len(row[j])
+			} else if len(row[j]) != length {
+				sameLength = false
+			}
+		}
+		switch {
+		case filled == 0:
+		case numbers:
+			votes++
+		case sameLength && len(name) != length:
+			votes++
+		case sameLength:
+			votes--
+		}
+	}
+	return votes > 0
+}
+
+// numericColumn reports whether column j's non-empty cells are all numbers
+func numericColumn(rows [][]string, j int) bool {
+	found := false
+	for _, row := range rows {
+		if row[j] == "" {
+			continue
+		}
+		if !isNumber(row[j]) {
+			return false
+		}
+		found = true
+	}
+	return found
+}
+
+func isNumber(s string) bool {
+	_, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	return err == nil
+}
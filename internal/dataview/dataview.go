@@ -0,0 +1,121 @@
+// Package dataview renders structured data files for the preview pane: JSON (and JSON
+// Lines) as an indented tree, CSV and TSV as aligned tables, YAML and TOML as outlines
+// of their keys. Each format is a Previewer in its own file, looked up by file name, then
+// extension, in registries that Register adds to.
+package dataview
+
+import (
+	"bytes"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	keyStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("105"))
+	headerStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("105")).Bold(true)
+	stringStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
+	numberStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+	literalStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("99"))
+	dimStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+	ruleStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+)
+
+// Options shape a rendering
+type Options struct {
+	Width int // Columns the preview has; tables drop the columns that don't fit
+	Depth int // Levels of a tree or outline shown before folding, 0 for all
+}
+
+// Result is a rendered file
+type Result struct {
+	Summary string // One line about the whole file: "JSON · object, 12 keys"
+	Text    string
+	Depth   int // Levels the tree or outline has, 0 when it doesn't fold
+}
+
+// Previewer renders one format of data file
+type Previewer interface {
+	Name() string
+	// Render turns the file's contents into its preview, or says why it can't
+	Render(src []byte, opts Options) (Result, error)
+}
+
+// previewers are the registered previewers, by lower-case extension
+var previewers = map[string]Previewer{
+	".json":        JSON{},
+	".geojson":     JSON{},
+	".webmanifest": JSON{},
+	".jsonl":       JSONLines{},
+	".ndjson":      JSONLines{},
+	".csv":         CSV{Comma: ','},
+	".tsv":         CSV{Comma: '\t'},
+	".tab":         CSV{Comma: '\t'},
+	".yaml":        YAML{},
+	".yml":         YAML{},
+	".toml":        TOML{},
+}
+
+// named are the previewers for files known by their whole name, which take precedence
+// over the extension: dotfiles and lock files that are data without saying so
+var named = map[string]Previewer{
+	".babelrc":      jsonOrYAML{},
+	".eslintrc":     jsonOrYAML{},
+	".jshintrc":     jsonOrYAML{},
+	".prettierrc":   jsonOrYAML{},
+	".stylelintrc":  jsonOrYAML{},
+	".swcrc":        jsonOrYAML{},
+	"composer.lock": JSON{},
+	"flake.lock":    JSON{},
+	"pipfile.lock":  JSON{},
+	"cargo.lock":    TOML{},
+	"poetry.lock":   TOML{},
+	"pipfile":       TOML{},
+}
+
+// Register makes p the previewer for files with extension ext (".json"), replacing any
+func Register(ext string, p Previewer) {
+	previewers[strings.ToLower(ext)] = p
+}
+
+// RegisterName makes p the previewer for files called name (".babelrc"), replacing any
+func RegisterName(name string, p Previewer) {
+	named[strings.ToLower(name)] = p
+}
+
+// For returns the previewer for name, or nil if its type has none
+func For(name string) Previewer {
+	base := strings.ToLower(filepath.Base(name))
+	if p, ok := named[base]; ok {
+		return p
+	}
+	return previewers[filepath.Ext(base)]
+}
+
+// jsonOrYAML previews the rc files tools accept in either format: JSON if the content
+// starts like it, YAML otherwise
+type jsonOrYAML struct{}
+
+func (jsonOrYAML) Name() string { return "JSON or YAML" }
+
+func (jsonOrYAML) Render(src []byte, opts Options) (Result, error) {
+	if trimmed := bytes.TrimSpace(src); len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		return JSON{}.Render(src, opts)
+	}
+	return YAML{}.Render(src, opts)
+}
+
+// folded reports whether level is past the depth shown
+func folded(level, depth int) bool {
+	return depth > 0 && level >= depth
+}
+
+// plural is "1 key", "2 keys"
+func plural(n int, word string) string {
+	if n == 1 {
+		return "1 " + word
+	}
+	return strconv.Itoa(n) + " " + word + "s"
+}
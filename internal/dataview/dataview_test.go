@@ -0,0 +1,228 @@
+package dataview
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	xansi "github.com/charmbracelet/x/ansi"
+)
+
+func render(t *testing.T, name, src string, opts Options) Result {
+	t.Helper()
+	p := For(name)
+	if p == nil {
+		t.Fatalf("no previewer for %s", name)
+	}
+	res, err := p.Render([]byte(src), opts)
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	res.Text = xansi.Strip(res.Text)
+	return res
+}
+
+func TestForLooksUpByExtension(t *testing.T) {
+	for name, want := range map[string]string{
+		"a.json": "JSON", "B.CSV": "CSV", "c.tsv": "TSV", "d.yml": "YAML", "e.toml": "TOML",
+		"map.geojson": "JSON", "log.jsonl": "JSON Lines", "events.NDJSON": "JSON Lines", "f.tab": "TSV",
+		"/src/.babelrc": "JSON or YAML", "Cargo.lock": "TOML", "Pipfile": "TOML",
+	} {
+		if p := For(name); p == nil || p.Name() != want {
+			t.Errorf("For(%q) = %v, want %s", name, p, want)
+		}
+	}
+	if p := For("notes.txt"); p != nil {
+		t.Errorf("txt has previewer %s", p.Name())
+	}
+	if p := For("yarn.lock"); p != nil {
+		t.Errorf("yarn.lock has previewer %s", p.Name())
+	}
+	Register(".har", JSON{})
+	defer delete(previewers, ".har")
+	if p := For("session.har"); p == nil || p.Name() != "JSON" {
+		t.Error("registered previewer not found")
+	}
+}
+
+func TestJSONLinesAndRCFiles(t *testing.T) {
+	res := render(t, "log.jsonl", "{\"a\":1}\n\n[2,{\"b\":3}]\n", Options{})
+	want := `── line 1 ──
+{
+  "a": 1
+}
+── line 3 ──
+[
+  2,
+  {
+    "b": 3
+  }
+]`
+	if res.Text != want || res.Summary != "JSON Lines · 2 records" || res.Depth != 2 {
+		t.Errorf("%s, depth %d:\n%s", res.Summary, res.Depth, res.Text)
+	}
+	if _, err := (JSONLines{}).Render([]byte("{\"a\":1}\n{\"b\":\n"), Options{}); err == nil || !strings.HasPrefix(err.Error(), "line 2:") {
+		t.Errorf("broken record: %v", err)
+	}
+
+	if res := render(t, ".prettierrc", `{"semi": false}`, Options{}); !strings.HasPrefix(res.Summary, "JSON ·") {
+		t.Errorf("JSON rc file: %s", res.Summary)
+	}
+	if res := render(t, ".prettierrc", "semi: false\n", Options{}); !strings.HasPrefix(res.Summary, "YAML ·") {
+		t.Errorf("YAML rc file: %s", res.Summary)
+	}
+}
+
+func TestJSONTreeKeepsKeyOrderAndFolds(t *testing.T) {
+	src := `{"name":"scout","version":2,"tags":["tui","go"],"deps":{"bubbletea":{"v":"1.3"}},"empty":{},"ok":true,"none":null}`
+	res := render(t, "package.json", src, Options{})
+	want := `{
+  "name": "scout",
+  "version": 2,
+  "tags": [
+    "tui",
+    "go"
+  ],
+  "deps": {
+    "bubbletea": {
+      "v": "1.3"
+    }
+  },
+  "empty": {},
+  "ok": true,
+  "none": null
+}`
+	if res.Text != want {
+		t.Errorf("tree:\n%s", res.Text)
+	}
+	if res.Summary != "JSON · object, 7 keys" || res.Depth != 3 {
+		t.Errorf("summary %q, depth %d", res.Summary, res.Depth)
+	}
+
+	res = render(t, "package.json", src, Options{Depth: 1})
+	if !strings.Contains(res.Text, `"tags": […],  2 items`) || !strings.Contains(res.Text, `"deps": {…},  1 key`) || strings.Contains(res.Text, "bubbletea") {
+		t.Errorf("folded to depth 1:\n%s", res.Text)
+	}
+
+	for _, bad := range []string{`{"a":}`, `{"a":1} {"b":2}`, `[1,2`} {
+		if _, err := (JSON{}).Render([]byte(bad), Options{}); err == nil {
+			t.Errorf("%s: no error", bad)
+		}
+	}
+}
+
+func TestCSVTableAlignsAndDetectsHeader(t *testing.T) {
+	res := render(t, "people.csv", "name,age,city\nAlice,30,Paris\nBob,4,\"New\nYork\"\n", Options{Width: 80})
+	want := "name  │ age │ city\n" +
+		"──────┼─────┼─────────\n" +
+		"Alice │  30 │ Paris\n" +
+		"Bob   │   4 │ New York"
+	if res.Text != want {
+		t.Errorf("table:\n%s", res.Text)
+	}
+	if res.Summary != "CSV · 2 rows × 3 columns · header" {
+		t.Errorf("summary %q", res.Summary)
+	}
+
+	// Numbers in the first row: no header. Semicolons and tabs are picked up.
+	for _, src := range []string{"1;2\n3;4\n", "1\t2\n3\t4\n"} {
+		res = render(t, "data.csv", src, Options{Width: 80})
+		if res.Text != "1 │ 2\n3 │ 4" || strings.Contains(res.Summary, "header") {
+			t.Errorf("no header:\n%s (%s)", res.Text, res.Summary)
+		}
+	}
+
+	// Columns that don't fit are left off
+	res = render(t, "wide.tsv", "aaaaaaaaaa\tbbbbbbbbbb\tcccccccccc\n1\t2\t3\n", Options{Width: 25})
+	if !strings.HasPrefix(res.Summary, "TSV · 1 row × 3 columns (2 shown)") || strings.Contains(res.Text, "ccc") {
+		t.Errorf("narrow: %s\n%s", res.Summary, res.Text)
+	}
+
+	var rows strings.Builder
+	rows.WriteString("id\n")
+	for i := 0; i < maxTableRows+20; i++ {
+		fmt.Fprintf(&rows, "%d\n", i)
+	}
+	res = render(t, "long.csv", rows.String(), Options{Width: 80})
+	if !strings.HasSuffix(res.Text, "… 20 more rows") {
+		t.Errorf("long table ends %q", res.Text[len(res.Text)-40:])
+	}
+}
+
+func TestYAMLOutline(t *testing.T) {
+	src := `# config
+name: scout
+server:
+  host: localhost
+  ports:
+    - 80
+    - 443
+  motd: |
+    welcome: friend
+    bye
+users:
+  - name: ann
+    admin: true
+  - name: bo
+---
+other: 1
+`
+	res := render(t, "config.yaml", src, Options{})
+	want := `name: scout
+server
+  host: localhost
+  ports  [2 items]
+  motd: |…
+users
+  - name: ann
+  admin: true
+  - name: bo
+── document 2 ──
+other: 1`
+	if res.Text != want {
+		t.Errorf("outline:\n%s", res.Text)
+	}
+	if res.Summary != "YAML · 10 keys in 2 documents" || res.Depth != 2 {
+		t.Errorf("summary %q, depth %d", res.Summary, res.Depth)
+	}
+	res = render(t, "config.yaml", src, Options{Depth: 1})
+	if !strings.Contains(res.Text, "server …\nusers …\n") || strings.Contains(res.Text, "host") {
+		t.Errorf("folded:\n%s", res.Text)
+	}
+}
+
+func TestTOMLOutline(t *testing.T) {
+	src := `title = "demo" # comment
+[server]
+host = "example.com"
+ports = [
+  80,
+  443,
+]
+[server.tls]
+cert = """
+-----BEGIN-----
+"""
+[[plugins]]
+name = "a"
+[[plugins]]
+name = "b"
+`
+	res := render(t, "app.toml", src, Options{})
+	want := `title = "demo"
+[server]
+  host = "example.com"
+  ports = […]
+  [server.tls]
+    cert = """…
+[[plugins]] #1
+  name = "a"
+[[plugins]] #2
+  name = "b"`
+	if res.Text != want {
+		t.Errorf("outline:\n%s", res.Text)
+	}
+	if res.Summary != "TOML · 4 tables, 6 keys" {
+		t.Errorf("summary %q", res.Summary)
+	}
+}
@@ -0,0 +1,155 @@
+package dataview
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// maxJSONNesting guards the recursive parse against absurdly nested input
+const maxJSONNesting = 1000
+
+// JSON previews JSON as an indented tree, keys in the order the file has them. Objects
+// and arrays nested deeper than the depth asked for fold to a count of what's in them.
+type JSON struct{}
+
+func (JSON) Name() string { return "JSON" }
+
+// jsonNode is a value in the tree: a container with children, or a styled scalar
+type jsonNode struct {
+	key      string // Quoted key, in an object
+	open     byte   // '{' or '[' for containers, 0 for scalars
+	scalar   string
+	children []*jsonNode
+}
+
+func (JSON) Render(src []byte, opts Options) (Result, error) {
+	root, err := parseJSONValue(src)
+	if err != nil {
+		return Result{}, err
+	}
+
+	var lines []string
+	writeJSON(&lines, root, 0, opts.Depth, true)
+
+	summary := "JSON · " + root.scalar
+	switch root.open {
+	case '{':
+		summary = "JSON · object, " + plural(len(root.children), "key")
+	case '[':
+		summary = "JSON · array, " + plural(len(root.children), "item")
+	}
+	return Result{Summary: summary, Text: strings.Join(lines, "\n"), Depth: jsonDepth(root)}, nil
+}
+
+// parseJSONValue parses src, which must hold a single value
+func parseJSONValue(src []byte) (*jsonNode, error) {
+	dec := json.NewDecoder(bytes.NewReader(src))
+	dec.UseNumber()
+	root, err := parseJSON(dec, 0)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("more data after the top-level value")
+	}
+	return root, nil
+}
+
+// parseJSON reads the next value from dec
+func parseJSON(dec *json.Decoder, nesting int) (*jsonNode, error) {
+	if nesting > maxJSONNesting {
+		return nil, errors.New("nested too deeply")
+	}
+	tok, err := dec.Token()
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		n := &jsonNode{open: byte(t)}
+		for dec.More() {
+			var key string
+			if t == '{' {
+				k, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				key = strconv.Quote(k.(string))
+			}
+			child, err := parseJSON(dec, nesting+1)
+			if err != nil {
+				return nil, err
+			}
+			child.key = key
+			n.children = append(n.children, child)
+		}
+		if _, err := dec.Token(); err != nil { // The closing delimiter
+			return nil, err
+		}
+		return n, nil
+	case string:
+		return &jsonNode{scalar: stringStyle.Render(strconv.Quote(t))}, nil
+	case json.Number:
+		return &jsonNode{scalar: numberStyle.Render(t.String())}, nil
+	case bool:
+		return &jsonNode{scalar: literalStyle.Render(strconv.FormatBool(t))}, nil
+	case nil:
+		return &jsonNode{scalar: literalStyle.Render("null")}, nil
+	}
+	return nil, fmt.Errorf("unexpected %v", tok)
+}
+
+// writeJSON adds n's lines, indented for its level, folding it if it's past depth
+func writeJSON(lines *[]string, n *jsonNode, level, depth int, last bool) {
+	prefix := strings.Repeat("  ", level)
+	if n.key != "" {
+		prefix += keyStyle.Render(n.key) + ": "
+	}
+	comma := ","
+	if last {
+		comma = ""
+	}
+
+	if n.open == 0 {
+		*lines = append(*lines, prefix+n.scalar+comma)
+		return
+	}
+	closer := "}"
+	count := plural(len(n.children), "key")
+	if n.open == '[' {
+		closer = "]"
+		count = plural(len(n.children), "item")
+	}
+	switch {
+	case len(n.children) == 0:
+		*lines = append(*lines, prefix+string(n.open)+closer+comma)
+	case folded(level, depth):
+		*lines = append(*lines, prefix+string(n.open)+"…"+closer+comma+dimStyle.Render("  "+count))
+	default:
+		*lines = append(*lines, prefix+string(n.open))
+		for i, child := range n.children {
+			writeJSON(lines, child, level+1, depth, i == len(n.children)-1)
+		}
+		*lines = append(*lines, strings.Repeat("  ", level)+closer+comma)
+	}
+}
+
+// jsonDepth is how many levels of containers n has
+func jsonDepth(n *jsonNode) int {
+	if n.open == 0 {
+		return 0
+	}
+	deepest := 0
+	for _, child := range n.children {
+		deepest = max(deepest, jsonDepth(child))
+	}
+	return deepest + 1
+}
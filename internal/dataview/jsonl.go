@@ -0,0 +1,45 @@
+package dataview
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// maxJSONRecords is how many records of a JSON Lines file are shown; the rest are counted
+const maxJSONRecords = 200
+
+// JSONLines previews newline-delimited JSON (.jsonl, .ndjson): each record as its own
+// tree under a rule with its line number, folding like JSON.
+type JSONLines struct{}
+
+func (JSONLines) Name() string { return "JSON Lines" }
+
+func (JSONLines) Render(src []byte, opts Options) (Result, error) {
+	var lines []string
+	records, depth := 0, 0
+	for i, line := range bytes.Split(src, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		records++
+		if records > maxJSONRecords {
+			continue
+		}
+		root, err := parseJSONValue(line)
+		if err != nil {
+			return Result{}, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		lines = append(lines, dimStyle.Render(fmt.Sprintf("── line %d ──", i+1)))
+		writeJSON(&lines, root, 0, opts.Depth, true)
+		depth = max(depth, jsonDepth(root))
+	}
+	if records == 0 {
+		return Result{}, errors.New("no records")
+	}
+	if records > maxJSONRecords {
+		lines = append(lines, dimStyle.Render(fmt.Sprintf("… %d more records", records-maxJSONRecords)))
+	}
+	return Result{Summary: "JSON Lines · " + plural(records, "record"), Text: strings.Join(lines, "\n"), Depth: depth}, nil
+}
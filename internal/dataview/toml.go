@@ -0,0 +1,129 @@
+package dataview
+
+import (
+	"fmt"
+	"strings"
+
+	xansi "github.com/charmbracelet/x/ansi"
+)
+
+// TOML previews TOML as an outline: top-level keys, then each table with its keys under
+// it, nested by its dotted name, and arrays of tables numbered. Like YAML, it reads
+// lines rather than parsing.
+type TOML struct{}
+
+func (TOML) Name() string { return "TOML" }
+
+func (TOML) Render(src []byte, opts Options) (Result, error) {
+	var entries []outlineEntry
+	tables, keys := 0, 0
+	level := 0    // Level of the keys in the current table
+	closing := "" // Closing quotes of the multi-line string being skipped
+	brackets := 0 // Unclosed brackets of the multi-line array being skipped
+	arrays := map[string]int{}
+
+	for _, line := range strings.Split(string(src), "\n") {
+		line = strings.TrimSpace(line)
+		if closing != "" {
+			if strings.Contains(line, closing) {
+				closing = ""
+			}
+			continue
+		}
+		if brackets > 0 {
+			brackets += tomlBrackets(line)
+			continue
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			array := strings.HasPrefix(line, "[[")
+			name := strings.Trim(tomlStripComment(line), "[] \t")
+			parts := strings.Split(name, ".")
+			text := dimStyle.Render("[") + headerStyle.Render(name) + dimStyle.Render("]")
+			if array {
+				arrays[name]++
+				text = dimStyle.Render("[[") + headerStyle.Render(name) + dimStyle.Render(fmt.Sprintf("]] #%d", arrays[name]))
+			}
+			entries = append(entries, outlineEntry{level: len(parts) - 1, text: text})
+			level = len(parts)
+			tables++
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(tomlStripComment(value))
+		keys++
+		for _, quotes := range []string{`"""`, `'''`} {
+			if strings.HasPrefix(value, quotes) && !strings.Contains(value[3:], quotes) {
+				closing = quotes
+				value = quotes + "…"
+			}
+		}
+		if closing == "" && strings.HasPrefix(value, "[") {
+			if brackets = tomlBrackets(value); brackets > 0 {
+				value = "[…]"
+			}
+		}
+		entries = append(entries, outlineEntry{level: level, text: keyStyle.Render(key) + " = " + xansi.Truncate(value, maxOutlineValue, "…")})
+	}
+	if keys+tables == 0 {
+		return Result{}, fmt.Errorf("no keys found")
+	}
+
+	text, depth := writeOutline(entries, opts.Depth)
+	summary := fmt.Sprintf("TOML · %s, %s", plural(tables, "table"), plural(keys, "key"))
+	return Result{Summary: summary, Text: text, Depth: depth}, nil
+}
+
+// tomlBrackets is how many more brackets line opens than closes, outside strings
+func tomlBrackets(line string) int {
+	n := 0
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return n
+		case c == '[':
+			n++
+		case c == ']':
+			n--
+		}
+	}
+	return n
+}
+
+// tomlStripComment drops a trailing comment, leaving any # inside a string
+func tomlStripComment(s string) string {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return strings.TrimSpace(s[:i])
+		}
+	}
+	return s
+}
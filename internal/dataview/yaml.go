@@ -0,0 +1,150 @@
+package dataview
+
+import (
+	"fmt"
+	"strings"
+
+	xansi "github.com/charmbracelet/x/ansi"
+)
+
+// maxOutlineValue caps a scalar shown beside its key in an outline
+const maxOutlineValue = 60
+
+// YAML previews YAML as an outline of its keys, nested as the file indents them, with
+// scalar values beside their keys and lists of scalars counted. It reads lines rather
+// than parsing, so any YAML shows something, even one a parser would reject.
+type YAML struct{}
+
+func (YAML) Name() string { return "YAML" }
+
+// outlineEntry is a key in an outline
+type outlineEntry struct {
+	level int
+	text  string // Styled key, with its value if it has one
+	items int    // Scalars listed under it
+	note  bool   // Not a key: a document break
+}
+
+func (YAML) Render(src []byte, opts Options) (Result, error) {
+	type open struct{ indent, entry int }
+	var entries []outlineEntry
+	var stack []open // Keys enclosing the line being read, innermost last
+	docs, keys := 1, 0
+	block := -1 // Indent of the key a block scalar (| or >) belongs to, while inside one
+
+	for _, line := range strings.Split(string(src), "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		body := strings.TrimLeft(line, " ")
+		indent := len(line) - len(body)
+		if block >= 0 {
+			if body == "" || indent > block {
+				continue
+			}
+			block = -1
+		}
+		if body == "" || strings.HasPrefix(body, "#") || body == "..." || strings.HasPrefix(body, "%") {
+			continue
+		}
+		if body == "---" || strings.HasPrefix(body, "--- ") {
+			if len(entries) > 0 {
+				docs++
+				entries = append(entries, outlineEntry{text: dimStyle.Render(fmt.Sprintf("── document %d ──", docs)), note: true})
+			}
+			stack = stack[:0]
+			continue
+		}
+
+		item := body == "-" || strings.HasPrefix(body, "- ")
+		if item {
+			body = strings.TrimSpace(body[1:])
+			indent += 2
+		}
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+
+		key, value, ok := yamlKey(body)
+		if !ok {
+			if item && len(stack) > 0 {
+				entries[stack[len(stack)-1].entry].items++
+			}
+			continue // A list item, or a scalar running on from the line before
+		}
+		keys++
+		text := keyStyle.Render(key)
+		if item {
+			text = dimStyle.Render("- ") + text
+		}
+		switch {
+		case strings.HasPrefix(value, "|") || strings.HasPrefix(value, ">"):
+			block = indent
+			text += ": " + dimStyle.Render(value[:1]+"…")
+		case value != "":
+			text += ": " + xansi.Truncate(value, maxOutlineValue, "…")
+		}
+		stack = append(stack, open{indent: indent, entry: len(entries)})
+		entries = append(entries, outlineEntry{level: len(stack) - 1, text: text})
+	}
+	if keys == 0 {
+		return Result{}, fmt.Errorf("no keys found")
+	}
+
+	summary := "YAML · " + plural(keys, "key")
+	if docs > 1 {
+		summary += " in " + plural(docs, "document")
+	}
+	text, depth := writeOutline(entries, opts.Depth)
+	return Result{Summary: summary, Text: text, Depth: depth}, nil
+}
+
+// yamlKey splits "key: value" (or "key:"), skipping over quoted keys; flow collections
+// and plain scalars aren't keys
+func yamlKey(body string) (key, value string, ok bool) {
+	if body == "" || strings.ContainsRune("[{|>&*!", rune(body[0])) {
+		return "", "", false
+	}
+	start := 0
+	if q := body[0]; q == '"' || q == '\'' {
+		end := strings.IndexByte(body[1:], q)
+		if end < 0 {
+			return "", "", false
+		}
+		start = end + 2
+	}
+	i := strings.Index(body[start:], ":")
+	for i >= 0 {
+		at := start + i
+		if at+1 == len(body) || body[at+1] == ' ' || body[at+1] == '\t' {
+			value = strings.TrimSpace(body[at+1:])
+			if strings.HasPrefix(value, "#") {
+				value = ""
+			}
+			return body[:at], value, true
+		}
+		start = at + 1
+		i = strings.Index(body[start:], ":")
+	}
+	return "", "", false
+}
+
+// writeOutline indents the entries by level, leaving out those past depth and marking
+// the keys whose children were left out. It returns the text and the levels there are.
+func writeOutline(entries []outlineEntry, depth int) (string, int) {
+	levels := 0
+	var lines []string
+	for i, e := range entries {
+		levels = max(levels, e.level+1)
+		if folded(e.level, depth) {
+			continue
+		}
+		line := strings.Repeat("  ", e.level) + e.text
+		if e.items > 0 {
+			line += dimStyle.Render("  [" + plural(e.items, "item") + "]")
+		}
+		if !e.note && i+1 < len(entries) && entries[i+1].level > e.level && folded(e.level+1, depth) {
+			line += dimStyle.Render(" …")
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n"), levels
+}
@@ -0,0 +1,279 @@
+package fileops
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/LFroesch/scout/internal/logger"
+)
+
+// RenameOp moves From to To (both absolute paths)
+type RenameOp struct {
+	From string
+	To   string
+}
+
+// RenamePlan is a validated bulk rename, ready to apply
+type RenamePlan struct {
+	Renames []RenameOp // What was asked for, in list order (no-ops dropped)
+	Deletes []string   // Entries removed from the list
+	Steps   []RenameOp // Execution order: chains run back to front, cycles go through a temporary name
+	Cycles  int        // Swaps and longer cycles among Renames
+}
+
+// Empty reports whether the plan changes nothing
+func (p *RenamePlan) Empty() bool {
+	return len(p.Renames) == 0 && len(p.Deletes) == 0
+}
+
+const renameListHeader = `# Edit the names below, then save and quit to rename.
+# Keep the number at the start of each line; delete a line to move that file to the trash.
+# Lines starting with # are ignored.
+`
+
+// FormatRenameList writes paths as a numbered list for editing, vidir-style. Paths under
+// baseDir are written relative to it.
+func FormatRenameList(baseDir string, paths []string) string {
+	var b strings.Builder
+	b.WriteString(renameListHeader)
+	width := len(strconv.Itoa(len(paths)))
+	for i, p := range paths {
+		fmt.Fprintf(&b, "%0*d\t%s\n", width, i+1, displayPath(baseDir, p))
+	}
+	return b.String()
+}
+
+// ParseRenameList reads back a list written by FormatRenameList for paths. Changed names
+// become renames; numbers missing from the list become deletes. Relative names resolve
+// against baseDir.
+func ParseRenameList(baseDir string, paths []string, edited string) ([]RenameOp, []string, error) {
+	seen := make([]bool, len(paths))
+	var renames []RenameOp
+	for lineNo, line := range strings.Split(edited, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		digits := 0
+		for digits < len(line) && line[digits] >= '0' && line[digits] <= '9' {
+			digits++
+		}
+		if digits == 0 || digits == len(line) || (line[digits] != '\t' && line[digits] != ' ') {
+			return nil, nil, fmt.Errorf("line %d: expected a number followed by a name", lineNo+1)
+		}
+		id, _ := strconv.Atoi(line[:digits])
+		if id < 1 || id > len(paths) {
+			return nil, nil, fmt.Errorf("line %d: no entry numbered %d", lineNo+1, id)
+		}
+		if seen[id-1] {
+			return nil, nil, fmt.Errorf("line %d: entry %d appears more than once", lineNo+1, id)
+		}
+		seen[id-1] = true
+
+		name := strings.TrimLeft(line[digits:], "\t ")
+		if name == "" {
+			return nil, nil, fmt.Errorf("line %d: empty name", lineNo+1)
+		}
+		to := name
+		if !filepath.IsAbs(to) {
+			to = filepath.Join(baseDir, to)
+		}
+		renames = append(renames, RenameOp{From: paths[id-1], To: filepath.Clean(to)})
+	}
+
+	var deletes []string
+	for i, ok := range seen {
+		if !ok {
+			deletes = append(deletes, paths[i])
+		}
+	}
+	return renames, deletes, nil
+}
+
+// PlanRenames validates renames and deletes and orders the renames so none overwrites a
+// file that hasn't moved out of the way yet. It fails on collisions (two entries with the
+// same new name, or a new name that exists and isn't itself being renamed or deleted),
+// missing target directories, and entries nested inside other entries.
+func PlanRenames(renames []RenameOp, deletes []string) (*RenamePlan, error) {
+	plan := &RenamePlan{Deletes: deletes}
+	involved := make(map[string]bool) // Every path that moves or goes away
+	for _, d := range deletes {
+		involved[d] = true
+	}
+	for _, op := range renames {
+		if op.From != op.To {
+			plan.Renames = append(plan.Renames, op)
+			involved[op.From] = true
+		}
+	}
+
+	// An entry inside a renamed or deleted directory would be moved out from under us
+	for p := range involved {
+		if anc := involvedAncestor(involved, p); anc != "" {
+			return nil, fmt.Errorf("%s is inside %s; rename them separately", filepath.Base(p), filepath.Base(anc))
+		}
+	}
+
+	byFrom := make(map[string]int, len(plan.Renames))
+	targets := make(map[string]string, len(plan.Renames))
+	for i, op := range plan.Renames {
+		byFrom[op.From] = i
+	}
+	for _, op := range plan.Renames {
+		name := filepath.Base(op.To)
+		if other, dup := targets[op.To]; dup {
+			return nil, fmt.Errorf("collision: %s and %s would both be renamed to %s", filepath.Base(other), filepath.Base(op.From), name)
+		}
+		targets[op.To] = op.From
+
+		if anc := involvedAncestor(involved, op.To); anc != "" {
+			return nil, fmt.Errorf("cannot move %s into %s while it is renamed or deleted", filepath.Base(op.From), filepath.Base(anc))
+		}
+		if info, err := os.Stat(filepath.Dir(op.To)); err != nil || !info.IsDir() {
+			return nil, fmt.Errorf("cannot rename %s: directory %s does not exist", filepath.Base(op.From), filepath.Dir(op.To))
+		}
+		if existing, err := os.Lstat(op.To); err == nil && !involved[op.To] {
+			// A case-only rename on a case-insensitive filesystem finds the file itself
+			if from, err := os.Lstat(op.From); err != nil || !os.SameFile(existing, from) {
+				return nil, fmt.Errorf("collision: %s already exists", name)
+			}
+		}
+	}
+
+	// Each target is blocked by at most one rename (the one moving out of it), so the
+	// dependencies form chains and cycles. Follow each chain to its free end and emit it
+	// back to front; a chain that loops is a cycle, broken by parking one file under a
+	// temporary name.
+	state := make([]int, len(plan.Renames)) // 0 unvisited, 1 on the current chain, 2 emitted
+	for start := range plan.Renames {
+		if state[start] != 0 {
+			continue
+		}
+		var chain []int
+		i := start
+		for i >= 0 && state[i] == 0 {
+			state[i] = 1
+			chain = append(chain, i)
+			next, blocked := byFrom[plan.Renames[i].To]
+			if !blocked {
+				i = -1
+				break
+			}
+			i = next
+		}
+
+		waiting := chain
+		if i >= 0 && state[i] == 1 {
+			k := 0
+			for chain[k] != i {
+				k++
+			}
+			cycle := chain[k:]
+			waiting = chain[:k]
+			plan.Cycles++
+
+			first := plan.Renames[cycle[0]]
+			temp, err := tempRenamePath(first.From, len(plan.Steps))
+			if err != nil {
+				return nil, fmt.Errorf("cannot rename %s: %w", filepath.Base(first.From), err)
+			}
+			plan.Steps = append(plan.Steps, RenameOp{From: first.From, To: temp})
+			for j := len(cycle) - 1; j > 0; j-- {
+				plan.Steps = append(plan.Steps, plan.Renames[cycle[j]])
+			}
+			plan.Steps = append(plan.Steps, RenameOp{From: temp, To: first.To})
+		}
+		for j := len(waiting) - 1; j >= 0; j-- {
+			plan.Steps = append(plan.Steps, plan.Renames[waiting[j]])
+		}
+		for _, c := range chain {
+			state[c] = 2
+		}
+	}
+	return plan, nil
+}
+
+// ApplyRenames runs steps in order. If one fails, the steps already done are reversed so
+// the tree is left as it was, and the error is returned.
+func ApplyRenames(steps []RenameOp) error {
+	for i, step := range steps {
+		err := renameNoReplace(step.From, step.To)
+		if err == nil {
+			continue
+		}
+		for j := i - 1; j >= 0; j-- {
+			if rbErr := os.Rename(steps[j].To, steps[j].From); rbErr != nil {
+				logger.Error("Rollback %s -> %s failed: %v", steps[j].To, steps[j].From, rbErr)
+			}
+		}
+		return err
+	}
+	return nil
+}
+
+// UndoRenames reverses renames applied from a plan
+func UndoRenames(renames []RenameOp) error {
+	inverse := make([]RenameOp, len(renames))
+	for i, op := range renames {
+		inverse[i] = RenameOp{From: op.To, To: op.From}
+	}
+	plan, err := PlanRenames(inverse, nil)
+	if err != nil {
+		return err
+	}
+	return ApplyRenames(plan.Steps)
+}
+
+// renameNoReplace renames from to to, refusing to overwrite anything that appeared at to
+// since the plan was made (os.Rename silently replaces files on Unix)
+func renameNoReplace(from, to string) error {
+	if existing, err := os.Lstat(to); err == nil {
+		if src, err := os.Lstat(from); err != nil || !os.SameFile(existing, src) {
+			return fmt.Errorf("file already exists: %s appeared during the rename", filepath.Base(to))
+		}
+	}
+	if err := os.Rename(from, to); err != nil {
+		logger.Error("Rename %s -> %s failed: %v", from, to, err)
+		return FormatError(err, from, "rename")
+	}
+	return nil
+}
+
+// involvedAncestor returns the nearest ancestor of p that is in involved, or ""
+func involvedAncestor(involved map[string]bool, p string) string {
+	for dir := filepath.Dir(p); ; dir = filepath.Dir(dir) {
+		if involved[dir] {
+			return dir
+		}
+		if parent := filepath.Dir(dir); parent == dir {
+			return ""
+		}
+	}
+}
+
+// tempRenamePath picks an unused hidden name next to path for breaking a cycle. It fails
+// if a candidate can't be checked.
+func tempRenamePath(path string, n int) (string, error) {
+	dir, base := filepath.Split(path)
+	for ; ; n++ {
+		temp := filepath.Join(dir, fmt.Sprintf(".%s.scout-rename-%d", base, n))
+		_, err := os.Lstat(temp)
+		if os.IsNotExist(err) {
+			return temp, nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+// displayPath shows p relative to baseDir when it's inside it, absolute otherwise
+func displayPath(baseDir, p string) string {
+	if rel, err := filepath.Rel(baseDir, p); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return rel
+	}
+	return p
+}
@@ -0,0 +1,152 @@
+package fileops
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// bulkRenameDir creates files named after their own contents, so tests can check what ended up where
+func bulkRenameDir(t *testing.T, names ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func assertContents(t *testing.T, dir string, want map[string]string) {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != len(want) {
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		t.Fatalf("expected %d files, got %v", len(want), names)
+	}
+	for name, content := range want {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if string(data) != content {
+			t.Errorf("%s holds %q, want %q", name, data, content)
+		}
+	}
+}
+
+func TestParseRenameList(t *testing.T) {
+	dir := "/base"
+	paths := []string{"/base/a.txt", "/base/b.txt", "/base/c.txt", "/other/d.txt"}
+	list := FormatRenameList(dir, paths)
+	if !strings.Contains(list, "1\ta.txt\n") || !strings.Contains(list, "4\t/other/d.txt\n") {
+		t.Fatalf("unexpected list:\n%s", list)
+	}
+
+	edited := "# comment\n1\tA.txt\n3\tc.txt\n\n4 sub/d.txt\n"
+	renames, deletes, err := ParseRenameList(dir, paths, edited)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []RenameOp{{"/base/a.txt", "/base/A.txt"}, {"/base/c.txt", "/base/c.txt"}, {"/other/d.txt", "/base/sub/d.txt"}}
+	if len(renames) != len(want) {
+		t.Fatalf("renames = %v", renames)
+	}
+	for i := range want {
+		if renames[i] != want[i] {
+			t.Errorf("rename %d = %v, want %v", i, renames[i], want[i])
+		}
+	}
+	if len(deletes) != 1 || deletes[0] != "/base/b.txt" {
+		t.Errorf("deletes = %v", deletes)
+	}
+
+	for _, bad := range []string{"1\ta\n1\tb\n", "9\tx\n", "a.txt\n", "2\t\n"} {
+		if _, _, err := ParseRenameList(dir, paths, bad); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+}
+
+func TestPlanRenamesChainsAndCycles(t *testing.T) {
+	dir := bulkRenameDir(t, "a", "b", "c", "x", "y")
+	p := func(name string) string { return filepath.Join(dir, name) }
+
+	// a→b→c→a is a cycle; x→y→z is a chain that must run back to front
+	plan, err := PlanRenames([]RenameOp{
+		{p("a"), p("b")}, {p("b"), p("c")}, {p("c"), p("a")},
+		{p("x"), p("y")}, {p("y"), p("z")},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.Cycles != 1 {
+		t.Errorf("Cycles = %d, want 1", plan.Cycles)
+	}
+	if err := ApplyRenames(plan.Steps); err != nil {
+		t.Fatal(err)
+	}
+	assertContents(t, dir, map[string]string{"b": "a", "c": "b", "a": "c", "y": "x", "z": "y"})
+
+	if err := UndoRenames(plan.Renames); err != nil {
+		t.Fatal(err)
+	}
+	assertContents(t, dir, map[string]string{"a": "a", "b": "b", "c": "c", "x": "x", "y": "y"})
+}
+
+func TestPlanRenamesCollisions(t *testing.T) {
+	dir := bulkRenameDir(t, "a", "b", "keep")
+	p := func(name string) string { return filepath.Join(dir, name) }
+
+	cases := map[string][]RenameOp{
+		"same target":   {{p("a"), p("n")}, {p("b"), p("n")}},
+		"existing file": {{p("a"), p("keep")}},
+		"missing dir":   {{p("a"), p("nope/a")}},
+		"into itself":   {{p("a"), p("a/inner")}},
+	}
+	for name, ops := range cases {
+		if _, err := PlanRenames(ops, nil); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	// A deleted file's name is free for a rename
+	if _, err := PlanRenames([]RenameOp{{p("a"), p("keep")}}, []string{p("keep")}); err != nil {
+		t.Errorf("renaming onto a deleted entry: %v", err)
+	}
+}
+
+func TestApplyRenamesRollsBack(t *testing.T) {
+	dir := bulkRenameDir(t, "a", "b")
+	p := func(name string) string { return filepath.Join(dir, name) }
+
+	plan, err := PlanRenames([]RenameOp{{p("a"), p("a2")}, {p("b"), p("b2")}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Something appears at a target after planning; nothing may be overwritten
+	if err := os.WriteFile(p("b2"), []byte("new"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ApplyRenames(plan.Steps); err == nil {
+		t.Fatal("expected an error")
+	}
+	assertContents(t, dir, map[string]string{"a": "a", "b": "b", "b2": "new"})
+}
+
+func TestTempRenamePathFailsWhenUnreadable(t *testing.T) {
+	dir := bulkRenameDir(t, "a")
+	// Under a regular file every candidate fails with ENOTDIR; that must end the search
+	if got, err := tempRenamePath(filepath.Join(dir, "a", "b"), 0); err == nil {
+		t.Errorf("tempRenamePath under a file = %q, want an error", got)
+	}
+}
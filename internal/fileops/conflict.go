@@ -0,0 +1,73 @@
+package fileops
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+)
+
+// ConflictAction is what a paste does with an item whose name is already taken in the
+// destination directory
+type ConflictAction int
+
+const (
+	ConflictOverwrite        ConflictAction = iota // Replace files, merge into directories
+	ConflictSkip                                   // Leave the item out
+	ConflictKeepBoth                               // Paste under a free "name (1)" instead
+	ConflictOverwriteIfNewer                       // Like overwrite, but only files newer than what's there
+)
+
+func (a ConflictAction) String() string {
+	switch a {
+	case ConflictSkip:
+		return "skip"
+	case ConflictKeepBoth:
+		return "keep both"
+	case ConflictOverwriteIfNewer:
+		return "overwrite if newer"
+	}
+	return "overwrite"
+}
+
+// copySuffix matches a " (N)" a previous keep-both already added to a name
+var copySuffix = regexp.MustCompile(`^(.*) \((\d+)\)$`)
+
+// KeepBothPath returns a free path next to path for a "keep both" paste: "name (1).ext",
+// "name (2).ext", ... A name that already ends in " (N)" counts on from N. Paths in
+// reserved are treated as taken, so a batch can't hand out the same name twice. It fails
+// if a candidate can't be checked (the directory is unreadable or gone).
+func KeepBothPath(path string, isDir bool, reserved map[string]bool) (string, error) {
+	dir, name := filepath.Split(path)
+	stem, ext := splitExt(name, isDir)
+	n := 1
+	if m := copySuffix.FindStringSubmatch(stem); m != nil {
+		if prev, err := strconv.Atoi(m[2]); err == nil {
+			stem, n = m[1], prev+1
+		}
+	}
+	for ; ; n++ {
+		candidate := filepath.Join(dir, fmt.Sprintf("%s (%d)%s", stem, n, ext))
+		if reserved[candidate] {
+			continue
+		}
+		_, err := os.Lstat(candidate)
+		if os.IsNotExist(err) {
+			return candidate, nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+// skipOlder reports whether an OnlyNewer copy leaves dst alone: it exists and src isn't
+// newer than it
+func skipOlder(dst string, src os.FileInfo, opts CopyOptions) bool {
+	if !opts.OnlyNewer {
+		return false
+	}
+	existing, err := os.Lstat(dst)
+	return err == nil && !existing.IsDir() && !src.ModTime().After(existing.ModTime())
+}
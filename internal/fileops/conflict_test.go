@@ -0,0 +1,84 @@
+package fileops
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestKeepBothPath(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"report.pdf", "report (1).pdf", "photos", "notes (3).txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cases := []struct {
+		name  string
+		isDir bool
+		want  string
+	}{
+		{"report.pdf", false, "report (2).pdf"},
+		{"photos", true, "photos (1)"},
+		{"notes (3).txt", false, "notes (4).txt"},
+		{"archive.tar.gz", false, "archive.tar (1).gz"},
+		{".bashrc", false, ".bashrc (1)"},
+	}
+	for _, c := range cases {
+		got, err := KeepBothPath(filepath.Join(dir, c.name), c.isDir, nil)
+		if err != nil || got != filepath.Join(dir, c.want) {
+			t.Errorf("KeepBothPath(%q) = %q, %v, want %q", c.name, filepath.Base(got), err, c.want)
+		}
+	}
+
+	// Names handed out earlier in the same batch count as taken
+	reserved := map[string]bool{filepath.Join(dir, "report (2).pdf"): true}
+	if got, _ := KeepBothPath(filepath.Join(dir, "report.pdf"), false, reserved); filepath.Base(got) != "report (3).pdf" {
+		t.Errorf("KeepBothPath with reserved name = %q, want report (3).pdf", filepath.Base(got))
+	}
+
+	// A directory that can't be looked into is an error, not an endless search
+	if got, err := KeepBothPath(filepath.Join(dir, "report.pdf", "inside.txt"), false, nil); err == nil {
+		t.Errorf("KeepBothPath under a file = %q, want an error", got)
+	}
+}
+
+func TestCopyOnlyNewer(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	dst := filepath.Join(dir, "dst")
+	os.MkdirAll(src, 0755)
+	os.MkdirAll(dst, 0755)
+	old, now := time.Now().Add(-time.Hour), time.Now()
+
+	// fresh.txt is newer in src, stale.txt is newer in dst, only.txt is new
+	for name, content := range map[string]string{"fresh.txt": "src", "stale.txt": "src", "only.txt": "src"} {
+		os.WriteFile(filepath.Join(src, name), []byte(content), 0644)
+	}
+	for name, content := range map[string]string{"fresh.txt": "dst", "stale.txt": "dst"} {
+		os.WriteFile(filepath.Join(dst, name), []byte(content), 0644)
+	}
+	os.Chtimes(filepath.Join(src, "fresh.txt"), now, now)
+	os.Chtimes(filepath.Join(dst, "fresh.txt"), old, old)
+	os.Chtimes(filepath.Join(src, "stale.txt"), old, old)
+	os.Chtimes(filepath.Join(dst, "stale.txt"), now, now)
+
+	var skipped []string
+	var reported int64
+	opts := CopyOptions{OnlyNewer: true, OnSkip: func(p string) { skipped = append(skipped, p) }, OnBytes: func(n int64) { reported += n }}
+	if err := CopyFileOrDir(src, dst, opts); err != nil {
+		t.Fatalf("CopyFileOrDir: %v", err)
+	}
+	for name, want := range map[string]string{"fresh.txt": "src", "stale.txt": "dst", "only.txt": "src"} {
+		if data, _ := os.ReadFile(filepath.Join(dst, name)); string(data) != want {
+			t.Errorf("%s = %q, want %q", name, data, want)
+		}
+	}
+	if len(skipped) != 1 || skipped[0] != filepath.Join(src, "stale.txt") {
+		t.Errorf("skipped = %v, want only stale.txt", skipped)
+	}
+	if reported != 9 {
+		t.Errorf("reported %d bytes, want 9 (skipped files count as done)", reported)
+	}
+}
@@ -0,0 +1,266 @@
+package fileops
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/LFroesch/scout/internal/logger"
+)
+
+// copyBufferSize is the chunk size for the plain read/write fallback. Big enough to keep
+// disks streaming, small enough that a 4GB file never sits in memory.
+const copyBufferSize = 1 << 20
+
+// CopyOptions picks the metadata a copy carries over on top of what it always keeps:
+// contents, permission bits (including setuid/setgid/sticky), access and modification
+// times, and symlinks (copied as links, never followed). The hooks let a caller running
+// the copy in the background follow and stop it.
+type CopyOptions struct {
+	Ownership bool // Keep uid/gid. Only works as root (or for your own files); otherwise skipped
+	Xattrs    bool // Keep extended attributes the destination filesystem accepts
+	OnlyNewer bool // Leave files already at the destination alone unless the source is newer
+
+	OnBytes func(n int64)     // Called as file contents are written, with the bytes since the last call
+	OnFile  func(path string) // Called with each source file, link or directory once it's copied (or skipped)
+	OnSkip  func(path string) // Called with each source file OnlyNewer left uncopied
+	Cancel  <-chan struct{}   // Closing it stops the copy with ErrCancelled
+}
+
+// ErrCancelled is returned by a copy whose Cancel channel was closed
+var ErrCancelled = errors.New("cancelled")
+
+// errUnsupported marks metadata the platform or filesystem can't carry; those are skipped
+var errUnsupported = errors.New("not supported")
+
+// cancelled reports whether opts.Cancel has been closed
+func (opts CopyOptions) cancelled() bool {
+	select {
+	case <-opts.Cancel:
+		return true
+	default:
+		return false
+	}
+}
+
+func (opts CopyOptions) addBytes(n int64) {
+	if opts.OnBytes != nil && n > 0 {
+		opts.OnBytes(n)
+	}
+}
+
+func (opts CopyOptions) fileDone(path string) {
+	if opts.OnFile != nil {
+		opts.OnFile(path)
+	}
+}
+
+// copyEntry copies src to dst according to its type (as reported by Lstat)
+func copyEntry(src, dst string, info fs.FileInfo, opts CopyOptions) error {
+	if opts.cancelled() {
+		return ErrCancelled
+	}
+	if !info.IsDir() && skipOlder(dst, info, opts) {
+		// Counted as done so progress still adds up to the total
+		if info.Mode().IsRegular() {
+			opts.addBytes(info.Size())
+		}
+		if opts.OnSkip != nil {
+			opts.OnSkip(src)
+		}
+		opts.fileDone(src)
+		return nil
+	}
+	switch mode := info.Mode(); {
+	case mode.IsDir():
+		return copyDir(src, dst, info, opts)
+	case mode&fs.ModeSymlink != 0:
+		return copySymlink(src, dst, info, opts)
+	case mode.IsRegular():
+		return copyFile(src, dst, info, opts)
+	default:
+		// Devices, sockets and FIFOs have no contents to copy
+		logger.Warn("Skipping special file %s (%s)", src, mode.Type())
+		opts.fileDone(src)
+		return nil
+	}
+}
+
+// copyFile streams a regular file to dst, then applies src's metadata. The contents go to
+// a temporary file next to dst that's renamed over it only once complete, so a cancelled
+// or failed copy leaves a file already at dst as it was (and a symlink there is replaced,
+// never written through).
+func copyFile(src, dst string, info fs.FileInfo, opts CopyOptions) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	if existing, err := os.Lstat(dst); err == nil && os.SameFile(existing, info) {
+		return fmt.Errorf("cannot copy %s onto itself", filepath.Base(src))
+	}
+	out, err := os.CreateTemp(filepath.Dir(dst), "."+filepath.Base(dst)+".*.part")
+	if err != nil {
+		return err
+	}
+	tmp := out.Name()
+	done := false
+	defer func() {
+		if !done {
+			os.Remove(tmp)
+		}
+	}()
+
+	if err := copyContents(out, in, opts); err != nil {
+		out.Close()
+		if errors.Is(err, ErrCancelled) {
+			return err
+		}
+		return fmt.Errorf("copy %s: %w", filepath.Base(src), err)
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	if err := applyMetadata(src, tmp, info, opts); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		return err
+	}
+	done = true
+	opts.fileDone(src)
+	return nil
+}
+
+// copyBuffered is the portable fallback for copyContents: a plain buffered read/write
+// loop that reports progress and checks for cancellation after every chunk
+func copyBuffered(out io.Writer, in io.Reader, opts CopyOptions) error {
+	// progressWriter also hides ReaderFrom/WriterTo, so io.CopyBuffer really uses the fixed buffer
+	_, err := io.CopyBuffer(progressWriter{out, opts}, struct{ io.Reader }{in}, make([]byte, copyBufferSize))
+	return err
+}
+
+// progressWriter forwards writes, reporting them to opts and failing once the copy is cancelled
+type progressWriter struct {
+	w    io.Writer
+	opts CopyOptions
+}
+
+func (pw progressWriter) Write(p []byte) (int, error) {
+	if pw.opts.cancelled() {
+		return 0, ErrCancelled
+	}
+	n, err := pw.w.Write(p)
+	pw.opts.addBytes(int64(n))
+	return n, err
+}
+
+// copySymlink recreates the link itself, pointing at the same (possibly relative or dangling) target
+func copySymlink(src, dst string, info fs.FileInfo, opts CopyOptions) error {
+	target, err := os.Readlink(src)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Lstat(dst); err == nil {
+		if err := os.Remove(dst); err != nil {
+			return err
+		}
+	}
+	if err := os.Symlink(target, dst); err != nil {
+		return err
+	}
+	if opts.Ownership {
+		copyOwnership(dst, info)
+	}
+	if opts.Xattrs {
+		if err := copyXattrs(src, dst); err != nil {
+			return err
+		}
+	}
+	if err := setSymlinkTimes(dst, fileAtime(info), info.ModTime()); err != nil && !errors.Is(err, errUnsupported) {
+		return err
+	}
+	opts.fileDone(src)
+	return nil
+}
+
+// copyDir copies a directory tree. The directory's own mode and times are applied last,
+// so a read-only source directory can still be filled and its mtime survives the copy.
+func copyDir(src, dst string, info fs.FileInfo, opts CopyOptions) error {
+	if err := os.MkdirAll(dst, 0700); err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		srcPath := filepath.Join(src, entry.Name())
+		entryInfo, err := os.Lstat(srcPath)
+		if err != nil {
+			return err
+		}
+		if err := copyEntry(srcPath, filepath.Join(dst, entry.Name()), entryInfo, opts); err != nil {
+			return err
+		}
+	}
+	if err := applyMetadata(src, dst, info, opts); err != nil {
+		return err
+	}
+	opts.fileDone(src)
+	return nil
+}
+
+// applyMetadata gives dst src's ownership (optional), mode, xattrs (optional) and times,
+// in that order: chown clears setuid bits, and times go last because the others touch ctime
+func applyMetadata(src, dst string, info fs.FileInfo, opts CopyOptions) error {
+	if opts.Ownership {
+		copyOwnership(dst, info)
+	}
+	if err := os.Chmod(dst, info.Mode()&(fs.ModePerm|fs.ModeSetuid|fs.ModeSetgid|fs.ModeSticky)); err != nil {
+		return err
+	}
+	if opts.Xattrs {
+		if err := copyXattrs(src, dst); err != nil {
+			return err
+		}
+	}
+	return os.Chtimes(dst, fileAtime(info), info.ModTime())
+}
+
+// copyOwnership chowns dst to src's owner. Not being allowed to (the usual case when not
+// root) isn't an error, same as cp -p.
+func copyOwnership(dst string, info fs.FileInfo) {
+	uid, gid, ok := fileOwner(info)
+	if !ok {
+		return
+	}
+	if err := os.Lchown(dst, uid, gid); err != nil {
+		logger.Debug("Not preserving ownership of %s: %v", dst, err)
+	}
+}
+
+// copyXattrs copies every extended attribute of src the destination accepts
+func copyXattrs(src, dst string) error {
+	attrs, err := readXattrs(src)
+	if errors.Is(err, errUnsupported) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read extended attributes of %s: %w", filepath.Base(src), err)
+	}
+	for name, value := range attrs {
+		if err := writeXattr(dst, name, value); err != nil {
+			if errors.Is(err, errUnsupported) {
+				logger.Debug("Not preserving xattr %s on %s: %v", name, dst, err)
+				continue
+			}
+			return fmt.Errorf("set extended attribute %s on %s: %w", name, filepath.Base(dst), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+//go:build linux
+
+package fileops
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// copyContents copies in to out inside the kernel where it can: a reflink (FICLONE) on
+// filesystems that share extents (btrfs, XFS), then copy_file_range, then a buffered loop.
+// Progress is reported and cancellation checked between chunks.
+func copyContents(out, in *os.File, opts CopyOptions) error {
+	if err := unix.IoctlFileClone(int(out.Fd()), int(in.Fd())); err == nil {
+		if info, err := out.Stat(); err == nil {
+			opts.addBytes(info.Size())
+		}
+		return nil
+	}
+
+	copied := false
+	for {
+		if opts.cancelled() {
+			return ErrCancelled
+		}
+		n, err := unix.CopyFileRange(int(in.Fd()), nil, int(out.Fd()), nil, copyBufferSize*4, 0)
+		if err == unix.EINTR {
+			continue
+		}
+		if err != nil {
+			if copied {
+				return err
+			}
+			// Not available here (old kernel, cross-filesystem before 5.3, special
+			// filesystems): start over with plain reads and writes
+			break
+		}
+		if n == 0 {
+			if copied {
+				return nil
+			}
+			// Some pseudo-filesystems report 0 for files that aren't empty
+			break
+		}
+		copied = true
+		opts.addBytes(int64(n))
+	}
+	if _, err := in.Seek(0, 0); err != nil {
+		return err
+	}
+	if _, err := out.Seek(0, 0); err != nil {
+		return err
+	}
+	if err := out.Truncate(0); err != nil {
+		return err
+	}
+	return copyBuffered(out, in, opts)
+}
+
+// fileAtime returns info's access time
+func fileAtime(info fs.FileInfo) time.Time {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		return time.Unix(st.Atim.Sec, st.Atim.Nsec)
+	}
+	return info.ModTime()
+}
+
+// fileOwner returns info's uid and gid
+func fileOwner(info fs.FileInfo) (int, int, bool) {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		return int(st.Uid), int(st.Gid), true
+	}
+	return 0, 0, false
+}
+
+// setSymlinkTimes sets the times of the link itself rather than its target
+func setSymlinkTimes(path string, atime, mtime time.Time) error {
+	ts := []unix.Timespec{unix.NsecToTimespec(atime.UnixNano()), unix.NsecToTimespec(mtime.UnixNano())}
+	return unix.UtimesNanoAt(unix.AT_FDCWD, path, ts, unix.AT_SYMLINK_NOFOLLOW)
+}
+
+// readXattrs returns path's extended attributes (without following a symlink)
+func readXattrs(path string) (map[string][]byte, error) {
+	names, err := xattrCall(func(buf []byte) (int, error) { return unix.Llistxattr(path, buf) })
+	if err != nil {
+		return nil, err
+	}
+	attrs := make(map[string][]byte)
+	for _, name := range strings.Split(string(names), "\x00") {
+		if name == "" {
+			continue
+		}
+		value, err := xattrCall(func(buf []byte) (int, error) { return unix.Lgetxattr(path, name, buf) })
+		if errors.Is(err, unix.ENODATA) {
+			continue // Removed since it was listed
+		}
+		if err != nil {
+			return nil, err
+		}
+		attrs[name] = value
+	}
+	return attrs, nil
+}
+
+// writeXattr sets one extended attribute on path (without following a symlink)
+func writeXattr(path, name string, value []byte) error {
+	return xattrErr(unix.Lsetxattr(path, name, value, 0))
+}
+
+// xattrCall runs a size-then-fill xattr syscall, retrying if the value grows in between
+func xattrCall(call func([]byte) (int, error)) ([]byte, error) {
+	for {
+		size, err := call(nil)
+		if err != nil {
+			return nil, xattrErr(err)
+		}
+		if size == 0 {
+			return nil, nil
+		}
+		buf := make([]byte, size)
+		n, err := call(buf)
+		if errors.Is(err, unix.ERANGE) {
+			continue
+		}
+		if err != nil {
+			return nil, xattrErr(err)
+		}
+		return buf[:n], nil
+	}
+}
+
+// xattrErr folds "this filesystem/namespace can't do that" into errUnsupported. EPERM
+// covers user.* attributes on symlinks and trusted.*/security.* without privileges.
+func xattrErr(err error) error {
+	if errors.Is(err, unix.ENOTSUP) || errors.Is(err, unix.EPERM) {
+		return errors.Join(errUnsupported, err)
+	}
+	return err
+}
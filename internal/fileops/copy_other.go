@@ -0,0 +1,35 @@
+//go:build !linux
+
+package fileops
+
+import (
+	"io/fs"
+	"os"
+	"time"
+)
+
+// copyContents copies in to out with a buffered loop
+func copyContents(out, in *os.File, opts CopyOptions) error {
+	return copyBuffered(out, in, opts)
+}
+
+// fileAtime falls back to the modification time where the access time isn't portable
+func fileAtime(info fs.FileInfo) time.Time {
+	return info.ModTime()
+}
+
+func fileOwner(info fs.FileInfo) (int, int, bool) {
+	return 0, 0, false
+}
+
+func setSymlinkTimes(path string, atime, mtime time.Time) error {
+	return errUnsupported
+}
+
+func readXattrs(path string) (map[string][]byte, error) {
+	return nil, errUnsupported
+}
+
+func writeXattr(path, name string, value []byte) error {
+	return errUnsupported
+}
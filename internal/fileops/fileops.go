@@ -138,22 +138,25 @@ func MoveToTrash(path string) error {
 		cmd := exec.Command("powershell", "-Command", fmt.Sprintf(`Add-Type -AssemblyName Microsoft.VisualBasic; [Microsoft.VisualBasic.FileIO.FileSystem]::DeleteFile('%s', 'OnlyErrorDialogs', 'SendToRecycleBin')`, path))
 		return cmd.Run()
 
-	default: // Linux and others
-		// Try using trash-cli or gio trash on Linux
-		if commandExists("gio") {
-			cmd := exec.Command("gio", "trash", path)
-			return cmd.Run()
-		}
-		if commandExists("trash-put") {
-			cmd := exec.Command("trash-put", path)
-			return cmd.Run()
-		}
-		return fmt.Errorf("trash command not available (install trash-cli or gvfs)")
+	default: // Linux and others: the freedesktop.org trash (see trash.go)
+		_, err := PutInTrash(path)
+		return err
 	}
 }
 
-// Delete deletes a file or directory (tries trash first, then permanent delete)
+// nativeTrash reports whether scout implements the trash itself (freedesktop.org spec)
+// rather than asking the OS
+func nativeTrash() bool {
+	return runtime.GOOS != "darwin" && runtime.GOOS != "windows"
+}
+
+// Delete moves a file or directory to the trash. Where the trash is the OS's, a failure
+// falls back to permanent delete; the native trash only fails for real problems (a
+// read-only filesystem, say), which are reported instead.
 func Delete(path string, isDir bool) error {
+	if nativeTrash() {
+		return MoveToTrash(path)
+	}
 	// Try to move to trash first
 	if err := MoveToTrash(path); err != nil {
 		logger.Warn("Trash failed for %s, falling back to permanent delete: %v", path, err)
@@ -195,13 +198,21 @@ func getUndoDir() (string, error) {
 	return undoDir, nil
 }
 
-// DeleteWithUndo deletes a file or directory and returns the trash path for undo
-// Returns (trashPath, error)
+// DeleteWithUndo moves a file or directory to the trash and returns what RestoreFromTrash
+// needs to bring it back: the entry's .trashinfo file, or on macOS and Windows a record
+// in ~/.config/scout/undo
 func DeleteWithUndo(path string, isDir bool) (string, error) {
 	// Check permissions first
 	if err := CheckPermissions(path, PermWrite); err != nil {
 		return "", err
 	}
+	if nativeTrash() {
+		entry, err := PutInTrash(path)
+		if err != nil {
+			return "", err
+		}
+		return entry.InfoPath(), nil
+	}
 
 	absPath, err := filepath.Abs(path)
 	if err != nil {
@@ -213,8 +224,7 @@ func DeleteWithUndo(path string, isDir bool) (string, error) {
 	basename := filepath.Base(absPath)
 	undoID := fmt.Sprintf("%s_%s", timestamp, basename)
 
-	// For Linux with trash-cli, files go to ~/.local/share/Trash
-	// For simple undo, we'll track the metadata
+	// The OS trash doesn't say where it put the file, so track the metadata
 	undoDir, err := getUndoDir()
 	if err != nil {
 		return "", err
@@ -230,12 +240,6 @@ func DeleteWithUndo(path string, isDir bool) (string, error) {
 
 	// Try to determine trash location based on OS
 	switch runtime.GOOS {
-	case "linux":
-		// Linux trash location
-		home, _ := os.UserHomeDir()
-		if home != "" {
-			info.TrashPath = filepath.Join(home, ".local", "share", "Trash", "files", basename)
-		}
 	case "darwin":
 		// macOS trash location
 		home, _ := os.UserHomeDir()
@@ -271,6 +275,16 @@ func RestoreFromTrash(undoInfoPath, originalPath string) error {
 	if undoInfoPath == "" {
 		return fmt.Errorf("undo not available for this deletion")
 	}
+	if strings.HasSuffix(undoInfoPath, ".trashinfo") {
+		entry, err := LoadTrashEntry(undoInfoPath)
+		if os.IsNotExist(err) {
+			return fmt.Errorf("file no longer in trash (may have been restored or permanently deleted)")
+		}
+		if err != nil {
+			return err
+		}
+		return entry.Restore()
+	}
 
 	// Read undo info
 	data, err := os.ReadFile(undoInfoPath)
@@ -383,68 +397,28 @@ func CreateDir(dir, name string) error {
 	return nil
 }
 
-// CopyFileOrDir copies a file or directory from src to dst
-func CopyFileOrDir(src, dst string) error {
-	srcInfo, err := os.Stat(src)
+// CopyFileOrDir copies a file, symlink or directory tree from src to dst, streaming
+// contents and keeping metadata as described by CopyOptions
+func CopyFileOrDir(src, dst string, opts CopyOptions) error {
+	srcInfo, err := os.Lstat(src)
 	if err != nil {
 		return err
 	}
 
 	if srcInfo.IsDir() {
-		return copyDir(src, dst)
-	}
-	return copyFile(src, dst)
-}
-
-// copyFile copies a single file
-func copyFile(src, dst string) error {
-	srcBytes, err := os.ReadFile(src)
-	if err != nil {
-		return err
-	}
-
-	return os.WriteFile(dst, srcBytes, 0644)
-}
-
-// copyDir copies a directory recursively
-func copyDir(src, dst string) error {
-	srcInfo, err := os.Stat(src)
-	if err != nil {
-		return err
-	}
-
-	if err := os.MkdirAll(dst, srcInfo.Mode()); err != nil {
-		return err
-	}
-
-	entries, err := os.ReadDir(src)
-	if err != nil {
-		return err
-	}
-
-	for _, entry := range entries {
-		srcPath := filepath.Join(src, entry.Name())
-		dstPath := filepath.Join(dst, entry.Name())
-
-		if entry.IsDir() {
-			if err := copyDir(srcPath, dstPath); err != nil {
-				return err
-			}
-		} else {
-			if err := copyFile(srcPath, dstPath); err != nil {
-				return err
-			}
+		rel, err := filepath.Rel(src, dst)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("cannot copy %s into itself", filepath.Base(src))
 		}
 	}
-
-	return nil
+	return copyEntry(src, dst, srcInfo, opts)
 }
 
 // CopyMultiple copies multiple files/directories to a destination directory
-func CopyMultiple(sources []string, destDir string) error {
+func CopyMultiple(sources []string, destDir string, opts CopyOptions) error {
 	for _, srcPath := range sources {
 		destPath := filepath.Join(destDir, filepath.Base(srcPath))
-		if err := CopyFileOrDir(srcPath, destPath); err != nil {
+		if err := CopyFileOrDir(srcPath, destPath, opts); err != nil {
 			logger.Error("Copy %s -> %s failed: %v", srcPath, destPath, err)
 			return err
 		}
@@ -459,7 +433,8 @@ func MoveMultiple(sources []string, destDir string) error {
 		if err := os.Rename(srcPath, destPath); err != nil {
 			// If rename fails (cross-device), copy then delete
 			logger.Info("Rename %s -> %s failed (%v), falling back to copy+delete", srcPath, destPath, err)
-			if err := CopyFileOrDir(srcPath, destPath); err != nil {
+			// A move keeps everything it can, like rename would have
+			if err := CopyFileOrDir(srcPath, destPath, CopyOptions{Ownership: true, Xattrs: true}); err != nil {
 				logger.Error("Move fallback copy %s -> %s failed: %v", srcPath, destPath, err)
 				return err
 			}
@@ -1,9 +1,13 @@
 package fileops
 
 import (
+	"bytes"
+	"errors"
 	"os"
 	"path/filepath"
+	"runtime"
 	"testing"
+	"time"
 )
 
 func TestCreateFile(t *testing.T) {
@@ -97,9 +101,9 @@ func TestCopyFile(t *testing.T) {
 
 	// Copy file
 	dstPath := filepath.Join(tempDir, "dest.txt")
-	err := copyFile(srcPath, dstPath)
+	err := CopyFileOrDir(srcPath, dstPath, CopyOptions{})
 	if err != nil {
-		t.Fatalf("copyFile failed: %v", err)
+		t.Fatalf("CopyFileOrDir failed: %v", err)
 	}
 
 	// Verify destination exists
@@ -128,9 +132,9 @@ func TestCopyDir(t *testing.T) {
 
 	// Copy directory
 	dstDir := filepath.Join(tempDir, "dstdir")
-	err := copyDir(srcDir, dstDir)
+	err := CopyFileOrDir(srcDir, dstDir, CopyOptions{})
 	if err != nil {
-		t.Fatalf("copyDir failed: %v", err)
+		t.Fatalf("CopyFileOrDir failed: %v", err)
 	}
 
 	// Verify destination directory exists
@@ -163,7 +167,7 @@ func TestCopyMultiple(t *testing.T) {
 
 	// Copy multiple files
 	sources := []string{file1, file2}
-	err := CopyMultiple(sources, dstDir)
+	err := CopyMultiple(sources, dstDir, CopyOptions{})
 	if err != nil {
 		t.Fatalf("CopyMultiple failed: %v", err)
 	}
@@ -178,6 +182,252 @@ func TestCopyMultiple(t *testing.T) {
 	}
 }
 
+func TestCopyLargeFileStreams(t *testing.T) {
+	tempDir := t.TempDir()
+
+	// Bigger than the copy buffer, with content that changes across chunk boundaries
+	content := make([]byte, 3*copyBufferSize+12345)
+	for i := range content {
+		content[i] = byte(i*7 + i/4096)
+	}
+	srcPath := filepath.Join(tempDir, "big.bin")
+	os.WriteFile(srcPath, content, 0644)
+
+	dstPath := filepath.Join(tempDir, "big-copy.bin")
+	var reported int64
+	files := 0
+	opts := CopyOptions{OnBytes: func(n int64) { reported += n }, OnFile: func(string) { files++ }}
+	if err := CopyFileOrDir(srcPath, dstPath, opts); err != nil {
+		t.Fatalf("CopyFileOrDir failed: %v", err)
+	}
+	dstContent, _ := os.ReadFile(dstPath)
+	if !bytes.Equal(dstContent, content) {
+		t.Error("Copied content doesn't match original")
+	}
+	if reported != int64(len(content)) || files != 1 {
+		t.Errorf("progress reported %d bytes, %d files; want %d bytes, 1 file", reported, files, len(content))
+	}
+
+	// The buffered fallback on its own
+	var buf bytes.Buffer
+	if err := copyBuffered(&buf, bytes.NewReader(content), CopyOptions{}); err != nil || !bytes.Equal(buf.Bytes(), content) {
+		t.Errorf("copyBuffered mangled the content (err %v)", err)
+	}
+
+	// and stopping as soon as it's cancelled
+	cancel := make(chan struct{})
+	close(cancel)
+	buf.Reset()
+	if err := copyBuffered(&buf, bytes.NewReader(content), CopyOptions{Cancel: cancel}); !errors.Is(err, ErrCancelled) || buf.Len() != 0 {
+		t.Errorf("cancelled copyBuffered = %v after %d bytes, want ErrCancelled before any", err, buf.Len())
+	}
+}
+
+func TestCancelledCopyKeepsExistingFile(t *testing.T) {
+	tempDir := t.TempDir()
+	srcPath := filepath.Join(tempDir, "new.txt")
+	dstPath := filepath.Join(tempDir, "old.txt")
+	os.WriteFile(srcPath, []byte("new contents"), 0644)
+	os.WriteFile(dstPath, []byte("old"), 0644)
+	info, _ := os.Lstat(srcPath)
+
+	cancel := make(chan struct{})
+	close(cancel)
+	err := copyFile(srcPath, dstPath, info, CopyOptions{Cancel: cancel})
+	if err == nil {
+		t.Skip("filesystem cloned the file before the cancel was seen")
+	}
+	if !errors.Is(err, ErrCancelled) {
+		t.Fatalf("copyFile = %v, want ErrCancelled", err)
+	}
+	if data, _ := os.ReadFile(dstPath); string(data) != "old" {
+		t.Errorf("destination = %q after a cancelled copy, want it untouched", data)
+	}
+	if entries, _ := os.ReadDir(tempDir); len(entries) != 2 {
+		t.Errorf("temporary file left behind: %d entries", len(entries))
+	}
+}
+
+func TestCopyPreservesModeAndTimes(t *testing.T) {
+	tempDir := t.TempDir()
+
+	srcPath := filepath.Join(tempDir, "script.sh")
+	os.WriteFile(srcPath, []byte("#!/bin/sh\necho hi\n"), 0644)
+	os.Chmod(srcPath, 0750)
+	atime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	mtime := time.Date(2021, 6, 7, 8, 9, 10, 0, time.UTC)
+	os.Chtimes(srcPath, atime, mtime)
+
+	dstPath := filepath.Join(tempDir, "script-copy.sh")
+	if err := CopyFileOrDir(srcPath, dstPath, CopyOptions{}); err != nil {
+		t.Fatalf("CopyFileOrDir failed: %v", err)
+	}
+	info, err := os.Stat(dstPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0750 {
+		t.Errorf("Mode = %v, want 0750 (executable bit lost?)", info.Mode().Perm())
+	}
+	if !info.ModTime().Equal(mtime) {
+		t.Errorf("ModTime = %v, want %v", info.ModTime(), mtime)
+	}
+	if runtime.GOOS == "linux" && !fileAtime(info).Equal(atime) {
+		t.Errorf("Atime = %v, want %v", fileAtime(info), atime)
+	}
+
+	// Read-only files copy fine and stay read-only
+	roPath := filepath.Join(tempDir, "readonly.txt")
+	os.WriteFile(roPath, []byte("ro"), 0444)
+	roCopy := filepath.Join(tempDir, "readonly-copy.txt")
+	if err := CopyFileOrDir(roPath, roCopy, CopyOptions{}); err != nil {
+		t.Fatalf("copying a read-only file failed: %v", err)
+	}
+	if info, _ := os.Stat(roCopy); info.Mode().Perm() != 0444 {
+		t.Errorf("read-only copy has mode %v", info.Mode().Perm())
+	}
+
+	// Copying a file onto itself must not truncate it
+	if err := CopyFileOrDir(srcPath, srcPath, CopyOptions{}); err == nil {
+		t.Error("Expected error when copying a file onto itself")
+	}
+	if data, _ := os.ReadFile(srcPath); len(data) == 0 {
+		t.Error("Source was truncated by copying onto itself")
+	}
+}
+
+func TestCopySymlinksAsLinks(t *testing.T) {
+	tempDir := t.TempDir()
+
+	srcDir := filepath.Join(tempDir, "src")
+	os.Mkdir(srcDir, 0755)
+	os.WriteFile(filepath.Join(srcDir, "target.txt"), []byte("target"), 0644)
+	os.Symlink("target.txt", filepath.Join(srcDir, "relative"))
+	os.Symlink(filepath.Join(tempDir, "missing"), filepath.Join(srcDir, "dangling"))
+
+	dstDir := filepath.Join(tempDir, "dst")
+	if err := CopyFileOrDir(srcDir, dstDir, CopyOptions{}); err != nil {
+		t.Fatalf("CopyFileOrDir failed: %v", err)
+	}
+	for link, want := range map[string]string{"relative": "target.txt", "dangling": filepath.Join(tempDir, "missing")} {
+		info, err := os.Lstat(filepath.Join(dstDir, link))
+		if err != nil {
+			t.Errorf("%s was not copied: %v", link, err)
+			continue
+		}
+		if info.Mode()&os.ModeSymlink == 0 {
+			t.Errorf("%s was copied as a regular file", link)
+			continue
+		}
+		if got, _ := os.Readlink(filepath.Join(dstDir, link)); got != want {
+			t.Errorf("%s points to %q, want %q", link, got, want)
+		}
+	}
+
+	// A symlink copied on its own stays a symlink too
+	single := filepath.Join(tempDir, "single-link")
+	if err := CopyFileOrDir(filepath.Join(srcDir, "relative"), single, CopyOptions{}); err != nil {
+		t.Fatalf("copying a symlink failed: %v", err)
+	}
+	if info, err := os.Lstat(single); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Error("top-level symlink was followed instead of copied")
+	}
+}
+
+func TestCopyDirPreservesModeAndTimes(t *testing.T) {
+	tempDir := t.TempDir()
+
+	srcDir := filepath.Join(tempDir, "src")
+	os.Mkdir(srcDir, 0755)
+	os.WriteFile(filepath.Join(srcDir, "file.txt"), []byte("content"), 0644)
+	mtime := time.Date(2019, 5, 6, 7, 8, 9, 0, time.UTC)
+	os.Chtimes(srcDir, mtime, mtime)
+	os.Chmod(srcDir, 0555) // Read-only: the copy still has to be filled
+	defer os.Chmod(srcDir, 0755)
+
+	dstDir := filepath.Join(tempDir, "dst")
+	if err := CopyFileOrDir(srcDir, dstDir, CopyOptions{}); err != nil {
+		t.Fatalf("CopyFileOrDir failed: %v", err)
+	}
+	defer os.Chmod(dstDir, 0755)
+	info, err := os.Stat(dstDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0555 {
+		t.Errorf("Mode = %v, want 0555", info.Mode().Perm())
+	}
+	if !info.ModTime().Equal(mtime) {
+		t.Errorf("ModTime = %v, want %v (entries written after the times were set?)", info.ModTime(), mtime)
+	}
+	if _, err := os.Stat(filepath.Join(dstDir, "file.txt")); err != nil {
+		t.Error("file.txt was not copied")
+	}
+
+	if err := CopyFileOrDir(tempDir, filepath.Join(srcDir, "inside"), CopyOptions{}); err == nil {
+		t.Error("Expected error when copying a directory into itself")
+	}
+}
+
+func TestCopyPreservesXattrs(t *testing.T) {
+	tempDir := t.TempDir()
+
+	srcPath := filepath.Join(tempDir, "tagged.txt")
+	os.WriteFile(srcPath, []byte("content"), 0644)
+	if err := writeXattr(srcPath, "user.scout.test", []byte("value")); err != nil {
+		t.Skipf("extended attributes not supported here: %v", err)
+	}
+
+	plain := filepath.Join(tempDir, "plain.txt")
+	if err := CopyFileOrDir(srcPath, plain, CopyOptions{}); err != nil {
+		t.Fatalf("CopyFileOrDir failed: %v", err)
+	}
+	if attrs, _ := readXattrs(plain); len(attrs) != 0 {
+		t.Errorf("xattrs copied without being asked for: %v", attrs)
+	}
+
+	tagged := filepath.Join(tempDir, "tagged-copy.txt")
+	if err := CopyFileOrDir(srcPath, tagged, CopyOptions{Xattrs: true}); err != nil {
+		t.Fatalf("CopyFileOrDir with xattrs failed: %v", err)
+	}
+	attrs, err := readXattrs(tagged)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(attrs["user.scout.test"]) != "value" {
+		t.Errorf("xattrs = %v, want user.scout.test=value", attrs)
+	}
+}
+
+func TestCopyPreservesOwnership(t *testing.T) {
+	tempDir := t.TempDir()
+
+	srcPath := filepath.Join(tempDir, "owned.txt")
+	os.WriteFile(srcPath, []byte("content"), 0644)
+	info, _ := os.Lstat(srcPath)
+	uid, gid, ok := fileOwner(info)
+	if !ok {
+		t.Skip("ownership not available on this platform")
+	}
+	if os.Geteuid() == 0 {
+		// As root the source can belong to someone else
+		uid, gid = 4242, 4343
+		if err := os.Chown(srcPath, uid, gid); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dstPath := filepath.Join(tempDir, "owned-copy.txt")
+	if err := CopyFileOrDir(srcPath, dstPath, CopyOptions{Ownership: true}); err != nil {
+		t.Fatalf("CopyFileOrDir with ownership failed: %v", err)
+	}
+	dstInfo, _ := os.Lstat(dstPath)
+	gotUID, gotGID, _ := fileOwner(dstInfo)
+	if gotUID != uid || gotGID != gid {
+		t.Errorf("owner = %d:%d, want %d:%d", gotUID, gotGID, uid, gid)
+	}
+}
+
 func TestFormatError(t *testing.T) {
 	// Test with nil error
 	err := FormatError(nil, "/test/path", "test operation")
@@ -0,0 +1,314 @@
+package fileops
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/LFroesch/scout/internal/logger"
+)
+
+// maxJournalOps caps the history kept on disk
+const maxJournalOps = 200
+
+// Operation kinds recorded in the journal
+const (
+	OpTrash    = "trash"    // Items sent to the trash
+	OpRename   = "rename"   // Renames in place, including bulk and pattern renames
+	OpMove     = "move"     // A cut-and-paste, possibly across filesystems
+	OpCopy     = "copy"     // A copy-and-paste
+	OpCreate   = "create"   // A new file or directory
+	OpExtract  = "extract"  // What an archive was unpacked into
+	OpCompress = "compress" // A new archive
+)
+
+// TrashedItem is a path sent to the trash with the undo record that brings it back
+type TrashedItem struct {
+	Path     string `json:"path"`
+	IsDir    bool   `json:"is_dir"`
+	UndoInfo string `json:"undo_info"` // As returned by DeleteWithUndo
+}
+
+// Op is one journal entry. Doing an op means: trash Trashed, then carry out Moves, then
+// (for copies, creates, extracts and compresses) Created exists. Undoing runs the inverse
+// in reverse order: created paths go to the trash (Stashed), moves go back, trashed items
+// are restored (Restored). Each step updates the op, so it always describes the state on disk and a
+// half-finished undo or redo can be retried.
+type Op struct {
+	Kind     string        `json:"kind"`
+	Time     time.Time     `json:"time"`
+	Trashed  []TrashedItem `json:"trashed,omitempty"`
+	Moves    []RenameOp    `json:"moves,omitempty"`
+	Created  []string      `json:"created,omitempty"`
+	Stashed  []TrashedItem `json:"stashed,omitempty"`  // Created paths parked in the trash while undone
+	Restored []TrashedItem `json:"restored,omitempty"` // Trashed paths brought back while undone
+	Reversed bool          `json:"reversed,omitempty"` // Moves are currently undone
+}
+
+// Describe summarises the op for the history list and status messages
+func (op Op) Describe() string {
+	var names []string
+	for _, items := range [][]TrashedItem{op.Trashed, op.Restored} {
+		for _, t := range items {
+			names = append(names, t.Path)
+		}
+	}
+	for _, m := range op.Moves {
+		names = append(names, m.From)
+	}
+	names = append(names, op.Created...)
+	for _, s := range op.Stashed {
+		names = append(names, s.Path)
+	}
+
+	what := "nothing"
+	switch len(names) {
+	case 0:
+	case 1:
+		what = filepath.Base(names[0])
+	default:
+		what = fmt.Sprintf("%s +%d", filepath.Base(names[0]), len(names)-1)
+	}
+	switch op.Kind {
+	case OpRename, OpMove:
+		if len(op.Moves) == 1 && len(op.Trashed)+len(op.Restored) == 0 {
+			m := op.Moves[0]
+			to := filepath.Base(m.To)
+			if op.Kind == OpMove {
+				to = filepath.Dir(m.To)
+			}
+			return fmt.Sprintf("%s %s → %s", op.Kind, filepath.Base(m.From), to)
+		}
+	}
+	return op.Kind + " " + what
+}
+
+// Journal is the undo/redo history of file operations, saved after every change so it
+// survives restarts. Ops[:Applied] are done (undo walks back from the end of that
+// range); Ops[Applied:] were undone and can be redone.
+type Journal struct {
+	Ops     []Op `json:"ops"`
+	Applied int  `json:"applied"`
+
+	path string // "" keeps the journal in memory only
+}
+
+// NewJournal returns an empty journal saved to path ("" for memory only)
+func NewJournal(path string) *Journal {
+	return &Journal{path: path}
+}
+
+// LoadJournal reads the journal at path. A missing or unreadable file gives an empty
+// journal (the next save replaces it).
+func LoadJournal(path string) *Journal {
+	j := NewJournal(path)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Warn("Cannot read history %s: %v", path, err)
+		}
+		return j
+	}
+	if err := json.Unmarshal(data, j); err != nil {
+		logger.Warn("Ignoring corrupted history %s: %v", path, err)
+		return NewJournal(path)
+	}
+	if j.Applied < 0 || j.Applied > len(j.Ops) {
+		j.Applied = len(j.Ops)
+	}
+	return j
+}
+
+// CanUndo reports whether there's an op to undo
+func (j *Journal) CanUndo() bool { return j.Applied > 0 }
+
+// CanRedo reports whether there's an undone op to redo
+func (j *Journal) CanRedo() bool { return j.Applied < len(j.Ops) }
+
+// Record adds a completed op, dropping anything that could have been redone
+func (j *Journal) Record(op Op) error {
+	if op.Time.IsZero() {
+		op.Time = time.Now()
+	}
+	j.Ops = append(j.Ops[:j.Applied], op)
+	if len(j.Ops) > maxJournalOps {
+		j.Ops = append([]Op(nil), j.Ops[len(j.Ops)-maxJournalOps:]...)
+	}
+	j.Applied = len(j.Ops)
+	return j.save()
+}
+
+// Undo reverses the most recent applied op and returns it. If that fails part way, the
+// op keeps what's left to undo and stays applied, so it can be retried.
+func (j *Journal) Undo() (Op, error) {
+	if !j.CanUndo() {
+		return Op{}, errors.New("nothing to undo")
+	}
+	op := &j.Ops[j.Applied-1]
+	err := undoOp(op)
+	if err == nil {
+		j.Applied--
+	}
+	if saveErr := j.save(); err == nil {
+		err = saveErr
+	}
+	return *op, err
+}
+
+// Redo applies the most recently undone op again and returns it
+func (j *Journal) Redo() (Op, error) {
+	if !j.CanRedo() {
+		return Op{}, errors.New("nothing to redo")
+	}
+	op := &j.Ops[j.Applied]
+	err := redoOp(op)
+	if err == nil {
+		j.Applied++
+	}
+	if saveErr := j.save(); err == nil {
+		err = saveErr
+	}
+	return *op, err
+}
+
+// save writes the journal atomically (temp file + rename)
+func (j *Journal) save() error {
+	if j.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(j.path), 0755); err != nil {
+		return err
+	}
+	tmp := j.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, j.path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// undoOp reverses op: stash created paths, move things back, restore trashed items
+func undoOp(op *Op) error {
+	for len(op.Created) > 0 {
+		path := op.Created[len(op.Created)-1]
+		info, err := os.Lstat(path)
+		if err != nil {
+			return FormatError(err, path, "undo")
+		}
+		undoInfo, err := DeleteWithUndo(path, info.IsDir())
+		if err != nil {
+			return err
+		}
+		op.Stashed = append(op.Stashed, TrashedItem{Path: path, IsDir: info.IsDir(), UndoInfo: undoInfo})
+		op.Created = op.Created[:len(op.Created)-1]
+	}
+
+	if len(op.Moves) > 0 && !op.Reversed {
+		inverse := make([]RenameOp, len(op.Moves))
+		for i, m := range op.Moves {
+			inverse[i] = RenameOp{From: m.To, To: m.From}
+		}
+		if err := applyMoves(op.Kind, inverse); err != nil {
+			return err
+		}
+		op.Reversed = true
+	}
+
+	for len(op.Trashed) > 0 {
+		t := op.Trashed[len(op.Trashed)-1]
+		if err := RestoreFromTrash(t.UndoInfo, t.Path); err != nil {
+			return fmt.Errorf("restore %s: %w", filepath.Base(t.Path), err)
+		}
+		op.Trashed = op.Trashed[:len(op.Trashed)-1]
+		op.Restored = append(op.Restored, TrashedItem{Path: t.Path, IsDir: t.IsDir})
+	}
+	return nil
+}
+
+// redoOp carries op out again: trash what it trashed, redo the moves, bring back what
+// it created
+func redoOp(op *Op) error {
+	for len(op.Restored) > 0 {
+		t := op.Restored[len(op.Restored)-1]
+		undoInfo, err := DeleteWithUndo(t.Path, t.IsDir)
+		if err != nil {
+			return err
+		}
+		op.Restored = op.Restored[:len(op.Restored)-1]
+		op.Trashed = append(op.Trashed, TrashedItem{Path: t.Path, IsDir: t.IsDir, UndoInfo: undoInfo})
+	}
+
+	if op.Reversed {
+		if err := applyMoves(op.Kind, op.Moves); err != nil {
+			return err
+		}
+		op.Reversed = false
+	}
+
+	for len(op.Stashed) > 0 {
+		s := op.Stashed[len(op.Stashed)-1]
+		if err := RestoreFromTrash(s.UndoInfo, s.Path); err != nil {
+			return fmt.Errorf("restore %s: %w", filepath.Base(s.Path), err)
+		}
+		op.Stashed = op.Stashed[:len(op.Stashed)-1]
+		op.Created = append(op.Created, s.Path)
+	}
+	return nil
+}
+
+// applyMoves carries out moves as one unit. Renames go through the planner (so swaps and
+// chains work); moves may cross filesystems and fall back to copying. Nothing is ever
+// overwritten: a target that exists fails the whole step.
+func applyMoves(kind string, moves []RenameOp) error {
+	if kind != OpMove {
+		plan, err := PlanRenames(moves, nil)
+		if err != nil {
+			return err
+		}
+		return ApplyRenames(plan.Steps)
+	}
+
+	for _, m := range moves {
+		if _, err := os.Lstat(m.To); err == nil {
+			return fmt.Errorf("file already exists: %s", m.To)
+		}
+	}
+	for i, m := range moves {
+		if err := moveItem(m.From, m.To); err != nil {
+			for k := i - 1; k >= 0; k-- {
+				if rbErr := moveItem(moves[k].To, moves[k].From); rbErr != nil {
+					logger.Error("Rollback %s -> %s failed: %v", moves[k].To, moves[k].From, rbErr)
+				}
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// moveItem renames from to to, copying (with all metadata) and removing the source when
+// they're on different filesystems
+func moveItem(from, to string) error {
+	if err := os.MkdirAll(filepath.Dir(to), 0755); err != nil {
+		return err
+	}
+	if err := os.Rename(from, to); !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+	if err := CopyFileOrDir(from, to, CopyOptions{Ownership: true, Xattrs: true}); err != nil {
+		os.RemoveAll(to)
+		return FormatError(err, from, "move")
+	}
+	return os.RemoveAll(from)
+}
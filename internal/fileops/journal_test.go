@@ -0,0 +1,182 @@
+package fileops
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// exists reports whether path is there
+func exists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
+
+func TestJournalUndoRedoRename(t *testing.T) {
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a.txt"), filepath.Join(dir, "b.txt")
+	os.WriteFile(a, []byte("a"), 0644)
+	os.WriteFile(b, []byte("b"), 0644)
+	// A swap, as a bulk rename would have done it
+	os.Rename(a, a+".tmp")
+	os.Rename(b, a)
+	os.Rename(a+".tmp", b)
+
+	j := NewJournal("")
+	if err := j.Record(Op{Kind: OpRename, Moves: []RenameOp{{From: a, To: b}, {From: b, To: a}}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := j.Undo(); err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+	if data, _ := os.ReadFile(a); string(data) != "a" {
+		t.Errorf("after undo a.txt = %q, want a", data)
+	}
+	if j.CanUndo() || !j.CanRedo() {
+		t.Errorf("after undo CanUndo=%v CanRedo=%v", j.CanUndo(), j.CanRedo())
+	}
+
+	if _, err := j.Redo(); err != nil {
+		t.Fatalf("Redo: %v", err)
+	}
+	if data, _ := os.ReadFile(a); string(data) != "b" {
+		t.Errorf("after redo a.txt = %q, want b", data)
+	}
+}
+
+func TestJournalUndoRedoMove(t *testing.T) {
+	root := t.TempDir()
+	src := filepath.Join(root, "src", "report")
+	dst := filepath.Join(root, "dest", "report")
+	os.MkdirAll(dst, 0755)
+	os.WriteFile(filepath.Join(dst, "q1.txt"), []byte("q1"), 0644)
+
+	j := NewJournal("")
+	j.Record(Op{Kind: OpMove, Moves: []RenameOp{{From: src, To: dst}}})
+	if _, err := j.Undo(); err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+	// The source directory is recreated if it's gone
+	if !exists(filepath.Join(src, "q1.txt")) || exists(dst) {
+		t.Fatalf("undo didn't move report back")
+	}
+	if _, err := j.Redo(); err != nil {
+		t.Fatalf("Redo: %v", err)
+	}
+	if !exists(filepath.Join(dst, "q1.txt")) || exists(src) {
+		t.Errorf("redo didn't move report again")
+	}
+}
+
+func TestJournalUndoNeverOverwrites(t *testing.T) {
+	root := t.TempDir()
+	src, dst := filepath.Join(root, "a.txt"), filepath.Join(root, "sub", "a.txt")
+	os.MkdirAll(filepath.Dir(dst), 0755)
+	os.WriteFile(dst, []byte("moved"), 0644)
+	os.WriteFile(src, []byte("new file since"), 0644)
+
+	j := NewJournal("")
+	j.Record(Op{Kind: OpMove, Moves: []RenameOp{{From: src, To: dst}}})
+	if _, err := j.Undo(); err == nil {
+		t.Fatal("undo replaced a file that took the old name")
+	}
+	if !j.CanUndo() {
+		t.Error("a failed undo should stay in the history to retry")
+	}
+	if data, _ := os.ReadFile(src); string(data) != "new file since" {
+		t.Errorf("a.txt = %q, want it untouched", data)
+	}
+}
+
+func TestJournalPersists(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "history.json")
+	from, to := filepath.Join(dir, "old.txt"), filepath.Join(dir, "new.txt")
+	os.WriteFile(to, []byte("x"), 0644)
+
+	j := LoadJournal(path)
+	j.Record(Op{Kind: OpRename, Moves: []RenameOp{{From: from, To: to}}})
+
+	// A later session can still undo it
+	again := LoadJournal(path)
+	if len(again.Ops) != 1 || again.Applied != 1 {
+		t.Fatalf("loaded %d ops, %d applied; want 1, 1", len(again.Ops), again.Applied)
+	}
+	if got := again.Ops[0].Describe(); got != "rename old.txt → new.txt" {
+		t.Errorf("Describe = %q", got)
+	}
+	if _, err := again.Undo(); err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+	if !exists(from) {
+		t.Error("old.txt not back after undo")
+	}
+	if LoadJournal(path).Applied != 0 {
+		t.Error("undo wasn't saved")
+	}
+}
+
+func TestJournalRecordDropsRedo(t *testing.T) {
+	j := NewJournal("")
+	j.Record(Op{Kind: OpRename})
+	j.Record(Op{Kind: OpRename})
+	if _, err := j.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	j.Record(Op{Kind: OpCreate})
+	if len(j.Ops) != 2 || j.Ops[1].Kind != OpCreate || j.CanRedo() {
+		t.Errorf("ops = %+v, applied %d: a new op should replace what could be redone", j.Ops, j.Applied)
+	}
+
+	for range maxJournalOps + 10 {
+		j.Record(Op{Kind: OpRename})
+	}
+	if len(j.Ops) != maxJournalOps || j.Applied != maxJournalOps {
+		t.Errorf("history holds %d ops (%d applied), want %d", len(j.Ops), j.Applied, maxJournalOps)
+	}
+}
+
+func TestLoadJournalIgnoresCorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+	os.WriteFile(path, []byte("{not json"), 0644)
+	if j := LoadJournal(path); len(j.Ops) != 0 || j.CanUndo() {
+		t.Errorf("corrupt history gave %d ops", len(j.Ops))
+	}
+}
+
+func TestJournalUndoRedoCreateAndTrash(t *testing.T) {
+	testTrash(t)
+	dir := t.TempDir()
+	made, gone := filepath.Join(dir, "new.txt"), filepath.Join(dir, "old.txt")
+	os.WriteFile(made, []byte("new"), 0644)
+	os.WriteFile(gone, []byte("old"), 0644)
+	undo, err := DeleteWithUndo(gone, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	j := NewJournal("")
+	j.Record(Op{Kind: OpCreate, Created: []string{made}})
+	j.Record(Op{Kind: OpTrash, Trashed: []TrashedItem{{Path: gone, UndoInfo: undo}}})
+
+	for range 2 {
+		if _, err := j.Undo(); err != nil {
+			t.Fatalf("Undo: %v", err)
+		}
+	}
+	if !exists(gone) || exists(made) {
+		t.Fatalf("after undo: old.txt there %v, new.txt there %v", exists(gone), exists(made))
+	}
+
+	for range 2 {
+		if _, err := j.Redo(); err != nil {
+			t.Fatalf("Redo: %v", err)
+		}
+	}
+	if exists(gone) || !exists(made) {
+		t.Errorf("after redo: old.txt there %v, new.txt there %v", exists(gone), exists(made))
+	}
+	if data, _ := os.ReadFile(made); string(data) != "new" {
+		t.Errorf("new.txt = %q after coming back from the trash", data)
+	}
+}
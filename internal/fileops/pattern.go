@@ -0,0 +1,361 @@
+package fileops
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// CaseMode is the case transform a batch rename applies to new names
+type CaseMode int
+
+const (
+	CaseKeep CaseMode = iota
+	CaseLower
+	CaseUpper
+	CaseTitle
+)
+
+func (c CaseMode) String() string {
+	switch c {
+	case CaseLower:
+		return "lower"
+	case CaseUpper:
+		return "UPPER"
+	case CaseTitle:
+		return "Title"
+	}
+	return "keep"
+}
+
+// RenamePattern is a batch rename rule. Find is a regexp matched against each name without
+// its extension (an empty Find replaces the whole stem); every match is replaced by Replace,
+// which may use $1/${name} capture groups and these tokens:
+//
+//	{n} {n:W} {n:W:S}   counter, zero-padded to W digits, starting at S (default 1)
+//	{date} {date:FMT}   modification time; FMT uses YYYY YY MM DD hh mm ss (default YYYY-MM-DD)
+//	{name} {ext}        the original stem and extension (without the dot)
+//	{{ }}               literal braces
+//
+// Ext replaces the extension of files ("" keeps it, "." drops it), then Case is applied
+// (Title only to the stem).
+type RenamePattern struct {
+	Find    string
+	Replace string
+	Case    CaseMode
+	Ext     string
+}
+
+// RenameSource is an item a batch rename may apply to
+type RenameSource struct {
+	Path    string
+	IsDir   bool
+	ModTime time.Time
+}
+
+// RenamePreview is one row of a batch rename preview. Conflict says why the rename can't
+// happen ("" when it can).
+type RenamePreview struct {
+	RenameOp
+	Conflict string
+}
+
+// templatePart is a literal run of a Replace template (expanded for $ groups) or a token
+type templatePart struct {
+	literal string
+	token   string // "n", "date", "name" or "ext"; "" for a literal
+	arg     string // Text after the token's first colon
+	width   int    // {n} padding
+	start   int    // {n} first value
+}
+
+// PreviewPattern works out what p does to items, in order. Only items it changes are
+// returned; the counter advances once per item Find matches. Conflicts are flagged, not
+// fatal: invalid names, two items getting the same name, and names taken by files that
+// aren't part of the batch. An error means the pattern itself is bad.
+func PreviewPattern(p RenamePattern, items []RenameSource) ([]RenamePreview, error) {
+	var re *regexp.Regexp
+	if p.Find != "" {
+		var err error
+		if re, err = regexp.Compile(p.Find); err != nil {
+			return nil, fmt.Errorf("bad find pattern: %v", err)
+		}
+	}
+	parts, err := parseTemplate(p.Replace)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []RenamePreview
+	sources := make(map[string]bool, len(items))
+	counter := 0
+	for _, item := range items {
+		sources[item.Path] = true
+		name := filepath.Base(item.Path)
+		stem, ext := splitExt(name, item.IsDir)
+
+		newStem := stem
+		switch {
+		case re != nil:
+			matches := re.FindAllStringSubmatchIndex(stem, -1)
+			if matches == nil {
+				continue
+			}
+			var b strings.Builder
+			last := 0
+			for _, match := range matches {
+				b.WriteString(stem[last:match[0]])
+				b.WriteString(expandTemplate(parts, re, stem, match, counter, item, stem, ext))
+				last = match[1]
+			}
+			b.WriteString(stem[last:])
+			newStem = b.String()
+		case p.Replace != "":
+			newStem = expandTemplate(parts, nil, stem, nil, counter, item, stem, ext)
+		}
+		counter++
+
+		newExt := ext
+		switch {
+		case p.Ext == "" || item.IsDir:
+		case p.Ext == ".":
+			newExt = ""
+		default:
+			newExt = "." + strings.TrimPrefix(p.Ext, ".")
+		}
+		switch p.Case {
+		case CaseLower:
+			newStem, newExt = strings.ToLower(newStem), strings.ToLower(newExt)
+		case CaseUpper:
+			newStem, newExt = strings.ToUpper(newStem), strings.ToUpper(newExt)
+		case CaseTitle:
+			newStem = titleCase(newStem)
+		}
+		newName := newStem + newExt
+		if newName == name {
+			continue
+		}
+		row := RenamePreview{RenameOp: RenameOp{From: item.Path, To: filepath.Join(filepath.Dir(item.Path), newName)}}
+		if row.Conflict = invalidName(newName); row.Conflict != "" {
+			row.To = filepath.Dir(item.Path) + string(filepath.Separator) + newName // Not cleaned, so the preview shows what was typed
+		}
+		rows = append(rows, row)
+	}
+
+	// Conflicts need the whole batch: a name is free if its current owner is renamed away
+	moving := make(map[string]bool, len(rows))
+	for _, r := range rows {
+		moving[r.From] = true
+	}
+	targets := make(map[string]int, len(rows))
+	for i := range rows {
+		r := &rows[i]
+		if r.Conflict != "" {
+			continue
+		}
+		if first, dup := targets[r.To]; dup {
+			r.Conflict = "duplicate"
+			if rows[first].Conflict == "" {
+				rows[first].Conflict = "duplicate"
+			}
+			continue
+		}
+		targets[r.To] = i
+		if moving[r.To] {
+			continue
+		}
+		if existing, err := os.Lstat(r.To); err == nil {
+			if from, err := os.Lstat(r.From); err != nil || !os.SameFile(existing, from) {
+				if sources[r.To] {
+					r.Conflict = "taken by unchanged item"
+				} else {
+					r.Conflict = "exists"
+				}
+			}
+		}
+	}
+	return rows, nil
+}
+
+// parseTemplate splits a Replace template into literal runs and {tokens}
+func parseTemplate(tmpl string) ([]templatePart, error) {
+	var parts []templatePart
+	var lit strings.Builder
+	flush := func() {
+		if lit.Len() > 0 {
+			parts = append(parts, templatePart{literal: lit.String()})
+			lit.Reset()
+		}
+	}
+	for i := 0; i < len(tmpl); i++ {
+		c := tmpl[i]
+		if (c == '{' || c == '}') && i+1 < len(tmpl) && tmpl[i+1] == c {
+			lit.WriteByte(c)
+			i++
+			continue
+		}
+		if c == '$' && i+1 < len(tmpl) && tmpl[i+1] == '{' {
+			// ${group} is a capture group, left for regexp.Expand
+			end := strings.IndexByte(tmpl[i:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed ${ in replacement")
+			}
+			lit.WriteString(tmpl[i : i+end+1])
+			i += end
+			continue
+		}
+		if c != '{' {
+			lit.WriteByte(c)
+			continue
+		}
+		end := strings.IndexByte(tmpl[i:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("unclosed { in replacement (use {{ for a literal brace)")
+		}
+		part, err := parseToken(tmpl[i+1 : i+end])
+		if err != nil {
+			return nil, err
+		}
+		flush()
+		parts = append(parts, part)
+		i += end
+	}
+	flush()
+	return parts, nil
+}
+
+func parseToken(body string) (templatePart, error) {
+	name, arg, hasArg := strings.Cut(body, ":")
+	part := templatePart{token: name, arg: arg, start: 1}
+	switch name {
+	case "n":
+		if !hasArg {
+			break
+		}
+		width, start, hasStart := strings.Cut(arg, ":")
+		var err error
+		if part.width, err = strconv.Atoi(width); err != nil || part.width < 0 || part.width > 12 {
+			return part, fmt.Errorf("bad counter width in {%s}", body)
+		}
+		if hasStart {
+			if part.start, err = strconv.Atoi(start); err != nil {
+				return part, fmt.Errorf("bad counter start in {%s}", body)
+			}
+		}
+	case "date":
+		if !hasArg {
+			part.arg = "YYYY-MM-DD"
+		}
+	case "name", "ext":
+		if hasArg {
+			return part, fmt.Errorf("{%s} takes no options", name)
+		}
+	default:
+		return part, fmt.Errorf("unknown token {%s}", body)
+	}
+	return part, nil
+}
+
+// expandTemplate renders parts for one match (match is nil without a Find regexp)
+func expandTemplate(parts []templatePart, re *regexp.Regexp, src string, match []int, counter int, item RenameSource, stem, ext string) string {
+	var b strings.Builder
+	for _, part := range parts {
+		switch part.token {
+		case "":
+			if re != nil {
+				b.Write(re.ExpandString(nil, part.literal, src, match))
+			} else {
+				b.WriteString(part.literal)
+			}
+		case "n":
+			fmt.Fprintf(&b, "%0*d", part.width, part.start+counter)
+		case "date":
+			b.WriteString(formatDate(item.ModTime, part.arg))
+		case "name":
+			b.WriteString(stem)
+		case "ext":
+			b.WriteString(strings.TrimPrefix(ext, "."))
+		}
+	}
+	return b.String()
+}
+
+// formatDate renders t with YYYY/YY/MM/DD/hh/mm/ss placeholders; anything else is literal
+func formatDate(t time.Time, format string) string {
+	fields := []struct {
+		key   string
+		value string
+	}{
+		{"YYYY", fmt.Sprintf("%04d", t.Year())},
+		{"YY", fmt.Sprintf("%02d", t.Year()%100)},
+		{"MM", fmt.Sprintf("%02d", int(t.Month()))},
+		{"DD", fmt.Sprintf("%02d", t.Day())},
+		{"hh", fmt.Sprintf("%02d", t.Hour())},
+		{"mm", fmt.Sprintf("%02d", t.Minute())},
+		{"ss", fmt.Sprintf("%02d", t.Second())},
+	}
+	var b strings.Builder
+next:
+	for i := 0; i < len(format); {
+		for _, f := range fields {
+			if strings.HasPrefix(format[i:], f.key) {
+				b.WriteString(f.value)
+				i += len(f.key)
+				continue next
+			}
+		}
+		b.WriteByte(format[i])
+		i++
+	}
+	return b.String()
+}
+
+// splitExt splits name into stem and extension (with its dot). Directories and dotfiles
+// like ".bashrc" have no extension.
+func splitExt(name string, isDir bool) (string, string) {
+	if isDir {
+		return name, ""
+	}
+	ext := filepath.Ext(name)
+	if ext == name {
+		return name, ""
+	}
+	return name[:len(name)-len(ext)], ext
+}
+
+// titleCase upper-cases the first letter of each word and lower-cases the rest
+func titleCase(s string) string {
+	runes := []rune(s)
+	startOfWord := true
+	for i, r := range runes {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if startOfWord {
+				runes[i] = unicode.ToUpper(r)
+			} else {
+				runes[i] = unicode.ToLower(r)
+			}
+			startOfWord = false
+		} else {
+			startOfWord = r != '\''
+		}
+	}
+	return string(runes)
+}
+
+// invalidName says why name can't be used as a file name ("" if it can)
+func invalidName(name string) string {
+	switch {
+	case name == "":
+		return "empty name"
+	case name == "." || name == "..":
+		return "invalid name"
+	case strings.ContainsRune(name, '/') || strings.ContainsRune(name, filepath.Separator):
+		return "contains /"
+	}
+	return ""
+}
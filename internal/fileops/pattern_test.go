@@ -0,0 +1,160 @@
+package fileops
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPreviewPattern(t *testing.T) {
+	dir := "/photos"
+	mtime := time.Date(2024, 3, 7, 9, 5, 0, 0, time.Local)
+	items := []RenameSource{
+		{Path: "/photos/IMG_0001.JPG", ModTime: mtime},
+		{Path: "/photos/IMG_0002.JPG", ModTime: mtime},
+		{Path: "/photos/notes.txt", ModTime: mtime},
+		{Path: "/photos/Raw Files", IsDir: true, ModTime: mtime},
+	}
+
+	tests := []struct {
+		name    string
+		pattern RenamePattern
+		want    map[string]string // old base -> new base, for every changed item
+	}{
+		{
+			name:    "capture groups",
+			pattern: RenamePattern{Find: `IMG_(\d+)`, Replace: "photo-$1"},
+			want:    map[string]string{"IMG_0001.JPG": "photo-0001.JPG", "IMG_0002.JPG": "photo-0002.JPG"},
+		},
+		{
+			name:    "named group with counter and date",
+			pattern: RenamePattern{Find: `^IMG_(?P<num>\d+)$`, Replace: "{date:YYYYMMDD}_{n:2:0}_${num}"},
+			want:    map[string]string{"IMG_0001.JPG": "20240307_00_0001.JPG", "IMG_0002.JPG": "20240307_01_0002.JPG"},
+		},
+		{
+			name:    "whole stem with tokens",
+			pattern: RenamePattern{Replace: "{n:3}-{name}-{ext}"},
+			want: map[string]string{
+				"IMG_0001.JPG": "001-IMG_0001-JPG.JPG", "IMG_0002.JPG": "002-IMG_0002-JPG.JPG",
+				"notes.txt": "003-notes-txt.txt", "Raw Files": "004-Raw Files-",
+			},
+		},
+		{
+			name:    "case and extension",
+			pattern: RenamePattern{Case: CaseLower, Ext: "jpeg"},
+			want: map[string]string{
+				"IMG_0001.JPG": "img_0001.jpeg", "IMG_0002.JPG": "img_0002.jpeg",
+				"notes.txt": "notes.jpeg", "Raw Files": "raw files",
+			},
+		},
+		{
+			name:    "title case keeps the extension",
+			pattern: RenamePattern{Find: "notes", Replace: "my meeting notes", Case: CaseTitle},
+			want:    map[string]string{"notes.txt": "My Meeting Notes.txt"},
+		},
+		{
+			name:    "drop extension",
+			pattern: RenamePattern{Ext: "."},
+			want:    map[string]string{"IMG_0001.JPG": "IMG_0001", "IMG_0002.JPG": "IMG_0002", "notes.txt": "notes"},
+		},
+		{
+			name:    "literal braces",
+			pattern: RenamePattern{Find: "notes", Replace: "{{draft}}"},
+			want:    map[string]string{"notes.txt": "{draft}.txt"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := PreviewPattern(tt.pattern, items)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rows) != len(tt.want) {
+				t.Fatalf("got %d rows, want %d: %v", len(rows), len(tt.want), rows)
+			}
+			for _, r := range rows {
+				if filepath.Dir(r.To) != dir {
+					t.Errorf("%s moved out of its directory: %s", r.From, r.To)
+				}
+				if got, want := filepath.Base(r.To), tt.want[filepath.Base(r.From)]; got != want {
+					t.Errorf("%s -> %q, want %q", filepath.Base(r.From), got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPreviewPatternErrors(t *testing.T) {
+	items := []RenameSource{{Path: "/x/a.txt"}}
+	for _, p := range []RenamePattern{
+		{Find: "("},
+		{Replace: "{bogus}"},
+		{Replace: "{n:x}"},
+		{Replace: "{n"},
+		{Replace: "{name:3}"},
+	} {
+		if _, err := PreviewPattern(p, items); err == nil {
+			t.Errorf("expected an error for %+v", p)
+		}
+	}
+}
+
+func TestPreviewPatternConflicts(t *testing.T) {
+	dir := bulkRenameDir(t, "a.txt", "b.txt", "c.txt", "other.md")
+	var items []RenameSource
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		items = append(items, RenameSource{Path: filepath.Join(dir, name)})
+	}
+	conflicts := func(p RenamePattern, sources []RenameSource) map[string]string {
+		t.Helper()
+		rows, err := PreviewPattern(p, sources)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := make(map[string]string)
+		for _, r := range rows {
+			got[filepath.Base(r.From)] = r.Conflict
+		}
+		return got
+	}
+
+	// Every name collapses to the same thing
+	got := conflicts(RenamePattern{Replace: "same"}, items)
+	if got["a.txt"] != "duplicate" || got["b.txt"] != "duplicate" || got["c.txt"] != "duplicate" {
+		t.Errorf("expected duplicates, got %v", got)
+	}
+
+	// Taken by a file outside the batch
+	got = conflicts(RenamePattern{Find: "a", Replace: "other", Ext: "md"}, items)
+	if got["a.txt"] != "exists" {
+		t.Errorf("expected exists, got %v", got)
+	}
+
+	// Shifting names along is fine: 1 takes 2's name as 2 moves to 3's, and 3 moves on
+	numbered := bulkRenameDir(t, "1.txt", "2.txt", "3.txt")
+	var shifted []RenameSource
+	for _, name := range []string{"1.txt", "2.txt", "3.txt"} {
+		shifted = append(shifted, RenameSource{Path: filepath.Join(numbered, name)})
+	}
+	got = conflicts(RenamePattern{Replace: "{n:0:2}"}, shifted)
+	if len(got) != 3 || got["1.txt"] != "" || got["2.txt"] != "" || got["3.txt"] != "" {
+		t.Errorf("expected three clean renames, got %v", got)
+	}
+
+	// ...but not onto an item in the batch that the pattern leaves alone
+	got = conflicts(RenamePattern{Find: "^a$", Replace: "b"}, items)
+	if got["a.txt"] != "taken by unchanged item" {
+		t.Errorf("expected a clash with an unchanged item, got %v", got)
+	}
+
+	// A slash is not a rename
+	got = conflicts(RenamePattern{Find: "a", Replace: "sub/a"}, items)
+	if got["a.txt"] != "contains /" || len(got) != 1 {
+		t.Errorf("expected invalid name, got %v", got)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "a.txt")); err != nil {
+		t.Error("previewing must not touch the files")
+	}
+}
@@ -0,0 +1,384 @@
+package fileops
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/LFroesch/scout/internal/logger"
+)
+
+// The freedesktop.org Trash spec (https://specifications.freedesktop.org/trash-spec/):
+// a trash directory holds files/ with the trashed items and info/ with a
+// <name>.trashinfo for each, recording the original path and deletion date. Items on the
+// home filesystem go to $XDG_DATA_HOME/Trash; items on other mounts go to that mount's
+// $topdir/.Trash/$uid (if an admin set up a sticky .Trash) or $topdir/.Trash-$uid, so
+// trashing never copies across filesystems.
+
+// trashInfoDate is the DeletionDate format (local time, no zone)
+const trashInfoDate = "2006-01-02T15:04:05"
+
+// TrashEntry is one item in a trash directory
+type TrashEntry struct {
+	Name         string // Name under files/ (unique within its trash)
+	Dir          string // Trash directory holding files/ and info/
+	OriginalPath string
+	DeletedAt    time.Time
+	IsDir        bool
+	Size         int64 // Size of a file (0 for directories)
+}
+
+// FilePath is where the trashed item itself is
+func (e TrashEntry) FilePath() string {
+	return filepath.Join(e.Dir, "files", e.Name)
+}
+
+// InfoPath is the entry's .trashinfo file
+func (e TrashEntry) InfoPath() string {
+	return filepath.Join(e.Dir, "info", e.Name+".trashinfo")
+}
+
+// homeTrashDir returns $XDG_DATA_HOME/Trash (~/.local/share/Trash by default)
+func homeTrashDir() (string, error) {
+	if dataHome := os.Getenv("XDG_DATA_HOME"); filepath.IsAbs(dataHome) {
+		return filepath.Join(dataHome, "Trash"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share", "Trash"), nil
+}
+
+// PutInTrash moves path into the trash for its filesystem and returns the new entry
+func PutInTrash(path string) (TrashEntry, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return TrashEntry{}, err
+	}
+	info, err := os.Lstat(abs)
+	if err != nil {
+		return TrashEntry{}, FormatError(err, abs, "trash")
+	}
+
+	dir, topdir, err := trashDirFor(abs)
+	if err != nil {
+		return TrashEntry{}, fmt.Errorf("no trash available for %s: %w", filepath.Base(abs), err)
+	}
+	entry := TrashEntry{Dir: dir, OriginalPath: abs, DeletedAt: time.Now().Truncate(time.Second), IsDir: info.IsDir()}
+	if !info.IsDir() {
+		entry.Size = info.Size()
+	}
+
+	// Paths in a mount's own trash are stored relative to the mount, so they survive
+	// it being mounted somewhere else
+	stored := abs
+	if topdir != "" {
+		if rel, err := filepath.Rel(topdir, abs); err == nil {
+			stored = rel
+		}
+	}
+	if entry.Name, err = reserveTrashName(dir, filepath.Base(abs), info.IsDir(), stored, entry.DeletedAt); err != nil {
+		return TrashEntry{}, err
+	}
+	if err := moveItem(abs, entry.FilePath()); err != nil {
+		os.Remove(entry.InfoPath())
+		logger.Error("Trash %s failed: %v", abs, err)
+		return TrashEntry{}, FormatError(err, abs, "trash")
+	}
+	return entry, nil
+}
+
+// trashDirFor picks the trash for abs: the home trash when abs is on the same filesystem,
+// otherwise the trash at the top of abs's mount (topdir). If the mount can't have one,
+// the home trash is used anyway and the item is copied there.
+func trashDirFor(abs string) (dir, topdir string, err error) {
+	home, err := homeTrashDir()
+	if err != nil {
+		return "", "", err
+	}
+	if err := ensureTrashDir(home); err != nil {
+		return "", "", err
+	}
+	topdir = mountTopdir(abs, home)
+	if topdir == "" {
+		return home, "", nil
+	}
+	dir, err = topdirTrashDir(topdir, true)
+	if err != nil {
+		logger.Warn("No trash on the mount at %s, using %s: %v", topdir, home, err)
+		return home, "", nil
+	}
+	return dir, topdir, nil
+}
+
+// topdirTrashDir returns the trash for the mount at topdir: $topdir/.Trash/$uid when
+// $topdir/.Trash is a real sticky directory, otherwise $topdir/.Trash-$uid. With create
+// the directories are made if missing.
+func topdirTrashDir(topdir string, create bool) (string, error) {
+	uid := strconv.Itoa(os.Getuid())
+	if info, err := os.Lstat(filepath.Join(topdir, ".Trash")); err == nil {
+		// The spec requires the shared directory to be sticky and not a symlink
+		if info.IsDir() && info.Mode()&os.ModeSticky != 0 {
+			dir := filepath.Join(topdir, ".Trash", uid)
+			if err := checkTrashDir(dir, create); err == nil {
+				return dir, nil
+			}
+		} else if create {
+			logger.Warn("Ignoring %s: not a sticky directory", filepath.Join(topdir, ".Trash"))
+		}
+	}
+	dir := filepath.Join(topdir, ".Trash-"+uid)
+	return dir, checkTrashDir(dir, create)
+}
+
+// checkTrashDir makes sure dir is a usable per-user trash: a real directory (not a
+// symlink) owned by us, with files/ and info/ inside
+func checkTrashDir(dir string, create bool) error {
+	if create {
+		if err := os.Mkdir(dir, 0700); err != nil && !os.IsExist(err) {
+			return err
+		}
+	}
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+	if uid, _, ok := fileOwner(info); ok && uid != os.Getuid() {
+		return fmt.Errorf("%s belongs to another user", dir)
+	}
+	if create {
+		return ensureTrashDir(dir)
+	}
+	return nil
+}
+
+// ensureTrashDir creates dir's files/ and info/ subdirectories
+func ensureTrashDir(dir string) error {
+	for _, sub := range []string{"files", "info"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0700); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reserveTrashName claims a free name in the trash by creating its .trashinfo
+// exclusively, so two deletions can't pick the same one: "name.ext", then "name.2.ext",
+// "name.3.ext", ...
+func reserveTrashName(dir, base string, isDir bool, stored string, deleted time.Time) (string, error) {
+	info := fmt.Sprintf("[Trash Info]\nPath=%s\nDeletionDate=%s\n",
+		(&url.URL{Path: stored}).EscapedPath(), deleted.Format(trashInfoDate))
+	stem, ext := splitExt(base, isDir)
+	for n := 1; ; n++ {
+		name := base
+		if n > 1 {
+			name = fmt.Sprintf("%s.%d%s", stem, n, ext)
+		}
+		infoPath := filepath.Join(dir, "info", name+".trashinfo")
+		f, err := os.OpenFile(infoPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if os.IsExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if _, err := os.Lstat(filepath.Join(dir, "files", name)); err == nil {
+			// A leftover without its info file: leave it alone
+			f.Close()
+			os.Remove(infoPath)
+			continue
+		}
+		_, err = f.WriteString(info)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(infoPath)
+			return "", err
+		}
+		return name, nil
+	}
+}
+
+// ListTrash returns everything in the home trash and the trashes of mounted
+// filesystems, most recently deleted first
+func ListTrash() ([]TrashEntry, error) {
+	home, err := homeTrashDir()
+	if err != nil {
+		return nil, err
+	}
+	dirs := []string{home}
+	for _, topdir := range mountPoints() {
+		if dir, err := topdirTrashDir(topdir, false); err == nil && dir != home {
+			dirs = append(dirs, dir)
+		}
+	}
+
+	var entries []TrashEntry
+	for _, dir := range dirs {
+		infos, err := os.ReadDir(filepath.Join(dir, "info"))
+		if err != nil {
+			if !os.IsNotExist(err) {
+				logger.Warn("Cannot read trash %s: %v", dir, err)
+			}
+			continue
+		}
+		for _, info := range infos {
+			if !strings.HasSuffix(info.Name(), ".trashinfo") {
+				continue
+			}
+			entry, err := LoadTrashEntry(filepath.Join(dir, "info", info.Name()))
+			if err != nil {
+				logger.Warn("Skipping trash entry %s: %v", info.Name(), err)
+				continue
+			}
+			entries = append(entries, entry)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].DeletedAt.After(entries[j].DeletedAt)
+	})
+	return entries, nil
+}
+
+// LoadTrashEntry reads the entry described by a .trashinfo file. It fails with an
+// fs.ErrNotExist error when the item is no longer in the trash.
+func LoadTrashEntry(infoPath string) (TrashEntry, error) {
+	dir := filepath.Dir(filepath.Dir(infoPath))
+	entry := TrashEntry{Dir: dir, Name: strings.TrimSuffix(filepath.Base(infoPath), ".trashinfo")}
+	data, err := os.ReadFile(infoPath)
+	if err != nil {
+		return entry, err
+	}
+	stored, deleted, err := parseTrashInfo(data)
+	if err != nil {
+		return entry, err
+	}
+	if !filepath.IsAbs(stored) {
+		stored = filepath.Join(trashTopdir(dir), stored)
+	}
+	entry.OriginalPath = stored
+	entry.DeletedAt = deleted
+
+	info, err := os.Lstat(entry.FilePath())
+	if err != nil {
+		return entry, err
+	}
+	entry.IsDir = info.IsDir()
+	if !info.IsDir() {
+		entry.Size = info.Size()
+	}
+	return entry, nil
+}
+
+// parseTrashInfo reads the Path and DeletionDate keys of a .trashinfo file
+func parseTrashInfo(data []byte) (string, time.Time, error) {
+	var path string
+	var deleted time.Time
+	inGroup := false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			inGroup = line == "[Trash Info]"
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !inGroup || !ok {
+			continue
+		}
+		switch key {
+		case "Path":
+			p, err := url.PathUnescape(value)
+			if err != nil {
+				return "", time.Time{}, fmt.Errorf("bad Path: %w", err)
+			}
+			path = p
+		case "DeletionDate":
+			// A missing or odd date isn't worth losing the entry over
+			deleted, _ = time.ParseInLocation(trashInfoDate, value, time.Local)
+		}
+	}
+	if path == "" {
+		return "", time.Time{}, errors.New("no Path in trash info")
+	}
+	return path, deleted, nil
+}
+
+// trashTopdir returns the mount a per-mount trash directory belongs to
+func trashTopdir(dir string) string {
+	parent := filepath.Dir(dir)
+	if filepath.Base(parent) == ".Trash" {
+		return filepath.Dir(parent) // $topdir/.Trash/$uid
+	}
+	return parent // $topdir/.Trash-$uid
+}
+
+// Restore moves the entry back to its original path, which must be free
+func (e TrashEntry) Restore() error {
+	if _, err := os.Lstat(e.OriginalPath); err == nil {
+		return fmt.Errorf("cannot restore: a file already exists at %s", e.OriginalPath)
+	}
+	if err := moveItem(e.FilePath(), e.OriginalPath); err != nil {
+		logger.Error("Restore %s -> %s failed: %v", e.FilePath(), e.OriginalPath, err)
+		return FormatError(err, e.OriginalPath, "restore")
+	}
+	if err := os.Remove(e.InfoPath()); err != nil && !os.IsNotExist(err) {
+		logger.Warn("Failed to remove trash info %s: %v", e.InfoPath(), err)
+	}
+	return nil
+}
+
+// Purge deletes the entry for good
+func (e TrashEntry) Purge() error {
+	if err := removeAllWritable(e.FilePath()); err != nil {
+		return FormatError(err, e.FilePath(), "delete")
+	}
+	if err := os.Remove(e.InfoPath()); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// EmptyTrash deletes every entry ListTrash reports
+func EmptyTrash() error {
+	entries, err := ListTrash()
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, e := range entries {
+		if err := e.Purge(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// removeAllWritable is os.RemoveAll that also clears out read-only directories (trashed
+// trees keep their modes)
+func removeAllWritable(path string) error {
+	if err := os.RemoveAll(path); err == nil {
+		return nil
+	}
+	filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err == nil && d.IsDir() {
+			os.Chmod(p, 0700)
+		}
+		return nil
+	})
+	return os.RemoveAll(path)
+}
@@ -0,0 +1,92 @@
+package fileops
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// deviceOf returns the ID of the filesystem path is on
+func deviceOf(path string) (uint64, bool) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return 0, false
+	}
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return uint64(st.Dev), true
+}
+
+// mountTopdir returns the mount point abs is under, or "" when that's the filesystem
+// homeTrash is on
+func mountTopdir(abs, homeTrash string) string {
+	dev, ok := deviceOf(abs)
+	if !ok {
+		return ""
+	}
+	if homeDev, ok := deviceOf(homeTrash); !ok || homeDev == dev {
+		return ""
+	}
+	dir := filepath.Dir(abs)
+	for {
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return dir
+		}
+		if parentDev, ok := deviceOf(parent); !ok || parentDev != dev {
+			return dir
+		}
+		dir = parent
+	}
+}
+
+// mountPoints lists the mounted filesystems, for finding their trash directories
+func mountPoints() []string {
+	f, err := os.Open("/proc/self/mounts")
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	var points []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 || virtualFS[fields[2]] {
+			continue
+		}
+		points = append(points, unescapeMount(fields[1]))
+	}
+	return points
+}
+
+// virtualFS are filesystem types that never hold a trash
+var virtualFS = map[string]bool{
+	"proc": true, "sysfs": true, "devtmpfs": true, "devpts": true, "cgroup": true,
+	"cgroup2": true, "securityfs": true, "debugfs": true, "tracefs": true, "pstore": true,
+	"bpf": true, "mqueue": true, "hugetlbfs": true, "configfs": true, "fusectl": true,
+	"binfmt_misc": true, "autofs": true, "overlay": true, "squashfs": true, "nsfs": true,
+}
+
+// unescapeMount decodes the octal escapes (\040 for a space) in /proc/self/mounts
+func unescapeMount(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+4 <= len(s) {
+			if n, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(n))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
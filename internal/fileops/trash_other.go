@@ -0,0 +1,13 @@
+//go:build !linux
+
+package fileops
+
+// mountTopdir can't tell filesystems apart here, so everything goes to the home trash
+func mountTopdir(abs, homeTrash string) string {
+	return ""
+}
+
+// mountPoints isn't available here; only the home trash is listed
+func mountPoints() []string {
+	return nil
+}
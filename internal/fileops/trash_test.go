@@ -0,0 +1,243 @@
+package fileops
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testTrash points the home trash at a temp dir and returns it
+func testTrash(t *testing.T) string {
+	t.Helper()
+	dataHome := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dataHome)
+	return filepath.Join(dataHome, "Trash")
+}
+
+// listTrashIn returns the entries ListTrash finds in trash (ignoring other mounts' trashes)
+func listTrashIn(t *testing.T, trash string) []TrashEntry {
+	t.Helper()
+	all, err := ListTrash()
+	if err != nil {
+		t.Fatalf("ListTrash: %v", err)
+	}
+	var entries []TrashEntry
+	for _, e := range all {
+		if e.Dir == trash {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+func TestPutInTrashWritesInfo(t *testing.T) {
+	trash := testTrash(t)
+	path := filepath.Join(t.TempDir(), "my notes.txt")
+	os.WriteFile(path, []byte("hello"), 0644)
+
+	entry, err := PutInTrash(path)
+	if err != nil {
+		t.Fatalf("PutInTrash: %v", err)
+	}
+	if entry.Dir != trash || entry.Name != "my notes.txt" {
+		t.Errorf("entry = %+v, want my notes.txt in %s", entry, trash)
+	}
+	if _, err := os.Lstat(path); !os.IsNotExist(err) {
+		t.Error("file still in place after trashing")
+	}
+	if data, _ := os.ReadFile(filepath.Join(trash, "files", "my notes.txt")); string(data) != "hello" {
+		t.Errorf("trashed content = %q", data)
+	}
+
+	info, err := os.ReadFile(entry.InfoPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(info)), "\n")
+	if len(lines) != 3 || lines[0] != "[Trash Info]" {
+		t.Fatalf("trashinfo = %q", info)
+	}
+	if want := "Path=" + strings.ReplaceAll(path, " ", "%20"); lines[1] != want {
+		t.Errorf("%s, want %s", lines[1], want)
+	}
+	if _, err := time.Parse(trashInfoDate, strings.TrimPrefix(lines[2], "DeletionDate=")); err != nil {
+		t.Errorf("bad date line %q: %v", lines[2], err)
+	}
+}
+
+func TestTrashNameCollisions(t *testing.T) {
+	trash := testTrash(t)
+	root := t.TempDir()
+	var paths []string
+	for _, dir := range []string{"one", "two", "three"} {
+		p := filepath.Join(root, dir, "report.txt")
+		os.MkdirAll(filepath.Dir(p), 0755)
+		os.WriteFile(p, []byte(dir), 0644)
+		paths = append(paths, p)
+	}
+
+	var names []string
+	for _, p := range paths {
+		entry, err := PutInTrash(p)
+		if err != nil {
+			t.Fatalf("PutInTrash %s: %v", p, err)
+		}
+		names = append(names, entry.Name)
+	}
+	if strings.Join(names, ",") != "report.txt,report.2.txt,report.3.txt" {
+		t.Errorf("names = %v", names)
+	}
+
+	entries := listTrashIn(t, trash)
+	if len(entries) != 3 {
+		t.Fatalf("ListTrash found %d entries, want 3", len(entries))
+	}
+	// Each comes back to where it came from, whatever its name in the trash
+	for _, e := range entries {
+		if err := e.Restore(); err != nil {
+			t.Fatalf("Restore %s: %v", e.Name, err)
+		}
+	}
+	for _, p := range paths {
+		want := filepath.Base(filepath.Dir(p))
+		if data, _ := os.ReadFile(p); string(data) != want {
+			t.Errorf("%s = %q, want %q", p, data, want)
+		}
+	}
+	if left := listTrashIn(t, trash); len(left) != 0 {
+		t.Errorf("%d entries left after restoring everything", len(left))
+	}
+}
+
+func TestRestoreRefusesToOverwrite(t *testing.T) {
+	testTrash(t)
+	path := filepath.Join(t.TempDir(), "a.txt")
+	os.WriteFile(path, []byte("old"), 0644)
+	entry, err := PutInTrash(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(path, []byte("new"), 0644)
+	if err := entry.Restore(); err == nil {
+		t.Fatal("restore replaced a file that took the name")
+	}
+	if data, _ := os.ReadFile(path); string(data) != "new" {
+		t.Errorf("a.txt = %q, want it untouched", data)
+	}
+}
+
+func TestUndoInfoRestores(t *testing.T) {
+	testTrash(t)
+	dir := filepath.Join(t.TempDir(), "photos")
+	os.MkdirAll(filepath.Join(dir, "2024"), 0755)
+	os.WriteFile(filepath.Join(dir, "2024", "a.jpg"), []byte("jpg"), 0644)
+
+	undo, err := DeleteWithUndo(dir, true)
+	if err != nil {
+		t.Fatalf("DeleteWithUndo: %v", err)
+	}
+	if err := RestoreFromTrash(undo, dir); err != nil {
+		t.Fatalf("RestoreFromTrash: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "2024", "a.jpg")); err != nil {
+		t.Errorf("tree not restored: %v", err)
+	}
+	if err := RestoreFromTrash(undo, dir); err == nil {
+		t.Error("restoring twice should fail")
+	}
+}
+
+func TestPurgeAndEmptyTrash(t *testing.T) {
+	trash := testTrash(t)
+	root := t.TempDir()
+	locked := filepath.Join(root, "locked")
+	os.MkdirAll(filepath.Join(locked, "inner"), 0755)
+	os.WriteFile(filepath.Join(locked, "inner", "f"), []byte("x"), 0644)
+	os.Chmod(filepath.Join(locked, "inner"), 0555)
+	for _, name := range []string{"a", "b"} {
+		os.WriteFile(filepath.Join(root, name), []byte(name), 0644)
+	}
+
+	var entries []TrashEntry
+	for _, name := range []string{"locked", "a", "b"} {
+		entry, err := PutInTrash(filepath.Join(root, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := entries[1].Purge(); err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+	if _, err := os.Lstat(entries[1].FilePath()); !os.IsNotExist(err) {
+		t.Error("purged file still in the trash")
+	}
+	if n := len(listTrashIn(t, trash)); n != 2 {
+		t.Errorf("%d entries after purging one, want 2", n)
+	}
+
+	if err := EmptyTrash(); err != nil {
+		t.Fatalf("EmptyTrash: %v", err)
+	}
+	for _, sub := range []string{"files", "info"} {
+		if left, _ := os.ReadDir(filepath.Join(trash, sub)); len(left) != 0 {
+			t.Errorf("%s/ not empty: %v", sub, left)
+		}
+	}
+}
+
+func TestTopdirTrashDir(t *testing.T) {
+	uid := strconv.Itoa(os.Getuid())
+
+	topdir := t.TempDir()
+	dir, err := topdirTrashDir(topdir, true)
+	if err != nil || dir != filepath.Join(topdir, ".Trash-"+uid) {
+		t.Fatalf("topdirTrashDir = %s, %v; want .Trash-%s", dir, err, uid)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "info")); err != nil {
+		t.Errorf("info/ not created: %v", err)
+	}
+
+	// A shared .Trash is only used when it's sticky
+	shared := t.TempDir()
+	os.Mkdir(filepath.Join(shared, ".Trash"), 0777)
+	if dir, _ := topdirTrashDir(shared, true); dir != filepath.Join(shared, ".Trash-"+uid) {
+		t.Errorf("non-sticky .Trash used: %s", dir)
+	}
+	os.Chmod(filepath.Join(shared, ".Trash"), 0777|os.ModeSticky)
+	if dir, _ := topdirTrashDir(shared, true); dir != filepath.Join(shared, ".Trash", uid) {
+		t.Errorf("sticky .Trash ignored: %s", dir)
+	}
+}
+
+func TestLoadTrashEntryRelativePath(t *testing.T) {
+	topdir := t.TempDir()
+	dir, err := topdirTrashDir(topdir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(dir, "files", "a b.txt"), []byte("x"), 0644)
+	os.WriteFile(filepath.Join(dir, "info", "a b.txt.trashinfo"),
+		[]byte("[Trash Info]\nPath=docs/a%20b.txt\nDeletionDate=2024-03-01T09:30:00\n"), 0644)
+
+	entry, err := LoadTrashEntry(filepath.Join(dir, "info", "a b.txt.trashinfo"))
+	if err != nil {
+		t.Fatalf("LoadTrashEntry: %v", err)
+	}
+	if want := filepath.Join(topdir, "docs", "a b.txt"); entry.OriginalPath != want {
+		t.Errorf("OriginalPath = %s, want %s", entry.OriginalPath, want)
+	}
+	if entry.DeletedAt.Format(trashInfoDate) != "2024-03-01T09:30:00" || entry.Size != 1 {
+		t.Errorf("entry = %+v", entry)
+	}
+	if err := entry.Restore(); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(topdir, "docs", "a b.txt")); err != nil {
+		t.Errorf("not restored under the mount: %v", err)
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package graphics
+
+// CellSize returns the usual 8×16: there's no tty to ask for the real size here
+func CellSize() (w, h int) {
+	return 8, 16
+}
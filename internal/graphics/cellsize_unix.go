@@ -0,0 +1,19 @@
+//go:build unix
+
+package graphics
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// CellSize returns the pixel size of a terminal cell as the tty reports it, or 8×16
+// when it doesn't say (or stdout isn't a tty)
+func CellSize() (w, h int) {
+	ws, err := unix.IoctlGetWinsize(int(os.Stdout.Fd()), unix.TIOCGWINSZ)
+	if err != nil || ws.Xpixel == 0 || ws.Ypixel == 0 || ws.Col == 0 || ws.Row == 0 {
+		return 8, 16
+	}
+	return int(ws.Xpixel / ws.Col), int(ws.Ypixel / ws.Row)
+}
@@ -0,0 +1,195 @@
+package graphics
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"image/png"
+	"strings"
+
+	"github.com/muesli/termenv"
+)
+
+// kittyChunk is the most base64 the kitty protocol takes in one escape sequence
+const kittyChunk = 4096
+
+// KittyClear removes every picture shown on screen with the kitty protocol (uploaded
+// pixels stay, so they can be drawn again by id)
+const KittyClear = "\x1b_Ga=d,d=a,q=2\x1b\\"
+
+// encodePNG returns img as PNG, which kitty and iTerm2 both take
+func encodePNG(img image.Image) []byte {
+	var buf bytes.Buffer
+	png.Encode(&buf, img) // Can't fail writing to memory
+	return buf.Bytes()
+}
+
+// kittyUpload transmits img as image id, split into chunks, without showing it
+func kittyUpload(img image.Image, id uint32) string {
+	data := base64.StdEncoding.EncodeToString(encodePNG(img))
+	var b strings.Builder
+	for i := 0; i < len(data); i += kittyChunk {
+		end := min(i+kittyChunk, len(data))
+		more := 0
+		if end < len(data) {
+			more = 1
+		}
+		if i == 0 {
+			fmt.Fprintf(&b, "\x1b_Ga=t,f=100,i=%d,q=2,m=%d;%s\x1b\\", id, more, data[i:end])
+		} else {
+			fmt.Fprintf(&b, "\x1b_Gm=%d;%s\x1b\\", more, data[i:end])
+		}
+	}
+	return b.String()
+}
+
+// kittyPlace shows uploaded image id at the cursor, without moving the cursor
+func kittyPlace(id uint32) string {
+	return fmt.Sprintf("\x1b_Ga=p,i=%d,p=1,C=1,q=2\x1b\\", id)
+}
+
+// iterm2 sends img inline at its own pixel size
+func iterm2(img image.Image) string {
+	data := encodePNG(img)
+	b := img.Bounds()
+	return fmt.Sprintf("\x1b]1337;File=inline=1;size=%d;width=%dpx;height=%dpx;preserveAspectRatio=1;doNotMoveCursor=1:%s\a",
+		len(data), b.Dx(), b.Dy(), base64.StdEncoding.EncodeToString(data))
+}
+
+// sixel dithers img to a 256 colour palette and encodes it six pixel rows at a time.
+// Mostly transparent pixels aren't painted, so the terminal background shows through.
+func sixel(img *image.NRGBA) string {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	pal := image.NewPaletted(b, palette.Plan9)
+	draw.FloydSteinberg.Draw(pal, b, img, b.Min)
+	opaque := func(x, y int) bool { return img.Pix[y*img.Stride+x*4+3] >= 128 }
+
+	var s strings.Builder
+	s.WriteString("\x1bP0;1;0q") // P2=1: unpainted pixels stay transparent
+	fmt.Fprintf(&s, "\"1;1;%d;%d", w, h)
+	used := make([]bool, len(palette.Plan9))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if opaque(x, y) {
+				used[pal.Pix[y*pal.Stride+x]] = true
+			}
+		}
+	}
+	for i, c := range palette.Plan9 {
+		if used[i] {
+			r, g, bl, _ := c.RGBA()
+			fmt.Fprintf(&s, "#%d;2;%d;%d;%d", i, r*100/0xffff, g*100/0xffff, bl*100/0xffff)
+		}
+	}
+
+	bits := make([][]byte, len(palette.Plan9))
+	for y0 := 0; y0 < h; y0 += 6 {
+		// One row of sixels per colour: bit n of column x is pixel (x, y0+n)
+		var colours []int
+		for y := y0; y < min(y0+6, h); y++ {
+			for x := 0; x < w; x++ {
+				if !opaque(x, y) {
+					continue
+				}
+				ci := pal.Pix[y*pal.Stride+x]
+				if bits[ci] == nil {
+					bits[ci] = make([]byte, w)
+					colours = append(colours, int(ci))
+				}
+				bits[ci][x] |= 1 << (y - y0)
+			}
+		}
+		for n, ci := range colours {
+			if n > 0 {
+				s.WriteByte('$') // Back to the start of the band for the next colour
+			}
+			fmt.Fprintf(&s, "#%d", ci)
+			writeSixels(&s, bits[ci])
+			bits[ci] = nil
+		}
+		s.WriteByte('-')
+	}
+	s.WriteString("\x1b\\")
+	return s.String()
+}
+
+// writeSixels writes one colour's row of sixels, run-length encoded, leaving off the
+// empty columns at the end
+func writeSixels(s *strings.Builder, row []byte) {
+	end := len(row)
+	for end > 0 && row[end-1] == 0 {
+		end--
+	}
+	for x := 0; x < end; {
+		run := 1
+		for x+run < end && row[x+run] == row[x] {
+			run++
+		}
+		ch := byte(63 + row[x])
+		if run > 3 {
+			fmt.Fprintf(s, "!%d%c", run, ch)
+		} else {
+			for i := 0; i < run; i++ {
+				s.WriteByte(ch)
+			}
+		}
+		x += run
+	}
+}
+
+// halfBlocks draws img two pixel rows per line: ▀ in the top pixel's colour over a
+// background of the bottom one. Mostly transparent pixels are left as the terminal's
+// background.
+func halfBlocks(img *image.NRGBA, profile termenv.Profile) []string {
+	if profile == termenv.Ascii {
+		return nil
+	}
+	b := img.Bounds()
+	colour := func(x, y int) (string, bool) {
+		if y >= b.Dy() {
+			return "", false
+		}
+		p := img.Pix[y*img.Stride+x*4:]
+		if p[3] < 128 {
+			return "", false
+		}
+		return fmt.Sprintf("#%02x%02x%02x", p[0], p[1], p[2]), true
+	}
+
+	var lines []string
+	for y := 0; y < b.Dy(); y += 2 {
+		var line strings.Builder
+		last := ""
+		for x := 0; x < b.Dx(); x++ {
+			top, topOK := colour(x, y)
+			bottom, bottomOK := colour(x, y+1)
+			var sgr, ch string
+			switch {
+			case topOK && bottomOK:
+				sgr, ch = profile.Color(top).Sequence(false)+";"+profile.Color(bottom).Sequence(true), "▀"
+			case topOK:
+				sgr, ch = profile.Color(top).Sequence(false), "▀"
+			case bottomOK:
+				sgr, ch = profile.Color(bottom).Sequence(false), "▄"
+			default:
+				ch = " "
+			}
+			// Only change colours when they differ from the cell before
+			if sgr != last {
+				line.WriteString("\x1b[0m")
+				if sgr != "" {
+					line.WriteString("\x1b[" + sgr + "m")
+				}
+				last = sgr
+			}
+			line.WriteString(ch)
+		}
+		line.WriteString("\x1b[0m")
+		lines = append(lines, line.String())
+	}
+	return lines
+}
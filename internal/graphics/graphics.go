@@ -0,0 +1,217 @@
+// Package graphics shows pictures in the preview pane. It works out which inline image
+// protocol the terminal speaks, decodes and scales the file, and encodes it as kitty,
+// iTerm2 or sixel escape sequences, or as coloured half blocks where none of those work.
+package graphics
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif" // Registered decoders: first frame only for animations
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/muesli/termenv"
+	_ "golang.org/x/image/bmp"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// MaxPixels caps what gets decoded; bigger images only show their Info
+const MaxPixels = 32 << 20
+
+// Protocol is how pictures are sent to the terminal
+type Protocol int
+
+const (
+	Blocks Protocol = iota // Unicode half blocks in colour: works anywhere with colours
+	Kitty                  // kitty graphics protocol (kitty, Ghostty)
+	ITerm2                 // iTerm2 inline images (iTerm2, WezTerm, mintty)
+	Sixel                  // DEC sixel (foot, mlterm, xterm -ti vt340)
+	None                   // No picture, just the image's details
+)
+
+var protocolNames = [...]string{"blocks", "kitty", "iterm2", "sixel", "none"}
+
+func (p Protocol) String() string {
+	if p < 0 || int(p) >= len(protocolNames) {
+		return "unknown"
+	}
+	return protocolNames[p]
+}
+
+// ParseProtocol reads a protocol name as written in the config
+func ParseProtocol(s string) (Protocol, bool) {
+	for i, name := range protocolNames {
+		if strings.EqualFold(s, name) {
+			return Protocol(i), true
+		}
+	}
+	return Blocks, false
+}
+
+// Detect picks the protocol for the terminal the environment describes (getenv is
+// os.Getenv outside tests). Terminals that can't be identified get half blocks.
+func Detect(getenv func(string) string) Protocol {
+	term, program := getenv("TERM"), getenv("TERM_PROGRAM")
+	switch {
+	case getenv("TMUX") != "" || strings.HasPrefix(term, "screen"):
+		// Multiplexers drop graphics sequences unless passthrough is set up
+		return Blocks
+	case getenv("KITTY_WINDOW_ID") != "" || term == "xterm-kitty" || term == "xterm-ghostty" || program == "ghostty":
+		return Kitty
+	case program == "iTerm.app" || program == "WezTerm" || program == "mintty":
+		return ITerm2
+	case strings.HasPrefix(term, "foot") || strings.Contains(term, "mlterm") || strings.Contains(term, "sixel"):
+		return Sixel
+	}
+	return Blocks
+}
+
+// IsImage reports whether name is an image format this package decodes
+func IsImage(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".png", ".jpg", ".jpeg", ".gif", ".webp", ".bmp":
+		return true
+	}
+	return false
+}
+
+// Info describes an image without decoding its pixels
+type Info struct {
+	Width, Height int
+	Format        string // As registered with package image: "png", "jpeg", ...
+	ColorModel    string // "RGBA", "YCbCr", "Paletted (256 colours)", ...
+}
+
+// Stat reads the image header at path
+func Stat(path string) (Info, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Info{}, err
+	}
+	defer f.Close()
+	cfg, format, err := image.DecodeConfig(f)
+	if err != nil {
+		return Info{}, err
+	}
+	return Info{Width: cfg.Width, Height: cfg.Height, Format: format, ColorModel: colorModelName(cfg.ColorModel)}, nil
+}
+
+// Load decodes the image at path, refusing ones over MaxPixels
+func Load(path string) (image.Image, error) {
+	info, err := Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.Width*info.Height > MaxPixels {
+		return nil, fmt.Errorf("%d×%d is too large to render", info.Width, info.Height)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	return img, err
+}
+
+func colorModelName(m color.Model) string {
+	if p, ok := m.(color.Palette); ok {
+		return fmt.Sprintf("Paletted (%d colours)", len(p))
+	}
+	switch m {
+	case color.RGBAModel:
+		return "RGBA"
+	case color.RGBA64Model:
+		return "RGBA (16-bit)"
+	case color.NRGBAModel:
+		return "NRGBA"
+	case color.NRGBA64Model:
+		return "NRGBA (16-bit)"
+	case color.AlphaModel, color.Alpha16Model:
+		return "Alpha"
+	case color.GrayModel:
+		return "Gray"
+	case color.Gray16Model:
+		return "Gray (16-bit)"
+	case color.YCbCrModel:
+		return "YCbCr"
+	case color.NYCbCrAModel:
+		return "YCbCr with alpha"
+	case color.CMYKModel:
+		return "CMYK"
+	}
+	return "unknown"
+}
+
+// Box is the space a picture gets: a number of cells, each CellW×CellH pixels
+type Box struct {
+	Cols, Rows   int
+	CellW, CellH int
+}
+
+// Picture is an image encoded for the terminal
+type Picture struct {
+	Cols, Rows int      // Cells covered
+	Lines      []string // Blocks: the picture itself, one string per row
+	Upload     string   // Kitty: sends the pixels without showing them
+	Draw       string   // Shows the picture with its top left corner at the cursor
+}
+
+// Render scales img to fit box (never enlarging it) and encodes it for p. id names the
+// picture for kitty, which keeps uploaded pixels and draws them again by id. Blocks use
+// the colours profile supports; with none they come out empty.
+func Render(img image.Image, p Protocol, box Box, id uint32, profile termenv.Profile) Picture {
+	if p == None || box.Cols <= 0 || box.Rows <= 0 {
+		return Picture{}
+	}
+	b := img.Bounds()
+	if p == Blocks {
+		// Each cell shows two pixels, one above the other
+		w, h := fit(b.Dx(), b.Dy(), box.Cols, box.Rows*2)
+		lines := halfBlocks(scale(img, w, h), profile)
+		return Picture{Cols: w, Rows: len(lines), Lines: lines}
+	}
+
+	w, h := fit(b.Dx(), b.Dy(), box.Cols*box.CellW, box.Rows*box.CellH)
+	scaled := scale(img, w, h)
+	pic := Picture{Cols: (w + box.CellW - 1) / box.CellW, Rows: (h + box.CellH - 1) / box.CellH}
+	switch p {
+	case Kitty:
+		pic.Upload = kittyUpload(scaled, id)
+		pic.Draw = kittyPlace(id)
+	case ITerm2:
+		pic.Draw = iterm2(scaled)
+	case Sixel:
+		pic.Draw = sixel(scaled)
+	}
+	return pic
+}
+
+// fit scales w×h down to fit in maxW×maxH, keeping the aspect ratio
+func fit(w, h, maxW, maxH int) (int, int) {
+	if w <= maxW && h <= maxH {
+		return max(w, 1), max(h, 1)
+	}
+	if w*maxH > h*maxW {
+		return maxW, max(h*maxW/w, 1)
+	}
+	return max(w*maxH/h, 1), maxH
+}
+
+// scale resamples img to w×h. Catmull-Rom looks best but costs time in proportion to the
+// source, so photos from cameras get the cheaper bilinear approximation.
+func scale(img image.Image, w, h int) *image.NRGBA {
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	b := img.Bounds()
+	var scaler draw.Scaler = draw.CatmullRom
+	if b.Dx()*b.Dy() > 4<<20 {
+		scaler = draw.ApproxBiLinear
+	}
+	scaler.Scale(dst, dst.Bounds(), img, b, draw.Src, nil)
+	return dst
+}
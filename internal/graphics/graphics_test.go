@@ -0,0 +1,162 @@
+package graphics
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	xansi "github.com/charmbracelet/x/ansi"
+	"github.com/muesli/termenv"
+)
+
+func TestDetect(t *testing.T) {
+	for _, tc := range []struct {
+		env  map[string]string
+		want Protocol
+	}{
+		{map[string]string{"TERM": "xterm-kitty"}, Kitty},
+		{map[string]string{"TERM": "xterm-256color", "KITTY_WINDOW_ID": "1"}, Kitty},
+		{map[string]string{"TERM_PROGRAM": "ghostty"}, Kitty},
+		{map[string]string{"TERM_PROGRAM": "iTerm.app"}, ITerm2},
+		{map[string]string{"TERM_PROGRAM": "WezTerm"}, ITerm2},
+		{map[string]string{"TERM": "foot"}, Sixel},
+		{map[string]string{"TERM": "xterm-256color"}, Blocks},
+		{map[string]string{"TERM": "xterm-kitty", "TMUX": "/tmp/tmux-0/default,1,0"}, Blocks},
+	} {
+		if got := Detect(func(k string) string { return tc.env[k] }); got != tc.want {
+			t.Errorf("Detect(%v) = %v, want %v", tc.env, got, tc.want)
+		}
+	}
+	if p, ok := ParseProtocol("Sixel"); !ok || p != Sixel {
+		t.Errorf("ParseProtocol(Sixel) = %v, %v", p, ok)
+	}
+	if _, ok := ParseProtocol("auto"); ok {
+		t.Error("auto parsed as a protocol")
+	}
+}
+
+func TestFit(t *testing.T) {
+	for _, tc := range []struct{ w, h, maxW, maxH, wantW, wantH int }{
+		{100, 50, 200, 200, 100, 50}, // Never enlarged
+		{400, 200, 100, 100, 100, 50},
+		{200, 400, 100, 100, 50, 100},
+		{1000, 1, 10, 10, 10, 1},
+	} {
+		if w, h := fit(tc.w, tc.h, tc.maxW, tc.maxH); w != tc.wantW || h != tc.wantH {
+			t.Errorf("fit(%d×%d in %d×%d) = %d×%d", tc.w, tc.h, tc.maxW, tc.maxH, w, h)
+		}
+	}
+}
+
+// testImage is red on top, blue below, with a transparent right half
+func testImage(w, h int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w/2; x++ {
+			c := color.NRGBA{255, 0, 0, 255}
+			if y >= h/2 {
+				c = color.NRGBA{0, 0, 255, 255}
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestRenderBlocks(t *testing.T) {
+	pic := Render(testImage(40, 40), Blocks, Box{Cols: 20, Rows: 5}, 1, termenv.TrueColor)
+	// 40×40 fits 20 cells across by 10 half-cell pixels down: squeezed to 10×10
+	if pic.Cols != 10 || pic.Rows != 5 || len(pic.Lines) != 5 {
+		t.Fatalf("got %d×%d with %d lines", pic.Cols, pic.Rows, len(pic.Lines))
+	}
+	for _, line := range pic.Lines {
+		if w := xansi.StringWidth(line); w != 10 {
+			t.Errorf("line %q is %d wide", line, w)
+		}
+	}
+	if !strings.Contains(pic.Lines[0], "38;2;255;0;0") || !strings.Contains(pic.Lines[4], "38;2;0;0;255") {
+		t.Errorf("colours wrong:\n%q\n%q", pic.Lines[0], pic.Lines[4])
+	}
+	if strings.Contains(xansi.Strip(pic.Lines[0]), "▀▀▀▀▀▀") {
+		t.Error("transparent half was drawn")
+	}
+	if pic := Render(testImage(4, 4), Blocks, Box{Cols: 4, Rows: 2}, 1, termenv.Ascii); len(pic.Lines) != 0 {
+		t.Error("drew blocks without colours")
+	}
+}
+
+func TestRenderProtocols(t *testing.T) {
+	box := Box{Cols: 10, Rows: 5, CellW: 10, CellH: 20}
+
+	// 300×150 fits 100×100 pixels: 100×50, so 10 cells by 3 rows
+	kitty := Render(testImage(300, 150), Kitty, box, 7, termenv.TrueColor)
+	if kitty.Cols != 10 || kitty.Rows != 3 {
+		t.Errorf("kitty covers %d×%d", kitty.Cols, kitty.Rows)
+	}
+	if !strings.HasPrefix(kitty.Upload, "\x1b_Ga=t,f=100,i=7,") || kitty.Draw != "\x1b_Ga=p,i=7,p=1,C=1,q=2\x1b\\" {
+		t.Errorf("kitty sequences: %.40q / %q", kitty.Upload, kitty.Draw)
+	}
+	// A big image is split into chunks, all but the last saying more follow
+	big := Render(noise(400, 400), Kitty, Box{Cols: 40, Rows: 20, CellW: 10, CellH: 20}, 1, termenv.TrueColor)
+	chunks := strings.Split(strings.TrimSuffix(big.Upload, "\x1b\\"), "\x1b\\")
+	if len(chunks) < 2 || !strings.Contains(chunks[0], "m=1;") || !strings.HasPrefix(chunks[len(chunks)-1], "\x1b_Gm=0;") {
+		t.Errorf("%d chunks, last %.20q", len(chunks), chunks[len(chunks)-1])
+	}
+
+	iterm := Render(testImage(300, 150), ITerm2, box, 1, termenv.TrueColor)
+	if !strings.HasPrefix(iterm.Draw, "\x1b]1337;File=inline=1;") || !strings.Contains(iterm.Draw, "width=100px;height=50px") {
+		t.Errorf("iterm2: %.80q", iterm.Draw)
+	}
+
+	six := Render(testImage(12, 12), Sixel, box, 1, termenv.TrueColor).Draw
+	if !strings.HasPrefix(six, "\x1bP0;1;0q\"1;1;12;12") || !strings.HasSuffix(six, "-\x1b\\") {
+		t.Errorf("sixel framing: %q", six)
+	}
+	// Two bands of six rows; the transparent half is left off each colour's run
+	if bands := strings.Count(six, "-"); bands != 2 {
+		t.Errorf("%d bands in %q", bands, six)
+	}
+	if !strings.Contains(six, "!6~") {
+		t.Errorf("no run of 6 full sixels in %q", six)
+	}
+}
+
+// noise is an image PNG can't compress much
+func noise(w, h int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	seed := uint32(1)
+	for i := range img.Pix {
+		seed = seed*1664525 + 1013904223
+		img.Pix[i] = byte(seed >> 24)
+	}
+	return img
+}
+
+func TestStatAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pic.png")
+	f, _ := os.Create(path)
+	png.Encode(f, testImage(30, 20))
+	f.Close()
+
+	info, err := Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info != (Info{Width: 30, Height: 20, Format: "png", ColorModel: "NRGBA"}) {
+		t.Errorf("Stat = %+v", info)
+	}
+	if img, err := Load(path); err != nil || img.Bounds().Dx() != 30 {
+		t.Errorf("Load: %v", err)
+	}
+	os.WriteFile(path, []byte("not a png"), 0o644)
+	if _, err := Load(path); err == nil {
+		t.Error("garbage decoded")
+	}
+	if !IsImage("a.JPG") || !IsImage("b.webp") || IsImage("c.svg") {
+		t.Error("IsImage")
+	}
+}
@@ -0,0 +1,202 @@
+// Package hexview formats bytes as a hex dump (offset, hex, ASCII, like hexdump -C) and
+// finds byte patterns in files of any size by reading them a chunk at a time.
+package hexview
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// RowWidth is how many columns a dump row of perRow bytes takes
+func RowWidth(perRow int) int {
+	w := 10 + perRow*3 + 2 + perRow + 1 // "offset  ", "xx " per byte, "|", ASCII, "|"
+	if perRow > 8 {
+		w++ // Gap after the eighth byte
+	}
+	return w
+}
+
+// PerRow picks 16 bytes a row, or 8 when 16 don't fit in width columns
+func PerRow(width int) int {
+	if width < RowWidth(16) {
+		return 8
+	}
+	return 16
+}
+
+// Row formats one dump row: data's offset, up to perRow bytes in hex, then as ASCII (dots
+// for the unprintable). A short last row is padded so the ASCII lines up. mark, if not
+// nil, gets each byte's offset and its hex or ASCII form to style (for search matches).
+func Row(data []byte, offset int64, perRow int, mark func(off int64, s string) string) string {
+	if mark == nil {
+		mark = func(_ int64, s string) string { return s }
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%08x  ", offset)
+	for i := 0; i < perRow; i++ {
+		if i == 8 {
+			b.WriteByte(' ')
+		}
+		if i < len(data) {
+			b.WriteString(mark(offset+int64(i), fmt.Sprintf("%02x", data[i])))
+			b.WriteByte(' ')
+		} else {
+			b.WriteString("   ")
+		}
+	}
+	b.WriteString(" |")
+	for i, c := range data {
+		ch := "."
+		if c >= 0x20 && c < 0x7f {
+			ch = string(rune(c))
+		}
+		b.WriteString(mark(offset+int64(i), ch))
+	}
+	b.WriteString("|")
+	return b.String()
+}
+
+// Dump formats data (read from offset) as rows of perRow bytes
+func Dump(data []byte, offset int64, perRow int) []string {
+	var rows []string
+	for i := 0; i < len(data); i += perRow {
+		rows = append(rows, Row(data[i:min(i+perRow, len(data))], offset+int64(i), perRow, nil))
+	}
+	return rows
+}
+
+// ParsePattern reads a search pattern: hex bytes ("7f 45 4c 46", "0xcafe", "deadbeef")
+// or, in double quotes, text with Go escapes ("\"ELF\"", "\"\\x00key\"")
+func ParsePattern(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, `"`) {
+		text, err := strconv.Unquote(s)
+		if err != nil {
+			return nil, fmt.Errorf("bad quoted text: %s", s)
+		}
+		if text == "" {
+			return nil, errors.New("empty pattern")
+		}
+		return []byte(text), nil
+	}
+
+	var digits strings.Builder
+	for _, field := range strings.Fields(s) {
+		field = strings.TrimPrefix(strings.TrimPrefix(field, "0x"), "0X")
+		digits.WriteString(field)
+	}
+	hex := digits.String()
+	if hex == "" {
+		return nil, errors.New("empty pattern")
+	}
+	if len(hex)%2 != 0 {
+		return nil, fmt.Errorf("odd number of hex digits: %s", s)
+	}
+	out := make([]byte, len(hex)/2)
+	for i := range out {
+		v, err := strconv.ParseUint(hex[2*i:2*i+2], 16, 8)
+		if err != nil {
+			return nil, fmt.Errorf("not hex: %s (quote text to search for it)", s)
+		}
+		out[i] = byte(v)
+	}
+	return out, nil
+}
+
+// ParseOffset reads a place to jump to in a file of size bytes: hex ("0x1f40", "1f40h"),
+// decimal ("8000"), a percentage ("50%"), or any of those after + or - to move from cur.
+// The result is clamped to the file.
+func ParseOffset(s string, cur, size int64) (int64, error) {
+	s = strings.TrimSpace(strings.ToLower(s))
+	sign := int64(0)
+	if strings.HasPrefix(s, "+") || strings.HasPrefix(s, "-") {
+		sign = 1
+		if s[0] == '-' {
+			sign = -1
+		}
+		s = strings.TrimSpace(s[1:])
+	}
+
+	var n int64
+	var err error
+	switch {
+	case strings.HasSuffix(s, "%"):
+		var pct float64
+		pct, err = strconv.ParseFloat(strings.TrimSuffix(s, "%"), 64)
+		n = int64(pct / 100 * float64(size))
+	case strings.HasPrefix(s, "0x"):
+		n, err = strconv.ParseInt(s[2:], 16, 64)
+	case strings.HasSuffix(s, "h"):
+		n, err = strconv.ParseInt(strings.TrimSuffix(s, "h"), 16, 64)
+	default:
+		n, err = strconv.ParseInt(s, 10, 64)
+	}
+	if err != nil || s == "" {
+		return 0, fmt.Errorf("not an offset: %s", s)
+	}
+	if sign != 0 {
+		n = cur + sign*n
+	}
+	return max(0, min(n, size-1)), nil
+}
+
+// chunkSize is how much Find reads at a time
+const chunkSize = 1 << 20
+
+// ErrCanceled is returned by Find when cancel is closed
+var ErrCanceled = errors.New("search canceled")
+
+// Find returns the offset of the first match of pattern at or after from (before from,
+// searching backward) in the size bytes of r, or -1. It reads a chunk at a time, so the
+// file can be any size; closing cancel stops it between chunks.
+func Find(r io.ReaderAt, size int64, pattern []byte, from int64, backward bool, cancel <-chan struct{}) (int64, error) {
+	if len(pattern) == 0 {
+		return -1, nil
+	}
+	overlap := int64(len(pattern) - 1)
+	buf := make([]byte, chunkSize+overlap)
+	canceled := func() bool {
+		select {
+		case <-cancel:
+			return true
+		default:
+			return false
+		}
+	}
+
+	if !backward {
+		for start := max(from, 0); start < size; start += chunkSize {
+			if canceled() {
+				return -1, ErrCanceled
+			}
+			n, err := r.ReadAt(buf[:min(int64(len(buf)), size-start)], start)
+			if err != nil && err != io.EOF {
+				return -1, err
+			}
+			if i := bytes.Index(buf[:n], pattern); i >= 0 {
+				return start + int64(i), nil
+			}
+		}
+		return -1, nil
+	}
+
+	// A match must start before from; each chunk ends overlap bytes into the next one
+	for end := min(from, size) - 1; end >= 0; end -= chunkSize {
+		if canceled() {
+			return -1, ErrCanceled
+		}
+		start := max(end-chunkSize+1, 0)
+		n, err := r.ReadAt(buf[:min(end+1+overlap, size)-start], start)
+		if err != nil && err != io.EOF {
+			return -1, err
+		}
+		if i := bytes.LastIndex(buf[:n], pattern); i >= 0 && start+int64(i) <= end {
+			return start + int64(i), nil
+		}
+	}
+	return -1, nil
+}
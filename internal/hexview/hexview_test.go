@@ -0,0 +1,140 @@
+package hexview
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRowMatchesHexdump(t *testing.T) {
+	data := []byte("\x7fELF\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00")
+	want := "00000000  7f 45 4c 46 02 01 01 00  00 00 00 00 00 00 00 00  |.ELF............|"
+	if got := Row(data, 0, 16, nil); got != want {
+		t.Errorf("Row =\n%q\nwant\n%q", got, want)
+	}
+	if len(want) != RowWidth(16) {
+		t.Errorf("RowWidth(16) = %d, row is %d", RowWidth(16), len(want))
+	}
+
+	// A short last row keeps the ASCII column where the full rows have it
+	short := Row([]byte("hi"), 0x1230, 16, nil)
+	if !strings.HasPrefix(short, "00001230  68 69 ") || strings.Index(short, "|") != strings.Index(want, "|") {
+		t.Errorf("short row %q", short)
+	}
+	if got := Row([]byte("abcdefgh"), 8, 8, nil); got != "00000008  61 62 63 64 65 66 67 68  |abcdefgh|" || len(got) != RowWidth(8) {
+		t.Errorf("8-byte row %q", got)
+	}
+}
+
+func TestRowMarksBytes(t *testing.T) {
+	got := Row([]byte("abc"), 0x10, 8, func(off int64, s string) string {
+		if off == 0x11 {
+			return "[" + s + "]"
+		}
+		return s
+	})
+	if !strings.Contains(got, "[62]") || !strings.Contains(got, "|a[b]c|") {
+		t.Errorf("marked row %q", got)
+	}
+}
+
+func TestPerRow(t *testing.T) {
+	if PerRow(78) != 16 || PerRow(77) != 8 {
+		t.Errorf("PerRow(78) = %d, PerRow(77) = %d", PerRow(78), PerRow(77))
+	}
+	if rows := Dump(make([]byte, 20), 0, 8); len(rows) != 3 || !strings.HasPrefix(rows[2], "00000010") {
+		t.Errorf("Dump rows %q", rows)
+	}
+}
+
+func TestParsePattern(t *testing.T) {
+	for in, want := range map[string][]byte{
+		"7f 45 4c 46":     {0x7f, 'E', 'L', 'F'},
+		"0xCAFE babe":     {0xca, 0xfe, 0xba, 0xbe},
+		"deadbeef":        {0xde, 0xad, 0xbe, 0xef},
+		`"ELF"`:           []byte("ELF"),
+		`"\x00key\n"`:     []byte("\x00key\n"),
+		`  "with space" `: []byte("with space"),
+	} {
+		got, err := ParsePattern(in)
+		if err != nil || !bytes.Equal(got, want) {
+			t.Errorf("ParsePattern(%q) = %x, %v; want %x", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "abc", "zz", `"`, `""`} {
+		if _, err := ParsePattern(in); err == nil {
+			t.Errorf("ParsePattern(%q) succeeded", in)
+		}
+	}
+}
+
+func TestParseOffset(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		cur  int64
+		want int64
+	}{
+		{"0x1f40", 0, 0x1f40},
+		{"1F40h", 0, 0x1f40},
+		{"8000", 0, 8000},
+		{"50%", 0, 5000},
+		{"+0x10", 100, 116},
+		{"- 50", 100, 50},
+		{"-500", 100, 0},
+		{"999999", 0, 9999},
+	} {
+		got, err := ParseOffset(tc.in, tc.cur, 10000)
+		if err != nil || got != tc.want {
+			t.Errorf("ParseOffset(%q, %d) = %d, %v; want %d", tc.in, tc.cur, got, err, tc.want)
+		}
+	}
+	for _, in := range []string{"", "+", "0xzz", "ten"} {
+		if _, err := ParseOffset(in, 0, 100); err == nil {
+			t.Errorf("ParseOffset(%q) succeeded", in)
+		}
+	}
+}
+
+func TestFindAcrossChunks(t *testing.T) {
+	// Matches straddling the chunk boundary, and one right at the end
+	data := make([]byte, 3*chunkSize)
+	pattern := []byte("needle")
+	at := []int64{10, chunkSize - 3, 2*chunkSize - 1, int64(len(data)) - int64(len(pattern))}
+	for _, off := range at {
+		copy(data[off:], pattern)
+	}
+	r := bytes.NewReader(data)
+	size := int64(len(data))
+
+	from := int64(0)
+	for _, want := range at {
+		got, err := Find(r, size, pattern, from, false, nil)
+		if err != nil || got != want {
+			t.Fatalf("Find forward from %d = %d, %v; want %d", from, got, err, want)
+		}
+		from = got + 1
+	}
+	if got, _ := Find(r, size, pattern, from, false, nil); got != -1 {
+		t.Errorf("Find past the last match = %d", got)
+	}
+
+	from = size
+	for i := len(at) - 1; i >= 0; i-- {
+		got, err := Find(r, size, pattern, from, true, nil)
+		if err != nil || got != at[i] {
+			t.Fatalf("Find backward from %d = %d, %v; want %d", from, got, err, at[i])
+		}
+		from = got
+	}
+	if got, _ := Find(r, size, pattern, from, true, nil); got != -1 {
+		t.Errorf("Find before the first match = %d", got)
+	}
+}
+
+func TestFindCanceled(t *testing.T) {
+	cancel := make(chan struct{})
+	close(cancel)
+	if _, err := Find(bytes.NewReader(make([]byte, 100)), 100, []byte{1}, 0, false, cancel); err != ErrCanceled {
+		t.Errorf("canceled Find returned %v", err)
+	}
+}
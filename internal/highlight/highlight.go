@@ -0,0 +1,133 @@
+// Package highlight colours source code for the preview pane. Lexing is done by chroma;
+// colours come from scout's own lipgloss palette, so they follow the terminal's colour
+// profile like the rest of the UI (and vanish when it has none).
+package highlight
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// MaxSize is the largest content highlighted; anything bigger is previewed as plain text,
+// since lexing it would stall the cursor
+const MaxSize = 256 * 1024
+
+// theme maps token types to styles. Types not listed fall back to their subcategory,
+// then category (so Keyword covers KeywordReserved, LiteralString covers every string),
+// then no colour at all.
+var theme = map[chroma.TokenType]lipgloss.Style{
+	chroma.Comment:             lipgloss.NewStyle().Foreground(lipgloss.Color("245")).Italic(true),
+	chroma.CommentPreproc:      lipgloss.NewStyle().Foreground(lipgloss.Color("99")),
+	chroma.Keyword:             lipgloss.NewStyle().Foreground(lipgloss.Color("105")).Bold(true),
+	chroma.KeywordType:         lipgloss.NewStyle().Foreground(lipgloss.Color("99")),
+	chroma.KeywordConstant:     lipgloss.NewStyle().Foreground(lipgloss.Color("214")),
+	chroma.NameBuiltin:         lipgloss.NewStyle().Foreground(lipgloss.Color("99")),
+	chroma.NameFunction:        lipgloss.NewStyle().Foreground(lipgloss.Color("230")),
+	chroma.NameClass:           lipgloss.NewStyle().Foreground(lipgloss.Color("230")).Bold(true),
+	chroma.NameDecorator:       lipgloss.NewStyle().Foreground(lipgloss.Color("214")),
+	chroma.NameConstant:        lipgloss.NewStyle().Foreground(lipgloss.Color("214")),
+	chroma.NameTag:             lipgloss.NewStyle().Foreground(lipgloss.Color("105")),
+	chroma.NameAttribute:       lipgloss.NewStyle().Foreground(lipgloss.Color("214")),
+	chroma.LiteralString:       lipgloss.NewStyle().Foreground(lipgloss.Color("42")),
+	chroma.LiteralStringEscape: lipgloss.NewStyle().Foreground(lipgloss.Color("214")),
+	chroma.LiteralNumber:       lipgloss.NewStyle().Foreground(lipgloss.Color("214")),
+	chroma.GenericHeading:      lipgloss.NewStyle().Foreground(lipgloss.Color("105")).Bold(true),
+	chroma.GenericSubheading:   lipgloss.NewStyle().Foreground(lipgloss.Color("105")),
+	chroma.GenericDeleted:      lipgloss.NewStyle().Foreground(lipgloss.Color("196")),
+	chroma.GenericInserted:     lipgloss.NewStyle().Foreground(lipgloss.Color("42")),
+	chroma.GenericEmph:         lipgloss.NewStyle().Italic(true),
+	chroma.GenericStrong:       lipgloss.NewStyle().Bold(true),
+}
+
+// span is the escape codes a style wraps text in
+type span struct {
+	open, close string
+}
+
+var (
+	spansOnce sync.Once
+	spans     map[chroma.TokenType]span
+)
+
+// buildSpans renders each theme style once around a placeholder and keeps the codes on
+// either side of it: writing those directly is far cheaper than a Render per token
+func buildSpans() {
+	spans = make(map[chroma.TokenType]span, len(theme))
+	for t, style := range theme {
+		open, close, _ := strings.Cut(style.Render("x"), "x")
+		spans[t] = span{open, close}
+	}
+}
+
+// spanFor finds the style for t, falling back through its subcategory and category
+func spanFor(t chroma.TokenType) span {
+	for _, tt := range []chroma.TokenType{t, t.SubCategory(), t.Category()} {
+		if s, ok := spans[tt]; ok {
+			return s
+		}
+	}
+	return span{}
+}
+
+// Code returns content with colours for the language name suggests (by file name, or for
+// extensionless scripts, by shebang). false when content is too big or no lexer knows it,
+// in which case content comes back unchanged.
+func Code(name, content string) (string, bool) {
+	if len(content) > MaxSize {
+		return content, false
+	}
+	lexer := lexers.Match(name)
+	if lexer == nil && strings.HasPrefix(content, "#!") {
+		lexer = lexers.Analyse(content)
+	}
+	return colour(lexer, content)
+}
+
+// Block colours a fenced code block by its info string language ("go", "python", "sh"...),
+// with the same fallbacks as Code
+func Block(lang, content string) (string, bool) {
+	if lang == "" || len(content) > MaxSize {
+		return content, false
+	}
+	lexer := lexers.Get(lang)
+	if lexer == nil {
+		lexer = lexers.Match("x." + lang)
+	}
+	return colour(lexer, content)
+}
+
+// colour tokenises content with lexer and wraps each token in its theme colours
+func colour(lexer chroma.Lexer, content string) (string, bool) {
+	if lexer == nil || lexer.Config().Name == "plaintext" {
+		return content, false
+	}
+	it, err := chroma.Coalesce(lexer).Tokenise(nil, content)
+	if err != nil {
+		return content, false
+	}
+
+	spansOnce.Do(buildSpans)
+	var b strings.Builder
+	b.Grow(len(content) * 2)
+	for tok := it(); tok != chroma.EOF; tok = it() {
+		s := spanFor(tok.Type)
+		// Each line is coloured on its own: the preview splits, wraps and scrolls by line
+		for i, part := range strings.Split(tok.Value, "\n") {
+			if i > 0 {
+				b.WriteByte('\n')
+			}
+			if part == "" || s.open == "" {
+				b.WriteString(part)
+				continue
+			}
+			b.WriteString(s.open)
+			b.WriteString(part)
+			b.WriteString(s.close)
+		}
+	}
+	return b.String(), true
+}
@@ -0,0 +1,74 @@
+package highlight
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	xansi "github.com/charmbracelet/x/ansi"
+	"github.com/muesli/termenv"
+)
+
+func TestMain(m *testing.M) {
+	lipgloss.SetColorProfile(termenv.ANSI256)
+	m.Run()
+}
+
+func TestCodeColoursKnownLanguages(t *testing.T) {
+	src := "package main\n\n// say hi\nfunc main() {\n\tprintln(\"hi\", 42)\n}\n"
+	out, ok := Code("main.go", src)
+	if !ok {
+		t.Fatal("Go source not highlighted")
+	}
+	if xansi.Strip(out) != src {
+		t.Errorf("highlighting changed the text:\n%q", xansi.Strip(out))
+	}
+	for _, want := range []string{"38;5;105", "38;5;42", "38;5;214", "38;5;245"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("no %s colour in %q", want, out)
+		}
+	}
+}
+
+func TestCodeKeepsLinesSelfContained(t *testing.T) {
+	out, ok := Code("a.c", "/* one\n   two */\nint x;\n")
+	if !ok {
+		t.Fatal("C not highlighted")
+	}
+	// A comment spanning lines is closed before each newline and reopened after it
+	for _, line := range strings.Split(out, "\n") {
+		if resets := strings.Count(line, "\x1b[0m"); strings.Count(line, "\x1b[") != 2*resets {
+			t.Errorf("line %q leaves its colour open", line)
+		}
+	}
+}
+
+func TestCodeFallsBack(t *testing.T) {
+	if _, ok := Code("notes.txt", "just words"); ok {
+		t.Error("plain text was highlighted")
+	}
+	if _, ok := Code("data.bin", "\x00\x01"); ok {
+		t.Error("unknown file was highlighted")
+	}
+	big := strings.Repeat("x := 1\n", MaxSize/7+1)
+	if out, ok := Code("big.go", big); ok || out != big {
+		t.Error("content over MaxSize was highlighted")
+	}
+	if _, ok := Code("deploy", "#!/bin/sh\necho hi\n"); !ok {
+		t.Error("shebang script not highlighted")
+	}
+}
+
+func TestBlockByLanguage(t *testing.T) {
+	for _, lang := range []string{"python", "py", "sh", "yaml"} {
+		if _, ok := Block(lang, "x = 1\n"); !ok {
+			t.Errorf("%s block not highlighted", lang)
+		}
+	}
+	if _, ok := Block("", "x = 1\n"); ok {
+		t.Error("block without a language was highlighted")
+	}
+	if _, ok := Block("no-such-lang", "x = 1\n"); ok {
+		t.Error("unknown language was highlighted")
+	}
+}
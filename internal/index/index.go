@@ -0,0 +1,336 @@
+package index
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/LFroesch/scout/internal/logger"
+	"github.com/LFroesch/scout/internal/search"
+)
+
+// maxIndexEntries caps a single index so indexing a huge drive can't eat all memory
+const maxIndexEntries = 5000000
+
+// Entry is one file or directory name recorded under a DirRecord
+type Entry struct {
+	Name  string
+	IsDir bool
+}
+
+// DirRecord holds the listing of one indexed directory.
+// Records are kept in depth-first preorder, so a directory's descendants always
+// follow it contiguously and Parent always points backwards.
+type DirRecord struct {
+	Path    string // Relative to Index.Root, "" for the root itself
+	Parent  int    // Index of the parent record, -1 for the root
+	ModTime int64  // Directory mtime (UnixNano) when Entries were read
+	Entries []Entry
+}
+
+// Index is an on-disk snapshot of every name under Root (minus skip dirs).
+// A published Index is never mutated; refreshes produce a new one.
+type Index struct {
+	Root      string
+	BuiltAt   time.Time
+	SkipKey   string // Fingerprint of the skip list it was built with
+	Truncated bool   // Hit maxIndexEntries while building
+	Dirs      []DirRecord
+
+	entryCount int
+	byPath     map[string]int
+}
+
+// prepare fills the in-memory lookup fields that aren't persisted
+func (idx *Index) prepare() {
+	idx.byPath = make(map[string]int, len(idx.Dirs))
+	idx.entryCount = 0
+	for i, d := range idx.Dirs {
+		idx.byPath[d.Path] = i
+		idx.entryCount += len(d.Entries)
+	}
+}
+
+// Entries returns the total number of files and directories in the index
+func (idx *Index) Entries() int {
+	return idx.entryCount
+}
+
+// Covers reports whether dir was indexed (it's under Root and wasn't skipped)
+func (idx *Index) Covers(dir string) bool {
+	_, ok := idx.find(dir)
+	return ok
+}
+
+// find returns the record index for an absolute directory path
+func (idx *Index) find(dir string) (int, bool) {
+	rel, ok := relTo(idx.Root, dir)
+	if !ok {
+		return 0, false
+	}
+	i, ok := idx.byPath[rel]
+	return i, ok
+}
+
+// relTo returns path relative to root ("" for root itself), or false if path is outside root
+func relTo(root, path string) (string, bool) {
+	root = filepath.Clean(root)
+	path = filepath.Clean(path)
+	if path == root {
+		return "", true
+	}
+	prefix := root
+	if !strings.HasSuffix(prefix, string(filepath.Separator)) {
+		prefix += string(filepath.Separator)
+	}
+	if !strings.HasPrefix(path, prefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(path, prefix), true
+}
+
+// build walks root and returns a fresh index. When prev is non-nil, directories whose
+// mtime hasn't changed reuse prev's listing instead of being re-read, so a refresh
+// costs one stat per directory rather than a full readdir of the tree.
+func build(root string, skipDirs []string, skipKey string, prev *Index, cancel <-chan struct{}) (*Index, bool) {
+	startTime := time.Now()
+	idx := &Index{Root: filepath.Clean(root), SkipKey: skipKey}
+
+	var prevByPath map[string]int
+	if prev != nil && prev.SkipKey == skipKey {
+		prevByPath = prev.byPath
+	}
+
+	entries := 0
+	reused := 0
+	var walk func(absPath, relPath string, parent int) bool
+	walk = func(absPath, relPath string, parent int) bool {
+		select {
+		case <-cancel:
+			return false
+		default:
+		}
+
+		info, err := os.Lstat(absPath)
+		if err != nil || !info.IsDir() {
+			return true
+		}
+		modTime := info.ModTime().UnixNano()
+
+		var listing []Entry
+		if i, ok := prevByPath[relPath]; ok && prev.Dirs[i].ModTime == modTime {
+			listing = prev.Dirs[i].Entries
+			reused++
+		} else {
+			dirEntries, err := os.ReadDir(absPath)
+			if err != nil {
+				return true // Permission denied etc. - leave it out like the walker does
+			}
+			listing = make([]Entry, 0, len(dirEntries))
+			for _, de := range dirEntries {
+				listing = append(listing, Entry{Name: de.Name(), IsDir: de.IsDir()})
+			}
+		}
+
+		self := len(idx.Dirs)
+		idx.Dirs = append(idx.Dirs, DirRecord{Path: relPath, Parent: parent, ModTime: modTime, Entries: listing})
+		entries += len(listing)
+		if entries > maxIndexEntries {
+			idx.Truncated = true
+			return false
+		}
+
+		for _, e := range listing {
+			if !e.IsDir {
+				continue
+			}
+			childAbs := filepath.Join(absPath, e.Name)
+			if search.ShouldSkipDir(childAbs, e.Name, skipDirs) {
+				continue
+			}
+			childRel := e.Name
+			if relPath != "" {
+				childRel = relPath + string(filepath.Separator) + e.Name
+			}
+			if !walk(childAbs, childRel, self) {
+				return false
+			}
+		}
+		return true
+	}
+
+	complete := walk(idx.Root, "", -1)
+	if idx.Truncated {
+		logger.Warn("Index for %s truncated at %d entries", idx.Root, maxIndexEntries)
+		complete = true
+	}
+	if !complete {
+		logger.Info("Index build for %s cancelled", idx.Root)
+		return nil, false
+	}
+
+	idx.BuiltAt = time.Now()
+	idx.prepare()
+	logger.Info("Indexed %s: %d dirs, %d entries (%d dirs reused) in %v", idx.Root, len(idx.Dirs), idx.entryCount, reused, time.Since(startTime))
+	return idx, true
+}
+
+// SearchOptions mirrors the knobs of search.RecursiveSearchFiles (minus the scan cap,
+// which doesn't apply to an index)
+type SearchOptions struct {
+	ShowHidden   bool
+	ShouldIgnore func(string) bool
+	MaxResults   int
+	MaxDepth     int
+	NameOnly     bool
+	Cancel       <-chan struct{}
+	OnResult     func(search.Result, search.MatchResult)
+	Filter       *search.Query // ext/size/mtime/type predicates (may be nil)
+}
+
+// Search runs a fuzzy filename search over the part of the index under dir.
+// Results match search.RecursiveSearchFiles: DisplayName is relative to dir and match
+// positions index into it. Matched paths are stat'd so stale entries are dropped and
+// size/mtime are current. .gitignore/.ignore rules apply unless opts.Filter.NoIgnore is set.
+func (idx *Index) Search(query, dir string, opts SearchOptions) ([]search.Result, []search.MatchResult) {
+	start, ok := idx.find(dir)
+	if !ok {
+		return nil, nil
+	}
+	startTime := time.Now()
+	matcher, err := opts.Filter.Matcher(query)
+	if err != nil {
+		return nil, nil
+	}
+	sep := string(filepath.Separator)
+
+	var results []search.Result
+	var matches []search.MatchResult
+
+	// Per-record state for the subtree, indexed by i-start
+	depth := []int{0}
+	excluded := []bool{false}
+	relDir := []string{""}
+	absDir := filepath.Clean(dir)
+
+	// Ignore rules per record; only directories listing an ignore file are read from disk
+	var ignorers []*search.Ignorer
+	if opts.Filter.RespectsIgnore() {
+		ignorers = []*search.Ignorer{search.NewIgnorer(absDir)}
+	}
+
+	for i := start; i < len(idx.Dirs); i++ {
+		rec := idx.Dirs[i]
+		k := i - start
+		if i != start {
+			p := rec.Parent - start
+			if rec.Parent < start {
+				break // Left the subtree (preorder keeps descendants contiguous)
+			}
+			name := filepath.Base(rec.Path)
+			depth = append(depth, depth[p]+1)
+			excluded = append(excluded, excluded[p] ||
+				(!opts.ShowHidden && strings.HasPrefix(name, ".")) ||
+				(opts.ShouldIgnore != nil && opts.ShouldIgnore(name)))
+			if relDir[p] == "" {
+				relDir = append(relDir, name)
+			} else {
+				relDir = append(relDir, relDir[p]+sep+name)
+			}
+			if ignorers != nil {
+				ig := ignorers[p]
+				recAbs := filepath.Join(absDir, relDir[k])
+				if !excluded[k] && ig.Ignored(recAbs, true) {
+					excluded[k] = true
+				}
+				if !excluded[k] && hasIgnoreFile(rec.Entries) {
+					ig = ig.Enter(recAbs)
+				}
+				ignorers = append(ignorers, ig)
+			}
+		}
+
+		if k%256 == 0 {
+			select {
+			case <-opts.Cancel:
+				return results, matches
+			default:
+			}
+		}
+
+		// Children of a dir at depth d have d separators in their relative path
+		if excluded[k] || depth[k] > opts.MaxDepth {
+			continue
+		}
+
+		for _, e := range rec.Entries {
+			if !opts.ShowHidden && strings.HasPrefix(e.Name, ".") {
+				continue
+			}
+			if opts.ShouldIgnore != nil && opts.ShouldIgnore(e.Name) {
+				continue
+			}
+
+			relPath := e.Name
+			if relDir[k] != "" {
+				relPath = relDir[k] + sep + e.Name
+			}
+
+			if !opts.Filter.MatchesName(e.Name, e.IsDir) {
+				continue
+			}
+			if ignorers != nil && ignorers[k].Ignored(filepath.Join(absDir, relPath), e.IsDir) {
+				continue
+			}
+			matchTarget, matchOffset := search.MatchTarget(relPath, e.Name, opts.NameOnly)
+			score, matchedIndexes, ok := matcher.Match(matchTarget)
+			if !ok {
+				continue
+			}
+
+			path := filepath.Join(absDir, relPath)
+			info, err := os.Lstat(path)
+			if err != nil {
+				continue // Deleted since the index was built
+			}
+			if !opts.Filter.Matches(e.Name, info.IsDir(), info.Size(), info.ModTime()) {
+				continue
+			}
+
+			for j := range matchedIndexes {
+				matchedIndexes[j] += matchOffset
+			}
+			result := search.Result{
+				Path:        path,
+				DisplayName: relPath,
+				IsDir:       info.IsDir(),
+				Size:        info.Size(),
+				ModTime:     info.ModTime(),
+			}
+			mr := search.MatchResult{Index: len(results), MatchedIndexes: matchedIndexes, Score: score}
+			results = append(results, result)
+			matches = append(matches, mr)
+			if opts.OnResult != nil {
+				opts.OnResult(result, mr)
+			}
+			if len(results) >= opts.MaxResults {
+				logger.Warn("Hit max results limit (%d)", opts.MaxResults)
+				return results, matches
+			}
+		}
+	}
+
+	logger.Info("Index search in %s for '%s': %d results in %v", dir, query, len(results), time.Since(startTime))
+	return results, matches
+}
+
+// hasIgnoreFile reports whether a listing holds a file that can change the ignore rules
+func hasIgnoreFile(entries []Entry) bool {
+	for _, e := range entries {
+		if search.IsIgnoreFile(e.Name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,320 @@
+package index
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/LFroesch/scout/internal/search"
+)
+
+func writeTree(t *testing.T, root string, files ...string) {
+	t.Helper()
+	for _, f := range files {
+		path := filepath.Join(root, f)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func searchNames(idx *Index, query, dir string, opts SearchOptions) []string {
+	if opts.MaxResults == 0 {
+		opts.MaxResults = 1000
+	}
+	if opts.MaxDepth == 0 {
+		opts.MaxDepth = 10
+	}
+	results, _ := idx.Search(query, dir, opts)
+	var names []string
+	for _, r := range results {
+		names = append(names, r.DisplayName)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestIndexSearch(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root,
+		"report.txt",
+		"docs/report-2024.md",
+		"docs/deep/a/b/report-old.md",
+		".hidden/report.txt",
+		"node_modules/report.js",
+		"other.go",
+	)
+
+	idx, ok := build(root, []string{"node_modules"}, "", nil, nil)
+	if !ok {
+		t.Fatal("build failed")
+	}
+
+	got := searchNames(idx, "report", root, SearchOptions{})
+	want := []string{"docs/deep/a/b/report-old.md", "docs/report-2024.md", "report.txt"}
+	if !equalStrings(got, want) {
+		t.Errorf("search = %v, want %v", got, want)
+	}
+
+	got = searchNames(idx, "report", root, SearchOptions{ShowHidden: true})
+	want = []string{".hidden/report.txt", "docs/deep/a/b/report-old.md", "docs/report-2024.md", "report.txt"}
+	if !equalStrings(got, want) {
+		t.Errorf("search with hidden = %v, want %v", got, want)
+	}
+
+	// maxDepth follows RecursiveSearchFiles: entries with more separators than maxDepth are dropped
+	got = searchNames(idx, "report", root, SearchOptions{MaxDepth: 1})
+	want = []string{"docs/report-2024.md", "report.txt"}
+	if !equalStrings(got, want) {
+		t.Errorf("search with maxDepth 1 = %v, want %v", got, want)
+	}
+
+	// Subtree search uses paths relative to the searched dir
+	got = searchNames(idx, "report", filepath.Join(root, "docs"), SearchOptions{})
+	want = []string{"deep/a/b/report-old.md", "report-2024.md"}
+	if !equalStrings(got, want) {
+		t.Errorf("subtree search = %v, want %v", got, want)
+	}
+
+	if idx.Covers(filepath.Join(root, "node_modules")) {
+		t.Error("skipped dir should not be covered by the index")
+	}
+}
+
+func TestIndexSearchMatchesWalker(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, "alpha.txt", "sub/alpha-two.txt", "sub/beta.txt", "target/alpha.bin")
+
+	idx, _ := build(root, nil, "", nil, nil)
+	ignore := func(name string) bool { return name == "target" }
+	cancel := make(chan struct{})
+	defer close(cancel)
+
+	walked, walkedMatches := search.RecursiveSearchFiles("alpha", root, false, ignore, cancel, nil, nil, 1000, 10, 100000, nil, true, nil)
+	indexed, indexedMatches := idx.Search("alpha", root, SearchOptions{ShouldIgnore: ignore, MaxResults: 1000, MaxDepth: 10, NameOnly: true})
+
+	if len(walked) != len(indexed) {
+		t.Fatalf("walker found %d, index found %d", len(walked), len(indexed))
+	}
+	byName := map[string][]int{}
+	for i, r := range walked {
+		byName[r.DisplayName] = walkedMatches[i].MatchedIndexes
+	}
+	for i, r := range indexed {
+		want, ok := byName[r.DisplayName]
+		if !ok {
+			t.Errorf("index returned %s which the walker didn't", r.DisplayName)
+			continue
+		}
+		if !equalInts(want, indexedMatches[i].MatchedIndexes) {
+			t.Errorf("%s: match positions %v, walker had %v", r.DisplayName, indexedMatches[i].MatchedIndexes, want)
+		}
+	}
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestIndexIncrementalRefresh(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, "a/one.txt", "b/two.txt")
+
+	first, _ := build(root, nil, "", nil, nil)
+
+	// Add a file in a and remove b entirely; bump mtimes so the change is visible
+	// even on filesystems with coarse timestamps
+	writeTree(t, root, "a/three.txt")
+	os.RemoveAll(filepath.Join(root, "b"))
+	future := time.Now().Add(time.Hour)
+	os.Chtimes(filepath.Join(root, "a"), future, future)
+	os.Chtimes(root, future, future)
+
+	second, _ := build(root, nil, "", first, nil)
+	got := searchNames(second, ".txt", root, SearchOptions{})
+	want := []string{"a/one.txt", "a/three.txt"}
+	if !equalStrings(got, want) {
+		t.Errorf("after refresh = %v, want %v", got, want)
+	}
+	if second.Covers(filepath.Join(root, "b")) {
+		t.Error("removed dir still covered after refresh")
+	}
+}
+
+func TestIndexDropsStaleEntries(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, "gone.txt")
+	idx, _ := build(root, nil, "", nil, nil)
+
+	os.Remove(filepath.Join(root, "gone.txt"))
+	if got := searchNames(idx, "gone", root, SearchOptions{}); len(got) != 0 {
+		t.Errorf("expected deleted file to be dropped, got %v", got)
+	}
+}
+
+func TestManagerSaveLoadAndRebuild(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, "file.txt", "sub/nested.txt")
+	store := t.TempDir()
+
+	mgr := NewManager(store, []string{"node_modules"})
+	if mgr.Lookup(root) != nil {
+		t.Fatal("expected no index before Ensure")
+	}
+	mgr.Ensure(root)
+	waitForBuild(t, mgr)
+
+	st := mgr.Status(filepath.Join(root, "sub"))
+	if !st.Ready || st.Root != root || st.Entries != 3 {
+		t.Fatalf("unexpected status %+v", st)
+	}
+
+	// A fresh manager picks the saved index up from disk, including for subdirectories
+	fresh := NewManager(store, []string{"node_modules"})
+	idx := fresh.Open(filepath.Join(root, "sub"))
+	if idx == nil || idx.Entries() != 3 {
+		t.Fatalf("expected saved index to load, got %v", idx)
+	}
+
+	// A different skip list invalidates the saved index
+	changed := NewManager(store, []string{"vendor"})
+	if changed.Open(root) != nil {
+		t.Error("index built with a different skip list should not load")
+	}
+
+	writeTree(t, root, "added.txt")
+	fresh.Rebuild(root)
+	waitForBuild(t, fresh)
+	if st := fresh.Status(root); st.Entries != 4 {
+		t.Errorf("rebuild entries = %d, want 4", st.Entries)
+	}
+}
+
+func TestNilManagerIsDisabled(t *testing.T) {
+	var mgr *Manager
+	mgr.Ensure("/")
+	if mgr.Open("/") != nil || mgr.Building() || mgr.Status("/").Ready {
+		t.Error("nil manager should behave as disabled")
+	}
+}
+
+func waitForBuild(t *testing.T, mgr *Manager) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for mgr.Building() {
+		if time.Now().After(deadline) {
+			t.Fatal("index build did not finish")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestIndexSearchHonoursIgnoreFiles(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, ".git/HEAD", ".gitignore", "main.go", "gen/out.go", "sub/x.go", "sub/.gitignore", "sub/skip.go")
+	os.WriteFile(filepath.Join(root, ".gitignore"), []byte("gen/\n"), 0644)
+	os.WriteFile(filepath.Join(root, "sub", ".gitignore"), []byte("skip.go\n"), 0644)
+
+	idx, _ := build(root, []string{".git"}, "", nil, nil)
+	filter := search.ParseQuery("ext:go")
+	got := searchNames(idx, "", root, SearchOptions{Filter: &filter})
+	want := []string{"main.go", filepath.Join("sub", "x.go")}
+	if !equalStrings(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	filter.NoIgnore = true
+	if got := searchNames(idx, "", root, SearchOptions{Filter: &filter}); len(got) != 4 {
+		t.Errorf("NoIgnore should return all 4 .go files, got %v", got)
+	}
+}
+
+func TestEnsureReusesAncestorIndex(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, "sub/file.txt")
+	store := t.TempDir()
+	mgr := NewManager(store, nil)
+	mgr.Ensure(root)
+	waitForBuild(t, mgr)
+
+	// A directory made after the build is picked up by refreshing the ancestor's index
+	writeTree(t, root, "sub/new/deep.txt")
+	future := time.Now().Add(time.Hour)
+	os.Chtimes(filepath.Join(root, "sub"), future, future)
+	mgr.mu.Lock()
+	stale := *mgr.indexes[root] // A copy: the saved one is never written to
+	stale.BuiltAt = time.Now().Add(-StaleAfter)
+	mgr.indexes[root] = &stale
+	mgr.mu.Unlock()
+	mgr.Ensure(filepath.Join(root, "sub", "new"))
+	waitForBuild(t, mgr)
+
+	if st := mgr.Status(filepath.Join(root, "sub", "new")); !st.Ready || st.Root != root {
+		t.Errorf("new directory covered by %+v", st)
+	}
+	if files, _ := os.ReadDir(store); len(files) != 1 {
+		t.Errorf("%d index files, want the ancestor's only", len(files))
+	}
+
+	// "/" is never indexed, not even on request
+	mgr.Ensure("/")
+	if root := mgr.Rebuild("/"); root != "" || mgr.Building() {
+		t.Errorf("started indexing / (Rebuild returned %q)", root)
+	}
+}
+
+func TestPruneRemovesOldAndExcessIndexes(t *testing.T) {
+	store := t.TempDir()
+	mgr := NewManager(store, nil)
+	write := func(name string, size int, age time.Duration) {
+		path := filepath.Join(store, name)
+		os.WriteFile(path, make([]byte, size), 0644)
+		when := time.Now().Add(-age)
+		os.Chtimes(path, when, when)
+	}
+	write("old.gob", 10, 40*24*time.Hour)
+	write("older.gob", 100, 3*time.Hour)
+	write("newer.gob", 100, time.Hour)
+	write("newest.gob", 100, time.Minute)
+	write(".index-123", 10, 2*time.Hour)
+	write(".index-456", 10, time.Minute) // A save in progress
+	write("notes.txt", 10, 40*24*time.Hour)
+
+	if removed := mgr.Prune(30*24*time.Hour, 250); removed != 3 {
+		t.Errorf("removed %d files, want 3", removed)
+	}
+	var left []string
+	entries, _ := os.ReadDir(store)
+	for _, e := range entries {
+		left = append(left, e.Name())
+	}
+	want := []string{".index-456", "newer.gob", "newest.gob", "notes.txt"}
+	if !equalStrings(left, want) {
+		t.Errorf("left %v, want %v", left, want)
+	}
+}
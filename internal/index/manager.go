@@ -0,0 +1,401 @@
+package index
+
+import (
+	"crypto/sha1"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/LFroesch/scout/internal/logger"
+)
+
+// StaleAfter is how old an index can get before a search triggers an incremental refresh
+const StaleAfter = 2 * time.Minute
+
+// Limits Prune keeps the index directory to
+const (
+	MaxAge   = 30 * 24 * time.Hour // Index files not saved for this long are removed
+	MaxBytes = 512 << 20           // Past this, the least recently saved files are removed
+)
+
+// Status summarises an index for display in the search header
+type Status struct {
+	Root     string
+	Ready    bool // A usable index exists (it may be refreshing in the background)
+	Building bool
+	Entries  int
+	BuiltAt  time.Time
+}
+
+// Manager owns the indexes for every root/drive, loading them from disk, building and
+// refreshing them in the background, and handing out the ready ones to searches.
+// A nil *Manager is valid and behaves as "indexing disabled".
+type Manager struct {
+	dir      string // Where index files live (~/.config/scout/index)
+	skipDirs []string
+	skipKey  string
+
+	mu       sync.Mutex
+	indexes  map[string]*Index
+	building map[string]chan struct{} // root -> cancel channel of the running build
+	loaded   map[string]bool          // roots we've already tried to load from disk
+}
+
+// NewManager creates a manager that stores its index files in dir
+func NewManager(dir string, skipDirs []string) *Manager {
+	return &Manager{
+		dir:      dir,
+		skipDirs: skipDirs,
+		skipKey:  strings.Join(skipDirs, "\x00"),
+		indexes:  make(map[string]*Index),
+		building: make(map[string]chan struct{}),
+		loaded:   make(map[string]bool),
+	}
+}
+
+// Lookup returns the ready index that covers dir, preferring the deepest root, or nil
+func (mgr *Manager) Lookup(dir string) *Index {
+	if mgr == nil {
+		return nil
+	}
+	mgr.mu.Lock()
+	defer mgr.mu.Unlock()
+
+	var best *Index
+	for _, idx := range mgr.indexes {
+		if idx.Covers(dir) && (best == nil || len(idx.Root) > len(best.Root)) {
+			best = idx
+		}
+	}
+	return best
+}
+
+// Open is Lookup plus a synchronous load from disk: if no index in memory covers dir,
+// saved indexes rooted at dir or any of its ancestors are loaded first. Meant to be
+// called from search goroutines so the first search after startup can use the index.
+func (mgr *Manager) Open(dir string) *Index {
+	if mgr == nil {
+		return nil
+	}
+	dir = filepath.Clean(dir)
+	if idx := mgr.Lookup(dir); idx != nil {
+		return idx
+	}
+
+	for root := dir; ; root = filepath.Dir(root) {
+		mgr.mu.Lock()
+		tried := mgr.loaded[root]
+		mgr.loaded[root] = true
+		mgr.mu.Unlock()
+
+		if !tried {
+			if idx, err := mgr.load(root); err == nil {
+				mgr.mu.Lock()
+				if _, ok := mgr.indexes[root]; !ok {
+					mgr.indexes[root] = idx
+				}
+				mgr.mu.Unlock()
+			} else if !os.IsNotExist(err) {
+				logger.Warn("Failed to load index for %s: %v", root, err)
+			}
+		}
+
+		if parent := filepath.Dir(root); parent == root {
+			break
+		}
+	}
+	return mgr.Lookup(dir)
+}
+
+// Ensure makes sure dir is indexed: it refreshes the covering index when it's stale,
+// or loads/builds one rooted at dir when nothing covers it. An index rooted above dir
+// that doesn't cover it yet (still building, or dir is newer) is refreshed instead of
+// starting another. Drives (C:\, /mnt/c) are indexed like any directory, but not "/":
+// that's every drive at once plus /proc and friends. Work happens in the background.
+func (mgr *Manager) Ensure(dir string) {
+	if mgr == nil {
+		return
+	}
+	root := filepath.Clean(dir)
+	if idx := mgr.Lookup(root); idx != nil {
+		if time.Since(idx.BuiltAt) < StaleAfter {
+			return
+		}
+		root = idx.Root
+	} else if ancestor, idx := mgr.ancestor(root); ancestor != "" {
+		if idx != nil && time.Since(idx.BuiltAt) < StaleAfter {
+			return
+		}
+		root = ancestor
+	} else if unixRoot(root) {
+		return
+	}
+	mgr.start(root, false)
+}
+
+// unixRoot reports whether root is "/", which is never indexed
+func unixRoot(root string) bool {
+	return root == "/"
+}
+
+// ancestor returns the deepest root, built or building, that dir is under, with its
+// index if it has one
+func (mgr *Manager) ancestor(dir string) (string, *Index) {
+	mgr.mu.Lock()
+	defer mgr.mu.Unlock()
+	best := ""
+	consider := func(root string) {
+		if _, under := relTo(root, dir); under && len(root) > len(best) {
+			best = root
+		}
+	}
+	for root := range mgr.indexes {
+		consider(root)
+	}
+	for root := range mgr.building {
+		consider(root)
+	}
+	return best, mgr.indexes[best]
+}
+
+// Rebuild throws away the index covering dir (or rooted at dir) and builds it from
+// scratch, returning its root. It returns "" for "/", which isn't indexed.
+func (mgr *Manager) Rebuild(dir string) string {
+	if mgr == nil {
+		return ""
+	}
+	root := filepath.Clean(dir)
+	if idx := mgr.Lookup(root); idx != nil {
+		root = idx.Root
+	} else if unixRoot(root) {
+		return ""
+	}
+
+	mgr.mu.Lock()
+	if cancel, ok := mgr.building[root]; ok {
+		close(cancel)
+		delete(mgr.building, root)
+	}
+	delete(mgr.indexes, root)
+	mgr.loaded[root] = true // Don't fall back to the old file on disk
+	mgr.mu.Unlock()
+
+	mgr.start(root, true)
+	return root
+}
+
+// start kicks off a background load/refresh/build for root unless one is already running
+func (mgr *Manager) start(root string, fromScratch bool) {
+	mgr.mu.Lock()
+	if _, running := mgr.building[root]; running {
+		mgr.mu.Unlock()
+		return
+	}
+	cancel := make(chan struct{})
+	mgr.building[root] = cancel
+	needLoad := !mgr.loaded[root]
+	mgr.loaded[root] = true
+	prev := mgr.indexes[root]
+	mgr.mu.Unlock()
+
+	go func() {
+		if needLoad && !fromScratch {
+			if idx, err := mgr.load(root); err == nil {
+				mgr.publish(root, idx, cancel)
+				prev = idx
+			} else if !os.IsNotExist(err) {
+				logger.Warn("Failed to load index for %s: %v", root, err)
+			}
+		}
+		if fromScratch {
+			prev = nil
+		}
+
+		idx, ok := build(root, mgr.skipDirs, mgr.skipKey, prev, cancel)
+		if !ok {
+			mgr.finish(root, cancel)
+			return
+		}
+		mgr.publish(root, idx, cancel)
+		if err := mgr.save(idx); err != nil {
+			logger.Warn("Failed to save index for %s: %v", root, err)
+		}
+		// Only now: until the file is written the build isn't done, and a new one
+		// mustn't start refreshing the index being encoded
+		mgr.finish(root, cancel)
+	}()
+}
+
+// publish makes idx the current index for root. Results from a build that was cancelled
+// (e.g. by Rebuild) are dropped.
+func (mgr *Manager) publish(root string, idx *Index, cancel chan struct{}) {
+	mgr.mu.Lock()
+	defer mgr.mu.Unlock()
+	if mgr.building[root] == cancel {
+		mgr.indexes[root] = idx
+	}
+}
+
+// finish clears the building flag once a build has been published and saved, or ended
+// without a result
+func (mgr *Manager) finish(root string, cancel chan struct{}) {
+	mgr.mu.Lock()
+	defer mgr.mu.Unlock()
+	if mgr.building[root] == cancel {
+		delete(mgr.building, root)
+	}
+}
+
+// Status reports the state of the index that covers dir (or would be built for it)
+func (mgr *Manager) Status(dir string) Status {
+	if mgr == nil {
+		return Status{}
+	}
+	root := filepath.Clean(dir)
+	idx := mgr.Lookup(root)
+	if idx != nil {
+		root = idx.Root
+	}
+
+	mgr.mu.Lock()
+	defer mgr.mu.Unlock()
+	st := Status{Root: root}
+	_, st.Building = mgr.building[root]
+	if idx != nil {
+		st.Ready = true
+		st.Entries = idx.Entries()
+		st.BuiltAt = idx.BuiltAt
+	}
+	return st
+}
+
+// Building reports whether any index build is in progress
+func (mgr *Manager) Building() bool {
+	if mgr == nil {
+		return false
+	}
+	mgr.mu.Lock()
+	defer mgr.mu.Unlock()
+	return len(mgr.building) > 0
+}
+
+// indexPath returns the on-disk file for root, named by a hash of the root path
+func (mgr *Manager) indexPath(root string) string {
+	sum := sha1.Sum([]byte(root))
+	return filepath.Join(mgr.dir, hex.EncodeToString(sum[:8])+".gob")
+}
+
+// load reads the saved index for root, rejecting it if it was built with a different skip list
+func (mgr *Manager) load(root string) (*Index, error) {
+	f, err := os.Open(mgr.indexPath(root))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	idx := &Index{}
+	if err := gob.NewDecoder(f).Decode(idx); err != nil {
+		return nil, fmt.Errorf("cannot decode index: %w", err)
+	}
+	if idx.Root != root {
+		return nil, fmt.Errorf("index file belongs to %s", idx.Root)
+	}
+	if idx.SkipKey != mgr.skipKey {
+		return nil, fmt.Errorf("skip directories changed since index was built")
+	}
+	idx.prepare()
+	logger.Info("Loaded index for %s: %d entries, built %v ago", root, idx.entryCount, time.Since(idx.BuiltAt).Round(time.Second))
+	return idx, nil
+}
+
+// save writes idx atomically (temp file + rename) so a crash never leaves a torn index
+func (mgr *Manager) save(idx *Index) error {
+	if err := os.MkdirAll(mgr.dir, 0755); err != nil {
+		return fmt.Errorf("cannot create index directory: %w", err)
+	}
+	path := mgr.indexPath(idx.Root)
+	tmp, err := os.CreateTemp(mgr.dir, ".index-*")
+	if err != nil {
+		return fmt.Errorf("cannot create temp index file: %w", err)
+	}
+	if err := gob.NewEncoder(tmp).Encode(idx); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("cannot encode index: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("cannot write index: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("cannot replace index: %w", err)
+	}
+	return nil
+}
+
+// Prune removes index files not saved for maxAge, then the least recently saved until
+// the rest fit in maxBytes, along with temp files left by interrupted saves. Indexes in
+// use are saved again on every refresh, so only roots nobody searches any more age out.
+// It returns how many files it removed.
+func (mgr *Manager) Prune(maxAge time.Duration, maxBytes int64) int {
+	if mgr == nil {
+		return 0
+	}
+	entries, err := os.ReadDir(mgr.dir)
+	if err != nil {
+		return 0
+	}
+
+	type indexFile struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	var files []indexFile
+	var total int64
+	removed := 0
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		path := filepath.Join(mgr.dir, e.Name())
+		age := time.Since(info.ModTime())
+		switch {
+		case strings.HasPrefix(e.Name(), ".index-"):
+			if age > time.Hour && os.Remove(path) == nil {
+				removed++
+			}
+		case filepath.Ext(e.Name()) != ".gob":
+		case age > maxAge:
+			if os.Remove(path) == nil {
+				removed++
+			}
+		default:
+			files = append(files, indexFile{path, info.Size(), info.ModTime()})
+			total += info.Size()
+		}
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+	for _, f := range files {
+		if total <= maxBytes {
+			break
+		}
+		if os.Remove(f.path) == nil {
+			removed++
+			total -= f.size
+		}
+	}
+	if removed > 0 {
+		logger.Info("Pruned %d index files from %s", removed, mgr.dir)
+	}
+	return removed
+}
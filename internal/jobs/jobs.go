@@ -0,0 +1,619 @@
+package jobs
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/LFroesch/scout/internal/archive"
+	"github.com/LFroesch/scout/internal/fileops"
+	"github.com/LFroesch/scout/internal/logger"
+)
+
+// maxFinished is how many finished jobs are kept around for the jobs panel
+const maxFinished = 50
+
+// Kind is what a job does with its sources
+type Kind int
+
+const (
+	Copy Kind = iota
+	Move
+	Extract  // Unpack each Src archive into its Dst directory
+	Compress // Pack every Src into the archive at Dst (which all items share)
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Move:
+		return "move"
+	case Extract:
+		return "extract"
+	case Compress:
+		return "compress"
+	}
+	return "copy"
+}
+
+// State is where a job is in its life
+type State int
+
+const (
+	Queued State = iota
+	Running
+	Done
+	Failed
+	Cancelled
+)
+
+func (s State) String() string {
+	switch s {
+	case Running:
+		return "running"
+	case Done:
+		return "done"
+	case Failed:
+		return "failed"
+	case Cancelled:
+		return "cancelled"
+	}
+	return "queued"
+}
+
+// Snapshot is a copy of a job's progress, safe to read from the UI
+type Snapshot struct {
+	ID      int
+	Kind    Kind
+	Sources []string
+	DestDir string
+	State   State
+	Err     error
+	Current string // Source item being worked on
+	Scanned bool   // Totals are known (sources have been measured)
+	// Created lists finished items whose destination didn't exist before (what undo can
+	// reverse): for an extract, each top-level path it unpacked; for a compress, the archive
+	Created []Item
+
+	FilesDone, FilesTotal int
+	BytesDone, BytesTotal int64
+
+	Started  time.Time // When the transfer began (after measuring)
+	Finished time.Time
+}
+
+// Active reports whether the job is queued or running
+func (s Snapshot) Active() bool {
+	return s.State == Queued || s.State == Running
+}
+
+// Throughput is the average transfer rate in bytes per second so far
+func (s Snapshot) Throughput() float64 {
+	end := s.Finished
+	if end.IsZero() {
+		end = time.Now()
+	}
+	elapsed := end.Sub(s.Started).Seconds()
+	if s.Started.IsZero() || elapsed <= 0 {
+		return 0
+	}
+	return float64(s.BytesDone) / elapsed
+}
+
+// ETA estimates the time left from the average throughput; false when there isn't enough
+// to go on yet
+func (s Snapshot) ETA() (time.Duration, bool) {
+	rate := s.Throughput()
+	if s.State != Running || !s.Scanned || rate <= 0 {
+		return 0, false
+	}
+	left := float64(s.BytesTotal-s.BytesDone) / rate
+	return time.Duration(left * float64(time.Second)), true
+}
+
+// Fraction is how far along the job is, from 0 to 1, by bytes (or files when there are no bytes)
+func (s Snapshot) Fraction() float64 {
+	switch {
+	case s.State == Done:
+		return 1
+	case s.BytesTotal > 0:
+		return float64(s.BytesDone) / float64(s.BytesTotal)
+	case s.FilesTotal > 0:
+		return float64(s.FilesDone) / float64(s.FilesTotal)
+	}
+	return 0
+}
+
+// Item is one source of a job and the path it's copied or moved to. Whatever is already
+// at Dst is overwritten (directories are merged), or with OnlyNewer, only by newer files.
+type Item struct {
+	Src       string
+	Dst       string
+	OnlyNewer bool
+}
+
+// job is a Snapshot plus what's needed to run and stop it
+type job struct {
+	Snapshot
+	items    []Item
+	opts     fileops.CopyOptions
+	cancel   chan struct{}
+	done     chan struct{}
+	reported bool // TakeFinished already handed it out
+}
+
+// Manager runs file operations in the background, one at a time in submission order, so
+// a big paste never blocks the UI and queued jobs don't fight over the disk.
+type Manager struct {
+	mu      sync.Mutex
+	jobs    []*job
+	nextID  int
+	running bool
+}
+
+// NewManager creates an empty job queue
+func NewManager() *Manager {
+	return &Manager{}
+}
+
+// Submit queues copying or moving items (pasted into destDir), or extracting or
+// compressing them, and returns the job's ID. opts picks the metadata copies keep; its
+// hooks are the manager's.
+func (mgr *Manager) Submit(kind Kind, items []Item, destDir string, opts fileops.CopyOptions) int {
+	mgr.mu.Lock()
+	defer mgr.mu.Unlock()
+	mgr.nextID++
+	sources := make([]string, len(items))
+	for i, it := range items {
+		sources[i] = it.Src
+	}
+	j := &job{
+		Snapshot: Snapshot{ID: mgr.nextID, Kind: kind, Sources: sources, DestDir: destDir},
+		items:    append([]Item(nil), items...),
+		opts:     opts,
+		cancel:   make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	mgr.jobs = append(mgr.jobs, j)
+	if !mgr.running {
+		mgr.running = true
+		go mgr.worker()
+	}
+	return j.ID
+}
+
+// Cancel stops a queued or running job. A running job removes what it had written of
+// the item in progress; items it already finished stay.
+func (mgr *Manager) Cancel(id int) {
+	mgr.mu.Lock()
+	defer mgr.mu.Unlock()
+	for _, j := range mgr.jobs {
+		if j.ID == id {
+			mgr.cancelLocked(j)
+		}
+	}
+}
+
+// CancelAll stops every queued and running job
+func (mgr *Manager) CancelAll() {
+	mgr.mu.Lock()
+	defer mgr.mu.Unlock()
+	for _, j := range mgr.jobs {
+		mgr.cancelLocked(j)
+	}
+}
+
+func (mgr *Manager) cancelLocked(j *job) {
+	if !j.Active() {
+		return
+	}
+	select {
+	case <-j.cancel:
+	default:
+		close(j.cancel)
+	}
+	if j.State == Queued {
+		j.State = Cancelled
+		j.Finished = time.Now()
+		close(j.done)
+	}
+}
+
+// Wait blocks until every submitted job has finished
+func (mgr *Manager) Wait() {
+	mgr.mu.Lock()
+	var pending []chan struct{}
+	for _, j := range mgr.jobs {
+		pending = append(pending, j.done)
+	}
+	mgr.mu.Unlock()
+	for _, done := range pending {
+		<-done
+	}
+}
+
+// Snapshots returns every job still listed, oldest first
+func (mgr *Manager) Snapshots() []Snapshot {
+	mgr.mu.Lock()
+	defer mgr.mu.Unlock()
+	snaps := make([]Snapshot, len(mgr.jobs))
+	for i, j := range mgr.jobs {
+		snaps[i] = j.Snapshot
+	}
+	return snaps
+}
+
+// Active returns the number of queued and running jobs
+func (mgr *Manager) Active() int {
+	mgr.mu.Lock()
+	defer mgr.mu.Unlock()
+	n := 0
+	for _, j := range mgr.jobs {
+		if j.Active() {
+			n++
+		}
+	}
+	return n
+}
+
+// TakeFinished returns jobs that finished since the last call, oldest first
+func (mgr *Manager) TakeFinished() []Snapshot {
+	mgr.mu.Lock()
+	defer mgr.mu.Unlock()
+	var finished []Snapshot
+	for _, j := range mgr.jobs {
+		if !j.Active() && !j.reported {
+			j.reported = true
+			finished = append(finished, j.Snapshot)
+		}
+	}
+	return finished
+}
+
+// ClearFinished drops finished jobs from the list
+func (mgr *Manager) ClearFinished() {
+	mgr.mu.Lock()
+	defer mgr.mu.Unlock()
+	kept := mgr.jobs[:0]
+	for _, j := range mgr.jobs {
+		if j.Active() {
+			kept = append(kept, j)
+		}
+	}
+	clear(mgr.jobs[len(kept):])
+	mgr.jobs = kept
+}
+
+// worker runs queued jobs until there are none left
+func (mgr *Manager) worker() {
+	for {
+		mgr.mu.Lock()
+		var next *job
+		for _, j := range mgr.jobs {
+			if j.State == Queued {
+				next = j
+				break
+			}
+		}
+		if next == nil {
+			mgr.running = false
+			mgr.mu.Unlock()
+			return
+		}
+		next.State = Running
+		mgr.mu.Unlock()
+
+		err := mgr.run(next)
+
+		mgr.mu.Lock()
+		next.Finished = time.Now()
+		next.Current = ""
+		switch {
+		case errors.Is(err, fileops.ErrCancelled):
+			next.State = Cancelled
+		case err != nil:
+			next.State = Failed
+			next.Err = err
+		default:
+			next.State = Done
+		}
+		close(next.done)
+		mgr.pruneLocked()
+		mgr.mu.Unlock()
+	}
+}
+
+// pruneLocked drops the oldest reported finished jobs beyond maxFinished
+func (mgr *Manager) pruneLocked() {
+	finished := 0
+	for _, j := range mgr.jobs {
+		if !j.Active() {
+			finished++
+		}
+	}
+	kept := mgr.jobs[:0]
+	for _, j := range mgr.jobs {
+		if finished > maxFinished && !j.Active() && j.reported {
+			finished--
+			continue
+		}
+		kept = append(kept, j)
+	}
+	clear(mgr.jobs[len(kept):])
+	mgr.jobs = kept
+}
+
+// update applies fn to the job's progress under the lock
+func (mgr *Manager) update(j *job, fn func(s *Snapshot)) {
+	mgr.mu.Lock()
+	fn(&j.Snapshot)
+	mgr.mu.Unlock()
+}
+
+// run measures the sources, then copies or moves them one by one
+func (mgr *Manager) run(j *job) error {
+	if j.Kind == Extract || j.Kind == Compress {
+		return mgr.runArchive(j)
+	}
+	files := make([]int, len(j.items))
+	bytes := make([]int64, len(j.items))
+	for i, it := range j.items {
+		var err error
+		if files[i], bytes[i], err = measure(it.Src, j.cancel); err != nil {
+			return err
+		}
+		mgr.update(j, func(s *Snapshot) {
+			s.FilesTotal += files[i]
+			s.BytesTotal += bytes[i]
+		})
+	}
+	mgr.update(j, func(s *Snapshot) {
+		s.Scanned = true
+		s.Started = time.Now()
+	})
+
+	opts := j.opts
+	opts.Cancel = j.cancel
+	opts.OnBytes = func(n int64) { mgr.update(j, func(s *Snapshot) { s.BytesDone += n }) }
+	opts.OnFile = func(string) { mgr.update(j, func(s *Snapshot) { s.FilesDone++ }) }
+
+	for i, it := range j.items {
+		var filesBefore int
+		var bytesBefore int64
+		mgr.update(j, func(s *Snapshot) {
+			s.Current = it.Src
+			filesBefore, bytesBefore = s.FilesDone, s.BytesDone
+		})
+		_, statErr := os.Lstat(it.Dst)
+		if err := runItem(j.Kind, it, opts); err != nil {
+			logger.Error("Job %d: %s %s -> %s failed: %v", j.ID, j.Kind, it.Src, it.Dst, err)
+			return err
+		}
+		// A move by rename (or a skipped item) reports no progress of its own
+		mgr.update(j, func(s *Snapshot) {
+			s.FilesDone = filesBefore + files[i]
+			s.BytesDone = bytesBefore + bytes[i]
+			if os.IsNotExist(statErr) {
+				s.Created = append(s.Created, it)
+			}
+		})
+	}
+	return nil
+}
+
+// runArchive extracts each item's archive, or packs all the items into their archive.
+// Stream formats (tar.gz and friends) don't say how many entries they hold without a full
+// read, so their extraction is measured in archive bytes only and FilesTotal stays 0.
+func (mgr *Manager) runArchive(j *job) error {
+	for _, it := range j.items {
+		var files int
+		var bytes int64
+		var err error
+		if j.Kind == Extract {
+			files, bytes, err = archive.Measure(it.Src)
+		} else {
+			files, bytes, err = measure(it.Src, j.cancel)
+		}
+		if err != nil {
+			return err
+		}
+		mgr.update(j, func(s *Snapshot) {
+			s.FilesTotal += files
+			s.BytesTotal += bytes
+		})
+	}
+	mgr.update(j, func(s *Snapshot) {
+		s.Scanned = true
+		s.Started = time.Now()
+	})
+
+	opts := fileops.CopyOptions{Cancel: j.cancel}
+	opts.OnBytes = func(n int64) { mgr.update(j, func(s *Snapshot) { s.BytesDone += n }) }
+	opts.OnFile = func(string) { mgr.update(j, func(s *Snapshot) { s.FilesDone++ }) }
+
+	if j.Kind == Compress {
+		dst := j.items[0].Dst
+		mgr.update(j, func(s *Snapshot) { s.Current = dst })
+		if err := archive.Create(dst, archive.Detect(dst), j.Sources, opts); err != nil {
+			logger.Error("Job %d: compress into %s failed: %v", j.ID, dst, err)
+			return err
+		}
+		mgr.update(j, func(s *Snapshot) { s.Created = append(s.Created, Item{Dst: dst}) })
+		return nil
+	}
+
+	for _, it := range j.items {
+		mgr.update(j, func(s *Snapshot) { s.Current = it.Src })
+		created, err := archive.Extract(it.Src, it.Dst, opts)
+		if err != nil {
+			logger.Error("Job %d: extract %s -> %s failed: %v", j.ID, it.Src, it.Dst, err)
+			return err
+		}
+		mgr.update(j, func(s *Snapshot) {
+			for _, path := range created {
+				s.Created = append(s.Created, Item{Src: it.Src, Dst: path})
+			}
+		})
+	}
+	return nil
+}
+
+// runItem copies or moves one item. If the copy fails or is cancelled, whatever it
+// created at Dst is removed; a Dst that was already there is left alone.
+func runItem(kind Kind, it Item, opts fileops.CopyOptions) error {
+	opts.OnlyNewer = it.OnlyNewer
+	existing, statErr := os.Lstat(it.Dst)
+	existed := statErr == nil
+	if existed {
+		srcInfo, err := os.Lstat(it.Src)
+		if err != nil {
+			return err
+		}
+		if srcInfo.IsDir() != existing.IsDir() {
+			if it.OnlyNewer && !srcInfo.ModTime().After(existing.ModTime()) {
+				return nil
+			}
+			return replaceItem(kind, it, opts)
+		}
+	}
+
+	var skipped map[string]bool
+	if kind == Move {
+		// Rename replaces files outright, so OnlyNewer has to go file by file
+		if !existed || !it.OnlyNewer {
+			if err := os.Rename(it.Src, it.Dst); err == nil {
+				return nil
+			}
+		}
+		// Across filesystems or into an existing directory: copy everything rename would
+		// have kept, then remove what was copied from the source
+		opts.Ownership, opts.Xattrs = true, true
+		skipped = make(map[string]bool)
+		opts.OnSkip = func(path string) { skipped[path] = true }
+	}
+
+	if err := fileops.CopyFileOrDir(it.Src, it.Dst, opts); err != nil {
+		if !existed {
+			if rmErr := os.RemoveAll(it.Dst); rmErr != nil {
+				logger.Warn("Failed to clean up partial copy %s: %v", it.Dst, rmErr)
+			}
+		}
+		return err
+	}
+	if kind == Move {
+		if err := removeMoved(it.Src, skipped); err != nil {
+			return fileops.FormatError(err, it.Src, "remove after moving")
+		}
+	}
+	return nil
+}
+
+// replaceItem copies or moves Src over a Dst of the other kind (a file over a directory or
+// the other way round), which can't be merged. Src goes to a hidden directory next to Dst
+// first, and Dst is only removed once its replacement is complete.
+func replaceItem(kind Kind, it Item, opts fileops.CopyOptions) error {
+	stage, err := os.MkdirTemp(filepath.Dir(it.Dst), ".scout-replace-*")
+	if err != nil {
+		return fileops.FormatError(err, it.Dst, "replace")
+	}
+	keepStage := false // Set if it ends up holding the only copy of a moved source
+	defer func() {
+		if !keepStage {
+			os.RemoveAll(stage)
+		}
+	}()
+	staged := filepath.Join(stage, filepath.Base(it.Dst))
+
+	renamed := kind == Move && os.Rename(it.Src, staged) == nil
+	if !renamed {
+		if kind == Move {
+			opts.Ownership, opts.Xattrs = true, true
+		}
+		if err := fileops.CopyFileOrDir(it.Src, staged, opts); err != nil {
+			return err
+		}
+	}
+
+	err = os.RemoveAll(it.Dst)
+	if err == nil {
+		err = os.Rename(staged, it.Dst)
+	}
+	if err != nil {
+		if renamed {
+			// Put the source back before the staging directory goes
+			if backErr := os.Rename(staged, it.Src); backErr != nil {
+				logger.Warn("Failed to move %s back from %s: %v", it.Src, staged, backErr)
+				keepStage = true
+			}
+		}
+		return fileops.FormatError(err, it.Dst, "replace")
+	}
+	if kind == Move && !renamed {
+		if err := os.RemoveAll(it.Src); err != nil {
+			return fileops.FormatError(err, it.Src, "remove after moving")
+		}
+	}
+	return nil
+}
+
+// removeMoved deletes root after a copy-based move, except the files in keep (sources
+// an OnlyNewer move didn't transfer) and the directories leading to them
+func removeMoved(root string, keep map[string]bool) error {
+	if len(keep) == 0 {
+		return os.RemoveAll(root)
+	}
+	if keep[root] {
+		return nil
+	}
+	keepDirs := make(map[string]bool)
+	for path := range keep {
+		for dir := filepath.Dir(path); !keepDirs[dir]; dir = filepath.Dir(dir) {
+			keepDirs[dir] = true
+			if dir == root || filepath.Dir(dir) == dir {
+				break
+			}
+		}
+	}
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if keep[path] || keepDirs[path] {
+			return nil
+		}
+		if err := os.RemoveAll(path); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+}
+
+// measure counts the entries (files, links, directories) under path and the bytes of its
+// regular files, matching what a copy reports through OnFile and OnBytes
+func measure(path string, cancel <-chan struct{}) (int, int64, error) {
+	files := 0
+	var bytes int64
+	err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		select {
+		case <-cancel:
+			return fileops.ErrCancelled
+		default:
+		}
+		if err != nil {
+			return err
+		}
+		files++
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				bytes += info.Size()
+			}
+		}
+		return nil
+	})
+	return files, bytes, err
+}
@@ -0,0 +1,319 @@
+package jobs
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/LFroesch/scout/internal/fileops"
+)
+
+// jobTree creates src/ holding a few files and a subdirectory, and an empty dest/
+func jobTree(t *testing.T) (src, dest string) {
+	t.Helper()
+	root := t.TempDir()
+	src = filepath.Join(root, "src")
+	dest = filepath.Join(root, "dest")
+	for _, dir := range []string{src, filepath.Join(src, "sub"), dest} {
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files := map[string]string{"a.txt": "aaaa", "b.txt": "bb", "sub/c.txt": "cccccc"}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(src, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return src, dest
+}
+
+// into builds Items pasting srcs into destDir under their own names
+func into(destDir string, srcs ...string) []Item {
+	items := make([]Item, len(srcs))
+	for i, src := range srcs {
+		items[i] = Item{Src: src, Dst: filepath.Join(destDir, filepath.Base(src))}
+	}
+	return items
+}
+
+func TestCopyJobReportsProgress(t *testing.T) {
+	src, dest := jobTree(t)
+	mgr := NewManager()
+	id := mgr.Submit(Copy, into(dest, src), dest, fileops.CopyOptions{})
+	mgr.Wait()
+
+	finished := mgr.TakeFinished()
+	if len(finished) != 1 || finished[0].ID != id {
+		t.Fatalf("TakeFinished = %+v, want job %d", finished, id)
+	}
+	s := finished[0]
+	if s.State != Done || s.Err != nil {
+		t.Fatalf("state = %v (%v), want done", s.State, s.Err)
+	}
+	// src, sub and three files
+	if s.FilesDone != 5 || s.FilesTotal != 5 {
+		t.Errorf("files = %d/%d, want 5/5", s.FilesDone, s.FilesTotal)
+	}
+	if s.BytesDone != 12 || s.BytesTotal != 12 {
+		t.Errorf("bytes = %d/%d, want 12/12", s.BytesDone, s.BytesTotal)
+	}
+	if s.Fraction() != 1 {
+		t.Errorf("Fraction = %v, want 1", s.Fraction())
+	}
+	if data, err := os.ReadFile(filepath.Join(dest, "src", "sub", "c.txt")); err != nil || string(data) != "cccccc" {
+		t.Errorf("copied file = %q, %v", data, err)
+	}
+	if again := mgr.TakeFinished(); len(again) != 0 {
+		t.Errorf("TakeFinished reported %d jobs twice", len(again))
+	}
+}
+
+func TestMoveJobCountsRenamedItems(t *testing.T) {
+	src, dest := jobTree(t)
+	mgr := NewManager()
+	mgr.Submit(Move, into(dest, filepath.Join(src, "a.txt"), filepath.Join(src, "sub")), dest, fileops.CopyOptions{})
+	mgr.Wait()
+
+	s := mgr.Snapshots()[0]
+	if s.State != Done {
+		t.Fatalf("state = %v (%v), want done", s.State, s.Err)
+	}
+	if s.FilesDone != 3 || s.BytesDone != 10 {
+		t.Errorf("progress = %d files, %d bytes, want 3 and 10", s.FilesDone, s.BytesDone)
+	}
+	for _, gone := range []string{"a.txt", "sub"} {
+		if _, err := os.Lstat(filepath.Join(src, gone)); !os.IsNotExist(err) {
+			t.Errorf("%s still in source after move", gone)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dest, "sub", "c.txt")); err != nil {
+		t.Errorf("moved tree missing: %v", err)
+	}
+}
+
+func TestJobsRunInOrder(t *testing.T) {
+	src, dest := jobTree(t)
+	mgr := NewManager()
+	first := mgr.Submit(Copy, into(dest, filepath.Join(src, "a.txt")), dest, fileops.CopyOptions{})
+	second := mgr.Submit(Copy, into(dest, filepath.Join(src, "b.txt")), dest, fileops.CopyOptions{})
+	mgr.Wait()
+
+	snaps := mgr.Snapshots()
+	if len(snaps) != 2 || snaps[0].ID != first || snaps[1].ID != second {
+		t.Fatalf("snapshots = %+v", snaps)
+	}
+	if snaps[1].Started.Before(snaps[0].Finished) {
+		t.Errorf("second job started before the first finished")
+	}
+	if mgr.Active() != 0 {
+		t.Errorf("Active = %d after Wait", mgr.Active())
+	}
+	mgr.ClearFinished()
+	if n := len(mgr.Snapshots()); n != 0 {
+		t.Errorf("%d jobs left after ClearFinished", n)
+	}
+}
+
+func TestCancelRemovesPartialCopy(t *testing.T) {
+	src, dest := jobTree(t)
+	cancel := make(chan struct{})
+	opts := fileops.CopyOptions{
+		Cancel: cancel,
+		// Stop as soon as the first file lands
+		OnFile: func(string) {
+			select {
+			case <-cancel:
+			default:
+				close(cancel)
+			}
+		},
+	}
+	dst := filepath.Join(dest, "src")
+	err := runItem(Copy, Item{Src: src, Dst: dst}, opts)
+	if !errors.Is(err, fileops.ErrCancelled) {
+		t.Fatalf("runItem = %v, want ErrCancelled", err)
+	}
+	if _, err := os.Lstat(dst); !os.IsNotExist(err) {
+		t.Errorf("partial copy %s left behind", dst)
+	}
+	if _, err := os.Stat(filepath.Join(src, "sub", "c.txt")); err != nil {
+		t.Errorf("source damaged: %v", err)
+	}
+}
+
+func TestCancelKeepsExistingDestination(t *testing.T) {
+	src, dest := jobTree(t)
+	dst := filepath.Join(dest, "src")
+	if err := os.Mkdir(dst, 0755); err != nil {
+		t.Fatal(err)
+	}
+	cancel := make(chan struct{})
+	close(cancel)
+	if err := runItem(Copy, Item{Src: src, Dst: dst}, fileops.CopyOptions{Cancel: cancel}); !errors.Is(err, fileops.ErrCancelled) {
+		t.Fatalf("runItem = %v, want ErrCancelled", err)
+	}
+	if _, err := os.Stat(dst); err != nil {
+		t.Errorf("directory that was already there got removed: %v", err)
+	}
+}
+
+func TestCancelQueuedJob(t *testing.T) {
+	src, dest := jobTree(t)
+	mgr := NewManager()
+	mgr.mu.Lock() // Hold the worker off so both jobs are still queued
+	mgr.running = true
+	mgr.mu.Unlock()
+	first := mgr.Submit(Copy, into(dest, filepath.Join(src, "a.txt")), dest, fileops.CopyOptions{})
+	second := mgr.Submit(Copy, into(dest, filepath.Join(src, "b.txt")), dest, fileops.CopyOptions{})
+	mgr.Cancel(second)
+	go mgr.worker()
+	mgr.Wait()
+
+	snaps := mgr.Snapshots()
+	if snaps[0].ID != first || snaps[0].State != Done {
+		t.Errorf("first job = %v, want done", snaps[0].State)
+	}
+	if snaps[1].State != Cancelled {
+		t.Errorf("second job = %v, want cancelled", snaps[1].State)
+	}
+	if _, err := os.Lstat(filepath.Join(dest, "b.txt")); !os.IsNotExist(err) {
+		t.Errorf("cancelled job still copied b.txt")
+	}
+}
+
+func TestMoveOnlyNewerKeepsOlderSources(t *testing.T) {
+	src, dest := jobTree(t)
+	dst := filepath.Join(dest, "src")
+	if err := os.MkdirAll(filepath.Join(dst, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	// dest has a newer a.txt and an older sub/c.txt than the source
+	old, now := time.Now().Add(-time.Hour), time.Now()
+	os.WriteFile(filepath.Join(dst, "a.txt"), []byte("newer"), 0644)
+	os.Chtimes(filepath.Join(src, "a.txt"), old, old)
+	os.WriteFile(filepath.Join(dst, "sub", "c.txt"), []byte("older"), 0644)
+	os.Chtimes(filepath.Join(dst, "sub", "c.txt"), old, old)
+	os.Chtimes(filepath.Join(src, "sub", "c.txt"), now, now)
+
+	if err := runItem(Move, Item{Src: src, Dst: dst, OnlyNewer: true}, fileops.CopyOptions{}); err != nil {
+		t.Fatalf("runItem: %v", err)
+	}
+	for name, want := range map[string]string{"a.txt": "newer", "b.txt": "bb", "sub/c.txt": "cccccc"} {
+		if data, _ := os.ReadFile(filepath.Join(dst, name)); string(data) != want {
+			t.Errorf("%s = %q, want %q", name, data, want)
+		}
+	}
+	// Only the source file that wasn't moved is left
+	if _, err := os.Stat(filepath.Join(src, "a.txt")); err != nil {
+		t.Errorf("older a.txt was removed from the source: %v", err)
+	}
+	for _, gone := range []string{"b.txt", "sub"} {
+		if _, err := os.Lstat(filepath.Join(src, gone)); !os.IsNotExist(err) {
+			t.Errorf("%s still in the source after moving", gone)
+		}
+	}
+}
+
+func TestOverwriteReplacesMismatchedType(t *testing.T) {
+	src, dest := jobTree(t)
+	dst := filepath.Join(dest, "a.txt")
+	if err := os.Mkdir(dst, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := runItem(Copy, Item{Src: filepath.Join(src, "a.txt"), Dst: dst}, fileops.CopyOptions{}); err != nil {
+		t.Fatalf("runItem: %v", err)
+	}
+	if data, err := os.ReadFile(dst); err != nil || string(data) != "aaaa" {
+		t.Errorf("directory not replaced by the file: %q, %v", data, err)
+	}
+}
+
+func TestCancelledReplaceKeepsMismatchedDestination(t *testing.T) {
+	src, dest := jobTree(t)
+	dst := filepath.Join(dest, "sub")
+	os.WriteFile(dst, []byte("keep"), 0644)
+	cancel := make(chan struct{})
+	opts := fileops.CopyOptions{Cancel: cancel, OnFile: func(string) {
+		select {
+		case <-cancel:
+		default:
+			close(cancel)
+		}
+	}}
+	// sub/ holds a single file, so give it a second for the cancel to stop
+	os.WriteFile(filepath.Join(src, "sub", "d.txt"), []byte("d"), 0644)
+	if err := runItem(Copy, Item{Src: filepath.Join(src, "sub"), Dst: dst}, opts); !errors.Is(err, fileops.ErrCancelled) {
+		t.Fatalf("runItem = %v, want ErrCancelled", err)
+	}
+	if data, err := os.ReadFile(dst); err != nil || string(data) != "keep" {
+		t.Errorf("file replaced by a cancelled copy: %q, %v", data, err)
+	}
+	if entries, _ := os.ReadDir(dest); len(entries) != 1 {
+		t.Errorf("staging left behind: %d entries in dest", len(entries))
+	}
+}
+
+func TestMoveReplacesMismatchedType(t *testing.T) {
+	src, dest := jobTree(t)
+	dst := filepath.Join(dest, "sub")
+	os.WriteFile(dst, []byte("old"), 0644)
+	if err := runItem(Move, Item{Src: filepath.Join(src, "sub"), Dst: dst}, fileops.CopyOptions{}); err != nil {
+		t.Fatalf("runItem: %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dst, "c.txt")); err != nil || string(data) != "cccccc" {
+		t.Errorf("file not replaced by the directory: %q, %v", data, err)
+	}
+	if _, err := os.Lstat(filepath.Join(src, "sub")); !os.IsNotExist(err) {
+		t.Error("source still there after moving")
+	}
+	if entries, _ := os.ReadDir(dest); len(entries) != 1 {
+		t.Errorf("staging left behind: %d entries in dest", len(entries))
+	}
+}
+
+func TestCreatedListsOnlyNewDestinations(t *testing.T) {
+	src, dest := jobTree(t)
+	if err := os.WriteFile(filepath.Join(dest, "b.txt"), []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mgr := NewManager()
+	mgr.Submit(Copy, into(dest, filepath.Join(src, "a.txt"), filepath.Join(src, "b.txt")), dest, fileops.CopyOptions{})
+	mgr.Wait()
+
+	s := mgr.Snapshots()[0]
+	if len(s.Created) != 1 || s.Created[0].Dst != filepath.Join(dest, "a.txt") {
+		t.Errorf("Created = %+v, want just a.txt (b.txt was overwritten)", s.Created)
+	}
+}
+
+func TestArchiveJobs(t *testing.T) {
+	src, dest := jobTree(t)
+	mgr := NewManager()
+	zipPath := filepath.Join(dest, "src.zip")
+	mgr.Submit(Compress, []Item{{Src: src, Dst: zipPath}}, dest, fileops.CopyOptions{})
+	mgr.Wait()
+	s := mgr.TakeFinished()[0]
+	if s.State != Done || len(s.Created) != 1 || s.Created[0].Dst != zipPath {
+		t.Fatalf("compress: state %v (%v), created %+v", s.State, s.Err, s.Created)
+	}
+	if s.FilesDone != 5 || s.BytesDone != 12 {
+		t.Errorf("compress progress = %d files, %d bytes; want 5, 12", s.FilesDone, s.BytesDone)
+	}
+
+	out := filepath.Join(dest, "out")
+	mgr.Submit(Extract, []Item{{Src: zipPath, Dst: out}}, out, fileops.CopyOptions{})
+	mgr.Wait()
+	s = mgr.TakeFinished()[0]
+	if s.State != Done || len(s.Created) != 1 || s.Created[0].Dst != out {
+		t.Fatalf("extract: state %v (%v), created %+v", s.State, s.Err, s.Created)
+	}
+	if s.FilesDone != s.FilesTotal || s.BytesDone != s.BytesTotal {
+		t.Errorf("extract progress = %d/%d files, %d/%d bytes", s.FilesDone, s.FilesTotal, s.BytesDone, s.BytesTotal)
+	}
+	if data, _ := os.ReadFile(filepath.Join(out, "src", "sub", "c.txt")); string(data) != "cccccc" {
+		t.Errorf("extracted c.txt = %q", data)
+	}
+}
@@ -0,0 +1,309 @@
+// Package markdown renders Markdown for the preview pane: headings, lists, quotes, code
+// blocks (coloured by internal/highlight), tables and links, in scout's palette. Output
+// doesn't depend on the pane width: each paragraph is one line and the preview's own
+// wrapping reflows it.
+package markdown
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	xansi "github.com/charmbracelet/x/ansi"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/text"
+
+	"github.com/LFroesch/scout/internal/highlight"
+)
+
+// maxCellWidth caps a table column; longer cells are cut with an ellipsis
+const maxCellWidth = 40
+
+var (
+	h1Style      = lipgloss.NewStyle().Foreground(lipgloss.Color("105")).Bold(true)
+	h2Style      = lipgloss.NewStyle().Foreground(lipgloss.Color("105")).Bold(true)
+	hStyle       = lipgloss.NewStyle().Foreground(lipgloss.Color("99")).Bold(true)
+	codeStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+	linkStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("105")).Underline(true)
+	dimStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+	quoteStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("245")).Italic(true)
+	ruleStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	markerStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("99"))
+	checkedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
+	headerStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("105")).Bold(true)
+)
+
+// bullets for unordered lists, by nesting depth
+var bullets = []string{"•", "◦", "▪"}
+
+var parser = goldmark.New(goldmark.WithExtensions(extension.GFM)).Parser()
+
+// Is reports whether name is a Markdown file
+func Is(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".md", ".markdown":
+		return true
+	}
+	return false
+}
+
+// Render returns src as styled text for the terminal
+func Render(src string) string {
+	source := []byte(src)
+	r := renderer{src: source}
+	return strings.Join(r.blocks(parser.Parse(text.NewReader(source)), false), "\n")
+}
+
+// renderer walks a parsed document, turning each block into lines
+type renderer struct {
+	src   []byte
+	depth int  // List nesting
+	quote bool // Inside a blockquote
+}
+
+// blocks renders n's children in order, with a blank line between them unless tight
+func (r *renderer) blocks(n ast.Node, tight bool) []string {
+	var lines []string
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		if len(lines) > 0 && !tight {
+			lines = append(lines, "")
+		}
+		lines = append(lines, r.block(c)...)
+	}
+	return lines
+}
+
+func (r *renderer) block(n ast.Node) []string {
+	switch n := n.(type) {
+	case *ast.Heading:
+		switch n.Level {
+		case 1:
+			title := r.inline(n, h1Style)
+			return []string{title, h1Style.Render(strings.Repeat("═", lipgloss.Width(title)))}
+		case 2:
+			title := r.inline(n, h2Style)
+			return []string{title, ruleStyle.Render(strings.Repeat("─", lipgloss.Width(title)))}
+		}
+		return []string{r.inline(n, hStyle)}
+
+	case *ast.Paragraph, *ast.TextBlock:
+		base := lipgloss.NewStyle()
+		if r.quote {
+			base = quoteStyle
+		}
+		return strings.Split(r.inline(n, base), "\n")
+
+	case *ast.List:
+		return r.list(n)
+
+	case *ast.Blockquote:
+		outer := r.quote
+		r.quote = true
+		lines := r.blocks(n, false)
+		r.quote = outer
+		for i, line := range lines {
+			lines[i] = ruleStyle.Render("│ ") + line
+		}
+		return lines
+
+	case *ast.FencedCodeBlock:
+		return r.code(n, string(n.Language(r.src)))
+
+	case *ast.CodeBlock:
+		return r.code(n, "")
+
+	case *ast.ThematicBreak:
+		return []string{ruleStyle.Render(strings.Repeat("─", 40))}
+
+	case *ast.HTMLBlock:
+		lines := strings.Split(strings.TrimRight(r.text(n.Lines()), "\n"), "\n")
+		for i, line := range lines {
+			lines[i] = dimStyle.Render(line)
+		}
+		return lines
+
+	case *east.Table:
+		return r.table(n)
+	}
+	return r.blocks(n, false)
+}
+
+// list renders items with their bullet or number, hanging the rest of each item under
+// its first line
+func (r *renderer) list(l *ast.List) []string {
+	r.depth++
+	defer func() { r.depth-- }()
+
+	var lines []string
+	num := l.Start
+	for item := l.FirstChild(); item != nil; item = item.NextSibling() {
+		marker := bullets[min(r.depth, len(bullets))-1]
+		if l.IsOrdered() {
+			marker = fmt.Sprintf("%d%c", num, l.Marker)
+			num++
+		}
+		if len(lines) > 0 && !l.IsTight {
+			lines = append(lines, "")
+		}
+		indent := strings.Repeat(" ", len([]rune(marker))+1)
+		for i, line := range r.blocks(item, l.IsTight) {
+			switch {
+			case i == 0:
+				line = markerStyle.Render(marker) + " " + line
+			case line != "":
+				line = indent + line
+			}
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// code renders a code block behind a bar, coloured when lang is known
+func (r *renderer) code(n ast.Node, lang string) []string {
+	src := strings.TrimRight(r.text(n.Lines()), "\n")
+	if highlighted, ok := highlight.Block(lang, src); ok {
+		src = highlighted
+	}
+	lines := strings.Split(src, "\n")
+	for i, line := range lines {
+		lines[i] = ruleStyle.Render("│ ") + line
+	}
+	return lines
+}
+
+// table renders a GFM table with columns padded to their widest cell (up to maxCellWidth)
+func (r *renderer) table(t *east.Table) []string {
+	var rows [][]string
+	for row := t.FirstChild(); row != nil; row = row.NextSibling() {
+		style := lipgloss.NewStyle()
+		if row.Kind() == east.KindTableHeader {
+			style = headerStyle
+		}
+		var cells []string
+		for cell := row.FirstChild(); cell != nil; cell = cell.NextSibling() {
+			cells = append(cells, xansi.Truncate(r.inline(cell, style), maxCellWidth, "…"))
+		}
+		rows = append(rows, cells)
+	}
+
+	widths := make([]int, len(t.Alignments))
+	for _, cells := range rows {
+		for i, cell := range cells {
+			if i < len(widths) {
+				widths[i] = max(widths[i], lipgloss.Width(cell))
+			}
+		}
+	}
+
+	sep := ruleStyle.Render(" │ ")
+	var lines []string
+	for n, cells := range rows {
+		padded := make([]string, len(widths))
+		for i, w := range widths {
+			cell := ""
+			if i < len(cells) {
+				cell = cells[i]
+			}
+			padded[i] = pad(cell, w, t.Alignments[i])
+		}
+		lines = append(lines, strings.Join(padded, sep))
+		if n == 0 {
+			rule := make([]string, len(widths))
+			for i, w := range widths {
+				rule[i] = strings.Repeat("─", w)
+			}
+			lines = append(lines, ruleStyle.Render(strings.Join(rule, "─┼─")))
+		}
+	}
+	return lines
+}
+
+// pad fills cell out to width w, on the side its column alignment calls for
+func pad(cell string, w int, align east.Alignment) string {
+	gap := w - lipgloss.Width(cell)
+	switch align {
+	case east.AlignRight:
+		return strings.Repeat(" ", gap) + cell
+	case east.AlignCenter:
+		return strings.Repeat(" ", gap/2) + cell + strings.Repeat(" ", gap-gap/2)
+	}
+	return cell + strings.Repeat(" ", gap)
+}
+
+// inline renders n's inline children, each run of text in style plus whatever its
+// emphasis, code span or link adds. Soft line breaks become spaces, hard ones newlines.
+func (r *renderer) inline(n ast.Node, style lipgloss.Style) string {
+	var b strings.Builder
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		switch c := c.(type) {
+		case *ast.Text:
+			b.WriteString(styled(style, string(c.Segment.Value(r.src))))
+			if c.HardLineBreak() {
+				b.WriteString("\n")
+			} else if c.SoftLineBreak() {
+				b.WriteString(" ")
+			}
+		case *ast.String:
+			b.WriteString(styled(style, string(c.Value)))
+		case *ast.CodeSpan:
+			b.WriteString(r.inline(c, codeStyle.Inherit(style)))
+		case *ast.Emphasis:
+			if c.Level >= 2 {
+				b.WriteString(r.inline(c, style.Bold(true)))
+			} else {
+				b.WriteString(r.inline(c, style.Italic(true)))
+			}
+		case *east.Strikethrough:
+			b.WriteString(r.inline(c, style.Strikethrough(true)))
+		case *ast.Link:
+			label := r.inline(c, linkStyle.Inherit(style))
+			b.WriteString(label)
+			if dest := string(c.Destination); dest != xansi.Strip(label) {
+				b.WriteString(dimStyle.Render(" (" + dest + ")"))
+			}
+		case *ast.AutoLink:
+			b.WriteString(styled(linkStyle.Inherit(style), string(c.Label(r.src))))
+		case *ast.Image:
+			b.WriteString(dimStyle.Render("[image: " + xansi.Strip(r.inline(c, style)) + "]"))
+		case *ast.RawHTML:
+			for i := 0; i < c.Segments.Len(); i++ {
+				seg := c.Segments.At(i)
+				b.WriteString(dimStyle.Render(string(seg.Value(r.src))))
+			}
+		case *east.TaskCheckBox:
+			if c.IsChecked {
+				b.WriteString(checkedStyle.Render("☑") + " ")
+			} else {
+				b.WriteString(markerStyle.Render("☐") + " ")
+			}
+		default:
+			b.WriteString(r.inline(c, style))
+		}
+	}
+	return b.String()
+}
+
+// text joins the raw source lines of a block
+func (r *renderer) text(lines *text.Segments) string {
+	var b strings.Builder
+	for i := 0; i < lines.Len(); i++ {
+		seg := lines.At(i)
+		b.Write(seg.Value(r.src))
+	}
+	return b.String()
+}
+
+// styled wraps s in style's escape codes. Those come from rendering a placeholder, since
+// Render itself underlines rune by rune and would mangle tabs.
+func styled(style lipgloss.Style, s string) string {
+	if s == "" {
+		return ""
+	}
+	open, close, _ := strings.Cut(style.Render("x"), "x")
+	return open + s + close
+}
@@ -0,0 +1,75 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	xansi "github.com/charmbracelet/x/ansi"
+	"github.com/muesli/termenv"
+)
+
+func TestMain(m *testing.M) {
+	lipgloss.SetColorProfile(termenv.ANSI256)
+	m.Run()
+}
+
+func TestRenderBlocks(t *testing.T) {
+	src := "# Scout\n\nA *fast* file\nexplorer, see [docs](https://x.io).\n\n" +
+		"- one\n- two\n  - nested\n- [x] done\n\n3. third\n4. fourth\n\n" +
+		"> quoted\n\n```go\nfunc main() {}\n```\n\n---\n"
+	want := strings.Join([]string{
+		"Scout",
+		"═════",
+		"",
+		"A fast file explorer, see docs (https://x.io).",
+		"",
+		"• one",
+		"• two",
+		"  ◦ nested",
+		"• ☑ done",
+		"",
+		"3. third",
+		"4. fourth",
+		"",
+		"│ quoted",
+		"",
+		"│ func main() {}",
+		"",
+		strings.Repeat("─", 40),
+	}, "\n")
+
+	out := Render(src)
+	if got := xansi.Strip(out); got != want {
+		t.Errorf("rendered:\n%s\nwant:\n%s", got, want)
+	}
+	// Emphasis, links and the code block are coloured
+	for _, code := range []string{"\x1b[3m", "38;5;105", "38;5;230"} {
+		if !strings.Contains(out, code) {
+			t.Errorf("no %q in %q", code, out)
+		}
+	}
+}
+
+func TestRenderTable(t *testing.T) {
+	src := "| Key | Action |\n|:---|---:|\n| `j` | down |\n| k | up a lot |\n"
+	want := strings.Join([]string{
+		"Key │   Action",
+		"────┼─────────",
+		"j   │     down",
+		"k   │ up a lot",
+	}, "\n")
+	if got := xansi.Strip(Render(src)); got != want {
+		t.Errorf("rendered:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestIs(t *testing.T) {
+	for name, want := range map[string]bool{
+		"README.md": true, "notes.MARKDOWN": true, "md": false, "doc.mdx": false, "a.txt": false,
+	} {
+		if Is(name) != want {
+			t.Errorf("Is(%q) = %v", name, !want)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package search
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// Scoring follows fzf's v1 algorithm: every matched character is worth scoreMatch,
+// gaps cost a little, and characters that start a "word" (after a separator, space,
+// punctuation, or a lower→upper camelCase step) earn a bonus. The first query
+// character's bonus counts double, so "mg" prefers "model.go" over "image".
+const (
+	scoreMatch        = 16
+	scoreGapStart     = -3
+	scoreGapExtension = -1
+
+	bonusBoundary          = scoreMatch / 2
+	bonusNonWord           = scoreMatch / 2
+	bonusBoundaryWhite     = bonusBoundary + 2
+	bonusBoundaryDelimiter = bonusBoundary + 1
+	bonusCamel123          = bonusBoundary + scoreGapExtension
+	bonusConsecutive       = -(scoreGapStart + scoreGapExtension)
+
+	bonusFirstCharMultiplier = 2
+)
+
+type charClass int
+
+const (
+	charWhite charClass = iota
+	charNonWord
+	charDelimiter
+	charLower
+	charUpper
+	charLetter
+	charNumber
+)
+
+func classOf(r rune) charClass {
+	switch {
+	case r >= 'a' && r <= 'z':
+		return charLower
+	case r >= 'A' && r <= 'Z':
+		return charUpper
+	case r >= '0' && r <= '9':
+		return charNumber
+	case r == '/' || r == '\\' || r == ',' || r == ':' || r == ';' || r == '|':
+		return charDelimiter
+	case r == ' ' || r == '\t' || r == '\n':
+		return charWhite
+	case unicode.IsLower(r):
+		return charLower
+	case unicode.IsUpper(r):
+		return charUpper
+	case unicode.IsNumber(r):
+		return charNumber
+	case unicode.IsLetter(r):
+		return charLetter
+	case unicode.IsSpace(r):
+		return charWhite
+	}
+	return charNonWord
+}
+
+// bonusFor is the bonus for matching a character of class cur right after one of class prev
+func bonusFor(prev, cur charClass) int {
+	if cur > charDelimiter {
+		switch prev {
+		case charWhite:
+			return bonusBoundaryWhite
+		case charDelimiter:
+			return bonusBoundaryDelimiter
+		case charNonWord:
+			return bonusBoundary
+		}
+	}
+	if prev == charLower && cur == charUpper || prev != charNumber && cur == charNumber {
+		return bonusCamel123
+	}
+	switch cur {
+	case charNonWord, charDelimiter:
+		return bonusNonWord
+	case charWhite:
+		return bonusBoundaryWhite
+	}
+	return 0
+}
+
+// FuzzyMatcher scores targets against one query. Build it once per search with
+// NewFuzzyMatcher and reuse it for every candidate.
+type FuzzyMatcher struct {
+	pattern       []rune
+	caseSensitive bool
+}
+
+// NewFuzzyMatcher prepares query for matching. Smart-case: the match is case-insensitive
+// unless the query contains an uppercase letter.
+func NewFuzzyMatcher(query string) *FuzzyMatcher {
+	fm := &FuzzyMatcher{pattern: []rune(query)}
+	for _, r := range fm.pattern {
+		if unicode.IsUpper(r) {
+			fm.caseSensitive = true
+			break
+		}
+	}
+	if !fm.caseSensitive {
+		for i, r := range fm.pattern {
+			fm.pattern[i] = unicode.ToLower(r)
+		}
+	}
+	return fm
+}
+
+func (fm *FuzzyMatcher) fold(r rune) rune {
+	if fm.caseSensitive {
+		return r
+	}
+	if r < utf8.RuneSelf {
+		if r >= 'A' && r <= 'Z' {
+			return r + ('a' - 'A')
+		}
+		return r
+	}
+	return unicode.ToLower(r)
+}
+
+// Match reports whether the query is a subsequence of target. On a match it returns
+// the score (higher is better) and the matched rune positions in target, ready for
+// utils.HighlightMatches.
+func (fm *FuzzyMatcher) Match(target string) (int, []int, bool) {
+	if len(fm.pattern) == 0 {
+		return 0, nil, false
+	}
+
+	// Cheap rejection without allocating: most candidates don't contain the subsequence
+	pidx := 0
+	for _, r := range target {
+		if fm.fold(r) == fm.pattern[pidx] {
+			pidx++
+			if pidx == len(fm.pattern) {
+				break
+			}
+		}
+	}
+	if pidx < len(fm.pattern) {
+		return 0, nil, false
+	}
+
+	text := []rune(target)
+
+	// Forward pass: where does the earliest complete match end?
+	pidx = 0
+	eidx := 0
+	for i, r := range text {
+		if fm.fold(r) == fm.pattern[pidx] {
+			pidx++
+			if pidx == len(fm.pattern) {
+				eidx = i + 1
+				break
+			}
+		}
+	}
+
+	// Backward pass from there: tightest start for that end
+	pidx = len(fm.pattern) - 1
+	sidx := 0
+	for i := eidx - 1; i >= 0; i-- {
+		if fm.fold(text[i]) == fm.pattern[pidx] {
+			pidx--
+			if pidx < 0 {
+				sidx = i
+				break
+			}
+		}
+	}
+
+	return fm.score(text, sidx, eidx)
+}
+
+// score walks text[sidx:eidx] matching the pattern greedily and totals bonuses and gap penalties
+func (fm *FuzzyMatcher) score(text []rune, sidx, eidx int) (int, []int, bool) {
+	positions := make([]int, 0, len(fm.pattern))
+	score, pidx, consecutive, firstBonus := 0, 0, 0, 0
+	inGap := false
+
+	prevClass := charWhite // Start of string counts as a word boundary
+	if sidx > 0 {
+		prevClass = classOf(text[sidx-1])
+	}
+
+	for i := sidx; i < eidx; i++ {
+		r := text[i]
+		class := classOf(r)
+		if pidx < len(fm.pattern) && fm.fold(r) == fm.pattern[pidx] {
+			positions = append(positions, i)
+			score += scoreMatch
+			bonus := bonusFor(prevClass, class)
+			if consecutive == 0 {
+				firstBonus = bonus
+			} else {
+				// A run keeps the bonus of the boundary it started on
+				if bonus >= bonusBoundary && bonus > firstBonus {
+					firstBonus = bonus
+				}
+				bonus = max(bonus, firstBonus, bonusConsecutive)
+			}
+			if pidx == 0 {
+				score += bonus * bonusFirstCharMultiplier
+			} else {
+				score += bonus
+			}
+			inGap = false
+			consecutive++
+			pidx++
+		} else {
+			if inGap {
+				score += scoreGapExtension
+			} else {
+				score += scoreGapStart
+			}
+			inGap = true
+			consecutive = 0
+			firstBonus = 0
+		}
+		prevClass = class
+	}
+	return score, positions, true
+}
+
+// FuzzyMatch is a one-off convenience wrapper around NewFuzzyMatcher(query).Match(target)
+func FuzzyMatch(query, target string) (int, []int, bool) {
+	return NewFuzzyMatcher(query).Match(target)
+}
+
+// FuzzyMatchNames fuzzy-matches query against each name, returning matches in input order
+// with their scores and matched rune positions
+func FuzzyMatchNames(query string, names []string) []MatchResult {
+	if query == "" {
+		return nil
+	}
+	fm := NewFuzzyMatcher(query)
+	var results []MatchResult
+	for i, name := range names {
+		if score, positions, ok := fm.Match(name); ok {
+			results = append(results, MatchResult{Index: i, MatchedIndexes: positions, Score: score})
+		}
+	}
+	return results
+}
@@ -0,0 +1,98 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFuzzyMatchSubsequence(t *testing.T) {
+	score, positions, ok := FuzzyMatch("mdlgo", "model.go")
+	if !ok {
+		t.Fatal("expected mdlgo to match model.go")
+	}
+	if score <= 0 {
+		t.Errorf("expected positive score, got %d", score)
+	}
+	if want := []int{0, 2, 4, 6, 7}; !reflect.DeepEqual(positions, want) {
+		t.Errorf("positions = %v, want %v", positions, want)
+	}
+
+	if _, _, ok := FuzzyMatch("mdlgo", "golden.md"); ok {
+		t.Error("out-of-order characters should not match")
+	}
+	if _, _, ok := FuzzyMatch("", "anything"); ok {
+		t.Error("empty query should not match")
+	}
+}
+
+func TestFuzzyMatchSmartCase(t *testing.T) {
+	if _, _, ok := FuzzyMatch("readme", "README.md"); !ok {
+		t.Error("lowercase query should match case-insensitively")
+	}
+	if _, _, ok := FuzzyMatch("README", "readme.md"); ok {
+		t.Error("query with uppercase should match case-sensitively")
+	}
+	if _, _, ok := FuzzyMatch("RM", "ReadMe.md"); !ok {
+		t.Error("uppercase query should match exact-case characters")
+	}
+}
+
+func TestFuzzyMatchRanking(t *testing.T) {
+	tests := []struct {
+		query, better, worse string
+	}{
+		// Word boundaries beat mid-word hits
+		{"mg", "model.go", "image"},
+		// Path separator boundaries
+		{"sm", "search/model.go", "transmit.go"},
+		// camelCase humps
+		{"fb", "FooBar.go", "fabric.go"},
+		// Consecutive runs beat scattered characters
+		{"test", "test_util.go", "t_e_s_t.go"},
+	}
+
+	for _, tt := range tests {
+		better, _, ok1 := FuzzyMatch(tt.query, tt.better)
+		worse, _, ok2 := FuzzyMatch(tt.query, tt.worse)
+		if !ok1 || !ok2 {
+			t.Errorf("%q: expected both %q and %q to match", tt.query, tt.better, tt.worse)
+			continue
+		}
+		if better <= worse {
+			t.Errorf("%q: expected %q (%d) to outscore %q (%d)", tt.query, tt.better, better, tt.worse, worse)
+		}
+	}
+}
+
+func TestFuzzyMatchRunePositions(t *testing.T) {
+	// Positions are rune offsets so utils.HighlightMatches lines up on non-ASCII names
+	_, positions, ok := FuzzyMatch("ab", "éa-b")
+	if !ok {
+		t.Fatal("expected match")
+	}
+	if want := []int{1, 3}; !reflect.DeepEqual(positions, want) {
+		t.Errorf("positions = %v, want %v", positions, want)
+	}
+}
+
+func TestFuzzyMatchNames(t *testing.T) {
+	names := []string{"model.go", "update.go", "view.go", "main.go"}
+	results := FuzzyMatchNames("mgo", names)
+	if len(results) != 2 {
+		t.Fatalf("expected model.go and main.go, got %d results", len(results))
+	}
+	if results[0].Index != 0 || results[1].Index != 3 {
+		t.Errorf("expected input order to be kept, got %+v", results)
+	}
+}
+
+func TestMatchTargetNameOnlyOffset(t *testing.T) {
+	target, offset := MatchTarget("dir/sub/naïve.txt", "naïve.txt", true)
+	if target != "naïve.txt" || offset != 8 {
+		t.Errorf("MatchTarget = (%q, %d), want (naïve.txt, 8)", target, offset)
+	}
+	target, offset = MatchTarget("dir/file.go", "file.go", false)
+	if target != "dir/file.go" || offset != 0 {
+		t.Errorf("MatchTarget = (%q, %d), want (dir/file.go, 0)", target, offset)
+	}
+}
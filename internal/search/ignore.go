@@ -0,0 +1,192 @@
+package search
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/LFroesch/scout/internal/logger"
+)
+
+// ignoreRule is one compiled line of a .gitignore-style file
+type ignoreRule struct {
+	re       *regexp.Regexp
+	negate   bool // "!pattern" re-includes what an earlier rule excluded
+	dirOnly  bool // "pattern/" only matches directories
+	anchored bool // Pattern had a slash: matched against the path relative to the file's dir
+	git      bool // From .gitignore or .git/info/exclude: only applies inside a git repository
+}
+
+// Ignorer holds the ignore rules in effect for one directory of a walk: that directory's
+// .gitignore and .ignore (plus .git/info/exclude at a repository root) chained onto its
+// parent's rules. A nil Ignorer has no rules. Ignorers are immutable, so children can
+// share a parent across goroutines.
+type Ignorer struct {
+	parent *Ignorer
+	dir    string       // Directory the rules are relative to
+	rules  []ignoreRule // Later rules win: info/exclude, then .gitignore, then .ignore
+	repo   bool         // dir is a git repository root
+	inRepo bool         // dir is at or below a git repository root
+}
+
+// IsIgnoreFile reports whether name is one of the files that changes the ignore rules of
+// the directory holding it. Lets callers that already have a listing skip Enter's reads.
+func IsIgnoreFile(name string) bool {
+	return name == ".gitignore" || name == ".ignore" || name == ".git"
+}
+
+// NewIgnorer returns the rules in effect for root, including ignore files in its ancestors
+// up to the enclosing git repository root (or the filesystem root outside a repository)
+func NewIgnorer(root string) *Ignorer {
+	root = filepath.Clean(root)
+	var chain []string
+	for dir := root; ; {
+		chain = append(chain, dir)
+		if isRepoRoot(dir) {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	var ig *Ignorer
+	for i := len(chain) - 1; i >= 0; i-- {
+		ig = ig.Enter(chain[i])
+	}
+	return ig
+}
+
+// Enter reads the ignore files in dir, a child of ig's directory, and returns the rules in
+// effect inside it. When dir has none of its own, ig itself is returned.
+func (ig *Ignorer) Enter(dir string) *Ignorer {
+	repo := isRepoRoot(dir)
+	var rules []ignoreRule
+	if repo {
+		rules = append(rules, readIgnoreFile(filepath.Join(dir, ".git", "info", "exclude"), true)...)
+	}
+	rules = append(rules, readIgnoreFile(filepath.Join(dir, ".gitignore"), true)...)
+	rules = append(rules, readIgnoreFile(filepath.Join(dir, ".ignore"), false)...)
+	if len(rules) == 0 && !repo {
+		return ig
+	}
+	return &Ignorer{
+		parent: ig,
+		dir:    dir,
+		rules:  rules,
+		repo:   repo,
+		inRepo: repo || (ig != nil && ig.inRepo),
+	}
+}
+
+// Ignored reports whether path (below ig's directory) is excluded by the rules in effect.
+// Deeper ignore files take precedence, and within a file the last matching line wins.
+// .gitignore rules stop at the repository root, so a nested repo isn't governed by its parent's.
+func (ig *Ignorer) Ignored(path string, isDir bool) bool {
+	if ig == nil {
+		return false
+	}
+	name := filepath.Base(path)
+	gitActive := ig.inRepo
+	for f := ig; f != nil; f = f.parent {
+		var rel string
+		for i := len(f.rules) - 1; i >= 0; i-- {
+			r := f.rules[i]
+			if (r.git && !gitActive) || (r.dirOnly && !isDir) {
+				continue
+			}
+			target := name
+			if r.anchored {
+				if rel == "" {
+					rel = filepath.ToSlash(strings.TrimPrefix(path, f.dir+string(filepath.Separator)))
+				}
+				target = rel
+			}
+			if r.re.MatchString(target) {
+				return !r.negate
+			}
+		}
+		if f.repo {
+			gitActive = false
+		}
+	}
+	return false
+}
+
+// isRepoRoot reports whether dir has a .git directory (or a .git file, for worktrees and submodules)
+func isRepoRoot(dir string) bool {
+	_, err := os.Lstat(filepath.Join(dir, ".git"))
+	return err == nil
+}
+
+// readIgnoreFile parses an ignore file, returning nil if it doesn't exist
+func readIgnoreFile(path string, git bool) []ignoreRule {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var rules []ignoreRule
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		rule, ok, err := parseIgnoreLine(scanner.Text())
+		if err != nil {
+			logger.Debug("Skipping bad pattern in %s: %v", path, err)
+			continue
+		}
+		if ok {
+			rule.git = git
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// parseIgnoreLine compiles one line of gitignore syntax. ok is false for blank lines and comments.
+func parseIgnoreLine(line string) (ignoreRule, bool, error) {
+	var rule ignoreRule
+	line = strings.TrimSuffix(line, "\r")
+
+	// Trailing spaces are dropped unless escaped with a backslash
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, `\ `) {
+		line = line[:len(line)-1]
+	}
+	if line == "" || strings.HasPrefix(line, "#") {
+		return rule, false, nil
+	}
+
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		line = line[1:]
+	}
+
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return rule, false, nil
+	}
+
+	// A slash at the start or in the middle anchors the pattern to the ignore file's directory
+	if strings.Contains(line, "/") {
+		rule.anchored = true
+		line = strings.TrimPrefix(line, "/")
+	}
+
+	expr, err := globToRegexp(line)
+	if err != nil {
+		return rule, false, err
+	}
+	rule.re, err = regexp.Compile(expr)
+	if err != nil {
+		return rule, false, err
+	}
+	return rule, true, nil
+}
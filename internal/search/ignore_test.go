@@ -0,0 +1,158 @@
+package search
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func TestParseIgnoreLine(t *testing.T) {
+	tests := []struct {
+		line   string
+		target string // Name, or path relative to the ignore file for anchored patterns
+		isDir  bool
+		want   bool
+	}{
+		{"*.log", "debug.log", false, true},
+		{"*.log", "debug.txt", false, false},
+		{"build/", "build", true, true},
+		{"build/", "build", false, false}, // Trailing slash: directories only
+		{"/gen", "gen", true, true},
+		{"/gen", "sub/gen", true, false}, // Leading slash anchors to the file's dir
+		{"docs/*.md", "docs/a.md", false, true},
+		{"docs/*.md", "docs/x/a.md", false, false},
+		{"**/cache", "a/b/cache", true, true},
+		{"**/cache", "cache", true, true},
+		{"out/**", "out/a/b", false, true},
+		{"a/**/z", "a/z", false, true},
+		{"a/**/z", "a/b/c/z", false, true},
+		{`\#notes`, "#notes", false, true},
+		{"trailing   ", "trailing", false, true},
+		{"File.TXT", "file.txt", false, false}, // Case-sensitive, like git
+	}
+	for _, tt := range tests {
+		rule, ok, err := parseIgnoreLine(tt.line)
+		if err != nil || !ok {
+			t.Errorf("%q: ok=%v err=%v", tt.line, ok, err)
+			continue
+		}
+		got := (!rule.dirOnly || tt.isDir) && rule.re.MatchString(tt.target)
+		if got != tt.want {
+			t.Errorf("%q against %q (dir=%v) = %v, want %v", tt.line, tt.target, tt.isDir, got, tt.want)
+		}
+	}
+
+	for _, line := range []string{"", "   ", "# comment", "/"} {
+		if _, ok, _ := parseIgnoreLine(line); ok {
+			t.Errorf("%q should not produce a rule", line)
+		}
+	}
+	if rule, _, _ := parseIgnoreLine("!keep.log"); !rule.negate {
+		t.Error("! should negate")
+	}
+	if rule, _, _ := parseIgnoreLine(`\!bang`); rule.negate || !rule.re.MatchString("!bang") {
+		t.Error(`\! should be a literal !`)
+	}
+}
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func recursiveNames(t *testing.T, dir string, filter *Query) []string {
+	t.Helper()
+	cancel := make(chan struct{})
+	defer close(cancel)
+	noIgnore := func(string) bool { return false }
+	results, _ := RecursiveSearchFiles("", dir, false, noIgnore, cancel, nil, nil, 5000, 10, 100000, nil, false, filter)
+	var names []string
+	for _, r := range results {
+		if !r.IsDir {
+			names = append(names, filepath.ToSlash(r.DisplayName))
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestRecursiveSearchHonoursIgnoreFiles(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".git/info/exclude": "secret.txt\n",
+		".gitignore":        "*.log\n!keep.log\n/gen/\n",
+		"app.go":            "",
+		"debug.log":         "",
+		"keep.log":          "",
+		"secret.txt":        "",
+		"gen/out.go":        "",
+		"sub/gen/src.go":    "", // /gen/ is anchored to the root
+		"sub/.gitignore":    "*.tmp\n",
+		"sub/a.tmp":         "",
+		"a.tmp":             "", // sub's rules don't reach the parent
+		"sub/.ignore":       "!b.tmp\n",
+		"sub/b.tmp":         "", // .ignore overrides .gitignore in the same dir
+	})
+
+	filter := ParseQuery("type:file")
+	got := recursiveNames(t, root, &filter)
+	want := []string{"a.tmp", "app.go", "keep.log", "sub/b.tmp", "sub/gen/src.go"}
+	if !equalStringSlices(got, want) {
+		t.Errorf("with ignore files: got %v, want %v", got, want)
+	}
+
+	filter.NoIgnore = true
+	got = recursiveNames(t, root, &filter)
+	if len(got) != 9 {
+		t.Errorf("NoIgnore should list all 9 visible files, got %v", got)
+	}
+}
+
+func TestGitignoreNeedsRepository(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".gitignore":      "a.txt\n",
+		".ignore":         "b.txt\n",
+		"a.txt":           "",
+		"b.txt":           "",
+		"c.txt":           "",
+		"repo/.git/HEAD":  "",
+		"repo/.gitignore": "",
+		"repo/a.txt":      "", // Parent .gitignore is outside this repo either way
+		"repo/b.txt":      "", // .ignore still applies from the parent
+		"repo/sub/c.txt":  "",
+	})
+
+	got := recursiveNames(t, root, nil)
+	want := []string{"a.txt", "c.txt", "repo/a.txt", "repo/sub/c.txt"}
+	if !equalStringSlices(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// Starting inside a subdirectory still picks up the repository's rules
+	writeFiles(t, root, map[string]string{"repo/.gitignore": "c.txt\n"})
+	if got := recursiveNames(t, filepath.Join(root, "repo", "sub"), nil); len(got) != 0 {
+		t.Errorf("ancestor .gitignore should apply from a subdirectory, got %v", got)
+	}
+}
+
+func equalStringSlices(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,293 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"unicode"
+)
+
+// MatchMode selects how filename searches compare the query against names
+type MatchMode int
+
+const (
+	MatchFuzzy     MatchMode = iota // fzf-style subsequence (default)
+	MatchSubstring                  // Contiguous text
+	MatchGlob                       // Shell glob: * ? [abc] and ** across directories
+	MatchRegex                      // Go regexp (RE2)
+)
+
+var matchModeNames = [...]string{"fuzzy", "substring", "glob", "regex"}
+
+func (mm MatchMode) String() string {
+	if mm < 0 || int(mm) >= len(matchModeNames) {
+		return "fuzzy"
+	}
+	return matchModeNames[mm]
+}
+
+// Next returns the mode after mm, wrapping around
+func (mm MatchMode) Next() MatchMode {
+	return (mm + 1) % MatchMode(len(matchModeNames))
+}
+
+// Matcher scores a single name or path. Positions are rune offsets into target.
+type Matcher interface {
+	Match(target string) (score int, positions []int, ok bool)
+}
+
+// matchAll accepts everything, for filter-only queries like "ext:go size:>1M"
+type matchAll struct{}
+
+func (matchAll) Match(string) (int, []int, bool) { return 0, nil, true }
+
+// NewMatcher compiles pattern for mode. An empty pattern matches everything.
+// All modes are smart-case: case-insensitive unless the pattern has an uppercase letter.
+func NewMatcher(mode MatchMode, pattern string) (Matcher, error) {
+	if pattern == "" {
+		return matchAll{}, nil
+	}
+	switch mode {
+	case MatchSubstring:
+		return newSubstringMatcher(pattern), nil
+	case MatchGlob:
+		return newGlobMatcher(pattern)
+	case MatchRegex:
+		re, err := compileSmartCase(pattern)
+		if err != nil {
+			return nil, err
+		}
+		return &regexMatcher{re: re}, nil
+	}
+	return NewFuzzyMatcher(pattern), nil
+}
+
+// Matcher compiles pattern with the query's match mode (fuzzy for a nil query)
+func (q *Query) Matcher(pattern string) (Matcher, error) {
+	mode := MatchFuzzy
+	if q != nil {
+		mode = q.Mode
+	}
+	return NewMatcher(mode, pattern)
+}
+
+// hasUpper reports whether pattern has an uppercase letter outside escapes like \D or \S
+func hasUpper(pattern string) bool {
+	escaped := false
+	for _, r := range pattern {
+		if escaped {
+			escaped = false
+			continue
+		}
+		if r == '\\' {
+			escaped = true
+			continue
+		}
+		if unicode.IsUpper(r) {
+			return true
+		}
+	}
+	return false
+}
+
+func compileSmartCase(expr string) (*regexp.Regexp, error) {
+	flags := ""
+	if !hasUpper(expr) {
+		flags = "(?i)"
+	}
+	re, err := regexp.Compile(flags + expr)
+	if err != nil {
+		// Trim Go's "error parsing regexp: " prefix so the message fits in the header
+		var serr *syntax.Error
+		if errors.As(err, &serr) {
+			return nil, fmt.Errorf("%s: `%s`", serr.Code, serr.Expr)
+		}
+		return nil, err
+	}
+	return re, nil
+}
+
+type substringMatcher struct {
+	pattern []rune
+	fold    bool
+}
+
+func newSubstringMatcher(pattern string) *substringMatcher {
+	sm := &substringMatcher{pattern: []rune(pattern), fold: !hasUpper(pattern)}
+	if sm.fold {
+		for i, r := range sm.pattern {
+			sm.pattern[i] = unicode.ToLower(r)
+		}
+	}
+	return sm
+}
+
+func (sm *substringMatcher) Match(target string) (int, []int, bool) {
+	text := []rune(target)
+	n := len(sm.pattern)
+	for start := 0; start+n <= len(text); start++ {
+		j := 0
+		for ; j < n; j++ {
+			r := text[start+j]
+			if sm.fold {
+				r = unicode.ToLower(r)
+			}
+			if r != sm.pattern[j] {
+				break
+			}
+		}
+		if j == n {
+			positions := make([]int, n)
+			for k := range positions {
+				positions[k] = start + k
+			}
+			return 0, positions, true
+		}
+	}
+	return 0, nil, false
+}
+
+// regexMatcher highlights the capture groups when the pattern has any, otherwise the whole match
+type regexMatcher struct {
+	re         *regexp.Regexp
+	groupsOnly bool // Never fall back to the whole match (globs: only literal runs are groups)
+}
+
+func (rm *regexMatcher) Match(target string) (int, []int, bool) {
+	loc := rm.re.FindStringSubmatchIndex(target)
+	if loc == nil {
+		return 0, nil, false
+	}
+	spans := loc[:2]
+	if len(loc) > 2 {
+		spans = loc[2:]
+	} else if rm.groupsOnly {
+		spans = nil
+	}
+	return 0, byteSpansToRunes(target, spans), true
+}
+
+// byteSpansToRunes turns [start, end) byte pairs (unset groups are -1) into rune positions
+func byteSpansToRunes(target string, spans []int) []int {
+	var positions []int
+	runeIdx := 0
+	for byteIdx := range target {
+		for i := 0; i+1 < len(spans); i += 2 {
+			if spans[i] >= 0 && byteIdx >= spans[i] && byteIdx < spans[i+1] {
+				positions = append(positions, runeIdx)
+				break
+			}
+		}
+		runeIdx++
+	}
+	return positions
+}
+
+// globMatcher matches a glob against the whole relative path when the pattern contains a
+// slash, and against the last path element otherwise (so "*_test.go" works recursively)
+type globMatcher struct {
+	re       regexMatcher
+	fullPath bool
+}
+
+func newGlobMatcher(pattern string) (*globMatcher, error) {
+	expr, err := globToRegexp(pattern)
+	if err != nil {
+		return nil, err
+	}
+	re, err := compileSmartCase(expr)
+	if err != nil {
+		return nil, err
+	}
+	return &globMatcher{re: regexMatcher{re: re, groupsOnly: true}, fullPath: strings.Contains(pattern, "/")}, nil
+}
+
+func (gm *globMatcher) Match(target string) (int, []int, bool) {
+	target = filepath.ToSlash(target)
+	offset := 0
+	if !gm.fullPath {
+		if slash := strings.LastIndexByte(target, '/'); slash >= 0 {
+			offset = len([]rune(target[:slash+1]))
+			target = target[slash+1:]
+		}
+	}
+	score, positions, ok := gm.re.Match(target)
+	for i := range positions {
+		positions[i] += offset
+	}
+	return score, positions, ok
+}
+
+// globToRegexp translates a glob into an anchored regexp. Literal runs become capture
+// groups so the matched text can be highlighted; wildcards don't.
+func globToRegexp(glob string) (string, error) {
+	var b, lit strings.Builder
+	flush := func() {
+		if lit.Len() > 0 {
+			b.WriteString("(" + regexp.QuoteMeta(lit.String()) + ")")
+			lit.Reset()
+		}
+	}
+
+	b.WriteString("^")
+	rs := []rune(glob)
+	for i := 0; i < len(rs); i++ {
+		switch c := rs[i]; c {
+		case '*':
+			flush()
+			if i+1 < len(rs) && rs[i+1] == '*' {
+				i++
+				if i+1 < len(rs) && rs[i+1] == '/' {
+					i++
+					b.WriteString("(?:.*/)?") // "**/" also matches zero directories
+				} else {
+					b.WriteString(".*")
+				}
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			flush()
+			b.WriteString("[^/]")
+		case '[':
+			flush()
+			j := i + 1
+			negate := j < len(rs) && (rs[j] == '!' || rs[j] == '^')
+			if negate {
+				j++
+			}
+			classStart := j
+			if j < len(rs) && rs[j] == ']' {
+				j++ // A leading ] is literal
+			}
+			for j < len(rs) && rs[j] != ']' {
+				j++
+			}
+			if j >= len(rs) {
+				return "", fmt.Errorf("unclosed [ in glob")
+			}
+			class := strings.ReplaceAll(string(rs[classStart:j]), `\`, `\\`)
+			if negate {
+				b.WriteString("[^/" + class + "]")
+			} else {
+				b.WriteString("[" + class + "]")
+			}
+			i = j
+		case '\\':
+			if i+1 < len(rs) {
+				i++
+				lit.WriteRune(rs[i])
+			} else {
+				lit.WriteRune(c)
+			}
+		default:
+			lit.WriteRune(c)
+		}
+	}
+	flush()
+	b.WriteString("$")
+	return b.String(), nil
+}
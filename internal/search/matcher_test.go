@@ -0,0 +1,88 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewMatcherModes(t *testing.T) {
+	tests := []struct {
+		mode    MatchMode
+		pattern string
+		target  string
+		want    bool
+	}{
+		{MatchSubstring, "del", "model.go", true},
+		{MatchSubstring, "mdl", "model.go", false}, // Not contiguous
+		{MatchSubstring, "MODEL", "model.go", false},
+		{MatchGlob, "*_test.go", "internal/search/fuzzy_test.go", true}, // No slash: matches the name
+		{MatchGlob, "*_test.go", "fuzzy.go", false},
+		{MatchGlob, "**/*_test.go", "a/b/c_test.go", true},
+		{MatchGlob, "**/*_test.go", "c_test.go", true}, // ** also matches zero dirs
+		{MatchGlob, "internal/*.go", "internal/search/x.go", false},
+		{MatchGlob, "file[0-9].txt", "file7.txt", true},
+		{MatchGlob, "file[!0-9].txt", "file7.txt", false},
+		{MatchGlob, "?.go", "ab.go", false},
+		{MatchRegex, `^mod.*\.go$`, "model.go", true},
+		{MatchRegex, `\d{3}`, "log_2026.txt", true},
+		{MatchRegex, "Model", "model.go", false}, // Smart-case
+	}
+	for _, tt := range tests {
+		matcher, err := NewMatcher(tt.mode, tt.pattern)
+		if err != nil {
+			t.Errorf("%s %q: unexpected error %v", tt.mode, tt.pattern, err)
+			continue
+		}
+		if _, _, ok := matcher.Match(tt.target); ok != tt.want {
+			t.Errorf("%s %q against %q = %v, want %v", tt.mode, tt.pattern, tt.target, ok, tt.want)
+		}
+	}
+}
+
+func TestMatcherHighlightPositions(t *testing.T) {
+	tests := []struct {
+		mode    MatchMode
+		pattern string
+		target  string
+		want    []int
+	}{
+		{MatchSubstring, "del", "model.go", []int{2, 3, 4}},
+		{MatchRegex, `o.e`, "model.go", []int{1, 2, 3}},
+		// With groups only the submatches are highlighted
+		{MatchRegex, `(m)o(de)`, "model.go", []int{0, 2, 3}},
+		// Glob highlights the literal runs, offset to the name within the path
+		{MatchGlob, "*_test.go", "dir/x_test.go", []int{5, 6, 7, 8, 9, 10, 11, 12}},
+		// Rune offsets, not bytes
+		{MatchRegex, "ve", "naïve", []int{3, 4}},
+	}
+	for _, tt := range tests {
+		matcher, err := NewMatcher(tt.mode, tt.pattern)
+		if err != nil {
+			t.Fatalf("%s %q: %v", tt.mode, tt.pattern, err)
+		}
+		_, positions, ok := matcher.Match(tt.target)
+		if !ok {
+			t.Errorf("%s %q should match %q", tt.mode, tt.pattern, tt.target)
+			continue
+		}
+		if !reflect.DeepEqual(positions, tt.want) {
+			t.Errorf("%s %q on %q: positions = %v, want %v", tt.mode, tt.pattern, tt.target, positions, tt.want)
+		}
+	}
+}
+
+func TestNewMatcherInvalidPatterns(t *testing.T) {
+	if _, err := NewMatcher(MatchRegex, "foo("); err == nil {
+		t.Error("expected error for unbalanced regex")
+	}
+	if _, err := NewMatcher(MatchGlob, "file[0-9"); err == nil {
+		t.Error("expected error for unclosed glob class")
+	}
+	matcher, err := NewMatcher(MatchRegex, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, ok := matcher.Match("anything"); !ok {
+		t.Error("empty pattern should match everything")
+	}
+}
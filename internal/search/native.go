@@ -0,0 +1,243 @@
+package search
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/LFroesch/scout/internal/logger"
+	xansi "github.com/charmbracelet/x/ansi"
+)
+
+const (
+	nativeMaxFileSize   = 1024 * 1024      // Match ripgrep's --max-filesize=1M
+	nativeBinarySniff   = 8192             // Bytes inspected for NUL when detecting binary files
+	nativeSearchTimeout = 30 * time.Second // Same hard timeout as the ripgrep path
+)
+
+// compileContentQuery treats the query as a regex (like ripgrep), falling back to a
+// literal match when it isn't valid Go regexp syntax
+func compileContentQuery(query string) *regexp.Regexp {
+	if re, err := regexp.Compile(query); err == nil {
+		return re
+	}
+	return regexp.MustCompile(regexp.QuoteMeta(query))
+}
+
+// SearchFileContentNative is the pure-Go content searcher used when ripgrep isn't installed.
+// It mirrors SearchFileContent: same cancellation, streaming via onResult, maxResults cap,
+// ripgrep-style maxDepth (files directly in currentDir are depth 1), skip-dir patterns,
+// hidden-file handling, .gitignore/.ignore rules, binary-file skipping, the 1MB size cap
+// and query filters.
+// Files are read by a bounded pool of workers fed from a single filepath.WalkDir.
+func SearchFileContentNative(query, currentDir string, showHidden bool, cancelChan <-chan struct{}, onResult func(Result), maxResults, maxDepth int, customSkipDirs []string, filter *Query) ([]Result, error) {
+	logger.Info("Starting native content search in %s for query '%s'", currentDir, query)
+	startTime := time.Now()
+
+	re := compileContentQuery(query)
+
+	// stop is closed once on cancel, timeout or hitting maxResults; workers and the walker watch it
+	stop := make(chan struct{})
+	var stopOnce sync.Once
+	halt := func() { stopOnce.Do(func() { close(stop) }) }
+	stopped := func() bool {
+		select {
+		case <-stop:
+			return true
+		default:
+			return false
+		}
+	}
+
+	go func() {
+		select {
+		case <-cancelChan:
+			logger.Info("Native content search cancelled during execution")
+			halt()
+		case <-time.After(nativeSearchTimeout):
+			logger.Warn("Native content search timed out after %v", nativeSearchTimeout)
+			halt()
+		case <-stop:
+		}
+	}()
+
+	dirPrefix := currentDir
+	if !strings.HasSuffix(dirPrefix, string(filepath.Separator)) {
+		dirPrefix += string(filepath.Separator)
+	}
+
+	var (
+		mu      sync.Mutex
+		results []Result
+	)
+	// emit records a result and reports whether the worker should keep going
+	emit := func(r Result) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		if len(results) >= maxResults || stopped() {
+			return false
+		}
+		results = append(results, r)
+		if onResult != nil {
+			onResult(r)
+		}
+		if len(results) >= maxResults {
+			logger.Warn("Hit max results limit (%d)", maxResults)
+			halt()
+			return false
+		}
+		return true
+	}
+
+	paths := make(chan string, 256)
+	workers := runtime.NumCPU()
+	if workers > 8 {
+		workers = 8
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			buf := make([]byte, nativeMaxFileSize)
+			for path := range paths {
+				if stopped() {
+					continue // drain so the walker never blocks
+				}
+				relPath := strings.TrimPrefix(path, dirPrefix)
+				grepFile(path, relPath, re, buf, emit, stopped)
+			}
+		}()
+	}
+
+	var ignorers map[string]*Ignorer
+	if filter.RespectsIgnore() {
+		ignorers = map[string]*Ignorer{filepath.Clean(currentDir): NewIgnorer(currentDir)}
+	}
+
+	filesQueued := 0
+	filepath.WalkDir(currentDir, func(path string, d fs.DirEntry, err error) error {
+		if stopped() {
+			return filepath.SkipAll
+		}
+		if err != nil {
+			return nil // permission errors etc. — ripgrep skips these too
+		}
+
+		relPath := strings.TrimPrefix(path, dirPrefix)
+		if relPath == path {
+			return nil // root entry
+		}
+
+		if !showHidden && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		var ig *Ignorer
+		if ignorers != nil {
+			ig = ignorers[filepath.Dir(path)]
+		}
+		depth := strings.Count(relPath, string(filepath.Separator)) + 1
+		if d.IsDir() {
+			if ShouldSkipDir(path, d.Name(), customSkipDirs) || depth >= maxDepth || ig.Ignored(path, true) {
+				return filepath.SkipDir
+			}
+			if ignorers != nil {
+				ignorers[path] = ig.Enter(path)
+			}
+			return nil
+		}
+		if depth > maxDepth || !d.Type().IsRegular() || ig.Ignored(path, false) {
+			return nil
+		}
+		if !filter.MatchesName(d.Name(), false) {
+			return nil
+		}
+		if filter.NeedsStat() {
+			info, err := d.Info()
+			if err != nil || !filter.Matches(d.Name(), false, info.Size(), info.ModTime()) {
+				return nil
+			}
+		}
+
+		select {
+		case paths <- path:
+			filesQueued++
+		case <-stop:
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	close(paths)
+	wg.Wait()
+	halt()
+
+	logger.Info("Native content search complete: %d results from %d files in %v", len(results), filesQueued, time.Since(startTime))
+	return results, nil
+}
+
+// grepFile scans one file line by line, emitting a Result per matching line.
+// Files over the size cap and files that look binary are skipped.
+func grepFile(path, relPath string, re *regexp.Regexp, buf []byte, emit func(Result) bool, stopped func() bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil || info.Size() > nativeMaxFileSize {
+		return
+	}
+
+	n, err := io.ReadFull(f, buf[:info.Size()])
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return
+	}
+	data := buf[:n]
+
+	sniff := data
+	if len(sniff) > nativeBinarySniff {
+		sniff = sniff[:nativeBinarySniff]
+	}
+	if bytes.IndexByte(sniff, 0) != -1 {
+		return
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), nativeMaxFileSize)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Bytes()
+		if !re.Match(line) {
+			continue
+		}
+		if stopped() {
+			return
+		}
+
+		displayName := fmt.Sprintf("%s:%d - %s", relPath, lineNum, strings.TrimSpace(string(line)))
+		displayName = xansi.Truncate(displayName, 100, "...")
+		if !emit(Result{
+			Path:        path,
+			DisplayName: displayName,
+			IsDir:       false,
+			LineNumber:  lineNum,
+		}) {
+			return
+		}
+	}
+}
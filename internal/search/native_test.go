@@ -0,0 +1,123 @@
+package search
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSearchFileContentNative(t *testing.T) {
+	tempDir := t.TempDir()
+
+	os.WriteFile(filepath.Join(tempDir, "a.txt"), []byte("first line\nneedle here\nlast"), 0644)
+	os.WriteFile(filepath.Join(tempDir, ".hidden.txt"), []byte("needle hidden"), 0644)
+	os.WriteFile(filepath.Join(tempDir, "bin.dat"), []byte("needle\x00binary"), 0644)
+	os.WriteFile(filepath.Join(tempDir, "big.txt"), []byte(strings.Repeat("needle\n", 200000)), 0644) // > 1MB
+	os.MkdirAll(filepath.Join(tempDir, "node_modules"), 0755)
+	os.WriteFile(filepath.Join(tempDir, "node_modules", "dep.js"), []byte("needle"), 0644)
+	os.MkdirAll(filepath.Join(tempDir, "sub", "deeper"), 0755)
+	os.WriteFile(filepath.Join(tempDir, "sub", "b.go"), []byte("// needle"), 0644)
+	os.WriteFile(filepath.Join(tempDir, "sub", "deeper", "c.go"), []byte("// needle"), 0644)
+
+	cancelChan := make(chan struct{})
+	defer close(cancelChan)
+
+	var streamed int
+	results, err := SearchFileContentNative("needle", tempDir, false, cancelChan, func(Result) { streamed++ }, 5000, 2, []string{"node_modules"}, nil)
+	if err != nil {
+		t.Fatalf("SearchFileContentNative failed: %v", err)
+	}
+
+	got := map[string]int{}
+	for _, r := range results {
+		rel, _ := filepath.Rel(tempDir, r.Path)
+		got[rel] = r.LineNumber
+	}
+
+	if got["a.txt"] != 2 {
+		t.Errorf("expected match on line 2 of a.txt, got %v", got)
+	}
+	if _, ok := got[filepath.Join("sub", "b.go")]; !ok {
+		t.Errorf("expected match in sub/b.go (depth 2), got %v", got)
+	}
+	for _, excluded := range []string{".hidden.txt", "bin.dat", "big.txt", filepath.Join("node_modules", "dep.js"), filepath.Join("sub", "deeper", "c.go")} {
+		if _, ok := got[excluded]; ok {
+			t.Errorf("expected %s to be excluded", excluded)
+		}
+	}
+	if streamed != len(results) {
+		t.Errorf("onResult called %d times for %d results", streamed, len(results))
+	}
+}
+
+func TestSearchFileContentNativeMaxResults(t *testing.T) {
+	tempDir := t.TempDir()
+	for i := 0; i < 20; i++ {
+		os.WriteFile(filepath.Join(tempDir, "f"+string(rune('a'+i))+".txt"), []byte("hit\nhit\nhit"), 0644)
+	}
+
+	cancelChan := make(chan struct{})
+	defer close(cancelChan)
+
+	results, _ := SearchFileContentNative("hit", tempDir, false, cancelChan, nil, 7, 5, nil, nil)
+	if len(results) != 7 {
+		t.Errorf("expected results capped at 7, got %d", len(results))
+	}
+}
+
+func TestSearchFileContentNativeTruncatesOnRuneBoundary(t *testing.T) {
+	tempDir := t.TempDir()
+	os.WriteFile(filepath.Join(tempDir, "ab.txt"), []byte("needle "+strings.Repeat("é", 200)), 0644)
+
+	cancelChan := make(chan struct{})
+	defer close(cancelChan)
+
+	results, _ := SearchFileContentNative("needle", tempDir, false, cancelChan, nil, 100, 5, nil, nil)
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(results))
+	}
+	name := results[0].DisplayName
+	if !utf8.ValidString(name) || !strings.HasSuffix(name, "...") || utf8.RuneCountInString(name) > 100 {
+		t.Errorf("bad truncation %q", name)
+	}
+}
+
+func TestSearchFileContentNativeInvalidRegexFallsBackToLiteral(t *testing.T) {
+	tempDir := t.TempDir()
+	os.WriteFile(filepath.Join(tempDir, "a.txt"), []byte("call foo(bar\n"), 0644)
+
+	cancelChan := make(chan struct{})
+	defer close(cancelChan)
+
+	results, _ := SearchFileContentNative("foo(bar", tempDir, false, cancelChan, nil, 100, 5, nil, nil)
+	if len(results) != 1 {
+		t.Errorf("expected literal match for invalid regex, got %d results", len(results))
+	}
+}
+
+func TestSearchFileContentNativeHonoursIgnoreFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	writeFiles(t, tempDir, map[string]string{
+		".ignore":        "generated/\n*.min.js\n",
+		"app.js":         "needle",
+		"app.min.js":     "needle",
+		"generated/x.js": "needle",
+	})
+
+	cancelChan := make(chan struct{})
+	defer close(cancelChan)
+
+	results, _ := SearchFileContentNative("needle", tempDir, false, cancelChan, nil, 5000, 5, nil, nil)
+	if len(results) != 1 || filepath.Base(results[0].Path) != "app.js" {
+		t.Errorf("expected only app.js, got %v", results)
+	}
+
+	filter := ParseQuery("")
+	filter.NoIgnore = true
+	results, _ = SearchFileContentNative("needle", tempDir, false, cancelChan, nil, 5000, 5, nil, &filter)
+	if len(results) != 3 {
+		t.Errorf("NoIgnore should search all 3 files, got %d", len(results))
+	}
+}
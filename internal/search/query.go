@@ -0,0 +1,285 @@
+package search
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Query is a parsed search input: free text for the matcher plus optional filters.
+//
+//	ext:go,md     extension is one of go or md
+//	size:>10M     bigger than 10 MiB (also <, >=, <=; units B K M G T)
+//	mtime:<7d     modified less than 7 days ago (> for older; units s m h d w y)
+//	type:dir      only directories (type:file for files)
+//
+// Anything else is free text. Filter tokens with a bad value are reported in Errors
+// and otherwise ignored.
+type Query struct {
+	Text      string
+	Exts      []string  // Lowercase, without the leading dot, each once
+	MinSize   int64     // -1 when unset
+	MaxSize   int64     // -1 when unset
+	NewerThan time.Time // Zero when unset
+	OlderThan time.Time // Zero when unset
+	Type      string    // "", "file" or "dir"
+	Chips     []string  // Display labels for the active filters, in input order
+	Errors    []string  // Filter tokens that couldn't be parsed
+	Mode      MatchMode // How Text is matched against names; set by the caller, not parsed
+	NoIgnore  bool      // Include files excluded by .gitignore/.ignore; set by the caller, not parsed
+}
+
+// ParseQuery splits input into free text and filters, resolving ages against now
+func ParseQuery(input string) Query {
+	return parseQueryAt(input, time.Now())
+}
+
+func parseQueryAt(input string, now time.Time) Query {
+	q := Query{MinSize: -1, MaxSize: -1}
+	var text []string
+	extChip := -1 // Index of the one ext chip, which every ext: token adds to
+
+	for _, tok := range strings.Fields(input) {
+		key, val, ok := strings.Cut(tok, ":")
+		if !ok || val == "" {
+			text = append(text, tok)
+			continue
+		}
+
+		var err error
+		switch strings.ToLower(key) {
+		case "ext":
+			for _, e := range strings.Split(val, ",") {
+				e = strings.ToLower(strings.TrimPrefix(e, "."))
+				if e != "" && !slices.Contains(q.Exts, e) {
+					q.Exts = append(q.Exts, e)
+				}
+			}
+			if len(q.Exts) == 0 {
+				continue
+			}
+			if extChip < 0 {
+				extChip = len(q.Chips)
+				q.Chips = append(q.Chips, "")
+			}
+			q.Chips[extChip] = "ext:" + strings.Join(q.Exts, ",")
+		case "size":
+			err = q.parseSize(val)
+		case "mtime":
+			err = q.parseMtime(val, now)
+		case "type":
+			switch strings.ToLower(val) {
+			case "d", "dir", "directory", "folder":
+				q.Type = "dir"
+			case "f", "file":
+				q.Type = "file"
+			default:
+				err = fmt.Errorf("type must be file or dir")
+			}
+			if err == nil {
+				q.Chips = append(q.Chips, "type:"+q.Type)
+			}
+		default:
+			text = append(text, tok) // Not a filter key, e.g. "http://..." in a content search
+			continue
+		}
+		if err != nil {
+			q.Errors = append(q.Errors, fmt.Sprintf("%s (%v)", tok, err))
+		}
+	}
+
+	q.Text = strings.Join(text, " ")
+	return q
+}
+
+// splitOp peels a comparison operator off a filter value; a bare value means ">="
+func splitOp(val string) (string, string) {
+	for _, op := range []string{">=", "<=", ">", "<"} {
+		if strings.HasPrefix(val, op) {
+			return op, val[len(op):]
+		}
+	}
+	return ">=", val
+}
+
+func (q *Query) parseSize(val string) error {
+	op, num := splitOp(val)
+	size, err := parseSizeValue(num)
+	if err != nil {
+		return err
+	}
+	switch op {
+	case ">":
+		q.MinSize = size + 1
+	case ">=":
+		q.MinSize = size
+	case "<":
+		q.MaxSize = size - 1
+	case "<=":
+		q.MaxSize = size
+	}
+	q.Chips = append(q.Chips, "size"+op+num)
+	return nil
+}
+
+// parseSizeValue parses "10M", "512k", "1.5GB" (binary units) into bytes
+func parseSizeValue(s string) (int64, error) {
+	upper := strings.ToUpper(s)
+	upper = strings.TrimSuffix(upper, "IB")
+	upper = strings.TrimSuffix(upper, "B")
+	mult := int64(1)
+	if n := len(upper); n > 0 {
+		switch upper[n-1] {
+		case 'K':
+			mult = 1 << 10
+		case 'M':
+			mult = 1 << 20
+		case 'G':
+			mult = 1 << 30
+		case 'T':
+			mult = 1 << 40
+		}
+		if mult > 1 {
+			upper = upper[:n-1]
+		}
+	}
+	f, err := strconv.ParseFloat(upper, 64)
+	// !(f >= 0) also catches NaN; the upper bound keeps the int64 conversion from overflowing
+	if err != nil || !(f >= 0) || f >= float64(math.MaxInt64/mult) {
+		return 0, fmt.Errorf("bad size %q", s)
+	}
+	return int64(f * float64(mult)), nil
+}
+
+func (q *Query) parseMtime(val string, now time.Time) error {
+	op, num := splitOp(val)
+	if op == ">=" && !strings.HasPrefix(val, ">=") {
+		op = "<" // A bare age reads as "within", e.g. mtime:7d
+	}
+	age, err := parseAge(num)
+	if err != nil {
+		return err
+	}
+	switch op {
+	case "<", "<=":
+		q.NewerThan = now.Add(-age)
+	case ">", ">=":
+		q.OlderThan = now.Add(-age)
+	}
+	q.Chips = append(q.Chips, "mtime"+op+num)
+	return nil
+}
+
+// parseAge parses "30s", "15m", "2h", "7d", "2w", "1y"
+func parseAge(s string) (time.Duration, error) {
+	if len(s) < 2 {
+		return 0, fmt.Errorf("bad age %q", s)
+	}
+	n, err := strconv.ParseFloat(s[:len(s)-1], 64)
+	if err != nil || !(n >= 0) {
+		return 0, fmt.Errorf("bad age %q", s)
+	}
+	var unit time.Duration
+	switch s[len(s)-1] {
+	case 's':
+		unit = time.Second
+	case 'm':
+		unit = time.Minute
+	case 'h':
+		unit = time.Hour
+	case 'd':
+		unit = 24 * time.Hour
+	case 'w':
+		unit = 7 * 24 * time.Hour
+	case 'y':
+		unit = 365 * 24 * time.Hour
+	default:
+		return 0, fmt.Errorf("bad age unit in %q", s)
+	}
+	if n >= float64(math.MaxInt64/unit) {
+		return 0, fmt.Errorf("bad age %q", s)
+	}
+	return time.Duration(n * float64(unit)), nil
+}
+
+// HasFilters reports whether any filter predicate is active
+func (q *Query) HasFilters() bool {
+	return q != nil && (len(q.Exts) > 0 || q.MinSize >= 0 || q.MaxSize >= 0 ||
+		!q.NewerThan.IsZero() || !q.OlderThan.IsZero() || q.Type != "")
+}
+
+// NeedsStat reports whether the filters look at size or mtime (so callers must stat
+// every candidate rather than only text matches)
+func (q *Query) NeedsStat() bool {
+	return q != nil && (q.MinSize >= 0 || q.MaxSize >= 0 || !q.NewerThan.IsZero() || !q.OlderThan.IsZero())
+}
+
+// RespectsIgnore reports whether .gitignore/.ignore rules apply (the default, including for a nil query)
+func (q *Query) RespectsIgnore() bool {
+	return q == nil || !q.NoIgnore
+}
+
+// MatchesName checks the filters that only need the name and kind (ext, type)
+func (q *Query) MatchesName(name string, isDir bool) bool {
+	if q == nil {
+		return true
+	}
+	switch q.Type {
+	case "dir":
+		if !isDir {
+			return false
+		}
+	case "file":
+		if isDir {
+			return false
+		}
+	}
+	if len(q.Exts) > 0 {
+		if isDir {
+			return false
+		}
+		dot := strings.LastIndexByte(name, '.')
+		if dot == -1 {
+			return false
+		}
+		ext := strings.ToLower(name[dot+1:])
+		found := false
+		for _, e := range q.Exts {
+			if e == ext {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Matches checks every filter against a result's name, kind, size and mtime.
+// Size filters only apply to files.
+func (q *Query) Matches(name string, isDir bool, size int64, modTime time.Time) bool {
+	if q == nil {
+		return true
+	}
+	if !q.MatchesName(name, isDir) {
+		return false
+	}
+	if q.MinSize >= 0 && (isDir || size < q.MinSize) {
+		return false
+	}
+	if q.MaxSize >= 0 && (isDir || size > q.MaxSize) {
+		return false
+	}
+	if !q.NewerThan.IsZero() && modTime.Before(q.NewerThan) {
+		return false
+	}
+	if !q.OlderThan.IsZero() && modTime.After(q.OlderThan) {
+		return false
+	}
+	return true
+}
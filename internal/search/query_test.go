@@ -0,0 +1,128 @@
+package search
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseQuery(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	q := parseQueryAt("main ext:go,.MD size:>10M mtime:<7d type:file", now)
+
+	if q.Text != "main" {
+		t.Errorf("Text = %q, want main", q.Text)
+	}
+	if want := []string{"go", "md"}; !reflect.DeepEqual(q.Exts, want) {
+		t.Errorf("Exts = %v, want %v", q.Exts, want)
+	}
+	if q.MinSize != 10<<20+1 || q.MaxSize != -1 {
+		t.Errorf("size = [%d, %d], want [%d, -1]", q.MinSize, q.MaxSize, 10<<20+1)
+	}
+	if want := now.Add(-7 * 24 * time.Hour); !q.NewerThan.Equal(want) || !q.OlderThan.IsZero() {
+		t.Errorf("mtime = newer %v older %v, want newer %v", q.NewerThan, q.OlderThan, want)
+	}
+	if q.Type != "file" {
+		t.Errorf("Type = %q, want file", q.Type)
+	}
+	if want := []string{"ext:go,md", "size>10M", "mtime<7d", "type:file"}; !reflect.DeepEqual(q.Chips, want) {
+		t.Errorf("Chips = %v, want %v", q.Chips, want)
+	}
+	if len(q.Errors) != 0 {
+		t.Errorf("unexpected errors %v", q.Errors)
+	}
+}
+
+func TestParseQueryMergesExtensions(t *testing.T) {
+	q := ParseQuery("ext:.GO,go ext:md,.go ext:, type:dir")
+	if want := []string{"go", "md"}; !reflect.DeepEqual(q.Exts, want) {
+		t.Errorf("Exts = %v, want %v", q.Exts, want)
+	}
+	if want := []string{"ext:go,md", "type:dir"}; !reflect.DeepEqual(q.Chips, want) {
+		t.Errorf("Chips = %v, want %v", q.Chips, want)
+	}
+}
+
+func TestParseQueryKeepsUnknownAndReportsBadFilters(t *testing.T) {
+	q := ParseQuery("http://host size:lots type:pipe mtime:3q")
+	if q.Text != "http://host" {
+		t.Errorf("Text = %q, want unknown keys kept as text", q.Text)
+	}
+	if len(q.Errors) != 3 {
+		t.Errorf("expected 3 errors, got %v", q.Errors)
+	}
+	if q.HasFilters() {
+		t.Error("bad filters must not become active")
+	}
+
+	// Values ParseFloat accepts but that aren't a usable size or age
+	for _, input := range []string{"size:>nan", "size:<inf", "size:>1e30", "size:<9000000T", "mtime:<nand", "mtime:>infy", "mtime:<1e12y"} {
+		if q := ParseQuery(input); len(q.Errors) != 1 || q.HasFilters() {
+			t.Errorf("%s: errors %v, filters active %v", input, q.Errors, q.HasFilters())
+		}
+	}
+}
+
+func TestQueryMatches(t *testing.T) {
+	now := time.Now()
+	q := ParseQuery("size:<=1K mtime:>1d ext:log")
+	old := now.Add(-48 * time.Hour)
+
+	tests := []struct {
+		name    string
+		isDir   bool
+		size    int64
+		modTime time.Time
+		want    bool
+	}{
+		{"app.log", false, 1024, old, true},
+		{"app.LOG", false, 10, old, true},
+		{"app.log", false, 1025, old, false}, // Too big
+		{"app.log", false, 10, now, false},   // Too new
+		{"app.txt", false, 10, old, false},   // Wrong extension
+		{"logs.log", true, 0, old, false},    // Directories have no extension
+	}
+	for _, tt := range tests {
+		if got := q.Matches(tt.name, tt.isDir, tt.size, tt.modTime); got != tt.want {
+			t.Errorf("Matches(%q, dir=%v, %d, %v) = %v, want %v", tt.name, tt.isDir, tt.size, tt.modTime, got, tt.want)
+		}
+	}
+
+	var none *Query
+	if !none.Matches("anything", false, 0, now) {
+		t.Error("nil query should match everything")
+	}
+}
+
+func TestRecursiveSearchFilesWithFilter(t *testing.T) {
+	tempDir := t.TempDir()
+	os.WriteFile(filepath.Join(tempDir, "small.go"), []byte("x"), 0644)
+	os.WriteFile(filepath.Join(tempDir, "big.go"), make([]byte, 4096), 0644)
+	os.WriteFile(filepath.Join(tempDir, "big.txt"), make([]byte, 4096), 0644)
+	os.Mkdir(filepath.Join(tempDir, "pkg"), 0755)
+	os.WriteFile(filepath.Join(tempDir, "pkg", "huge.go"), make([]byte, 8192), 0644)
+
+	cancelChan := make(chan struct{})
+	defer close(cancelChan)
+
+	// Filter-only query: no text, matches everything that passes the filters
+	q := ParseQuery("ext:go size:>1K")
+	results, _ := RecursiveSearchFiles(q.Text, tempDir, false, func(string) bool { return false }, cancelChan, nil, nil, 100, 5, 1000, nil, false, &q)
+
+	got := map[string]bool{}
+	for _, r := range results {
+		got[r.DisplayName] = true
+	}
+	want := map[string]bool{"big.go": true, filepath.Join("pkg", "huge.go"): true}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("results = %v, want %v", got, want)
+	}
+
+	q = ParseQuery("type:dir")
+	results, _ = RecursiveSearchFiles(q.Text, tempDir, false, func(string) bool { return false }, cancelChan, nil, nil, 100, 5, 1000, nil, false, &q)
+	if len(results) != 1 || results[0].DisplayName != "pkg" {
+		t.Errorf("type:dir results = %+v, want only pkg", results)
+	}
+}
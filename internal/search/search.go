@@ -3,12 +3,17 @@ package search
 import (
 	"bufio"
 	"bytes"
+	"errors"
 	"fmt"
 	"io/fs"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+	"unicode/utf8"
 
 	"github.com/LFroesch/scout/internal/logger"
 )
@@ -23,12 +28,12 @@ type Result struct {
 	LineNumber  int // For content searches
 }
 
-// shouldSkipDir checks if a directory should be skipped based on the skip list.
+// ShouldSkipDir checks if a directory should be skipped based on the skip list.
 // Supports three pattern types:
 //   - Absolute paths: "/usr/bin" matches the exact full path
 //   - Wildcards: "Python*" matches any name starting with "Python"
 //   - Exact names: "node_modules" matches the directory name
-func shouldSkipDir(path, name string, skipDirs []string) bool {
+func ShouldSkipDir(path, name string, skipDirs []string) bool {
 	for _, pattern := range skipDirs {
 		if strings.HasPrefix(pattern, "/") {
 			// Absolute path — match against full path
@@ -57,10 +62,15 @@ func shouldSkipDir(path, name string, skipDirs []string) bool {
 	return false
 }
 
-// SearchFileContent searches for content within files using ripgrep
+// SearchFileContent searches for content within files using ripgrep,
+// falling back to the pure-Go searcher (SearchFileContentNative) when rg isn't installed
 // Limits configurable via maxResults, maxDepth parameters
 // onResult is called for each result as it's found (may be nil)
-func SearchFileContent(query, currentDir string, showHidden bool, cancelChan <-chan struct{}, onResult func(Result), maxResults, maxDepth int, customSkipDirs []string) ([]Result, error) {
+// filter (may be nil) restricts which files are searched by extension, size, mtime and type
+func SearchFileContent(query, currentDir string, showHidden bool, cancelChan <-chan struct{}, onResult func(Result), maxResults, maxDepth int, customSkipDirs []string, filter *Query) ([]Result, error) {
+	if query == "" {
+		return nil, nil // Filters alone don't make a content search
+	}
 	logger.Info("Starting content search in %s for query '%s'", currentDir, query)
 	startTime := time.Now()
 	// Try to find ripgrep binary
@@ -73,7 +83,8 @@ func SearchFileContent(query, currentDir string, showHidden bool, cancelChan <-c
 	}
 
 	if rgPath == "" {
-		return nil, fmt.Errorf("ripgrep not found - install with: sudo apt install ripgrep")
+		logger.Info("ripgrep not found, using native content search")
+		return SearchFileContentNative(query, currentDir, showHidden, cancelChan, onResult, maxResults, maxDepth, customSkipDirs, filter)
 	}
 
 	// Build ripgrep command with appropriate flags
@@ -92,7 +103,17 @@ func SearchFileContent(query, currentDir string, showHidden bool, cancelChan <-c
 		args = append(args, "--glob", "!"+pattern)
 	}
 
-	// Respect .gitignore and ignore common directories
+	// Extension filters become ripgrep globs; the rest is checked per file below
+	if filter != nil {
+		for _, ext := range filter.Exts {
+			args = append(args, "--iglob", "*."+ext)
+		}
+	}
+
+	// ripgrep honours .gitignore/.ignore by default; the include-ignored toggle turns that off
+	if !filter.RespectsIgnore() {
+		args = append(args, "--no-ignore")
+	}
 	if !showHidden {
 		args = append(args, "--no-hidden")
 	} else {
@@ -148,6 +169,8 @@ func SearchFileContent(query, currentDir string, showHidden bool, cancelChan <-c
 
 	// Parse results line-by-line as ripgrep outputs them
 	var results []Result
+	fileAllowed := make(map[string]bool) // filter verdict per file, so each file is stat'd once
+	checkFilter := filter.NeedsStat() || (filter != nil && filter.Type != "")
 	scanner := bufio.NewScanner(stdout)
 	scanner.Buffer(make([]byte, 1024*1024), 1024*1024)
 
@@ -165,6 +188,20 @@ func SearchFileContent(query, currentDir string, showHidden bool, cancelChan <-c
 			filePath := parts[0]
 			content := parts[3]
 
+			if checkFilter {
+				allowed, seen := fileAllowed[filePath]
+				if !seen {
+					allowed = false
+					if info, err := os.Stat(filePath); err == nil {
+						allowed = filter.Matches(info.Name(), info.IsDir(), info.Size(), info.ModTime())
+					}
+					fileAllowed[filePath] = allowed
+				}
+				if !allowed {
+					continue
+				}
+			}
+
 			relPath := filePath
 			if strings.HasPrefix(filePath, currentDir) {
 				if rel, err := filepath.Rel(currentDir, filePath); err == nil {
@@ -223,7 +260,8 @@ func SearchFileContent(query, currentDir string, showHidden bool, cancelChan <-c
 // MatchResult contains fuzzy match information
 type MatchResult struct {
 	Index          int
-	MatchedIndexes []int
+	MatchedIndexes []int // Rune positions of matched characters (for highlighting)
+	Score          int   // Fuzzy match score, higher is better (0 for substring matches)
 }
 
 // SubstringMatchNames performs case-insensitive substring matching on a list of names
@@ -254,151 +292,156 @@ func SubstringMatchNames(query string, names []string) []MatchResult {
 	return results
 }
 
-// RecursiveSearchFiles searches for files recursively using substring matching with streaming results
-// Limits configurable via maxResults, maxDepth, maxFilesScanned parameters
-// onResult is called for each matching file as it's found (may be nil)
+// MatchTarget picks the string a recursive search matches against: the whole relative
+// path, or just the name when nameOnly is set. The returned offset (in runes) translates
+// match positions in the target back to positions in relPath for highlighting.
+func MatchTarget(relPath, name string, nameOnly bool) (string, int) {
+	if !nameOnly {
+		return relPath, 0
+	}
+	return name, utf8.RuneCountInString(relPath) - utf8.RuneCountInString(name)
+}
+
+// RecursiveSearchFiles searches for files recursively using fuzzy matching with streaming results.
+// Directories are listed concurrently by a bounded pool of workers (see walkParallel), so
+// results arrive in no particular order; callers rank them.
+// Limits configurable via maxResults, maxDepth, maxFilesScanned parameters, enforced across workers
+// onResult is called for each matching file as it's found (may be nil); calls are serialized
+// onProgress (may be nil) may be called from several goroutines
 // customSkipDirs are user-configurable directories to skip (merged with hardcoded essentials)
-func RecursiveSearchFiles(query, currentDir string, showHidden bool, shouldIgnoreFn func(string) bool, cancelChan <-chan struct{}, onProgress func(scanned int), onResult func(Result, MatchResult), maxResults, maxDepth, maxFilesScanned int, customSkipDirs []string, nameOnly bool) ([]Result, []MatchResult) {
+// filter (may be nil) drops results failing its ext/size/mtime/type predicates; with filters,
+// an empty query matches every entry. Unless filter.NoIgnore is set, entries excluded by
+// .gitignore/.ignore files (and .git/info/exclude) are skipped as the walk reaches them.
+func RecursiveSearchFiles(query, currentDir string, showHidden bool, shouldIgnoreFn func(string) bool, cancelChan <-chan struct{}, onProgress func(scanned int), onResult func(Result, MatchResult), maxResults, maxDepth, maxFilesScanned int, customSkipDirs []string, nameOnly bool, filter *Query) ([]Result, []MatchResult) {
 
 	logger.Info("Starting recursive search in %s for query '%s'", currentDir, query)
 	startTime := time.Now()
 
-	lowerQuery := strings.ToLower(query)
+	matcher, err := filter.Matcher(query)
+	if err != nil {
+		logger.Warn("Invalid search pattern %q: %v", query, err)
+		return nil, nil
+	}
 	var filteredFiles []Result
 	var searchMatches []MatchResult
-	scannedCount := 0
-	skippedDirs := 0
-	permissionErrors := 0
-
-	// Precompute prefix for fast relative path calculation (avoid filepath.Rel per file)
-	dirPrefix := currentDir
-	if !strings.HasSuffix(dirPrefix, string(filepath.Separator)) {
-		dirPrefix += string(filepath.Separator)
-	}
-
-	filepath.WalkDir(currentDir, func(path string, d fs.DirEntry, err error) error {
-		// Check cancellation first (critical for responsiveness)
-		select {
-		case <-cancelChan:
-			logger.Info("Recursive search cancelled after scanning %d files in %v", scannedCount, time.Since(startTime))
-			return filepath.SkipAll
-		default:
-		}
-
-		if err != nil {
-			// Don't spam logs with permission errors - just count them
-			if strings.Contains(err.Error(), "permission denied") {
-				permissionErrors++
-				return nil
-			}
-			// Log other errors normally
-			logger.Error("WalkDir error at %s: %v", path, err)
-			return nil // Skip errors
+	var resultsMu sync.Mutex // Guards filteredFiles/searchMatches and serializes onResult
+	var scannedCount, skippedDirs, ignoredCount, permissionErrors atomic.Int64
+
+	// stop ends the walk once maxResults or maxFilesScanned is reached
+	stop := make(chan struct{})
+	var stopOnce sync.Once
+	halt := func() { stopOnce.Do(func() { close(stop) }) }
+
+	onReadErr := func(path string, err error) {
+		// Don't spam logs with permission errors - just count them
+		if errors.Is(err, fs.ErrPermission) {
+			permissionErrors.Add(1)
+			return
 		}
+		logger.Error("ReadDir error at %s: %v", path, err)
+	}
 
+	visit := func(dir *walkDir, path, relPath string, d fs.DirEntry) bool {
 		// Hard stop if we've scanned too many files
-		scannedCount++
-		if scannedCount%1000 == 0 && onProgress != nil {
-			onProgress(scannedCount) // Report progress every 1000 files
+		scanned := scannedCount.Add(1)
+		if scanned%1000 == 0 && onProgress != nil {
+			onProgress(int(scanned)) // Report progress every 1000 files
 		}
-
-		if scannedCount > maxFilesScanned {
-			logger.Warn("Hit max files scanned limit (%d)", maxFilesScanned)
-			return filepath.SkipAll
+		if scanned > int64(maxFilesScanned) {
+			if scanned == int64(maxFilesScanned)+1 {
+				logger.Warn("Hit max files scanned limit (%d)", maxFilesScanned)
+			}
+			halt()
+			return false
 		}
 
 		// Skip directories from config skip list (supports exact, wildcard, and absolute paths)
-		if d.IsDir() && shouldSkipDir(path, d.Name(), customSkipDirs) {
-			skippedDirs++
-			return filepath.SkipDir
-		}
-
-		// Fast relative path via prefix trim (avoids filepath.Rel syscall overhead)
-		relPath := strings.TrimPrefix(path, dirPrefix)
-		if relPath == path {
-			// path == currentDir itself, skip the root entry
-			return nil
+		if d.IsDir() && ShouldSkipDir(path, d.Name(), customSkipDirs) {
+			skippedDirs.Add(1)
+			return false
 		}
 
 		// Check depth limit (prevents deep recursion on large drives)
-		depth := strings.Count(relPath, string(filepath.Separator))
-		if depth > maxDepth {
-			if d.IsDir() {
-				return filepath.SkipDir
-			}
-			return nil
+		if dir.depth > maxDepth {
+			return false
 		}
 
-		// Skip hidden files if not showing them
+		// Skip hidden files if not showing them, then ignored patterns and .gitignore/.ignore rules
 		if !showHidden && strings.HasPrefix(d.Name(), ".") {
-			if d.IsDir() {
-				return filepath.SkipDir
-			}
-			return nil
+			return false
 		}
-
-		// Skip ignored patterns
 		if shouldIgnoreFn(d.Name()) {
-			if d.IsDir() {
-				return filepath.SkipDir
-			}
-			return nil
+			return false
+		}
+		if dir.ign.Ignored(path, d.IsDir()) {
+			ignoredCount.Add(1)
+			return false
 		}
 
-		// Inline substring matching - match as we walk, no second pass needed
+		// Inline fuzzy matching - match as we walk, no second pass needed
 		// d.Info() is deferred to only matched files (avoids stat syscall on every file)
-		var matchTarget string
-		var matchOffset int // offset to translate match position back to relPath for highlighting
-		if nameOnly {
-			matchTarget = strings.ToLower(d.Name())
-			// basename starts at last separator + 1 in relPath
-			if lastSep := strings.LastIndex(relPath, string(filepath.Separator)); lastSep != -1 {
-				matchOffset = lastSep + 1
-			}
-		} else {
-			matchTarget = strings.ToLower(relPath)
+		if !filter.MatchesName(d.Name(), d.IsDir()) {
+			return true
+		}
+		matchTarget, matchOffset := MatchTarget(relPath, d.Name(), nameOnly)
+		score, matchedIndexes, ok := matcher.Match(matchTarget)
+		if !ok {
+			return true
+		}
+		for j := range matchedIndexes {
+			matchedIndexes[j] += matchOffset
 		}
-		if idx := strings.Index(matchTarget, lowerQuery); idx != -1 {
-			matchedIndexes := make([]int, len(query))
-			for j := range query {
-				matchedIndexes[j] = idx + j + matchOffset
-			}
 
-			// Only stat matched files (d.Info() triggers a syscall)
-			var size int64
-			var modTime time.Time
-			if info, err := d.Info(); err == nil {
-				size = info.Size()
-				modTime = info.ModTime()
-			}
+		// Only stat matched files (d.Info() triggers a syscall)
+		var size int64
+		var modTime time.Time
+		if info, err := d.Info(); err == nil {
+			size = info.Size()
+			modTime = info.ModTime()
+		}
+		if !filter.Matches(d.Name(), d.IsDir(), size, modTime) {
+			return true
+		}
 
-			result := Result{
-				Path:        path,
-				DisplayName: relPath,
-				IsDir:       d.IsDir(),
-				Size:        size,
-				ModTime:     modTime,
-			}
-			mr := MatchResult{Index: len(filteredFiles), MatchedIndexes: matchedIndexes}
-			filteredFiles = append(filteredFiles, result)
-			searchMatches = append(searchMatches, mr)
-			if onResult != nil {
-				onResult(result, mr)
-			}
-			if len(filteredFiles) >= maxResults {
-				logger.Warn("Hit max results limit (%d)", maxResults)
-				return filepath.SkipAll
-			}
+		result := Result{
+			Path:        path,
+			DisplayName: relPath,
+			IsDir:       d.IsDir(),
+			Size:        size,
+			ModTime:     modTime,
 		}
 
-		return nil
-	})
+		resultsMu.Lock()
+		defer resultsMu.Unlock()
+		if len(filteredFiles) >= maxResults {
+			return false // Another worker filled the quota first
+		}
+		mr := MatchResult{Index: len(filteredFiles), MatchedIndexes: matchedIndexes, Score: score}
+		filteredFiles = append(filteredFiles, result)
+		searchMatches = append(searchMatches, mr)
+		if onResult != nil {
+			onResult(result, mr)
+		}
+		if len(filteredFiles) >= maxResults {
+			logger.Warn("Hit max results limit (%d)", maxResults)
+			halt()
+		}
+		return true
+	}
+
+	walkParallel(currentDir, filter.RespectsIgnore(), cancelChan, stop, visit, onReadErr)
+
+	select {
+	case <-cancelChan:
+		logger.Info("Recursive search cancelled after scanning %d files in %v", scannedCount.Load(), time.Since(startTime))
+	default:
+	}
 
 	// Log summary with permission error count if any occurred
-	if permissionErrors > 0 {
-		logger.Warn("Scan complete: %d files scanned, %d dirs skipped, %d permission errors in %v", scannedCount, skippedDirs, permissionErrors, time.Since(startTime))
+	if permissionErrors.Load() > 0 {
+		logger.Warn("Scan complete: %d files scanned, %d dirs skipped, %d gitignored, %d permission errors in %v", scannedCount.Load(), skippedDirs.Load(), ignoredCount.Load(), permissionErrors.Load(), time.Since(startTime))
 	} else {
-		logger.Info("Scan complete: %d files scanned, %d dirs skipped in %v", scannedCount, skippedDirs, time.Since(startTime))
+		logger.Info("Scan complete: %d files scanned, %d dirs skipped, %d gitignored in %v", scannedCount.Load(), skippedDirs.Load(), ignoredCount.Load(), time.Since(startTime))
 	}
 
 	logger.Info("Recursive search complete: returned %d results", len(filteredFiles))
@@ -0,0 +1,183 @@
+package search
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+)
+
+// walkWorkers picks how many directories are read at once. Listing is mostly waiting on
+// the filesystem (especially WSL's 9p mounts), so it goes past the CPU count.
+func walkWorkers() int {
+	n := runtime.NumCPU() * 2
+	if n < 4 {
+		n = 4
+	}
+	if n > 16 {
+		n = 16
+	}
+	return n
+}
+
+// walkDir is a directory queued for listing
+type walkDir struct {
+	path  string
+	rel   string   // Relative to the walk root, "" for the root itself
+	depth int      // Separators in the relative paths of this directory's entries
+	ign   *Ignorer // Ignore rules in effect inside the directory (the parent's until it's listed)
+}
+
+// walkVisitor is called for every entry under the root, from several goroutines at once.
+// relPath is path relative to the root. For directories, returning true descends into it.
+type walkVisitor func(dir *walkDir, path, relPath string, d fs.DirEntry) bool
+
+// walkQueue is the shared LIFO of directories waiting to be listed. LIFO keeps the walk
+// roughly depth-first, so the backlog stays small on wide trees.
+type walkQueue struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	dirs    []walkDir
+	pending int  // Directories queued or being listed
+	stopped bool // Set on cancel or stop; workers finish their current directory and exit
+
+	cancel, halt <-chan struct{}
+}
+
+func (q *walkQueue) push(dirs ...walkDir) {
+	q.mu.Lock()
+	q.dirs = append(q.dirs, dirs...)
+	q.pending += len(dirs)
+	q.mu.Unlock()
+	q.cond.Broadcast()
+}
+
+// pop blocks until there's a directory to list; false means the walk is over
+func (q *walkQueue) pop() (walkDir, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for len(q.dirs) == 0 && q.pending > 0 && !q.stopped {
+		q.cond.Wait()
+	}
+	if q.stopped || q.closed() || len(q.dirs) == 0 {
+		return walkDir{}, false
+	}
+	d := q.dirs[len(q.dirs)-1]
+	q.dirs = q.dirs[:len(q.dirs)-1]
+	return d, true
+}
+
+func (q *walkQueue) done() {
+	q.mu.Lock()
+	q.pending--
+	finished := q.pending == 0
+	q.mu.Unlock()
+	if finished {
+		q.cond.Broadcast()
+	}
+}
+
+func (q *walkQueue) stop() {
+	q.mu.Lock()
+	q.stopped = true
+	q.mu.Unlock()
+	q.cond.Broadcast()
+}
+
+// closed checks the channels directly, so a walk cancelled before it starts lists nothing
+func (q *walkQueue) closed() bool {
+	select {
+	case <-q.cancel:
+		return true
+	case <-q.halt:
+		return true
+	default:
+		return false
+	}
+}
+
+// walkParallel lists root's tree on a bounded pool of workers, calling visit for each
+// entry. It stops early when cancel or stop is closed and returns only once every worker
+// has exited, so visit is never called after it returns. With ignore set, each directory's
+// .gitignore/.ignore files are loaded before its entries are visited. onReadErr (may be
+// nil) sees directories that couldn't be listed.
+func walkParallel(root string, ignore bool, cancel, stop <-chan struct{}, visit walkVisitor, onReadErr func(path string, err error)) {
+	q := &walkQueue{cancel: cancel, halt: stop}
+	q.cond = sync.NewCond(&q.mu)
+
+	var rootIgn *Ignorer
+	if ignore {
+		rootIgn = NewIgnorer(root)
+	}
+	q.push(walkDir{path: root, ign: rootIgn})
+
+	// Turn cancellation into a queue stop so blocked workers wake up
+	finished := make(chan struct{})
+	go func() {
+		select {
+		case <-cancel:
+			q.stop()
+		case <-stop:
+			q.stop()
+		case <-finished:
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < walkWorkers(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				dir, ok := q.pop()
+				if !ok {
+					return
+				}
+				children := listDir(q, &dir, ignore, visit, onReadErr)
+				if len(children) > 0 {
+					q.push(children...)
+				}
+				q.done()
+			}
+		}()
+	}
+	wg.Wait()
+	close(finished)
+}
+
+// listDir reads one directory, visits its entries and returns the subdirectories to descend into
+func listDir(q *walkQueue, dir *walkDir, ignore bool, visit walkVisitor, onReadErr func(string, error)) []walkDir {
+	if ignore && dir.rel != "" {
+		dir.ign = dir.ign.Enter(dir.path) // The root's rules came from NewIgnorer
+	}
+	f, err := os.Open(dir.path)
+	if err != nil {
+		if onReadErr != nil {
+			onReadErr(dir.path, err)
+		}
+		return nil
+	}
+	entries, err := f.ReadDir(-1) // Unsorted: order doesn't matter and sorting costs on huge dirs
+	f.Close()
+	if err != nil && onReadErr != nil {
+		onReadErr(dir.path, err)
+	}
+
+	var children []walkDir
+	for i, d := range entries {
+		if i%64 == 0 && q.closed() {
+			return nil
+		}
+		path := filepath.Join(dir.path, d.Name())
+		relPath := d.Name()
+		if dir.rel != "" {
+			relPath = dir.rel + string(filepath.Separator) + d.Name()
+		}
+		if !visit(dir, path, relPath, d) || !d.IsDir() {
+			continue
+		}
+		children = append(children, walkDir{path: path, rel: relPath, depth: dir.depth + 1, ign: dir.ign})
+	}
+	return children
+}
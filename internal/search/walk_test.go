@@ -0,0 +1,105 @@
+package search
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+)
+
+// makeWideTree creates dirs*files files spread over dirs nested directories
+func makeWideTree(t *testing.T, root string, dirs, files int) {
+	t.Helper()
+	for d := 0; d < dirs; d++ {
+		dir := filepath.Join(root, fmt.Sprintf("d%02d", d), "inner")
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		for f := 0; f < files; f++ {
+			os.WriteFile(filepath.Join(dir, fmt.Sprintf("file%03d.txt", f)), nil, 0644)
+		}
+	}
+}
+
+func TestWalkParallelVisitsEverything(t *testing.T) {
+	root := t.TempDir()
+	makeWideTree(t, root, 20, 10)
+
+	var mu sync.Mutex
+	var got []string
+	walkParallel(root, false, nil, nil, func(dir *walkDir, path, relPath string, d fs.DirEntry) bool {
+		mu.Lock()
+		got = append(got, filepath.ToSlash(relPath))
+		mu.Unlock()
+		if want := filepath.Join(root, relPath); path != want {
+			t.Errorf("path %s doesn't match relPath %s", path, relPath)
+		}
+		return true
+	}, nil)
+
+	var want []string
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if path != root {
+			rel, _ := filepath.Rel(root, path)
+			want = append(want, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	sort.Strings(got)
+	sort.Strings(want)
+	if !equalStringSlices(got, want) {
+		t.Errorf("parallel walk visited %d entries, WalkDir %d", len(got), len(want))
+	}
+}
+
+func TestRecursiveSearchFilesLimitsAcrossWorkers(t *testing.T) {
+	root := t.TempDir()
+	makeWideTree(t, root, 20, 50) // 1000 files, 40 dirs
+
+	cancel := make(chan struct{})
+	defer close(cancel)
+	noIgnore := func(string) bool { return false }
+
+	var streamed int
+	results, matches := RecursiveSearchFiles("file", root, false, noIgnore, cancel, nil, func(Result, MatchResult) { streamed++ }, 37, 10, 100000, nil, true, nil)
+	if len(results) != 37 || len(matches) != 37 || streamed != 37 {
+		t.Errorf("maxResults 37: got %d results, %d matches, %d streamed", len(results), len(matches), streamed)
+	}
+	for i, mr := range matches {
+		if mr.Index != i {
+			t.Fatalf("match %d has Index %d", i, mr.Index)
+		}
+	}
+
+	results, _ = RecursiveSearchFiles("file", root, false, noIgnore, cancel, nil, nil, 5000, 10, 100, nil, true, nil)
+	if len(results) > 100 {
+		t.Errorf("maxFilesScanned 100 let through %d results", len(results))
+	}
+
+	results, _ = RecursiveSearchFiles("file", root, false, noIgnore, cancel, nil, nil, 5000, 10, 100000, nil, true, nil)
+	if len(results) != 1000 {
+		t.Errorf("expected all 1000 files, got %d", len(results))
+	}
+}
+
+func TestRecursiveSearchFilesCancelled(t *testing.T) {
+	root := t.TempDir()
+	makeWideTree(t, root, 10, 10)
+
+	cancel := make(chan struct{})
+	close(cancel)
+	var calls int
+	var mu sync.Mutex
+	results, _ := RecursiveSearchFiles("file", root, false, func(string) bool { return false }, cancel, nil, func(Result, MatchResult) {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+	}, 5000, 10, 100000, nil, true, nil)
+
+	if len(results) != 0 || calls != 0 {
+		t.Errorf("cancelled search returned %d results (%d streamed)", len(results), calls)
+	}
+}
@@ -0,0 +1,136 @@
+//go:build linux
+
+package watcher
+
+import (
+	"fmt"
+	"sync"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/LFroesch/scout/internal/logger"
+)
+
+// inotifyMask covers everything that changes what a directory listing shows
+const inotifyMask = unix.IN_CREATE | unix.IN_DELETE | unix.IN_MOVED_FROM | unix.IN_MOVED_TO |
+	unix.IN_CLOSE_WRITE | unix.IN_ATTRIB | unix.IN_DELETE_SELF | unix.IN_MOVE_SELF
+
+// inotifyBackend watches directories (non-recursively) with a single inotify fd
+type inotifyBackend struct {
+	fd   int
+	raw  chan<- string
+	lost func() // Called when a watch is dropped or can't be added
+
+	mu      sync.Mutex
+	wdToDir map[int]string
+	dirToWd map[string]int
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newNativeBackend(raw chan<- string, lost func()) (backend, error) {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return nil, fmt.Errorf("inotify_init1: %w", err)
+	}
+	b := &inotifyBackend{
+		fd:      fd,
+		raw:     raw,
+		lost:    lost,
+		wdToDir: make(map[int]string),
+		dirToWd: make(map[string]int),
+		done:    make(chan struct{}),
+	}
+	b.wg.Add(1)
+	go b.readLoop()
+	return b, nil
+}
+
+func (b *inotifyBackend) setDirs(dirs []string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	want := make(map[string]bool, len(dirs))
+	for _, d := range dirs {
+		want[d] = true
+	}
+	for dir, wd := range b.dirToWd {
+		if !want[dir] {
+			unix.InotifyRmWatch(b.fd, uint32(wd))
+			delete(b.dirToWd, dir)
+			delete(b.wdToDir, wd)
+		}
+	}
+	for _, dir := range dirs {
+		if _, ok := b.dirToWd[dir]; ok {
+			continue
+		}
+		wd, err := unix.InotifyAddWatch(b.fd, dir, inotifyMask)
+		if err != nil {
+			logger.Warn("Cannot watch %s: %v", dir, err)
+			b.lost()
+			continue
+		}
+		b.dirToWd[dir] = wd
+		b.wdToDir[wd] = dir
+	}
+}
+
+func (b *inotifyBackend) close() {
+	close(b.done)
+	b.wg.Wait()
+	unix.Close(b.fd)
+}
+
+// readLoop polls the non-blocking fd with a short timeout so close() never hangs on a read
+func (b *inotifyBackend) readLoop() {
+	defer b.wg.Done()
+	buf := make([]byte, 64*(unix.SizeofInotifyEvent+unix.NAME_MAX+1))
+	fds := []unix.PollFd{{Fd: int32(b.fd), Events: unix.POLLIN}}
+
+	for {
+		select {
+		case <-b.done:
+			return
+		default:
+		}
+
+		n, err := unix.Poll(fds, 200)
+		if err != nil && err != unix.EINTR {
+			logger.Error("inotify poll failed: %v", err)
+			return
+		}
+		if n <= 0 {
+			continue
+		}
+
+		n, err = unix.Read(b.fd, buf)
+		if err != nil || n < unix.SizeofInotifyEvent {
+			continue
+		}
+
+		for offset := 0; offset+unix.SizeofInotifyEvent <= n; {
+			ev := (*unix.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			offset += unix.SizeofInotifyEvent + int(ev.Len)
+
+			b.mu.Lock()
+			dir, ok := b.wdToDir[int(ev.Wd)]
+			dropped := ev.Mask&unix.IN_IGNORED != 0 && ok
+			if dropped {
+				// Watch removed by the kernel (dir deleted) - forget it so it can be re-added
+				delete(b.wdToDir, int(ev.Wd))
+				delete(b.dirToWd, dir)
+			}
+			b.mu.Unlock()
+			if dropped {
+				b.lost()
+			}
+
+			if ok {
+				notify(b.raw, dir)
+			}
+		}
+	}
+}
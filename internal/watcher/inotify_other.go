@@ -0,0 +1,9 @@
+//go:build !linux
+
+package watcher
+
+import "errors"
+
+func newNativeBackend(raw chan<- string, lost func()) (backend, error) {
+	return nil, errors.New("no native watcher on this platform")
+}
@@ -0,0 +1,99 @@
+package watcher
+
+import (
+	"hash/fnv"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// pollBackend re-reads each watched directory on a timer and reports it when its
+// listing signature (names, sizes, mtimes) changes
+type pollBackend struct {
+	raw chan<- string
+
+	mu   sync.Mutex
+	sigs map[string]uint64
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newPollBackend(raw chan<- string, interval time.Duration) backend {
+	b := &pollBackend{
+		raw:  raw,
+		sigs: make(map[string]uint64),
+		done: make(chan struct{}),
+	}
+	b.wg.Add(1)
+	go b.loop(interval)
+	return b
+}
+
+func (b *pollBackend) setDirs(dirs []string) {
+	sigs := make(map[string]uint64, len(dirs))
+	for _, d := range dirs {
+		sigs[d] = dirSignature(d)
+	}
+	b.mu.Lock()
+	b.sigs = sigs
+	b.mu.Unlock()
+}
+
+func (b *pollBackend) close() {
+	close(b.done)
+	b.wg.Wait()
+}
+
+func (b *pollBackend) loop(interval time.Duration) {
+	defer b.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.done:
+			return
+		case <-ticker.C:
+		}
+
+		b.mu.Lock()
+		dirs := make([]string, 0, len(b.sigs))
+		for d := range b.sigs {
+			dirs = append(dirs, d)
+		}
+		b.mu.Unlock()
+
+		for _, d := range dirs {
+			sig := dirSignature(d)
+			b.mu.Lock()
+			old, still := b.sigs[d]
+			if still {
+				b.sigs[d] = sig
+			}
+			b.mu.Unlock()
+			if still && sig != old {
+				notify(b.raw, d)
+			}
+		}
+	}
+}
+
+// dirSignature hashes a directory listing; 0 means the directory can't be read
+func dirSignature(dir string) uint64 {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0
+	}
+	h := fnv.New64a()
+	for _, e := range entries {
+		h.Write([]byte(e.Name()))
+		if info, err := e.Info(); err == nil {
+			h.Write([]byte(strconv.FormatInt(info.Size(), 10)))
+			h.Write([]byte(strconv.FormatInt(info.ModTime().UnixNano(), 10)))
+		}
+		h.Write([]byte{0})
+	}
+	return h.Sum64() | 1
+}
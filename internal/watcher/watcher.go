@@ -0,0 +1,167 @@
+package watcher
+
+import (
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/LFroesch/scout/internal/logger"
+)
+
+// Debounce is how long a directory has to stay quiet before a change is reported,
+// so a build writing hundreds of files triggers one reload instead of hundreds
+const Debounce = 250 * time.Millisecond
+
+// MaxWait caps how long a change waits for quiet: a directory written to without pause
+// (a log rotating every 200ms) is still reported this often
+const MaxWait = 2 * time.Second
+
+// pollInterval is how often the polling fallback re-checks watched directories
+const pollInterval = time.Second
+
+// backend is a source of raw "something changed in dir" notifications
+type backend interface {
+	setDirs(dirs []string)
+	close()
+}
+
+// Watcher reports directories whose contents changed. It uses inotify on Linux and
+// falls back to polling elsewhere (or when inotify is unavailable).
+// A nil *Watcher is valid and never reports anything.
+type Watcher struct {
+	backend backend
+	raw     chan string
+	events  chan string
+	done    chan struct{}
+
+	mu      sync.Mutex
+	watched []string
+}
+
+// New starts a watcher with the native backend, falling back to polling
+func New() *Watcher {
+	w := newWatcher()
+	b, err := newNativeBackend(w.raw, w.forget)
+	if err != nil {
+		logger.Info("Native file watching unavailable (%v), falling back to polling", err)
+		b = newPollBackend(w.raw, pollInterval)
+	}
+	w.backend = b
+	go w.debounceLoop()
+	return w
+}
+
+// newPolling starts a watcher that always uses the polling backend
+func newPolling(interval time.Duration) *Watcher {
+	w := newWatcher()
+	w.backend = newPollBackend(w.raw, interval)
+	go w.debounceLoop()
+	return w
+}
+
+func newWatcher() *Watcher {
+	return &Watcher{
+		raw:    make(chan string, 64),
+		events: make(chan string, 16),
+		done:   make(chan struct{}),
+	}
+}
+
+// Events delivers each changed directory once per burst of activity
+func (w *Watcher) Events() <-chan string {
+	if w == nil {
+		return nil
+	}
+	return w.events
+}
+
+// Watch replaces the set of watched directories. Duplicates and unchanged sets are cheap.
+func (w *Watcher) Watch(dirs ...string) {
+	if w == nil {
+		return
+	}
+	seen := make(map[string]bool, len(dirs))
+	var clean []string
+	for _, d := range dirs {
+		if d == "" {
+			continue
+		}
+		d = filepath.Clean(d)
+		if !seen[d] {
+			seen[d] = true
+			clean = append(clean, d)
+		}
+	}
+
+	w.mu.Lock()
+	same := len(clean) == len(w.watched)
+	for i := 0; same && i < len(clean); i++ {
+		same = clean[i] == w.watched[i]
+	}
+	w.watched = clean
+	w.mu.Unlock()
+
+	if !same {
+		w.backend.setDirs(clean)
+	}
+}
+
+// forget clears the watched set so the next Watch hands every directory to the backend
+// again. Backends call it when they lose a watch or can't add one (a deleted directory),
+// or Watch with the same set would never retry it.
+func (w *Watcher) forget() {
+	w.mu.Lock()
+	w.watched = nil
+	w.mu.Unlock()
+}
+
+// Close stops the watcher; Events is closed once pending notifications are dropped
+func (w *Watcher) Close() {
+	if w == nil {
+		return
+	}
+	w.backend.close()
+	close(w.done)
+}
+
+// debounceLoop coalesces raw notifications per directory and emits each one after
+// Debounce of quiet, or MaxWait after the first of them if the quiet never comes
+func (w *Watcher) debounceLoop() {
+	defer close(w.events)
+
+	pending := make(map[string]bool)
+	var first time.Time // When the oldest pending notification came in
+	timer := time.NewTimer(Debounce)
+	timer.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			timer.Stop()
+			return
+		case dir := <-w.raw:
+			if len(pending) == 0 {
+				first = time.Now()
+			}
+			pending[dir] = true
+			timer.Reset(max(min(Debounce, MaxWait-time.Since(first)), 0))
+		case <-timer.C:
+			for dir := range pending {
+				select {
+				case w.events <- dir:
+				case <-w.done:
+					return
+				}
+			}
+			pending = make(map[string]bool)
+		}
+	}
+}
+
+// notify forwards a raw change without ever blocking a backend
+func notify(raw chan<- string, dir string) {
+	select {
+	case raw <- dir:
+	default:
+	}
+}
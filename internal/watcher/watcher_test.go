@@ -0,0 +1,115 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func waitForEvent(t *testing.T, w *Watcher, want string) {
+	t.Helper()
+	timeout := time.After(3 * time.Second)
+	for {
+		select {
+		case dir := <-w.Events():
+			if dir == want {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("no change reported for %s", want)
+		}
+	}
+}
+
+func expectQuiet(t *testing.T, w *Watcher, d time.Duration) {
+	t.Helper()
+	select {
+	case dir := <-w.Events():
+		t.Fatalf("unexpected change reported for %s", dir)
+	case <-time.After(d):
+	}
+}
+
+func testWatcher(t *testing.T, w *Watcher) {
+	dir := t.TempDir()
+	other := t.TempDir()
+	w.Watch(dir, other)
+
+	// A burst of writes is reported once
+	for i := 0; i < 5; i++ {
+		os.WriteFile(filepath.Join(dir, "file"+string(rune('a'+i))), []byte("x"), 0644)
+	}
+	waitForEvent(t, w, dir)
+	expectQuiet(t, w, 2*Debounce)
+
+	os.Remove(filepath.Join(dir, "filea"))
+	waitForEvent(t, w, dir)
+
+	os.Mkdir(filepath.Join(other, "sub"), 0755)
+	waitForEvent(t, w, other)
+
+	// Dropping a directory from the set stops its notifications
+	w.Watch(other)
+	os.WriteFile(filepath.Join(dir, "late"), []byte("x"), 0644)
+	expectQuiet(t, w, 1500*time.Millisecond)
+}
+
+func TestWatcherNative(t *testing.T) {
+	w := New()
+	defer w.Close()
+	testWatcher(t, w)
+}
+
+func TestWatcherPolling(t *testing.T) {
+	w := newPolling(50 * time.Millisecond)
+	defer w.Close()
+	testWatcher(t, w)
+}
+
+func TestNilWatcher(t *testing.T) {
+	var w *Watcher
+	w.Watch("/tmp")
+	w.Close()
+	if w.Events() != nil {
+		t.Error("nil watcher should have no events channel")
+	}
+}
+
+func TestWatcherReportsSteadyWrites(t *testing.T) {
+	w := New()
+	defer w.Close()
+	dir := t.TempDir()
+	w.Watch(dir)
+
+	// Writes closer together than Debounce never leave a quiet spell, but still get reported
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			case <-time.After(Debounce / 5):
+				os.WriteFile(filepath.Join(dir, "log"), []byte{byte(i)}, 0644)
+			}
+		}
+	}()
+	waitForEvent(t, w, dir)
+}
+
+func TestWatcherRewatchesRecreatedDirectory(t *testing.T) {
+	w := New()
+	defer w.Close()
+	dir := filepath.Join(t.TempDir(), "build")
+	os.Mkdir(dir, 0755)
+	w.Watch(dir)
+
+	os.Remove(dir)
+	waitForEvent(t, w, dir) // The removal itself
+	os.Mkdir(dir, 0755)
+	w.Watch(dir)
+
+	os.WriteFile(filepath.Join(dir, "out"), []byte("x"), 0644)
+	waitForEvent(t, w, dir)
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/LFroesch/scout/internal/jobs"
+	"github.com/LFroesch/scout/internal/utils"
+)
+
+// Background file operations: paste (see paste.go) hands the clipboard to the
+// jobs.Manager, which copies or moves it on its own goroutine; extract and compress (see
+// archive.go) queue there too. A tick loop keeps the
+// status bar and the jobs panel live and reports each job as it finishes.
+
+// jobTickMsg redraws job progress and collects finished jobs
+type jobTickMsg struct{}
+
+func jobTick() tea.Cmd {
+	return tea.Tick(250*time.Millisecond, func(t time.Time) tea.Msg {
+		return jobTickMsg{}
+	})
+}
+
+// startJobPolling starts the tick loop if it isn't already running
+func (m *model) startJobPolling() tea.Cmd {
+	if m.jobPolling {
+		return nil
+	}
+	m.jobPolling = true
+	return jobTick()
+}
+
+// handleJobTick reports finished jobs and keeps ticking while any are queued or running
+func (m *model) handleJobTick() tea.Cmd {
+	refresh := m.reportFinishedJobs()
+	if m.jobs.Active() > 0 {
+		return tea.Batch(jobTick(), refresh)
+	}
+	m.jobPolling = false
+	return refresh
+}
+
+// reportFinishedJobs records each newly finished job in the undo history, puts it in the
+// status bar (failures get the error dialog) and queues a reload of the directories it
+// touched
+func (m *model) reportFinishedJobs() tea.Cmd {
+	finished := m.jobs.TakeFinished()
+	if len(finished) == 0 {
+		return nil
+	}
+	for _, job := range finished {
+		m.recordJob(job)
+		m.queueDirRefresh(job.DestDir)
+		if job.Kind == jobs.Move {
+			for _, src := range job.Sources {
+				m.queueDirRefresh(filepath.Dir(src))
+			}
+		}
+
+		switch job.State {
+		case jobs.Done:
+			m.statusMsg = fmt.Sprintf("%s: %d item(s) to %s done", job.Kind, len(job.Sources), filepath.Base(job.DestDir))
+		case jobs.Cancelled:
+			m.statusMsg = fmt.Sprintf("%s to %s cancelled", job.Kind, filepath.Base(job.DestDir))
+		case jobs.Failed:
+			// Don't pull the user out of a dialog; the jobs panel keeps the error
+			if m.mode == modeNormal {
+				progress := fmt.Sprintf("%d of %d", job.FilesDone, job.FilesTotal)
+				if job.FilesTotal == 0 {
+					progress = fmt.Sprint(job.FilesDone)
+				}
+				title := "PASTE FAILED"
+				if job.Kind == jobs.Extract || job.Kind == jobs.Compress {
+					title = strings.ToUpper(job.Kind.String()) + " FAILED"
+				}
+				m.showError(title, fmt.Sprintf("%s to %s stopped after %s file(s).\n\n%v",
+					job.Kind, job.DestDir, progress, job.Err))
+			} else {
+				m.statusMsg = fmt.Sprintf("%s failed: %v (J: jobs)", job.Kind, job.Err)
+			}
+		}
+		m.statusExpiry = time.Now().Add(3 * time.Second)
+	}
+	return m.flushDirRefresh()
+}
+
+// openJobsPanel shows the jobs panel
+func (m *model) openJobsPanel() {
+	m.mode = modeJobs
+	m.jobsCursor = 0
+}
+
+// closeJobsPanel goes back to the file list, reloading anything finished jobs changed
+func (m *model) closeJobsPanel() tea.Cmd {
+	m.mode = modeNormal
+	return m.flushDirRefresh()
+}
+
+// handleJobsKey handles keys while the jobs panel is open
+func (m *model) handleJobsKey(msg tea.KeyMsg) tea.Cmd {
+	snaps := m.jobs.Snapshots()
+	switch msg.String() {
+	case "esc", "J":
+		return m.closeJobsPanel()
+	case "q", "ctrl+c":
+		// Quitting stops every job; main waits for them to clean up
+		m.jobs.CancelAll()
+		return tea.Quit
+	case "j", "down":
+		if m.jobsCursor < len(snaps)-1 {
+			m.jobsCursor++
+		}
+	case "k", "up":
+		if m.jobsCursor > 0 {
+			m.jobsCursor--
+		}
+	case "x", "d":
+		if m.jobsCursor < len(snaps) && snaps[m.jobsCursor].Active() {
+			m.jobs.Cancel(snaps[m.jobsCursor].ID)
+			m.statusMsg = "cancelling job..."
+			m.statusExpiry = time.Now().Add(2 * time.Second)
+		}
+	case "C":
+		m.jobs.ClearFinished()
+		m.jobsCursor = 0
+	}
+	return nil
+}
+
+// quitOrShowJobs quits, unless jobs are still running: then the jobs panel opens so the
+// user can wait for them or press q again to cancel them and quit
+func (m *model) quitOrShowJobs() tea.Cmd {
+	if m.jobs.Active() == 0 {
+		return tea.Quit
+	}
+	m.openJobsPanel()
+	m.statusMsg = fmt.Sprintf("%d job(s) still running: q to cancel them and quit, esc to keep going", m.jobs.Active())
+	m.statusExpiry = time.Now().Add(5 * time.Second)
+	return nil
+}
+
+// jobProgressText summarises running jobs for the status bar ("" when there are none)
+func (m *model) jobProgressText() string {
+	var running *jobs.Snapshot
+	queued := 0
+	for _, s := range m.jobs.Snapshots() {
+		switch s.State {
+		case jobs.Running:
+			running = &s
+		case jobs.Queued:
+			queued++
+		}
+	}
+	if running == nil {
+		return ""
+	}
+	text := running.Kind.String() + " " + jobProgress(*running)
+	if queued > 0 {
+		text += fmt.Sprintf(" (+%d queued)", queued)
+	}
+	return text
+}
+
+// jobProgress renders a job's percentage, throughput and ETA
+func jobProgress(s jobs.Snapshot) string {
+	if s.State == jobs.Running && !s.Scanned {
+		return "scanning..."
+	}
+	text := fmt.Sprintf("%d%%", int(s.Fraction()*100))
+	if s.State != jobs.Running {
+		return text
+	}
+	if rate := s.Throughput(); rate > 0 {
+		text += fmt.Sprintf(" %s/s", utils.FormatFileSize(int64(rate)))
+	}
+	if eta, ok := s.ETA(); ok {
+		text += " eta " + formatETA(eta)
+	}
+	return text
+}
+
+// formatETA renders a remaining time compactly: 45s, 3m05s, 2h10m
+func formatETA(d time.Duration) string {
+	d = d.Round(time.Second)
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+	}
+	return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// finishJobs waits for every background job, then delivers the tick that reports them
+func finishJobs(m *model) {
+	m.jobs.Wait()
+	m.Update(jobTickMsg{})
+}
+
+func TestPasteRunsAsJob(t *testing.T) {
+	src := t.TempDir()
+	dest := t.TempDir()
+	file := filepath.Join(src, "big.bin")
+	if err := os.WriteFile(file, make([]byte, 3<<20), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := testModelForUpdate(t, dest)
+	m.mode = modeNormal
+	m.loadFiles()
+	m.clipboard = []string{file}
+	m.clipboardOp = opCut
+
+	_, cmd := m.Update(runeKey('p'))
+	if cmd == nil || !m.jobPolling {
+		t.Fatal("paste didn't start the job tick loop")
+	}
+	if len(m.clipboard) != 0 || m.clipboardOp != opNone {
+		t.Errorf("cut clipboard not cleared on submit: %v", m.clipboard)
+	}
+	finishJobs(&m)
+
+	if _, err := os.Stat(filepath.Join(dest, "big.bin")); err != nil {
+		t.Fatalf("file not moved: %v", err)
+	}
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Errorf("source still there after move")
+	}
+	if m.jobPolling {
+		t.Error("tick loop still running with no jobs left")
+	}
+	if !strings.Contains(m.statusMsg, "done") {
+		t.Errorf("status = %q, want a done report", m.statusMsg)
+	}
+	found := false
+	for _, f := range m.files {
+		found = found || f.name == "big.bin"
+	}
+	if !found {
+		t.Error("listing not refreshed after the job finished")
+	}
+}
+
+func TestFailedPasteShowsError(t *testing.T) {
+	dest := t.TempDir()
+	m := testModelForUpdate(t, dest)
+	m.mode = modeNormal
+	m.loadFiles()
+	m.clipboard = []string{filepath.Join(t.TempDir(), "missing.txt")}
+	m.clipboardOp = opCopy
+
+	m.Update(runeKey('p'))
+	finishJobs(&m)
+	if m.mode != modeErrorDialog || m.errorMsg != "PASTE FAILED" {
+		t.Errorf("mode = %v (%q), want the paste error dialog", m.mode, m.errorMsg)
+	}
+}
+
+func TestJobsPanelKeys(t *testing.T) {
+	m := testModelForUpdate(t, t.TempDir())
+	m.mode = modeNormal
+	m.Update(runeKey('J'))
+	if m.mode != modeJobs {
+		t.Fatalf("J opened mode %v, want the jobs panel", m.mode)
+	}
+	if !strings.Contains(m.renderJobsDialog(), "no jobs") {
+		t.Error("empty jobs panel doesn't say so")
+	}
+	m.Update(runeKey('J'))
+	if m.mode != modeNormal {
+		t.Errorf("J didn't close the jobs panel")
+	}
+}
+
+func TestFormatETA(t *testing.T) {
+	cases := map[time.Duration]string{
+		45 * time.Second:              "45s",
+		3*time.Minute + 5*time.Second: "3m05s",
+		2*time.Hour + 10*time.Minute:  "2h10m",
+	}
+	for d, want := range cases {
+		if got := formatETA(d); got != want {
+			t.Errorf("formatETA(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/LFroesch/scout/internal/bigfile"
+)
+
+// Large files: text previews over largeFileSize read only the lines in view (see
+// internal/bigfile) instead of the whole file. The window starts at the top; w/s move it
+// a line at a time, t flips it between the start and the end, and : jumps to a line.
+// Lines are counted only as far as the furthest one asked for.
+
+// largeFileSize is the size above which a text preview is a window onto the file
+const largeFileSize = 1 << 20
+
+var lineNumberStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+
+// largePreview is the window onto the large file being previewed
+type largePreview struct {
+	path string
+	file *bigfile.File
+	tail bool  // Pinned to the end of the file
+	top  int64 // Offset of the first line shown
+	line int   // Number of that line from 0, or -1 if unknown (reached from the end)
+	end  int64 // Offset after the last line shown, as last drawn
+}
+
+// largeFilePreview adds the window onto path's lines to the preview, opening the file
+// the first time and keeping the window where it was on the way back
+func (m *model) largeFilePreview(path string, info os.FileInfo, preview *strings.Builder) {
+	lp := m.largePreview
+	if lp == nil || lp.path != path {
+		m.closeLargePreview()
+		f, err := bigfile.Open(path)
+		if err != nil {
+			preview.WriteString(fmt.Sprintf("Error reading file: %v", err))
+			return
+		}
+		lp = &largePreview{path: path, file: f}
+		m.largePreview = lp
+	} else if lp.file.Resize(info.Size()); lp.top > info.Size() {
+		lp.top, lp.line = 0, 0 // Truncated under the window
+	}
+
+	width := m.width/2 - 6 // renderPreview's line width
+	used := len(m.wrapTextToLines(preview.String(), width))
+	rows := max(m.height-uiOverhead-1-used, 1) // The header takes a line
+
+	lines, err := lp.read(rows, width, m)
+	if err != nil {
+		preview.WriteString(fmt.Sprintf("Error reading file: %v", err))
+		return
+	}
+
+	var header string
+	switch {
+	case len(lines) == 0:
+		header = "empty"
+	case lp.line < 0:
+		header = fmt.Sprintf("last %d lines", len(lines))
+	default:
+		header = fmt.Sprintf("lines %d–%d", lp.line+1, lp.line+len(lines))
+	}
+	// The total is only known once every line has been counted
+	if total, complete := lp.file.Lines(); complete && total > 0 {
+		header += fmt.Sprintf(" of %d", total)
+	}
+	preview.WriteString(lineNumberStyle.Render("─── "+header+" · t: start/end, :: line ───") + "\n")
+	preview.WriteString(strings.Join(lines, "\n"))
+}
+
+// read fills the window with as many lines as fit in rows once wrapped to width, from the
+// top or, pinned to the end, back from it. Known line numbers go in a gutter.
+func (lp *largePreview) read(rows, width int, m *model) ([]string, error) {
+	size := lp.file.Size()
+	if lp.tail {
+		lines, start, err := lp.file.ReadBefore(size, rows)
+		if err != nil {
+			return nil, err
+		}
+		// Drop lines off the top until the wrapped rest fits
+		for len(lines) > 1 && wrappedRows(lines, width, m) > rows {
+			lines = lines[1:]
+		}
+		if _, start, err = lp.file.ReadBefore(size, len(lines)); err != nil {
+			return nil, err
+		}
+		lp.top, lp.line = start, -1
+		if total, complete := lp.file.Lines(); complete {
+			lp.line = total - len(lines)
+		}
+	}
+
+	lines, end, err := lp.file.ReadFrom(lp.top, rows)
+	if err != nil {
+		return nil, err
+	}
+	gutter := 0
+	if lp.line >= 0 {
+		gutter = len(strconv.Itoa(lp.line+len(lines))) + 3
+	}
+	// Drop lines off the bottom until the wrapped window fits
+	if kept := len(lines); kept > 1 && wrappedRows(lines, width-gutter, m) > rows {
+		for kept > 1 && wrappedRows(lines[:kept], width-gutter, m) > rows {
+			kept--
+		}
+		lines = lines[:kept]
+		if _, end, err = lp.file.ReadFrom(lp.top, kept); err != nil {
+			return nil, err
+		}
+	}
+	lp.end = end
+
+	if gutter > 0 {
+		for i, line := range lines {
+			num := fmt.Sprintf("%*d │ ", gutter-3, lp.line+i+1)
+			lines[i] = lineNumberStyle.Render(num) + line
+		}
+	}
+	return lines, nil
+}
+
+// wrappedRows counts the rows lines take in the preview once wrapped to width
+func wrappedRows(lines []string, width int, m *model) int {
+	rows := 0
+	for _, line := range lines {
+		rows += len(m.wrapTextToLines(line, width))
+	}
+	return rows
+}
+
+// closeLargePreview lets go of the large file's window
+func (m *model) closeLargePreview() {
+	if m.largePreview != nil {
+		m.largePreview.file.Close()
+		m.largePreview = nil
+	}
+}
+
+// scrollLargePreview moves the window delta lines, leaving the end it was pinned to
+func (m *model) scrollLargePreview(delta int) {
+	lp := m.largePreview
+	lp.tail = false
+	for ; delta > 0 && lp.end < lp.file.Size(); delta-- {
+		_, next, err := lp.file.ReadFrom(lp.top, 1)
+		if err != nil {
+			break
+		}
+		lp.top = next
+		if lp.line >= 0 {
+			lp.line++
+		}
+		_, lp.end, _ = lp.file.ReadFrom(lp.end, 1)
+	}
+	for ; delta < 0 && lp.top > 0; delta++ {
+		_, start, err := lp.file.ReadBefore(lp.top, 1)
+		if err != nil {
+			break
+		}
+		lp.top = start
+		if lp.line > 0 {
+			lp.line--
+		}
+	}
+	m.updatePreview()
+}
+
+// activeLargePreview is the large file window, if the preview shows one for the file
+// under the cursor
+func (m *model) activeLargePreview() *largePreview {
+	lp := m.largePreview
+	if lp == nil || m.cursor >= len(m.filteredFiles) || m.filteredFiles[m.cursor].path != lp.path {
+		return nil
+	}
+	return lp
+}
+
+// openPreviewLineDialog asks which line of the previewed file to go to (:)
+func (m *model) openPreviewLineDialog() tea.Cmd {
+	if !m.showPreview || len(m.filteredFiles) == 0 || m.cursor >= len(m.filteredFiles) || m.filteredFiles[m.cursor].isDir {
+		return nil
+	}
+	m.previousMode = m.mode
+	m.mode = modePreviewLine
+	m.textInput.SetValue("")
+	m.textInput.Placeholder = "line number"
+	m.textInput.Focus()
+	return textinput.Blink
+}
+
+// applyPreviewLine goes to the line typed in the dialog
+func (m *model) applyPreviewLine(input string) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return
+	}
+	n, err := strconv.Atoi(input)
+	if err != nil || n < 1 {
+		m.statusMsg = "not a line number: " + input
+		m.statusExpiry = time.Now().Add(2 * time.Second)
+		return
+	}
+	m.gotoPreviewLine(n)
+}
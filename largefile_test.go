@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	xansi "github.com/charmbracelet/x/ansi"
+)
+
+func TestLargeFilePreviewReadsAWindow(t *testing.T) {
+	m := selectionTestModel(t, "small.txt")
+	var log strings.Builder
+	for i := 1; log.Len() <= largeFileSize; i++ {
+		fmt.Fprintf(&log, "entry %06d\n", i)
+	}
+	total := strings.Count(log.String(), "\n")
+	path := filepath.Join(m.currentDir, "app.log")
+	os.WriteFile(path, []byte(log.String()), 0o644)
+	m.loadFiles()
+	for i, f := range m.filteredFiles {
+		if f.name == "app.log" {
+			m.cursor = i
+		}
+	}
+
+	preview := func() string {
+		t.Helper()
+		m.updatePreview()
+		return xansi.Strip(m.previewContent)
+	}
+	got := preview()
+	if !strings.Contains(got, "lines 1–") || !strings.Contains(got, " 1 │ entry 000001\n") {
+		t.Fatalf("head of the large file:\n%s", got)
+	}
+	if _, cached := m.previewCache[path]; cached {
+		t.Error("a large file went into the preview cache")
+	}
+	// Only the window fits, counted lines stop short of the end
+	if n := strings.Count(got, "entry "); n > m.height {
+		t.Errorf("window has %d lines", n)
+	}
+
+	m = pressKeys(m, runeKey('s'), runeKey('s'))
+	if got := preview(); !strings.Contains(got, "lines 3–") || !strings.Contains(got, " 3 │ entry 000003") {
+		t.Errorf("after s s:\n%s", got)
+	}
+	m = pressKeys(m, runeKey('w'))
+	if got := preview(); !strings.Contains(got, " 2 │ entry 000002") {
+		t.Errorf("after w:\n%s", got)
+	}
+
+	// t pins the window to the end: the line numbers aren't known yet
+	m = pressKeys(m, runeKey('t'))
+	got = preview()
+	if !strings.Contains(got, "last ") || !strings.HasSuffix(got, fmt.Sprintf("entry %06d", total)) {
+		t.Errorf("tail:\n%s", got)
+	}
+	m = pressKeys(m, runeKey('s'))
+	if got := preview(); !strings.HasSuffix(got, fmt.Sprintf("entry %06d", total)) {
+		t.Error("scrolled past the end")
+	}
+
+	// : counts lines as far as the one asked for
+	m = pressKeys(m, runeKey(':'))
+	if m.mode != modePreviewLine {
+		t.Fatalf(": opened mode %v", m.mode)
+	}
+	m.textInput.SetValue("40000")
+	m = pressKeys(m, tea.KeyMsg{Type: tea.KeyEnter})
+	got = preview()
+	if m.mode != modeNormal || !strings.Contains(got, "lines 40000–") || !strings.Contains(got, "40000 │ entry 040000") {
+		t.Errorf("after :40000 (mode %v):\n%s", m.mode, got)
+	}
+	if counted, complete := m.largePreview.file.Lines(); complete || counted >= total {
+		t.Errorf("counted %d of %d lines to reach line 40000", counted, total)
+	}
+
+	// Moving off the file lets it go
+	lp := m.largePreview
+	for i, f := range m.filteredFiles {
+		if f.name == "small.txt" {
+			m.cursor = i
+		}
+	}
+	m.updatePreview()
+	if m.largePreview != nil || lp.file.Close() == nil {
+		t.Error("large file still open after moving off it")
+	}
+}
+
+func TestPreviewGotoLineInSmallFiles(t *testing.T) {
+	m := selectionTestModel(t)
+	var text strings.Builder
+	for i := 1; i <= 200; i++ {
+		fmt.Fprintf(&text, "row %d\n", i)
+	}
+	os.WriteFile(filepath.Join(m.currentDir, "rows.txt"), []byte(text.String()), 0o644)
+	m.loadFiles()
+	for i, f := range m.filteredFiles {
+		if f.name == "rows.txt" {
+			m.cursor = i
+		}
+	}
+	m.updatePreview()
+
+	m = pressKeys(m, runeKey(':'))
+	m.textInput.SetValue("50")
+	m = pressKeys(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if line := m.previewLines[m.previewScroll]; line != "row 50" {
+		t.Errorf(":50 scrolled to %q", line)
+	}
+	m = pressKeys(m, runeKey('t'))
+	if m.previewScroll != m.previewMaxScroll() {
+		t.Errorf("t scrolled to %d of %d", m.previewScroll, m.previewMaxScroll())
+	}
+	m = pressKeys(m, runeKey('t'))
+	if m.previewScroll != 0 {
+		t.Errorf("t again scrolled to %d", m.previewScroll)
+	}
+}
@@ -55,5 +55,11 @@ func main() {
 		logger.Error("Program crashed: %v", err)
 		log.Fatal(err)
 	}
+	// Stop any copy still running and let it remove its partial output before exiting
+	if n := m.jobs.Active(); n > 0 {
+		logger.Info("Cancelling %d background job(s) on exit", n)
+		m.jobs.CancelAll()
+		m.jobs.Wait()
+	}
 	logger.Info("scout exited cleanly")
 }
@@ -8,16 +8,26 @@ import (
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	xansi "github.com/charmbracelet/x/ansi"
 
+	"github.com/LFroesch/scout/internal/archive"
 	"github.com/LFroesch/scout/internal/config"
+	"github.com/LFroesch/scout/internal/dataview"
 	"github.com/LFroesch/scout/internal/fileops"
 	"github.com/LFroesch/scout/internal/git"
+	"github.com/LFroesch/scout/internal/graphics"
+	"github.com/LFroesch/scout/internal/highlight"
+	"github.com/LFroesch/scout/internal/index"
+	"github.com/LFroesch/scout/internal/jobs"
+	"github.com/LFroesch/scout/internal/markdown"
 	"github.com/LFroesch/scout/internal/search"
 	"github.com/LFroesch/scout/internal/utils"
+	"github.com/LFroesch/scout/internal/watcher"
 )
 
 type previewUpdateMsg struct{}
@@ -43,6 +53,7 @@ type searchPartialMsg struct {
 	drive string // Current drive being searched (for ultra search)
 }
 type searchCompleteMsg struct{}
+type indexTickMsg struct{} // Redraws the search header while an index builds
 type searchErrorMsg struct{ err error }
 
 // File open result message
@@ -63,14 +74,13 @@ const (
 const (
 	maxPreviewItems     = 20                     // Maximum items to show in directory preview
 	maxHistoryEntries   = 100                    // Maximum navigation history entries
-	maxUndoStackSize    = 10                     // Maximum undo operations to remember
 	previewUpdateDelay  = 250 * time.Millisecond // Delay before updating preview after cursor move
 	searchDebounceDelay = 300 * time.Millisecond // Delay before triggering search after typing
 	minSearchChars      = 2                      // Minimum characters before triggering expensive searches
 	configSaveInterval  = 10                     // Save config every N directory visits
 	maxPreviewCacheSize = 50                     // Maximum number of file previews to cache
 	gitStatusCacheTTL   = 5 * time.Second        // Git status cache validity duration
-	helpContentLines    = 78                     // Total lines in help view (update if help content changes)
+	helpContentLines    = 97                     // Total lines in help view (update if help content changes)
 )
 
 type mode int
@@ -89,6 +99,19 @@ const (
 	modeGitCommit
 	modeHelp
 	modeErrorDialog
+	modeSelectGlob
+	modeConfirmBulkRename
+	modeBatchRename
+	modeJobs
+	modePasteConflict
+	modeHistory
+	modeTrash
+	modeArchive
+	modeExtract
+	modeCompress
+	modeHex
+	modePreviewLine
+	modeFollowPattern
 )
 
 type sortMode int
@@ -125,13 +148,15 @@ type fileItem struct {
 	modTime    time.Time
 	isSymlink  bool
 	linkTarget string
+	score      int // Fuzzy match score while this item is a search result
 }
 
 // Config type is now in internal/config package
 
 type previewCacheEntry struct {
-	content string
-	modTime time.Time
+	content  string
+	modTime  time.Time
+	rendered bool // Markdown rendered rather than shown as source
 }
 
 type model struct {
@@ -172,10 +197,7 @@ type model struct {
 	sortBy               sortMode              // Current sort mode
 	dualPane             bool                  // Dual pane mode enabled
 	activePane           int                   // 0 = left, 1 = right
-	rightDir             string                // Right pane directory
-	rightFiles           []fileItem            // Right pane files
-	rightCursor          int                   // Right pane cursor
-	rightScrollOffset    int                   // Right pane scroll offset
+	otherPane            paneState             // State of the inactive pane (swapped in on tab)
 	contentSearchResults []contentSearchResult // Ripgrep search results
 	contentSearchCursor  int                   // Cursor in content search results
 	previewPending       bool                  // Preview update pending
@@ -185,7 +207,7 @@ type model struct {
 	helpScroll           int                   // Help screen scroll position
 	errorMsg             string                // Error dialog message
 	errorDetails         string                // Detailed error info
-	undoStack            []undoItem            // Undo history
+	journal              *fileops.Journal      // Undo/redo history of file operations (see history.go)
 	visitedDirs          map[string]bool       // Track visited dirs for symlink loop detection
 	lastClickTime        time.Time             // Time of last mouse click
 	lastClickY           int                   // Y position of last click
@@ -195,17 +217,48 @@ type model struct {
 	searchInProgress     bool                  // Whether a search is currently running
 	scannedFiles         int                   // Number of files scanned in current search
 	searchNameOnly       bool                  // Match filename only vs full path
+	searchMatchMode      search.MatchMode      // Fuzzy, substring, glob or regex for filename searches
+	searchNoIgnore       bool                  // Include files excluded by .gitignore/.ignore in recursive searches
+	searchPatternErr     string                // Why the current glob/regex doesn't compile, shown in the header
 	searchResultsLocked  bool                  // Whether search results are locked for navigation
 	searchResultChan     chan tea.Msg          // Channel for receiving search progress (ultra search only)
 	searchShared         *sharedSearchResults  // Shared state polled by ticker (non-ultra searches)
 	previousMode         mode                  // Mode to return to after sub-mode (rename, delete, help)
-}
-
-type undoItem struct {
-	operation string // "delete"
-	path      string // Original path
-	wasDir    bool   // Was it a directory?
-	trashPath string // Path in trash (if applicable)
+	indexes              *index.Manager        // Persistent filename indexes (nil when disabled)
+	indexPolling         bool                  // Whether an indexTickMsg loop is running
+	ultraDrives          []string              // Drives covered by the last ultra search (for index status)
+	ultraScanned         map[string]int        // Files scanned per drive label in the running ultra search
+	selected             map[string]bool       // Marked paths for bulk operations (see selection.go)
+	visualActive         bool                  // Visual range mode: everything from visualAnchor to the cursor is selected
+	visualAnchor         string                // Path of the item where visual mode started
+	selectGlobOn         bool                  // Select-by-glob prompt marks (true) or unmarks (false) matches
+	bulkRename           *bulkRenameState      // Bulk rename in progress (see bulkrename.go)
+	batchRename          *batchRenameState     // Pattern rename dialog (see batchrename.go)
+	watcher              *watcher.Watcher      // Reports outside changes to the directories on screen (nil in tests)
+	dirsChanged          map[string]bool       // Watched dirs changed on disk, waiting to be reloaded
+	dirRetryPending      bool                  // A dirRefreshRetryMsg tick is scheduled
+	jobs                 *jobs.Manager         // Background copies and moves (see jobs.go)
+	jobPolling           bool                  // Whether a jobTickMsg loop is running
+	jobsCursor           int                   // Selected row in the jobs panel
+	pastePlan            *pastePlan            // Paste waiting on the conflict dialog (see paste.go)
+	historyCursor        int                   // Selected row in the history panel (newest first)
+	trashEntries         []fileops.TrashEntry  // Trash contents shown in the trash panel (see trash.go)
+	trashCursor          int                   // Selected row in the trash panel
+	trashConfirm         string                // "purge" or "empty" while the trash panel asks for y
+	archiveBrowser       *archiveBrowser       // Archive opened with enter (see archive.go)
+	extract              *extractState         // Extract dialog
+	compress             *compressState        // Compress dialog
+	previewSource        bool                  // Markdown and data files preview as source instead of rendered (m)
+	previewDepth         int                   // Levels data previews show before folding (< >), 0 for all
+	previewLevels        int                   // Levels the data preview on screen has, 0 if it doesn't fold
+	imageProtocol        graphics.Protocol     // How image previews are drawn (see images.go)
+	imagePreview         *imagePreview         // Picture last rendered for the preview
+	imageLoad            tea.Cmd               // Background load started by the last preview, for Update to run
+	imageDraw            *imageDraw            // Last frame the picture was drawn into
+	hexViewer            *hexViewer            // File opened in the hex viewer with H (see hex.go)
+	largePreview         *largePreview         // Window onto a large previewed file (see largefile.go)
+	follow               *followState          // File the preview follows as it grows (see follow.go)
+	followTicks          int                   // Identifies the follow's tick loop: bumped on every start
 }
 
 type contentSearchResult struct {
@@ -276,6 +329,19 @@ func initialModel(rootOverride string) model {
 	textIn.CharLimit = 256
 	textIn.Width = 50
 
+	var indexes *index.Manager
+	if !cfg.DisableIndex {
+		if indexDir, err := config.GetIndexDir(); err == nil {
+			indexes = index.NewManager(indexDir, cfg.SkipDirectories)
+			go indexes.Prune(index.MaxAge, index.MaxBytes)
+		}
+	}
+
+	journal := fileops.NewJournal("")
+	if historyPath, err := config.GetHistoryPath(); err == nil {
+		journal = fileops.LoadJournal(historyPath)
+	}
+
 	m := model{
 		mode:                 modeNormal,
 		currentDir:           currentDir,
@@ -306,12 +372,15 @@ func initialModel(rootOverride string) model {
 		sortBy:               sortByName,
 		dualPane:             false,
 		activePane:           0,
-		rightDir:             currentDir,
-		rightFiles:           []fileItem{},
-		rightCursor:          0,
-		rightScrollOffset:    0,
+		otherPane:            paneState{dir: currentDir, sortBy: sortByName, showHidden: cfg.ShowHidden},
 		visitedDirs:          make(map[string]bool),
 		doubleClickThreshold: 400 * time.Millisecond,
+		indexes:              indexes,
+		searchNoIgnore:       cfg.NoIgnore,
+		watcher:              watcher.New(),
+		jobs:                 jobs.NewManager(),
+		journal:              journal,
+		imageProtocol:        imageProtocol(cfg.ImageProtocol),
 	}
 
 	m.loadFiles()
@@ -319,19 +388,41 @@ func initialModel(rootOverride string) model {
 }
 
 func (m *model) loadFiles() {
-	entries, err := os.ReadDir(m.currentDir)
+	files, err := m.readDirItems(m.currentDir, m.showHidden)
 	if err != nil {
 		m.showError("CANNOT READ DIRECTORY", fmt.Sprintf("failed to read %s: %v", filepath.Base(m.currentDir), err))
 		return
 	}
+	m.files = files
+
+	// Sort based on current sort mode
+	m.sortFiles()
+
+	m.filteredFiles = m.files
+	m.ensureCursorInBounds() // Ensure cursor is valid after loading new files
+	m.pruneSelection()
+	m.updatePreview()
+	m.syncWatches()
+
+	// Update frecency when visiting a directory
+	m.updateFrecency(m.currentDir)
+}
+
+// readDirItems lists dir as fileItems (with a ".." entry when we can go up), unsorted.
+// Shared by the active pane (loadFiles) and the inactive dual-pane side (loadOtherPane).
+func (m *model) readDirItems(dir string, showHidden bool) ([]fileItem, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
 
-	m.files = []fileItem{}
+	files := []fileItem{}
 
 	// Add parent directory (only if we can go up)
-	parentDir := filepath.Dir(m.currentDir)
-	if m.currentDir != "/" && m.currentDir != m.config.RootPath &&
+	parentDir := filepath.Dir(dir)
+	if dir != "/" && dir != m.config.RootPath &&
 		(m.config.RootPath == "" || strings.HasPrefix(parentDir, m.config.RootPath)) {
-		m.files = append(m.files, fileItem{
+		files = append(files, fileItem{
 			path:  parentDir,
 			name:  "..",
 			isDir: true,
@@ -339,7 +430,7 @@ func (m *model) loadFiles() {
 	}
 
 	for _, entry := range entries {
-		if !m.showHidden && strings.HasPrefix(entry.Name(), ".") {
+		if !showHidden && strings.HasPrefix(entry.Name(), ".") {
 			continue
 		}
 
@@ -348,7 +439,7 @@ func (m *model) loadFiles() {
 			continue
 		}
 
-		itemPath := filepath.Join(m.currentDir, entry.Name())
+		itemPath := filepath.Join(dir, entry.Name())
 
 		// Use Lstat to get symlink info without following it
 		linfo, err := os.Lstat(itemPath)
@@ -368,7 +459,7 @@ func (m *model) loadFiles() {
 			if target, err := os.Readlink(itemPath); err == nil {
 				// Make absolute if relative
 				if !filepath.IsAbs(target) {
-					linkTarget = filepath.Join(m.currentDir, target)
+					linkTarget = filepath.Join(dir, target)
 				} else {
 					linkTarget = target
 				}
@@ -390,7 +481,7 @@ func (m *model) loadFiles() {
 			actualModTime = linfo.ModTime()
 		}
 
-		item := fileItem{
+		files = append(files, fileItem{
 			path:       itemPath,
 			name:       entry.Name(),
 			isDir:      actualIsDir,
@@ -398,56 +489,53 @@ func (m *model) loadFiles() {
 			modTime:    actualModTime,
 			isSymlink:  isSymlink,
 			linkTarget: linkTarget,
-		}
-
-		m.files = append(m.files, item)
+		})
 	}
 
-	// Sort based on current sort mode
-	m.sortFiles()
-
-	m.filteredFiles = m.files
-	m.ensureCursorInBounds() // Ensure cursor is valid after loading new files
-	m.updatePreview()
-
-	// Update frecency when visiting a directory
-	m.updateFrecency(m.currentDir)
+	return files, nil
 }
 
 func (m *model) sortFiles() {
-	sort.Slice(m.files, func(i, j int) bool {
+	sortItems(m.files, m.sortBy)
+}
+
+// sortItems sorts files in place: ".." first, then directories (except for size sort), then by mode
+func sortItems(files []fileItem, by sortMode) {
+	sort.Slice(files, func(i, j int) bool {
 		// Keep ".." at top always
-		if m.files[i].name == ".." {
+		if files[i].name == ".." {
 			return true
 		}
-		if m.files[j].name == ".." {
+		if files[j].name == ".." {
 			return false
 		}
 
 		// Directories first (except for size sort)
-		if m.sortBy != sortBySize && m.files[i].isDir != m.files[j].isDir {
-			return m.files[i].isDir
+		if by != sortBySize && files[i].isDir != files[j].isDir {
+			return files[i].isDir
 		}
 
 		// Apply sort mode
-		switch m.sortBy {
+		switch by {
 		case sortBySize:
-			return m.files[i].size > m.files[j].size
+			return files[i].size > files[j].size
 		case sortByDate:
-			return m.files[i].modTime.After(m.files[j].modTime)
+			return files[i].modTime.After(files[j].modTime)
 		case sortByType:
-			extI := strings.ToLower(filepath.Ext(m.files[i].name))
-			extJ := strings.ToLower(filepath.Ext(m.files[j].name))
+			extI := strings.ToLower(filepath.Ext(files[i].name))
+			extJ := strings.ToLower(filepath.Ext(files[j].name))
 			if extI != extJ {
 				return extI < extJ
 			}
-			return strings.ToLower(m.files[i].name) < strings.ToLower(m.files[j].name)
+			return strings.ToLower(files[i].name) < strings.ToLower(files[j].name)
 		default: // sortByName
-			return strings.ToLower(m.files[i].name) < strings.ToLower(m.files[j].name)
+			return strings.ToLower(files[i].name) < strings.ToLower(files[j].name)
 		}
 	})
 }
 
+// sortSearchResults orders search results by the current sort mode. Name sort means
+// relevance here: best fuzzy score first.
 func (m *model) sortSearchResults() {
 	// Create a helper struct to keep file and match info together
 	type fileMatchPair struct {
@@ -465,7 +553,7 @@ func (m *model) sortSearchResults() {
 	}
 
 	// Sort pairs based on sort mode
-	sort.Slice(pairs, func(i, j int) bool {
+	sort.SliceStable(pairs, func(i, j int) bool {
 		// Keep ".." at top always
 		if pairs[i].file.name == ".." {
 			return true
@@ -492,7 +580,16 @@ func (m *model) sortSearchResults() {
 				return extI < extJ
 			}
 			return strings.ToLower(pairs[i].file.name) < strings.ToLower(pairs[j].file.name)
-		default: // sortByName
+		default: // sortByName ranks filename results by fuzzy score; shorter then alphabetical names break ties
+			if m.currentSearchType == searchContent {
+				return strings.ToLower(pairs[i].file.name) < strings.ToLower(pairs[j].file.name)
+			}
+			if pairs[i].file.score != pairs[j].file.score {
+				return pairs[i].file.score > pairs[j].file.score
+			}
+			if len(pairs[i].file.name) != len(pairs[j].file.name) {
+				return len(pairs[i].file.name) < len(pairs[j].file.name)
+			}
 			return strings.ToLower(pairs[i].file.name) < strings.ToLower(pairs[j].file.name)
 		}
 	})
@@ -518,12 +615,9 @@ func (m *model) createDir(name string) error {
 	return fileops.CreateDir(m.currentDir, name)
 }
 
-func (m *model) copyFiles() error {
-	return fileops.CopyMultiple(m.clipboard, m.currentDir)
-}
-
-func (m *model) cutFiles() error {
-	return fileops.MoveMultiple(m.clipboard, m.currentDir)
+// copyOptions is the metadata copies keep beyond mode, times and symlinks, from the config
+func (m *model) copyOptions() fileops.CopyOptions {
+	return fileops.CopyOptions{Ownership: m.config.CopyOwnership, Xattrs: m.config.CopyXattrs}
 }
 
 func (m *model) showError(title string, details string) {
@@ -532,20 +626,13 @@ func (m *model) showError(title string, details string) {
 	m.mode = modeErrorDialog
 }
 
-func (m *model) addToUndo(item undoItem) {
-	m.undoStack = append(m.undoStack, item)
-	// Keep only last N items
-	if len(m.undoStack) > maxUndoStackSize {
-		m.undoStack = m.undoStack[1:]
-	}
-}
-
 func (m *model) searchFileContent(query string) error {
 	// Create dummy cancel channel for sync operation
 	cancelChan := make(chan struct{})
 	defer close(cancelChan)
 
-	results, err := search.SearchFileContent(query, m.currentDir, m.showHidden, cancelChan, nil, m.config.MaxResults, m.config.MaxDepth, m.config.SkipDirectories)
+	parsed := m.parseSearchQuery(query)
+	results, err := search.SearchFileContent(parsed.Text, m.currentDir, m.showHidden, cancelChan, nil, m.config.MaxResults, m.config.MaxDepth, m.config.SkipDirectories, &parsed)
 	if err != nil {
 		return err
 	}
@@ -571,6 +658,7 @@ func (m *model) updateFilter() tea.Cmd {
 	if query == "" {
 		m.filteredFiles = m.files
 		m.searchMatches = [][]int{}
+		m.searchPatternErr = ""
 		m.statusMsg = ""
 		m.cancelCurrentSearch()
 		m.loading = false
@@ -592,6 +680,27 @@ func (m *model) updateFilter() tea.Cmd {
 		return nil
 	}
 
+	// Bad regex/glob patterns are shown in the header instead of returning nothing
+	parsed := m.parseSearchQuery(query)
+	m.searchPatternErr = ""
+	if m.currentSearchType != searchContent {
+		if _, err := parsed.Matcher(parsed.Text); err != nil {
+			m.searchPatternErr = err.Error()
+			m.cancelCurrentSearch()
+			m.loading = false
+			return nil
+		}
+	}
+
+	// Filters narrow a content search but can't replace the pattern
+	if m.currentSearchType == searchContent && parsed.Text == "" {
+		m.statusMsg = "content search needs text besides filters"
+		m.statusExpiry = time.Now().Add(2 * time.Second)
+		m.cancelCurrentSearch()
+		m.loading = false
+		return nil
+	}
+
 	// For expensive searches, use async + debounce
 	if isExpensiveSearch {
 		m.cancelCurrentSearch() // Cancel any ongoing search
@@ -609,23 +718,40 @@ func (m *model) updateFilter() tea.Cmd {
 	return nil
 }
 
+// parseSearchQuery parses the search input and applies the selected match mode and ignore toggle
+func (m *model) parseSearchQuery(query string) search.Query {
+	parsed := search.ParseQuery(query)
+	parsed.Mode = m.searchMatchMode
+	parsed.NoIgnore = m.searchNoIgnore
+	return parsed
+}
+
 func (m *model) searchCurrentDir(query string) {
-	// Build list of file names for substring matching
-	names := make([]string, len(m.files))
-	for i, file := range m.files {
-		names[i] = file.name
+	parsed := m.parseSearchQuery(query)
+	matcher, err := parsed.Matcher(parsed.Text)
+	if err != nil {
+		return // Reported in the header by updateFilter
 	}
 
-	// Use search module for substring matching
-	matches := search.SubstringMatchNames(query, names)
-
 	m.filteredFiles = []fileItem{}
 	m.searchMatches = [][]int{}
 
-	for _, match := range matches {
-		m.filteredFiles = append(m.filteredFiles, m.files[match.Index])
-		m.searchMatches = append(m.searchMatches, match.MatchedIndexes)
+	for _, file := range m.files {
+		if file.name == ".." && parsed.Text == "" {
+			continue // Filter-only queries list real entries
+		}
+		if !parsed.Matches(file.name, file.isDir, file.size, file.modTime) {
+			continue
+		}
+		score, positions, ok := matcher.Match(file.name)
+		if !ok {
+			continue
+		}
+		file.score = score
+		m.filteredFiles = append(m.filteredFiles, file)
+		m.searchMatches = append(m.searchMatches, positions)
 	}
+	m.sortSearchResults()
 }
 
 func (m *model) recursiveSearchFiles(query string) {
@@ -633,7 +759,8 @@ func (m *model) recursiveSearchFiles(query string) {
 	cancelChan := make(chan struct{})
 	defer close(cancelChan)
 
-	results, matches := search.RecursiveSearchFiles(query, m.currentDir, m.showHidden, utils.ShouldIgnore, cancelChan, nil, nil, m.config.MaxResults, m.config.MaxDepth, m.config.MaxFilesScanned, m.config.SkipDirectories, m.searchNameOnly)
+	parsed := m.parseSearchQuery(query)
+	results, matches := search.RecursiveSearchFiles(parsed.Text, m.currentDir, m.showHidden, utils.ShouldIgnore, cancelChan, nil, nil, m.config.MaxResults, m.config.MaxDepth, m.config.MaxFilesScanned, m.config.SkipDirectories, m.searchNameOnly, &parsed)
 
 	// Convert search results to fileItems
 	m.filteredFiles = []fileItem{}
@@ -663,25 +790,47 @@ func (m *model) ultraSearchFiles(query string) {
 	cancelChan := make(chan struct{})
 	defer close(cancelChan)
 
-	// Search across all drives
-	for _, drive := range drives {
-		results, matches := search.RecursiveSearchFiles(query, drive, m.showHidden, utils.ShouldIgnore, cancelChan, nil, nil, m.config.MaxResults, m.config.MaxDepth, m.config.MaxFilesScanned, m.config.SkipDirectories, m.searchNameOnly)
+	// Search all drives at once, then append in drive order so the listing is stable
+	parsed := m.parseSearchQuery(query)
+	type driveResult struct {
+		results []search.Result
+		matches []search.MatchResult
+	}
+	perDrive := make([]driveResult, len(drives))
+	var wg sync.WaitGroup
+	for i, drive := range drives {
+		wg.Add(1)
+		go func(i int, drive string) {
+			defer wg.Done()
+			results, matches := search.RecursiveSearchFiles(parsed.Text, drive, m.showHidden, utils.ShouldIgnore, cancelChan, nil, nil, m.config.MaxResults, m.config.MaxDepth, m.config.MaxFilesScanned, m.config.SkipDirectories, m.searchNameOnly, &parsed)
+			perDrive[i] = driveResult{results: results, matches: matches}
+		}(i, drive)
+	}
+	wg.Wait()
 
-		for i, result := range results {
+	for d, drive := range drives {
+		driveLabel := utils.GetDriveLabel(drive)
+		prefixLen := utf8.RuneCountInString(fmt.Sprintf("[%s] ", driveLabel))
+		for i, result := range perDrive[d].results {
 			// Add drive label prefix to display name for clarity
-			driveLabel := utils.GetDriveLabel(drive)
 			displayName := fmt.Sprintf("[%s] %s", driveLabel, result.DisplayName)
 
-			m.filteredFiles = append(m.filteredFiles, fileItem{
+			item := fileItem{
 				path:    result.Path,
 				name:    displayName,
 				isDir:   result.IsDir,
 				size:    result.Size,
 				modTime: result.ModTime,
-			})
-			if i < len(matches) {
-				m.searchMatches = append(m.searchMatches, matches[i].MatchedIndexes)
 			}
+			var positions []int
+			if i < len(perDrive[d].matches) {
+				item.score = perDrive[d].matches[i].Score
+				for _, pos := range perDrive[d].matches[i].MatchedIndexes {
+					positions = append(positions, pos+prefixLen)
+				}
+			}
+			m.filteredFiles = append(m.filteredFiles, item)
+			m.searchMatches = append(m.searchMatches, positions)
 		}
 	}
 }
@@ -739,6 +888,12 @@ func (m *model) performAsyncSearch(query string) tea.Cmd {
 	maxFilesScanned := m.config.MaxFilesScanned
 	skipDirectories := m.config.SkipDirectories
 	nameOnly := m.searchNameOnly
+	indexes := m.indexes
+
+	// Split "ext:go size:>10M foo" into the text to match and the filters to apply while walking
+	parsed := m.parseSearchQuery(query)
+	filter := &parsed
+	query = parsed.Text
 
 	// Clear previous results and show loading
 	m.filteredFiles = []fileItem{}
@@ -751,12 +906,14 @@ func (m *model) performAsyncSearch(query string) tea.Cmd {
 		m.searchResultChan = resultChan
 		m.searchShared = nil
 
+		drives := utils.GetMountedDrives()
+		m.ultraDrives = drives
+		m.ultraScanned = make(map[string]int, len(drives))
+
 		go func() {
 			var files []fileItem
 			var matches [][]int
 
-			drives := utils.GetMountedDrives()
-
 			type driveResult struct {
 				files   []fileItem
 				matches [][]int
@@ -785,20 +942,24 @@ func (m *model) performAsyncSearch(query string) tea.Cmd {
 						}
 					}
 
-					results, matchResults := search.RecursiveSearchFiles(query, drivePath, showHidden, utils.ShouldIgnore, cancelChan, driveProgress, nil, maxResults, maxDepth, maxFilesScanned, skipDirectories, nameOnly)
+					results, matchResults := recursiveNameSearch(indexes, query, drivePath, showHidden, cancelChan, driveProgress, nil, maxResults, maxDepth, maxFilesScanned, skipDirectories, nameOnly, filter)
 
 					var driveFiles []fileItem
 					var driveMatches [][]int
 					for i, result := range results {
 						displayName := fmt.Sprintf("[%s] %s", driveLabel, result.DisplayName)
-						prefixLen := len(fmt.Sprintf("[%s] ", driveLabel))
-						driveFiles = append(driveFiles, fileItem{
+						prefixLen := utf8.RuneCountInString(fmt.Sprintf("[%s] ", driveLabel))
+						item := fileItem{
 							path:    result.Path,
 							name:    displayName,
 							isDir:   result.IsDir,
 							size:    result.Size,
 							modTime: result.ModTime,
-						})
+						}
+						if i < len(matchResults) {
+							item.score = matchResults[i].Score
+						}
+						driveFiles = append(driveFiles, item)
 						if i < len(matchResults) {
 							adjustedMatches := make([]int, len(matchResults[i].MatchedIndexes))
 							for j, pos := range matchResults[i].MatchedIndexes {
@@ -829,7 +990,7 @@ func (m *model) performAsyncSearch(query string) tea.Cmd {
 			resultChan <- searchCompleteMsg{}
 		}()
 
-		return waitForSearchMsg(resultChan)
+		return tea.Batch(waitForSearchMsg(resultChan), m.startIndexPolling())
 	}
 
 	// Non-ultra searches: goroutine writes to shared state, UI polls every 50ms
@@ -852,7 +1013,7 @@ func (m *model) performAsyncSearch(query string) tea.Cmd {
 				})
 				shared.mu.Unlock()
 			}
-			_, err = search.SearchFileContent(query, currentDir, showHidden, cancelChan, onResult, maxResults, maxDepth, skipDirectories)
+			_, err = search.SearchFileContent(query, currentDir, showHidden, cancelChan, onResult, maxResults, maxDepth, skipDirectories, filter)
 
 		default: // searchRecursive and searchFilename (recursive fallback)
 			onResult := func(result search.Result, mr search.MatchResult) {
@@ -863,11 +1024,12 @@ func (m *model) performAsyncSearch(query string) tea.Cmd {
 					isDir:   result.IsDir,
 					size:    result.Size,
 					modTime: result.ModTime,
+					score:   mr.Score,
 				})
 				shared.matches = append(shared.matches, mr.MatchedIndexes)
 				shared.mu.Unlock()
 			}
-			search.RecursiveSearchFiles(query, currentDir, showHidden, utils.ShouldIgnore, cancelChan, nil, onResult, maxResults, maxDepth, maxFilesScanned, skipDirectories, nameOnly)
+			recursiveNameSearch(indexes, query, currentDir, showHidden, cancelChan, nil, onResult, maxResults, maxDepth, maxFilesScanned, skipDirectories, nameOnly, filter)
 		}
 
 		shared.mu.Lock()
@@ -876,7 +1038,84 @@ func (m *model) performAsyncSearch(query string) tea.Cmd {
 		shared.mu.Unlock()
 	}()
 
-	return pollSearch(shared)
+	if searchType == searchContent {
+		return pollSearch(shared)
+	}
+	return tea.Batch(pollSearch(shared), m.startIndexPolling())
+}
+
+// recursiveNameSearch answers a recursive filename search from the persistent index when
+// one covers dir (no scan cap, near-instant), and walks the disk otherwise. Either way it
+// asks the index manager to build or refresh an index so the next search is fast; for
+// ultra search that's one per drive (except "/").
+func recursiveNameSearch(indexes *index.Manager, query, dir string, showHidden bool, cancelChan <-chan struct{}, onProgress func(int), onResult func(search.Result, search.MatchResult), maxResults, maxDepth, maxFilesScanned int, skipDirs []string, nameOnly bool, filter *search.Query) ([]search.Result, []search.MatchResult) {
+	idx := indexes.Open(dir)
+	indexes.Ensure(dir)
+	if idx != nil {
+		return idx.Search(query, dir, index.SearchOptions{
+			ShowHidden:   showHidden,
+			ShouldIgnore: utils.ShouldIgnore,
+			MaxResults:   maxResults,
+			MaxDepth:     maxDepth,
+			NameOnly:     nameOnly,
+			Cancel:       cancelChan,
+			OnResult:     onResult,
+			Filter:       filter,
+		})
+	}
+	return search.RecursiveSearchFiles(query, dir, showHidden, utils.ShouldIgnore, cancelChan, onProgress, onResult, maxResults, maxDepth, maxFilesScanned, skipDirs, nameOnly, filter)
+}
+
+// startIndexPolling starts the header refresh loop while an index builds in the background
+func (m *model) startIndexPolling() tea.Cmd {
+	if m.indexPolling || m.indexes == nil {
+		return nil
+	}
+	m.indexPolling = true
+	return indexTick()
+}
+
+func indexTick() tea.Cmd {
+	return tea.Tick(500*time.Millisecond, func(t time.Time) tea.Msg {
+		return indexTickMsg{}
+	})
+}
+
+// rebuildIndex discards and rebuilds the index(es) behind the current search scope
+func (m *model) rebuildIndex() tea.Cmd {
+	if m.indexes == nil {
+		m.statusMsg = "filename index is disabled (disable_index in config)"
+		m.statusExpiry = time.Now().Add(3 * time.Second)
+		return nil
+	}
+
+	var roots []string
+	if m.currentSearchType == searchUltra {
+		drives := m.ultraDrives
+		if len(drives) == 0 {
+			drives = utils.GetMountedDrives()
+		}
+		for _, drive := range drives {
+			if root := m.indexes.Rebuild(drive); root != "" {
+				roots = append(roots, root)
+			}
+		}
+	} else if root := m.indexes.Rebuild(m.currentDir); root != "" {
+		roots = append(roots, root)
+	}
+	if len(roots) == 0 {
+		m.statusMsg = "/ isn't indexed: search a drive or directory under it"
+		m.statusExpiry = time.Now().Add(3 * time.Second)
+		return nil
+	}
+
+	if len(roots) == 1 {
+		m.statusMsg = fmt.Sprintf("rebuilding index: %s", roots[0])
+	} else {
+		m.statusMsg = fmt.Sprintf("rebuilding %d drive indexes", len(roots))
+	}
+	m.statusExpiry = time.Now().Add(3 * time.Second)
+	return m.startIndexPolling()
 }
 
 // waitForSearchMsg returns a command that waits for the next search message
@@ -920,6 +1159,8 @@ func (m *model) moveCursor(newPos int) tea.Cmd {
 
 func (m *model) updatePreview() {
 	if !m.showPreview || len(m.filteredFiles) == 0 || m.cursor >= len(m.filteredFiles) {
+		m.closeLargePreview()
+		m.stopFollow()
 		m.previewContent = ""
 		m.previewLines = []string{}
 		m.previewScroll = 0
@@ -929,6 +1170,14 @@ func (m *model) updatePreview() {
 	}
 
 	selected := m.filteredFiles[m.cursor]
+	if lp := m.largePreview; lp != nil && lp.path != selected.path {
+		m.closeLargePreview()
+	}
+	if fs := m.follow; fs != nil && fs.path != selected.path {
+		m.stopFollow()
+	}
+	scroll := m.previewScroll
+	m.previewLevels = 0 // Until a data preview says otherwise
 	if selected.isDir {
 		m.previewContent = m.previewDirectory(selected.path)
 	} else {
@@ -940,6 +1189,13 @@ func (m *model) updatePreview() {
 
 	m.previewLines = m.wrapTextToLines(m.previewContent, previewWidth)
 	m.previewScroll = 0
+	if fs := m.follow; fs != nil {
+		// A followed file stays at the end, or where it was scrolled to
+		m.previewScroll = min(scroll, m.previewMaxScroll())
+		if fs.pinned {
+			m.previewScroll = m.previewMaxScroll()
+		}
+	}
 	m.previewCursor = m.cursor
 	m.previewPending = false
 }
@@ -958,23 +1214,10 @@ func (m *model) wrapTextToLines(text string, width int) []string {
 		if lipgloss.Width(line) <= width {
 			wrappedLines = append(wrappedLines, line)
 		} else {
-			// Wrap long lines character by character to handle multi-byte chars
-			runes := []rune(line)
-			currentLine := ""
-			for _, r := range runes {
-				testLine := currentLine + string(r)
-				if lipgloss.Width(testLine) > width {
-					if currentLine != "" {
-						wrappedLines = append(wrappedLines, currentLine)
-					}
-					currentLine = string(r)
-				} else {
-					currentLine = testLine
-				}
-			}
-			if currentLine != "" {
-				wrappedLines = append(wrappedLines, currentLine)
-			}
+			// Wrap long lines at spaces by display width (words longer than the width are
+			// broken), never splitting a wide char or an escape code: highlighted and
+			// rendered previews are full of them
+			wrappedLines = append(wrappedLines, strings.Split(xansi.Wrap(line, width, ""), "\n")...)
 		}
 	}
 
@@ -1035,14 +1278,28 @@ func (m *model) previewFile(path string) string {
 		preview.WriteString("Git: Modified\n")
 	}
 
+	// Images are drawn whatever their size (see images.go)
+	if graphics.IsImage(path) {
+		m.previewImage(path, info, &preview)
+		return preview.String()
+	}
+
 	preview.WriteString("\n")
 
-	// Check if file is too large
-	if info.Size() > 1024*1024 {
-		preview.WriteString("─── File Metadata ───\n\n")
-		preview.WriteString(fmt.Sprintf("Type: Large File (%s)\n", utils.FormatFileSize(info.Size())))
-		preview.WriteString(fmt.Sprintf("Full Path: %s\n", path))
-		preview.WriteString("\n(File too large - preview disabled for performance)")
+	// A followed file shows its last lines as they're written (see follow.go)
+	if fs := m.follow; fs != nil && fs.path == path {
+		m.followPreview(&preview)
+		return preview.String()
+	}
+
+	if archive.Detect(path) != archive.Unknown {
+		preview.WriteString(m.archivePreview(path, info))
+		return preview.String()
+	}
+
+	// Binaries show their first bytes in hex, whatever their size (see hex.go)
+	if showsHex(path) {
+		m.hexPreview(path, info, &preview)
 		return preview.String()
 	}
 
@@ -1055,7 +1312,7 @@ func (m *model) previewFile(path string) string {
 		case utils.FileTypeMedia:
 			preview.WriteString(fmt.Sprintf("Type: Media File (%s)\n", strings.ToUpper(strings.TrimPrefix(filepath.Ext(path), "."))))
 			preview.WriteString(fmt.Sprintf("Full Path: %s\n", path))
-			preview.WriteString("\n(Image/Video/Audio files cannot be previewed in terminal)")
+			preview.WriteString("\n(Video, audio and this image format cannot be previewed in terminal)")
 		case utils.FileTypeDocument:
 			preview.WriteString(fmt.Sprintf("Type: Document (%s)\n", strings.ToUpper(strings.TrimPrefix(filepath.Ext(path), "."))))
 			preview.WriteString(fmt.Sprintf("Full Path: %s\n", path))
@@ -1064,18 +1321,10 @@ func (m *model) previewFile(path string) string {
 			preview.WriteString(fmt.Sprintf("Type: Archive (%s)\n", strings.ToUpper(strings.TrimPrefix(filepath.Ext(path), "."))))
 			preview.WriteString(fmt.Sprintf("Full Path: %s\n", path))
 			preview.WriteString("\n(Archive contents not displayed)")
-		case utils.FileTypeDatabase:
-			preview.WriteString(fmt.Sprintf("Type: Database (%s)\n", strings.ToUpper(strings.TrimPrefix(filepath.Ext(path), "."))))
-			preview.WriteString(fmt.Sprintf("Full Path: %s\n", path))
-			preview.WriteString("\n(Binary database file)")
 		case utils.FileTypeFont:
 			preview.WriteString(fmt.Sprintf("Type: Font (%s)\n", strings.ToUpper(strings.TrimPrefix(filepath.Ext(path), "."))))
 			preview.WriteString(fmt.Sprintf("Full Path: %s\n", path))
 			preview.WriteString("\n(Font files cannot be previewed)")
-		case utils.FileTypeExecutable:
-			preview.WriteString(fmt.Sprintf("Type: Executable/Compiled Binary\n"))
-			preview.WriteString(fmt.Sprintf("Full Path: %s\n", path))
-			preview.WriteString("\n(Binary file - preview unavailable)")
 		default:
 			preview.WriteString(fmt.Sprintf("Type: %s\n", strings.ToUpper(strings.TrimPrefix(filepath.Ext(path), "."))))
 			preview.WriteString(fmt.Sprintf("Full Path: %s\n", path))
@@ -1084,8 +1333,20 @@ func (m *model) previewFile(path string) string {
 		return preview.String()
 	}
 
+	// Large files show a window of lines, read as it moves (see largefile.go)
+	if info.Size() > largeFileSize {
+		m.largeFilePreview(path, info, &preview)
+		return preview.String()
+	}
+
+	// Data files show as a tree, table or outline (see datafiles.go)
+	if p := dataview.For(path); p != nil && !m.previewSource && m.dataPreview(p, path, &preview) {
+		return preview.String()
+	}
+
 	// Check preview cache
-	if cached, ok := m.previewCache[path]; ok && cached.modTime.Equal(info.ModTime()) {
+	rendered := m.rendersMarkdown(path)
+	if cached, ok := m.previewCache[path]; ok && cached.modTime.Equal(info.ModTime()) && cached.rendered == rendered {
 		preview.WriteString(cached.content)
 		return preview.String()
 	}
@@ -1097,22 +1358,123 @@ func (m *model) previewFile(path string) string {
 		return preview.String()
 	}
 
+	// Markdown is rendered, source code is coloured (ANSI, cached with the rest); big
+	// files stay plain text
 	contentStr := string(content)
+	if rendered {
+		contentStr = markdown.Render(contentStr)
+	} else if highlighted, ok := highlight.Code(filepath.Base(path), contentStr); ok {
+		contentStr = highlighted
+	}
 	preview.WriteString(contentStr)
 
 	// Add to cache
-	m.addToPreviewCache(path, contentStr, info.ModTime())
+	m.addToPreviewCache(path, previewCacheEntry{content: contentStr, modTime: info.ModTime(), rendered: rendered})
 
 	return preview.String()
 }
 
-// addToPreviewCache adds content to preview cache with LRU eviction
-func (m *model) addToPreviewCache(path, content string, modTime time.Time) {
-	// Add entry
-	m.previewCache[path] = previewCacheEntry{
-		content: content,
-		modTime: modTime,
+// rendersMarkdown reports whether path previews as rendered Markdown (rather than source)
+func (m *model) rendersMarkdown(path string) bool {
+	return !m.previewSource && markdown.Is(path)
+}
+
+// togglePreviewSource flips Markdown and data file previews between rendered and source
+func (m *model) togglePreviewSource() {
+	m.previewSource = !m.previewSource
+	if m.previewSource {
+		m.statusMsg = "preview: source"
+	} else {
+		m.statusMsg = "preview: rendered"
+	}
+	m.statusExpiry = time.Now().Add(2 * time.Second)
+	m.updatePreview()
+}
+
+// previewMaxScroll is the furthest the preview scrolls: its last line at the bottom
+func (m *model) previewMaxScroll() int {
+	availableHeight := m.height - uiOverhead
+	if availableHeight < 3 {
+		availableHeight = 3
+	}
+	contentHeight := availableHeight - 1
+	if contentHeight < 1 {
+		contentHeight = 1
+	}
+	return max(len(m.previewLines)-contentHeight, 0)
+}
+
+// scrollPreview moves the preview delta lines: the scroll offset for files read whole,
+// the window for large ones (see largefile.go)
+func (m *model) scrollPreview(delta int) {
+	if !m.showPreview {
+		return
+	}
+	if m.activeLargePreview() != nil {
+		m.scrollLargePreview(delta)
+		return
+	}
+	m.previewScroll = max(0, min(m.previewScroll+delta, m.previewMaxScroll()))
+	if m.follow != nil {
+		m.follow.pinned = m.previewScroll == m.previewMaxScroll()
+	}
+}
+
+// togglePreviewEnd jumps the preview to the end of the file, or back to the start (t)
+func (m *model) togglePreviewEnd() {
+	if !m.showPreview {
+		return
+	}
+	if lp := m.activeLargePreview(); lp != nil {
+		lp.tail = !lp.tail
+		if !lp.tail {
+			lp.top, lp.line = 0, 0
+		}
+		m.updatePreview()
+		return
 	}
+	if m.previewScroll < m.previewMaxScroll() {
+		m.previewScroll = m.previewMaxScroll()
+	} else {
+		m.previewScroll = 0
+	}
+	if m.follow != nil {
+		m.follow.pinned = m.previewScroll == m.previewMaxScroll()
+	}
+}
+
+// gotoPreviewLine scrolls the preview to line n (from 1) of the file
+func (m *model) gotoPreviewLine(n int) {
+	if lp := m.activeLargePreview(); lp != nil {
+		off, line, err := lp.file.Offset(n - 1)
+		if err != nil {
+			m.statusMsg = fmt.Sprintf("cannot read file: %v", err)
+			m.statusExpiry = time.Now().Add(3 * time.Second)
+			return
+		}
+		lp.tail = false
+		lp.top, lp.line = off, line
+		m.updatePreview()
+		return
+	}
+	// The file's lines follow the header and a blank line
+	header, body, ok := strings.Cut(m.previewContent, "\n\n")
+	if !ok {
+		return
+	}
+	var before strings.Builder
+	before.WriteString(header + "\n\n")
+	for _, line := range strings.Split(body, "\n")[:min(max(n-1, 0), strings.Count(body, "\n")+1)] {
+		before.WriteString(line + "\n")
+	}
+	// before ends where line n starts: the wrapped lines up to it, less its own empty one
+	m.previewScroll = min(len(m.wrapTextToLines(before.String(), (m.width/2)-4))-1, m.previewMaxScroll())
+}
+
+// addToPreviewCache adds an entry to the preview cache with LRU eviction
+func (m *model) addToPreviewCache(path string, entry previewCacheEntry) {
+	// Add entry
+	m.previewCache[path] = entry
 
 	// Update LRU order - remove if exists, then append
 	for i, p := range m.previewCacheOrder {
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/LFroesch/scout/internal/git"
+)
+
+// paneState holds everything that is per-pane in dual-pane mode, selection included. The
+// active pane always lives in the model's top-level fields (currentDir, files, cursor, ...)
+// so every existing key handler works unchanged; the inactive pane is parked here and
+// swapped in on tab.
+type paneState struct {
+	dir           string
+	files         []fileItem
+	filteredFiles []fileItem
+	cursor        int
+	scrollOffset  int
+	sortBy        sortMode
+	showHidden    bool
+	gitModified   map[string]bool
+	gitBranch     string
+	selected      map[string]bool
+	visualActive  bool
+	visualAnchor  string
+}
+
+// exchangePaneState swaps the active pane fields with the parked inactive pane.
+// It does not touch activePane, so callers can use it to render the other side.
+func (m *model) exchangePaneState() {
+	active := paneState{
+		dir:           m.currentDir,
+		files:         m.files,
+		filteredFiles: m.filteredFiles,
+		cursor:        m.cursor,
+		scrollOffset:  m.scrollOffset,
+		sortBy:        m.sortBy,
+		showHidden:    m.showHidden,
+		gitModified:   m.gitModified,
+		gitBranch:     m.gitBranch,
+		selected:      m.selected,
+		visualActive:  m.visualActive,
+		visualAnchor:  m.visualAnchor,
+	}
+
+	other := m.otherPane
+	m.currentDir = other.dir
+	m.files = other.files
+	m.filteredFiles = other.filteredFiles
+	if m.filteredFiles == nil {
+		m.filteredFiles = m.files
+	}
+	m.cursor = other.cursor
+	m.scrollOffset = other.scrollOffset
+	m.sortBy = other.sortBy
+	m.showHidden = other.showHidden
+	m.gitModified = other.gitModified
+	if m.gitModified == nil {
+		m.gitModified = make(map[string]bool)
+	}
+	m.gitBranch = other.gitBranch
+	m.selected = other.selected
+	m.visualActive = other.visualActive
+	m.visualAnchor = other.visualAnchor
+
+	m.otherPane = active
+}
+
+// switchPane makes the inactive pane the active one
+func (m *model) switchPane() {
+	if !m.dualPane {
+		return
+	}
+	m.exchangePaneState()
+	m.activePane = 1 - m.activePane
+	m.previewScroll = 0
+	m.ensureCursorInBounds()
+	m.updatePreview()
+}
+
+// toggleDualPane turns the two-pane commander layout on or off.
+// Turning it off keeps whichever pane was active as the single view.
+func (m *model) toggleDualPane() {
+	m.dualPane = !m.dualPane
+	if !m.dualPane {
+		m.activePane = 0
+		m.updatePreview()
+		m.syncWatches()
+		return
+	}
+
+	m.activePane = 0
+	if m.otherPane.dir == "" {
+		m.otherPane.dir = m.currentDir
+		m.otherPane.sortBy = m.sortBy
+		m.otherPane.showHidden = m.showHidden
+	}
+	m.loadOtherPane()
+}
+
+// loadOtherPane (re)reads the inactive pane's directory, keeping its cursor in range.
+// Falls back to the active directory if the other pane's directory has gone away.
+func (m *model) loadOtherPane() {
+	files, err := m.readDirItems(m.otherPane.dir, m.otherPane.showHidden)
+	if err != nil {
+		m.otherPane.dir = m.currentDir
+		files, err = m.readDirItems(m.otherPane.dir, m.otherPane.showHidden)
+		if err != nil {
+			return
+		}
+	}
+	sortItems(files, m.otherPane.sortBy)
+	m.otherPane.files = files
+	m.otherPane.filteredFiles = files
+	if m.otherPane.cursor >= len(files) {
+		m.otherPane.cursor = len(files) - 1
+	}
+	if m.otherPane.cursor < 0 {
+		m.otherPane.cursor = 0
+	}
+	m.otherPane.gitModified = git.GetModifiedFiles(m.otherPane.dir)
+	m.otherPane.gitBranch = git.GetBranch(m.otherPane.dir)
+	m.syncWatches()
+}
+
+// refreshPanes reloads the active pane and, in dual-pane mode, the inactive one too.
+// Used after file operations that may touch either side.
+func (m *model) refreshPanes() {
+	m.loadFiles()
+	if m.dualPane {
+		m.loadOtherPane()
+	}
+}
+
+// pasteTargetDir picks the paste destination. In dual-pane mode, files staged from the
+// active pane go to the other pane (commander-style); otherwise they land in the active pane.
+func (m *model) pasteTargetDir() string {
+	if !m.dualPane || len(m.clipboard) == 0 {
+		return m.currentDir
+	}
+	for _, src := range m.clipboard {
+		if filepath.Dir(src) != m.currentDir {
+			return m.currentDir
+		}
+	}
+	return m.otherPane.dir
+}
+
+// pasteClipboard queues the clipboard to be copied or moved into pasteTargetDir; the
+// panes refresh when the job finishes (see jobs.go)
+func (m *model) pasteClipboard() tea.Cmd {
+	return m.submitPaste(m.pasteTargetDir())
+}
+
+// renderOtherPane renders the inactive pane's file list without making it active
+func (m *model) renderOtherPane(width int) string {
+	m.exchangePaneState()
+	defer m.exchangePaneState()
+	return m.renderFileList(width, false)
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestDualPaneTabSwitchesActivePane(t *testing.T) {
+	left := t.TempDir()
+	right := t.TempDir()
+	if err := os.WriteFile(filepath.Join(right, "only-right.txt"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := testModelForUpdate(t, left)
+	m.mode = modeNormal
+	m.loadFiles()
+	m.otherPane = paneState{dir: right}
+	m.toggleDualPane()
+
+	gotModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	got := gotModel.(*model)
+
+	if got.activePane != 1 {
+		t.Fatalf("expected tab to activate right pane, got %d", got.activePane)
+	}
+	if got.currentDir != right {
+		t.Fatalf("expected active dir %q, got %q", right, got.currentDir)
+	}
+	if got.otherPane.dir != left {
+		t.Fatalf("expected left pane to be parked, got %q", got.otherPane.dir)
+	}
+	if got.mode != modeNormal {
+		t.Fatalf("expected tab in dual-pane mode not to enter search")
+	}
+}
+
+func TestDualPaneSortAndHiddenArePerPane(t *testing.T) {
+	dir := t.TempDir()
+	m := testModelForUpdate(t, dir)
+	m.mode = modeNormal
+	m.showHidden = false
+	m.loadFiles()
+	m.otherPane = paneState{dir: dir}
+	m.toggleDualPane()
+
+	gotModel, _ := m.Update(runeKey('.'))
+	got := gotModel.(*model)
+	gotModel, _ = got.Update(runeKey('S'))
+	got = gotModel.(*model)
+
+	if !got.showHidden || got.sortBy != sortBySize {
+		t.Fatalf("expected active pane to toggle hidden and sort")
+	}
+	if got.otherPane.showHidden || got.otherPane.sortBy != sortByName {
+		t.Fatalf("expected other pane settings to be untouched")
+	}
+}
+
+func TestDualPanePasteGoesToOtherPane(t *testing.T) {
+	left := t.TempDir()
+	right := t.TempDir()
+	src := filepath.Join(left, "note.txt")
+	if err := os.WriteFile(src, []byte("hello"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := testModelForUpdate(t, left)
+	m.mode = modeNormal
+	m.loadFiles()
+	m.otherPane = paneState{dir: right}
+	m.toggleDualPane()
+	m.clipboard = []string{src}
+	m.clipboardOp = opCopy
+
+	if got := m.pasteTargetDir(); got != right {
+		t.Fatalf("pasteTargetDir() = %q, want %q", got, right)
+	}
+
+	gotModel, _ := m.Update(runeKey('p'))
+	got := gotModel.(*model)
+	finishJobs(got)
+
+	if _, err := os.Stat(filepath.Join(right, "note.txt")); err != nil {
+		t.Fatalf("expected file pasted into other pane: %v", err)
+	}
+	found := false
+	for _, f := range got.otherPane.files {
+		if f.name == "note.txt" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected other pane to refresh after paste")
+	}
+
+	// Once the active pane is the destination, paste lands in the active pane
+	got.switchPane()
+	if target := got.pasteTargetDir(); target != right {
+		t.Fatalf("expected paste into active pane when clipboard came from the other side, got %q", target)
+	}
+}
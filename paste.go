@@ -0,0 +1,195 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/LFroesch/scout/internal/fileops"
+	"github.com/LFroesch/scout/internal/jobs"
+)
+
+// Paste: the clipboard is matched against the destination first. Names that are free go
+// straight into the job; clashes wait in the conflict dialog for overwrite / skip / keep
+// both / overwrite-if-newer, optionally applied to all the rest. A copy into the
+// directory it came from always keeps both.
+
+// pasteConflict is a clipboard item whose name is taken in the destination
+type pasteConflict struct {
+	src      string
+	dst      string
+	srcInfo  os.FileInfo
+	dstInfo  os.FileInfo // nil when the clash is with another item of the same paste
+	sameFile bool        // dst is src under another path (a symlinked or hard-linked directory)
+}
+
+// pastePlan is a paste being resolved before it's queued
+type pastePlan struct {
+	kind     jobs.Kind
+	destDir  string
+	items    []jobs.Item
+	pending  []pasteConflict
+	reserved map[string]bool // Destination paths already handed out to items
+	applyAll bool            // The next decision covers every remaining conflict
+	skipped  int
+	failed   []string // Items that couldn't be given a name to keep both, with why
+	prevMode mode     // Where the dialog returns to (normal, or locked search results)
+}
+
+// submitPaste works out where each clipboard item goes in destDir and queues the paste,
+// opening the conflict dialog first if any name is taken
+func (m *model) submitPaste(destDir string) tea.Cmd {
+	if len(m.clipboard) == 0 || m.clipboardOp == opNone {
+		return nil
+	}
+	plan := &pastePlan{kind: jobs.Copy, destDir: destDir, reserved: make(map[string]bool)}
+	if m.clipboardOp == opCut {
+		plan.kind = jobs.Move
+	}
+
+	for _, src := range m.clipboard {
+		dst := filepath.Join(destDir, filepath.Base(src))
+		srcInfo, err := os.Lstat(src)
+		if err != nil {
+			// Let the job report it with the others
+			plan.add(jobs.Item{Src: src, Dst: dst})
+			continue
+		}
+		if dst == src {
+			if plan.kind == jobs.Move {
+				plan.skipped++ // Already there
+			} else {
+				plan.keepBoth(src, dst, srcInfo.IsDir())
+			}
+			continue
+		}
+		if plan.reserved[dst] {
+			plan.pending = append(plan.pending, pasteConflict{src: src, dst: dst, srcInfo: srcInfo})
+			continue
+		}
+		if dstInfo, err := os.Lstat(dst); err == nil {
+			plan.reserved[dst] = true
+			plan.pending = append(plan.pending, pasteConflict{src: src, dst: dst, srcInfo: srcInfo, dstInfo: dstInfo, sameFile: os.SameFile(srcInfo, dstInfo)})
+			continue
+		}
+		plan.add(jobs.Item{Src: src, Dst: dst})
+	}
+
+	if len(plan.pending) > 0 {
+		plan.prevMode = m.mode
+		m.pastePlan = plan
+		m.mode = modePasteConflict
+		return nil
+	}
+	return m.queuePaste(plan)
+}
+
+// add puts an item in the paste and reserves its destination
+func (p *pastePlan) add(item jobs.Item) {
+	p.items = append(p.items, item)
+	p.reserved[item.Dst] = true
+}
+
+// keepBoth puts src in the paste under a free name next to dst
+func (p *pastePlan) keepBoth(src, dst string, isDir bool) {
+	free, err := fileops.KeepBothPath(dst, isDir, p.reserved)
+	if err != nil {
+		p.failed = append(p.failed, fmt.Sprintf("%s: %v", filepath.Base(src), err))
+		return
+	}
+	p.add(jobs.Item{Src: src, Dst: free})
+}
+
+// resolve applies action to the first pending conflict
+func (p *pastePlan) resolve(action fileops.ConflictAction) {
+	c := p.pending[0]
+	p.pending = p.pending[1:]
+	if c.sameFile && action != fileops.ConflictKeepBoth {
+		// Overwriting a file with itself would destroy it
+		p.skipped++
+		return
+	}
+	switch action {
+	case fileops.ConflictSkip:
+		p.skipped++
+	case fileops.ConflictKeepBoth:
+		p.keepBoth(c.src, c.dst, c.srcInfo.IsDir())
+	case fileops.ConflictOverwriteIfNewer:
+		p.add(jobs.Item{Src: c.src, Dst: c.dst, OnlyNewer: true})
+	default:
+		p.add(jobs.Item{Src: c.src, Dst: c.dst})
+	}
+}
+
+// resolvePasteConflict answers the conflict dialog, queueing the paste once nothing is
+// left to decide
+func (m *model) resolvePasteConflict(action fileops.ConflictAction) tea.Cmd {
+	plan := m.pastePlan
+	plan.resolve(action)
+	for plan.applyAll && len(plan.pending) > 0 {
+		plan.resolve(action)
+	}
+	if len(plan.pending) > 0 {
+		return nil
+	}
+	m.pastePlan = nil
+	m.mode = plan.prevMode
+	return m.queuePaste(plan)
+}
+
+// handlePasteConflictKey handles keys while the conflict dialog is open
+func (m *model) handlePasteConflictKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "o":
+		return m.resolvePasteConflict(fileops.ConflictOverwrite)
+	case "s":
+		return m.resolvePasteConflict(fileops.ConflictSkip)
+	case "r", "enter":
+		return m.resolvePasteConflict(fileops.ConflictKeepBoth)
+	case "n":
+		return m.resolvePasteConflict(fileops.ConflictOverwriteIfNewer)
+	case "a":
+		m.pastePlan.applyAll = !m.pastePlan.applyAll
+	case "esc", "ctrl+c", "q":
+		m.mode = m.pastePlan.prevMode
+		m.pastePlan = nil
+		m.statusMsg = "paste cancelled"
+		m.statusExpiry = time.Now().Add(2 * time.Second)
+	}
+	return nil
+}
+
+// queuePaste submits a resolved paste as a background job. A cut clipboard is emptied
+// straight away: its files are on their way, and pasting them twice can't work.
+func (m *model) queuePaste(plan *pastePlan) tea.Cmd {
+	if len(plan.failed) > 0 {
+		defer m.showError("PASTE INCOMPLETE", fmt.Sprintf("not pasted:\n%s", strings.Join(plan.failed, "\n")))
+	}
+	skipped := ""
+	if plan.skipped > 0 {
+		skipped = fmt.Sprintf(", %d skipped", plan.skipped)
+	}
+	if len(plan.items) == 0 {
+		m.statusMsg = "nothing to paste" + skipped
+		m.statusExpiry = time.Now().Add(2 * time.Second)
+		return nil
+	}
+
+	m.jobs.Submit(plan.kind, plan.items, plan.destDir, m.copyOptions())
+	if plan.kind == jobs.Move {
+		m.clipboard = []string{}
+		m.clipboardOp = opNone
+	}
+
+	verb := "copying"
+	if plan.kind == jobs.Move {
+		verb = "moving"
+	}
+	m.statusMsg = fmt.Sprintf("%s %d item(s) to %s in the background%s (J: jobs)", verb, len(plan.items), filepath.Base(plan.destDir), skipped)
+	m.statusExpiry = time.Now().Add(2 * time.Second)
+	return m.startJobPolling()
+}
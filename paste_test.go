@@ -0,0 +1,128 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// pasteTestModel returns a normal-mode model in dest with src's files on the clipboard
+func pasteTestModel(t *testing.T, dest string, op operationType, srcs ...string) model {
+	t.Helper()
+	m := testModelForUpdate(t, dest)
+	m.mode = modeNormal
+	m.loadFiles()
+	m.clipboard = srcs
+	m.clipboardOp = op
+	return m
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", filepath.Base(path), err)
+	}
+	return string(data)
+}
+
+func TestCopyIntoSameDirKeepsBoth(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"note.txt": "hello"})
+	m := pasteTestModel(t, dir, opCopy, filepath.Join(dir, "note.txt"))
+
+	m.Update(runeKey('p'))
+	if m.mode != modeNormal {
+		t.Fatalf("copy into the same dir asked about a conflict (mode %v)", m.mode)
+	}
+	finishJobs(&m)
+	if got := readFile(t, filepath.Join(dir, "note (1).txt")); got != "hello" {
+		t.Errorf("note (1).txt = %q", got)
+	}
+	if got := readFile(t, filepath.Join(dir, "note.txt")); got != "hello" {
+		t.Errorf("original changed to %q", got)
+	}
+}
+
+func TestPasteConflictChoices(t *testing.T) {
+	src := t.TempDir()
+	dest := t.TempDir()
+	writeFiles(t, src, map[string]string{"a.txt": "new a", "b.txt": "new b", "c.txt": "new c"})
+	writeFiles(t, dest, map[string]string{"a.txt": "old a", "b.txt": "old b"})
+	m := pasteTestModel(t, dest, opCopy, filepath.Join(src, "a.txt"), filepath.Join(src, "b.txt"), filepath.Join(src, "c.txt"))
+
+	m.Update(runeKey('p'))
+	if m.mode != modePasteConflict || len(m.pastePlan.pending) != 2 {
+		t.Fatalf("want the conflict dialog with 2 conflicts, mode %v", m.mode)
+	}
+	m.Update(runeKey('o')) // a.txt: overwrite
+	if m.mode != modePasteConflict {
+		t.Fatal("dialog closed with a conflict left")
+	}
+	m.Update(runeKey('r')) // b.txt: keep both
+	if m.mode != modeNormal || m.pastePlan != nil {
+		t.Fatalf("dialog still open after the last conflict")
+	}
+	finishJobs(&m)
+
+	for name, want := range map[string]string{"a.txt": "new a", "b.txt": "old b", "b (1).txt": "new b", "c.txt": "new c"} {
+		if got := readFile(t, filepath.Join(dest, name)); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestPasteConflictApplyToAll(t *testing.T) {
+	src := t.TempDir()
+	dest := t.TempDir()
+	writeFiles(t, src, map[string]string{"a.txt": "new", "b.txt": "new"})
+	writeFiles(t, dest, map[string]string{"a.txt": "old", "b.txt": "old"})
+	m := pasteTestModel(t, dest, opCut, filepath.Join(src, "a.txt"), filepath.Join(src, "b.txt"))
+
+	m.Update(runeKey('p'))
+	m.Update(runeKey('a'))
+	m.Update(runeKey('s'))
+	if m.mode != modeNormal {
+		t.Fatalf("skip with apply-to-all left the dialog open")
+	}
+	if m.jobs.Active() != 0 || len(m.clipboard) != 2 {
+		t.Errorf("skipping everything still queued a job or dropped the clipboard")
+	}
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if got := readFile(t, filepath.Join(dest, name)); got != "old" {
+			t.Errorf("%s overwritten despite skip", name)
+		}
+		if _, err := os.Stat(filepath.Join(src, name)); err != nil {
+			t.Errorf("skipped %s left the source: %v", name, err)
+		}
+	}
+}
+
+func TestPasteConflictCancel(t *testing.T) {
+	src := t.TempDir()
+	dest := t.TempDir()
+	writeFiles(t, src, map[string]string{"a.txt": "new", "b.txt": "new"})
+	writeFiles(t, dest, map[string]string{"a.txt": "old"})
+	m := pasteTestModel(t, dest, opCopy, filepath.Join(src, "a.txt"), filepath.Join(src, "b.txt"))
+
+	m.Update(runeKey('p'))
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m.jobs.Wait()
+	if m.mode != modeNormal {
+		t.Fatalf("esc left mode %v", m.mode)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "b.txt")); !os.IsNotExist(err) {
+		t.Error("cancelled paste still copied the item without a conflict")
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	xansi "github.com/charmbracelet/x/ansi"
+	"github.com/muesli/termenv"
+)
+
+func TestPreviewHighlightsAndWrapsCode(t *testing.T) {
+	profile := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.ANSI256)
+	t.Cleanup(func() { lipgloss.SetColorProfile(profile) })
+
+	dir := t.TempDir()
+	m := testModelForUpdate(t, dir)
+	src := "package main\n\n// " + strings.Repeat("a long comment ", 8) + "\nfunc main() {}\n"
+	path := filepath.Join(dir, "main.go")
+	os.WriteFile(path, []byte(src), 0o644)
+
+	got := m.previewFile(path)
+	if !strings.Contains(got, "\x1b[") || !strings.Contains(xansi.Strip(got), src) {
+		t.Fatalf("preview not highlighted:\n%q", got)
+	}
+	if cached := m.previewCache[path].content; cached != got[len(got)-len(cached):] {
+		t.Error("the highlighted text wasn't cached")
+	}
+
+	// Wrapping goes by display width: the colour codes don't count and aren't cut
+	for _, line := range m.wrapTextToLines(got, 30) {
+		if w := lipgloss.Width(line); w > 30 {
+			t.Errorf("line %q is %d wide", line, w)
+		}
+		if strings.HasSuffix(line, "\x1b") || strings.Contains(xansi.Strip(line), "[38;5") {
+			t.Errorf("escape code split in %q", line)
+		}
+	}
+
+	// Plain text stays plain
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("just words\n"), 0o644)
+	if got := m.previewFile(filepath.Join(dir, "notes.txt")); !strings.HasSuffix(got, "\n\njust words\n") {
+		t.Errorf("plain text was coloured: %q", got)
+	}
+}
+
+func TestPreviewRendersMarkdownAndToggles(t *testing.T) {
+	dir := t.TempDir()
+	m := testModelForUpdate(t, dir)
+	m.mode = modeNormal
+	path := filepath.Join(dir, "README.md")
+	os.WriteFile(path, []byte("# Title\n\n- item\n"), 0o644)
+
+	body := func() string {
+		_, body, _ := strings.Cut(xansi.Strip(m.previewFile(path)), "\n\n")
+		return body
+	}
+	if got := body(); got != "Title\n═════\n\n• item" {
+		t.Errorf("rendered preview:\n%s", got)
+	}
+	// m flips to the source and back; the cache never hands out one for the other
+	m = pressKeys(m, runeKey('m'))
+	if got := body(); got != "# Title\n\n- item\n" {
+		t.Errorf("source preview:\n%s", got)
+	}
+	m = pressKeys(m, runeKey('m'))
+	if got := body(); !strings.HasPrefix(got, "Title\n") {
+		t.Errorf("toggled back:\n%s", got)
+	}
+}
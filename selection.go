@@ -0,0 +1,330 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/LFroesch/scout/internal/fileops"
+	"github.com/LFroesch/scout/internal/search"
+	"github.com/LFroesch/scout/internal/utils"
+)
+
+// Multi-selection: marked paths live in m.selected (keyed by path, so marks survive sorting,
+// refreshes and leaving the directory), plus an optional visual range from visualAnchor to
+// the cursor. File operations act on the selection when there is one and on the cursor
+// item otherwise (see targetItems).
+
+// visualRange returns the filteredFiles index range covered by visual mode. ok is false when
+// visual mode is off or its anchor is no longer listed (e.g. after changing directory).
+func (m *model) visualRange() (lo, hi int, ok bool) {
+	if !m.visualActive {
+		return 0, 0, false
+	}
+	anchor := -1
+	for i, f := range m.filteredFiles {
+		if f.path == m.visualAnchor {
+			anchor = i
+			break
+		}
+	}
+	if anchor < 0 {
+		return 0, 0, false
+	}
+	lo, hi = anchor, m.cursor
+	if lo > hi {
+		lo, hi = hi, lo
+	}
+	return lo, hi, true
+}
+
+// startVisual begins a visual range at the cursor
+func (m *model) startVisual() {
+	if len(m.filteredFiles) == 0 || m.cursor >= len(m.filteredFiles) {
+		return
+	}
+	m.visualActive = true
+	m.visualAnchor = m.filteredFiles[m.cursor].path // ".." may anchor a range but is never part of it
+}
+
+// commitVisual adds the visual range to the marked selection and leaves visual mode
+func (m *model) commitVisual() {
+	if lo, hi, ok := m.visualRange(); ok {
+		for i := lo; i <= hi; i++ {
+			m.markSelected(m.filteredFiles[i].path, m.filteredFiles[i].name, true)
+		}
+	}
+	m.visualActive = false
+	m.visualAnchor = ""
+}
+
+// markSelected adds or removes path from the selection. ".." is never selectable.
+func (m *model) markSelected(path, name string, on bool) {
+	if name == ".." {
+		return
+	}
+	if !on {
+		delete(m.selected, path)
+		return
+	}
+	if m.selected == nil {
+		m.selected = make(map[string]bool)
+	}
+	m.selected[path] = true
+}
+
+// toggleSelection flips the mark on the cursor item and moves down, like mc's Insert
+func (m *model) toggleSelection() {
+	if len(m.filteredFiles) == 0 || m.cursor >= len(m.filteredFiles) {
+		return
+	}
+	item := m.filteredFiles[m.cursor]
+	m.markSelected(item.path, item.name, !m.selected[item.path])
+	if m.cursor < len(m.filteredFiles)-1 {
+		m.cursor++
+	}
+}
+
+// invertSelection flips the mark on every listed item
+func (m *model) invertSelection() {
+	m.commitVisual()
+	for _, f := range m.filteredFiles {
+		m.markSelected(f.path, f.name, !m.selected[f.path])
+	}
+}
+
+// selectByGlob marks (or unmarks) every listed item whose name matches pattern.
+// Returns how many items matched.
+func (m *model) selectByGlob(pattern string, on bool) (int, error) {
+	matcher, err := search.NewMatcher(search.MatchGlob, pattern)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, f := range m.filteredFiles {
+		if f.name == ".." {
+			continue
+		}
+		if _, _, ok := matcher.Match(f.name); ok {
+			m.markSelected(f.path, f.name, on)
+			count++
+		}
+	}
+	return count, nil
+}
+
+// clearSelection drops all marks and any visual range. Reports whether there was anything to clear.
+func (m *model) clearSelection() bool {
+	had := len(m.selected) > 0 || m.visualActive
+	m.selected = nil
+	m.visualActive = false
+	m.visualAnchor = ""
+	return had
+}
+
+// hasSelection reports whether operations should act on the selection rather than the cursor
+func (m *model) hasSelection() bool {
+	if len(m.selected) > 0 {
+		return true
+	}
+	_, _, ok := m.visualRange()
+	return ok
+}
+
+// isMarked reports whether the listed item at index i is selected, counting the visual range
+func (m *model) isMarked(i int, lo, hi int, inVisual bool) bool {
+	if inVisual && i >= lo && i <= hi && m.filteredFiles[i].name != ".." {
+		return true
+	}
+	return m.selected[m.filteredFiles[i].path]
+}
+
+// selectionCount is the number of items targetItems would return for a selection
+func (m *model) selectionCount() int {
+	count := len(m.selected)
+	if lo, hi, ok := m.visualRange(); ok {
+		for i := lo; i <= hi; i++ {
+			if f := m.filteredFiles[i]; f.name != ".." && !m.selected[f.path] {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// targetItems returns what a file operation should act on: the selection (marks plus the
+// visual range) sorted by path, or the cursor item when nothing is selected. Selected
+// paths outside the current listing are stat'd so they carry the right isDir, and paths
+// inside a selected directory are dropped: acting on the directory covers them.
+func (m *model) targetItems() []fileItem {
+	if !m.hasSelection() {
+		if len(m.filteredFiles) == 0 || m.cursor >= len(m.filteredFiles) || m.filteredFiles[m.cursor].name == ".." {
+			return nil
+		}
+		return []fileItem{m.filteredFiles[m.cursor]}
+	}
+
+	paths := make(map[string]bool, len(m.selected))
+	for p := range m.selected {
+		paths[p] = true
+	}
+	listed := make(map[string]fileItem, len(m.filteredFiles))
+	lo, hi, inVisual := m.visualRange()
+	for i, f := range m.filteredFiles {
+		if f.name == ".." {
+			continue
+		}
+		listed[f.path] = f
+		if inVisual && i >= lo && i <= hi {
+			paths[f.path] = true
+		}
+	}
+
+	var items []fileItem
+	for p := range paths {
+		if insideSelectedDir(p, paths) {
+			continue
+		}
+		if item, ok := listed[p]; ok {
+			items = append(items, item)
+			continue
+		}
+		info, err := os.Lstat(p)
+		if err != nil {
+			continue // Gone since it was marked
+		}
+		items = append(items, fileItem{path: p, name: filepath.Base(p), isDir: info.IsDir(), size: info.Size(), modTime: info.ModTime()})
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].path < items[j].path })
+	return items
+}
+
+// insideSelectedDir reports whether one of p's ancestors is in paths. Only directories
+// can have anything inside them, so their marks are the only ones that match.
+func insideSelectedDir(p string, paths map[string]bool) bool {
+	for dir := filepath.Dir(p); dir != p; p, dir = dir, filepath.Dir(dir) {
+		if paths[dir] {
+			return true
+		}
+	}
+	return false
+}
+
+// targetPaths returns the paths of targetItems
+func (m *model) targetPaths() []string {
+	items := m.targetItems()
+	paths := make([]string, len(items))
+	for i, item := range items {
+		paths[i] = item.path
+	}
+	return paths
+}
+
+// pruneSelection forgets marked paths in the current directory that no longer exist,
+// so a refresh after an outside delete doesn't leave ghost marks behind
+func (m *model) pruneSelection() {
+	for p := range m.selected {
+		if filepath.Dir(p) != m.currentDir {
+			continue
+		}
+		if _, err := os.Lstat(p); err != nil {
+			delete(m.selected, p)
+		}
+	}
+}
+
+// describeTargets summarizes items for status messages and dialogs: the name for one item, a count otherwise
+func describeTargets(items []fileItem) string {
+	if len(items) == 1 {
+		return items[0].name
+	}
+	return fmt.Sprintf("%d items", len(items))
+}
+
+// enterSelectGlob opens the select-by-glob prompt; on selects matches, !on deselects them
+func (m *model) enterSelectGlob(on bool) {
+	m.commitVisual()
+	m.selectGlobOn = on
+	m.previousMode = m.mode
+	m.mode = modeSelectGlob
+	m.textInput.SetValue("")
+	m.textInput.Placeholder = "e.g. *.go or test_*"
+	m.textInput.Focus()
+}
+
+// applySelectGlob runs the prompt's pattern and reports the result in the status bar
+func (m *model) applySelectGlob(pattern string) {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" {
+		return
+	}
+	count, err := m.selectByGlob(pattern, m.selectGlobOn)
+	if err != nil {
+		m.statusMsg = fmt.Sprintf("bad pattern: %v", err)
+		m.statusExpiry = time.Now().Add(3 * time.Second)
+		return
+	}
+	verb := "selected"
+	if !m.selectGlobOn {
+		verb = "deselected"
+	}
+	m.statusMsg = fmt.Sprintf("%s %d matching %s (%d selected)", verb, count, pattern, len(m.selected))
+	m.statusExpiry = time.Now().Add(2 * time.Second)
+}
+
+// stageSelection puts the selection on the clipboard for op, appending to a clipboard
+// already staged with the same op when appendTo is set (C/X), and clears the selection
+func (m *model) stageSelection(op operationType, appendTo bool) {
+	paths := m.targetPaths()
+	if len(paths) == 0 {
+		return
+	}
+	if !appendTo || m.clipboardOp != op || len(m.clipboard) == 0 {
+		m.clipboard = []string{}
+		m.clipboardOp = op
+	}
+	for _, p := range paths {
+		if !utils.Contains(m.clipboard, p) {
+			m.clipboard = append(m.clipboard, p)
+		}
+	}
+	m.clearSelection()
+
+	verb := "copy"
+	if op == opCut {
+		verb = "cut"
+	}
+	m.statusMsg = fmt.Sprintf("%d file(s) in %s clipboard", len(m.clipboard), verb)
+	m.statusExpiry = time.Now().Add(2 * time.Second)
+}
+
+// deleteSelection trashes every selected item, recording them as one undoable step
+func (m *model) deleteSelection() {
+	items := m.targetItems()
+	var failed []string
+	op := fileops.Op{Kind: fileops.OpTrash}
+	for _, item := range items {
+		trashPath, err := fileops.DeleteWithUndo(item.path, item.isDir)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", item.name, err))
+			continue
+		}
+		op.Trashed = append(op.Trashed, fileops.TrashedItem{Path: item.path, IsDir: item.isDir, UndoInfo: trashPath})
+	}
+	deleted := len(op.Trashed)
+	if deleted > 0 {
+		m.record(op)
+	}
+	m.clearSelection()
+	m.refreshPanes()
+
+	if len(failed) > 0 {
+		m.showError("DELETE FAILED", fmt.Sprintf("deleted %d of %d:\n%s", deleted, len(items), strings.Join(failed, "\n")))
+		return
+	}
+	m.statusMsg = fmt.Sprintf("deleted: %s (press 'u' to undo)", describeTargets(items))
+	m.statusExpiry = time.Now().Add(3 * time.Second)
+}
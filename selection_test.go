@@ -0,0 +1,179 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	xansi "github.com/charmbracelet/x/ansi"
+)
+
+func selectionTestModel(t *testing.T, names ...string) model {
+	t.Helper()
+	dir := t.TempDir()
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m := testModelForUpdate(t, dir)
+	m.mode = modeNormal
+	m.loadFiles()
+	return m
+}
+
+func pressKeys(m model, keys ...tea.KeyMsg) model {
+	for _, k := range keys {
+		gotModel, _ := m.Update(k)
+		m = *gotModel.(*model)
+	}
+	return m
+}
+
+func selectedNames(m model) []string {
+	var names []string
+	for _, item := range m.targetItems() {
+		names = append(names, item.name)
+	}
+	return names
+}
+
+func TestSpaceTogglesSelectionAndSurvivesSort(t *testing.T) {
+	m := selectionTestModel(t, "a.txt", "b.txt", "c.txt")
+	m.cursor = 1 // Skip ".."
+
+	space := tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+	m = pressKeys(m, space, runeKey('j'), space) // a.txt, skip b.txt, c.txt
+	if got := selectedNames(m); !equalStringSlice(got, []string{"a.txt", "c.txt"}) {
+		t.Fatalf("expected a.txt and c.txt selected, got %v", got)
+	}
+
+	m = pressKeys(m, runeKey('S'), runeKey('r')) // Re-sort and refresh
+	if got := selectedNames(m); !equalStringSlice(got, []string{"a.txt", "c.txt"}) {
+		t.Fatalf("selection should survive sort and refresh, got %v", got)
+	}
+
+	m = pressKeys(m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.hasSelection() {
+		t.Fatal("esc should clear the selection")
+	}
+	if m.currentDir != filepath.Dir(m.files[1].path) {
+		t.Fatal("esc with a selection should not leave the directory")
+	}
+}
+
+func TestVisualRangeInvertAndGlob(t *testing.T) {
+	m := selectionTestModel(t, "a.go", "b.go", "c.md", "d.md")
+	m.cursor = 0 // ".." anchors the range but is never selected
+
+	m = pressKeys(m, runeKey('V'), runeKey('j'), runeKey('j'))
+	if got := selectedNames(m); !equalStringSlice(got, []string{"a.go", "b.go"}) {
+		t.Fatalf("visual range: got %v", got)
+	}
+	m = pressKeys(m, runeKey('V'), runeKey('v'))
+	if got := selectedNames(m); !equalStringSlice(got, []string{"c.md", "d.md"}) {
+		t.Fatalf("invert: got %v", got)
+	}
+
+	m = pressKeys(m, runeKey('-'))
+	if m.mode != modeSelectGlob {
+		t.Fatalf("expected glob prompt, got mode %d", m.mode)
+	}
+	m.textInput.SetValue("c.*")
+	m = pressKeys(m, tea.KeyMsg{Type: tea.KeyEnter}, runeKey('+'))
+	m.textInput.SetValue("*.go")
+	m = pressKeys(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if got := selectedNames(m); !equalStringSlice(got, []string{"a.go", "b.go", "d.md"}) {
+		t.Fatalf("glob select/deselect: got %v", got)
+	}
+}
+
+func TestBulkDeleteAndCopyUseSelection(t *testing.T) {
+	m := selectionTestModel(t, "keep.txt", "one.log", "two.log")
+	m.selectByGlob("*.log", true)
+
+	m = pressKeys(m, runeKey('c'))
+	if len(m.clipboard) != 2 || m.clipboardOp != opCopy || m.hasSelection() {
+		t.Fatalf("c should stage the selection and clear it, got %v", m.clipboard)
+	}
+
+	m.selectByGlob("*.log", true)
+	m = pressKeys(m, runeKey('D'))
+	if m.mode != modeConfirmFileDelete {
+		t.Fatalf("expected delete confirmation, got mode %d", m.mode)
+	}
+	m = pressKeys(m, runeKey('y'))
+	for _, name := range []string{"one.log", "two.log"} {
+		if _, err := os.Stat(filepath.Join(m.currentDir, name)); !os.IsNotExist(err) {
+			t.Errorf("%s should have been deleted", name)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(m.currentDir, "keep.txt")); err != nil {
+		t.Error("keep.txt should survive")
+	}
+	if m.mode != modeNormal || m.hasSelection() {
+		t.Fatal("expected normal mode with an empty selection after delete")
+	}
+}
+
+func TestDeleteShowsMarksElsewhereAndSkipsNested(t *testing.T) {
+	m := selectionTestModel(t, "here.txt")
+	sub := filepath.Join(m.currentDir, "sub")
+	os.MkdirAll(filepath.Join(sub, "deep"), 0o755)
+	os.WriteFile(filepath.Join(sub, "deep", "inner.txt"), []byte("x"), 0o644)
+	other := t.TempDir()
+	os.WriteFile(filepath.Join(other, "far.txt"), []byte("x"), 0o644)
+	m.loadFiles()
+
+	// A directory and something inside it, plus a file in a directory not on screen
+	m.selectByGlob("*", true)
+	m.markSelected(filepath.Join(sub, "deep", "inner.txt"), "inner.txt", true)
+	m.markSelected(filepath.Join(other, "far.txt"), "far.txt", true)
+	if got := selectedNames(m); !equalStringSlice(got, []string{"here.txt", "sub", "far.txt"}) {
+		t.Fatalf("targets %v, want the nested mark dropped", got)
+	}
+
+	m = pressKeys(m, runeKey('D'))
+	if dialog := xansi.Strip(m.View()); !strings.Contains(dialog, "in other directories:") || !strings.Contains(dialog, "/far.txt") {
+		t.Errorf("off-screen mark not listed:\n%s", dialog)
+	}
+	m = pressKeys(m, runeKey('y'))
+	if m.mode != modeNormal {
+		t.Errorf("delete ended in mode %d (%s)", m.mode, m.errorMsg)
+	}
+	for _, p := range []string{filepath.Join(other, "far.txt"), sub} {
+		if _, err := os.Lstat(p); !os.IsNotExist(err) {
+			t.Errorf("%s not deleted", p)
+		}
+	}
+}
+
+func TestSelectionIsPerPane(t *testing.T) {
+	m := selectionTestModel(t, "a.txt")
+	m.otherPane = paneState{dir: m.currentDir}
+	m.toggleDualPane()
+	m.selectByGlob("*", true)
+
+	m = pressKeys(m, tea.KeyMsg{Type: tea.KeyTab})
+	if m.hasSelection() {
+		t.Fatal("the other pane should start with no selection")
+	}
+	m = pressKeys(m, tea.KeyMsg{Type: tea.KeyTab})
+	if got := selectedNames(m); !equalStringSlice(got, []string{"a.txt"}) {
+		t.Fatalf("first pane's selection should be restored, got %v", got)
+	}
+}
+
+func equalStringSlice(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/LFroesch/scout/internal/fileops"
+)
+
+// Trash browser: T lists everything in the freedesktop.org trash (home and per-mount)
+// with its original path and deletion date. Items can be restored to where they came
+// from, deleted for good, or the whole trash emptied; the last two ask first.
+
+// openTrashPanel loads the trash and shows it
+func (m *model) openTrashPanel() {
+	m.mode = modeTrash
+	m.trashCursor = 0
+	m.trashConfirm = ""
+	m.loadTrash()
+}
+
+// loadTrash re-reads the trash, keeping the cursor in range
+func (m *model) loadTrash() {
+	entries, err := fileops.ListTrash()
+	if err != nil {
+		m.statusMsg = fmt.Sprintf("cannot read trash: %v", err)
+		m.statusExpiry = time.Now().Add(3 * time.Second)
+	}
+	m.trashEntries = entries
+	m.trashCursor = max(min(m.trashCursor, len(entries)-1), 0)
+}
+
+// closeTrashPanel goes back to the file list, showing anything that was restored
+func (m *model) closeTrashPanel() {
+	m.mode = modeNormal
+	m.trashEntries = nil
+	m.refreshPanes()
+}
+
+// handleTrashKey handles keys while the trash panel is open
+func (m *model) handleTrashKey(msg tea.KeyMsg) {
+	key := msg.String()
+	if m.trashConfirm != "" {
+		// Purge and empty can't be undone: anything but y backs out
+		action := m.trashConfirm
+		m.trashConfirm = ""
+		if key == "y" || key == "Y" {
+			m.purgeTrash(action == "empty")
+		}
+		return
+	}
+
+	switch key {
+	case "esc", "T", "q":
+		m.closeTrashPanel()
+	case "j", "down":
+		if m.trashCursor < len(m.trashEntries)-1 {
+			m.trashCursor++
+		}
+	case "k", "up":
+		if m.trashCursor > 0 {
+			m.trashCursor--
+		}
+	case "g":
+		m.trashCursor = 0
+	case "G":
+		m.trashCursor = max(len(m.trashEntries)-1, 0)
+	case "r", "enter":
+		if m.trashCursor < len(m.trashEntries) {
+			m.restoreTrashEntry(m.trashEntries[m.trashCursor])
+		}
+	case "d", "x":
+		if m.trashCursor < len(m.trashEntries) {
+			m.trashConfirm = "purge"
+		}
+	case "E":
+		if len(m.trashEntries) > 0 {
+			m.trashConfirm = "empty"
+		}
+	}
+}
+
+// restoreTrashEntry puts an item back where it was deleted from
+func (m *model) restoreTrashEntry(entry fileops.TrashEntry) {
+	if err := entry.Restore(); err != nil {
+		m.statusMsg = err.Error()
+		m.statusExpiry = time.Now().Add(4 * time.Second)
+		return
+	}
+	m.statusMsg = fmt.Sprintf("restored: %s", entry.OriginalPath)
+	m.statusExpiry = time.Now().Add(3 * time.Second)
+	m.loadTrash()
+}
+
+// purgeTrash permanently deletes the selected entry, or with all, everything in the trash
+func (m *model) purgeTrash(all bool) {
+	var err error
+	if all {
+		err = fileops.EmptyTrash()
+		m.statusMsg = "trash emptied"
+	} else {
+		entry := m.trashEntries[m.trashCursor]
+		err = entry.Purge()
+		m.statusMsg = fmt.Sprintf("permanently deleted: %s", filepath.Base(entry.OriginalPath))
+	}
+	if err != nil {
+		m.statusMsg = fmt.Sprintf("delete failed: %v", err)
+	}
+	m.statusExpiry = time.Now().Add(3 * time.Second)
+	m.loadTrash()
+}
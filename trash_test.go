@@ -0,0 +1,52 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTrashPanelRestoreAndPurge(t *testing.T) {
+	m := selectionTestModel(t, "keep.txt", "junk.txt")
+	dir := m.currentDir
+
+	m.selectByGlob("*.txt", true)
+	m = pressKeys(m, runeKey('D'), runeKey('y'))
+	if m.mode != modeNormal {
+		t.Fatalf("delete failed: %s", m.errorDetails)
+	}
+
+	m = pressKeys(m, runeKey('T'))
+	if m.mode != modeTrash || len(m.trashEntries) != 2 {
+		t.Fatalf("trash panel: mode %v, %d entries", m.mode, len(m.trashEntries))
+	}
+	if filepath.Base(m.trashEntries[0].OriginalPath) != "keep.txt" {
+		m.trashCursor = 1
+	}
+	m = pressKeys(m, runeKey('r'))
+	if _, err := os.Stat(filepath.Join(dir, "keep.txt")); err != nil {
+		t.Fatalf("keep.txt not restored: %v", err)
+	}
+	if len(m.trashEntries) != 1 {
+		t.Fatalf("%d entries after restoring, want 1", len(m.trashEntries))
+	}
+
+	// Purging asks first; anything but y backs out
+	m = pressKeys(m, runeKey('d'), runeKey('n'))
+	if len(m.trashEntries) != 1 || m.trashConfirm != "" {
+		t.Fatalf("n should cancel the purge (%d entries)", len(m.trashEntries))
+	}
+	trashed := m.trashEntries[0].FilePath()
+	m = pressKeys(m, runeKey('d'), runeKey('y'))
+	if len(m.trashEntries) != 0 {
+		t.Errorf("%d entries after purging", len(m.trashEntries))
+	}
+	if _, err := os.Lstat(trashed); !os.IsNotExist(err) {
+		t.Error("purged file still in the trash")
+	}
+
+	m = pressKeys(m, runeKey('T'))
+	if m.mode != modeNormal {
+		t.Errorf("T should close the panel, mode %v", m.mode)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
+	"github.com/LFroesch/scout/internal/archive"
 	"github.com/LFroesch/scout/internal/config"
 	"github.com/LFroesch/scout/internal/fileops"
 	"github.com/LFroesch/scout/internal/git"
@@ -21,10 +22,22 @@ func (m *model) Init() tea.Cmd {
 	return tea.Batch(
 		tea.SetWindowTitle("🔍 Scout - File Explorer"),
 		tea.EnableMouseAllMotion,
+		m.waitForDirChange(),
 	)
 }
 
+// Update handles msg, then starts the image load the preview asked for, if any: the
+// preview is redrawn from too many places to hand back a command itself
 func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	model, cmd := m.update(msg)
+	if load := m.imageLoad; load != nil {
+		m.imageLoad = nil
+		cmd = tea.Batch(cmd, load)
+	}
+	return model, cmd
+}
+
+func (m *model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 
 	// Clear expired status messages
@@ -113,6 +126,10 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		m.filteredFiles = msg.files
 		m.searchMatches = msg.matches
+		if m.currentSearchType != searchContent {
+			// Rank filename results by fuzzy score (content results keep ripgrep's file/line order)
+			m.sortSearchResults()
+		}
 
 		orangeStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Background(lipgloss.Color("235")).Bold(true).Inline(true)
 		purpleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("99")).Background(lipgloss.Color("235")).Bold(true).Inline(true)
@@ -184,6 +201,45 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.statusExpiry = time.Now().Add(3 * time.Second)
 		return m, nil
 
+	case dirChangedMsg:
+		// Something changed in a directory on screen; keep listening for the next change
+		m.queueDirRefresh(msg.dir)
+		return m, tea.Batch(m.waitForDirChange(), m.flushDirRefresh())
+
+	case dirRefreshRetryMsg:
+		m.dirRetryPending = false
+		return m, m.flushDirRefresh()
+
+	case indexTickMsg:
+		// Keep redrawing while an index builds so the search header's index status stays live
+		if m.indexes.Building() {
+			return m, indexTick()
+		}
+		m.indexPolling = false
+		return m, nil
+
+	case jobTickMsg:
+		return m, m.handleJobTick()
+
+	case followTickMsg:
+		return m, m.handleFollowTick(msg)
+
+	case archiveListedMsg:
+		m.handleArchiveListed(msg)
+		return m, nil
+
+	case imageLoadedMsg:
+		m.handleImageLoaded(msg)
+		return m, nil
+
+	case hexFoundMsg:
+		m.handleHexFound(msg)
+		return m, nil
+
+	case bulkRenameEditedMsg:
+		m.finishBulkRename(msg)
+		return m, nil
+
 	case fileOpenResultMsg:
 		// File open result (success or failure)
 		m.statusMsg = msg.message
@@ -201,11 +257,24 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		whiteStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("255")).Background(lipgloss.Color("235")).Inline(true)
 
 		if msg.drive != "" {
-			if msg.count > 0 {
-				m.statusMsg = orangeStyle.Render("searching... ") + whiteStyle.Render(msg.drive+" ") + purpleStyle.Render(fmt.Sprintf("%d", msg.count)) + whiteStyle.Render(" files scanned")
-			} else {
-				m.statusMsg = orangeStyle.Render("searching... ") + whiteStyle.Render(msg.drive)
+			// Drives are walked in parallel: show every drive's count, in mount order
+			if m.ultraScanned == nil {
+				m.ultraScanned = make(map[string]int)
 			}
+			m.ultraScanned[msg.drive] = msg.count
+			total := 0
+			status := orangeStyle.Render("searching... ")
+			for _, drive := range m.ultraDrives {
+				label := utils.GetDriveLabel(drive)
+				count, started := m.ultraScanned[label]
+				if !started {
+					continue
+				}
+				total += count
+				status += whiteStyle.Render(label+" ") + purpleStyle.Render(fmt.Sprintf("%d", count)) + whiteStyle.Render("  ")
+			}
+			m.scannedFiles = total
+			m.statusMsg = status + whiteStyle.Render("files scanned")
 		} else {
 			m.statusMsg = orangeStyle.Render("searching... ") + purpleStyle.Render(fmt.Sprintf("%d", msg.count)) + whiteStyle.Render(" files scanned")
 		}
@@ -216,6 +285,17 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case tea.MouseMsg:
+		// Dual pane: any press over the inactive side focuses it first
+		if m.dualPane && m.mode == modeNormal && msg.Action == tea.MouseActionPress {
+			clickedPane := 0
+			if msg.X >= m.width/2 {
+				clickedPane = 1
+			}
+			if clickedPane != m.activePane {
+				m.switchPane()
+			}
+		}
+
 		// Handle mouse wheel scroll
 		if msg.Action == tea.MouseActionPress && (msg.Button == tea.MouseButtonWheelUp || msg.Button == tea.MouseButtonWheelDown) {
 			switch m.mode {
@@ -266,6 +346,17 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 				return m, nil
 
+			case modeHex:
+				// Scroll the hex viewer three rows at a time
+				v := m.hexViewer
+				perRow, rows := m.hexLayout()
+				step := int64(3 * perRow)
+				if msg.Button == tea.MouseButtonWheelUp {
+					step = -step
+				}
+				v.scrollTo(v.offset+step, perRow, rows)
+				return m, nil
+
 			case modeBookmarks:
 				// Scroll in bookmarks
 				if msg.Button == tea.MouseButtonWheelUp {
@@ -450,6 +541,8 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 											m.updatePreview()
 										}
 									}
+								} else if archive.Detect(selected.path) != archive.Unknown {
+									return m, m.openArchive(selected.path)
 								} else {
 									// Open file with smart fallback
 									if m.currentSearchType == searchContent {
@@ -691,6 +784,26 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case tea.KeyMsg:
 		switch m.mode {
+		case modeHistory:
+			m.handleHistoryKey(msg)
+			return m, nil
+
+		case modeTrash:
+			m.handleTrashKey(msg)
+			return m, nil
+
+		case modeArchive:
+			return m, m.handleArchiveKey(msg)
+
+		case modeExtract:
+			return m, m.handleExtractKey(msg)
+
+		case modeCompress:
+			return m, m.handleCompressKey(msg)
+
+		case modeHex:
+			return m, m.handleHexKey(msg)
+
 		case modeErrorDialog:
 			// Any key dismisses error dialog
 			m.mode = modeNormal
@@ -781,10 +894,41 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return m, nil
 
+		case modeBatchRename:
+			return m, m.handleBatchRenameKey(msg)
+
+		case modeJobs:
+			return m, m.handleJobsKey(msg)
+
+		case modePasteConflict:
+			return m, m.handlePasteConflictKey(msg)
+
+		case modeConfirmBulkRename:
+			switch msg.String() {
+			case "y", "Y", "enter":
+				m.applyBulkRename()
+			case "n", "N", "esc", "ctrl+c":
+				m.cancelBulkRename()
+			case "j", "down":
+				if m.bulkRename != nil && m.bulkRename.scroll < len(m.bulkRename.planLines())-1 {
+					m.bulkRename.scroll++
+				}
+			case "k", "up":
+				if m.bulkRename != nil && m.bulkRename.scroll > 0 {
+					m.bulkRename.scroll--
+				}
+			}
+			return m, nil
+
 		case modeConfirmFileDelete:
 			switch msg.String() {
 			case "y", "Y":
-				// Confirmed - delete the file
+				// Confirmed - delete the selection (normal mode) or the file under the cursor
+				if m.previousMode != modeSearch && m.hasSelection() {
+					m.deleteSelection()
+					m.mode = modeNormal
+					return m, nil
+				}
 				if len(m.filteredFiles) > 0 && m.cursor < len(m.filteredFiles) {
 					selected := m.filteredFiles[m.cursor]
 					if selected.name == ".." {
@@ -792,21 +936,13 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						return m, nil
 					}
 
-					// Add to undo stack before deleting
-					undoEntry := undoItem{
-						operation: "delete",
-						path:      selected.path,
-						wasDir:    selected.isDir,
-					}
-
 					// Try to move to trash (which we can potentially restore)
 					trashPath, err := fileops.DeleteWithUndo(selected.path, selected.isDir)
 					if err != nil {
 						m.showError("DELETE FAILED", err.Error())
 						return m, nil
 					}
-					undoEntry.trashPath = trashPath
-					m.addToUndo(undoEntry)
+					m.record(fileops.Op{Kind: fileops.OpTrash, Trashed: []fileops.TrashedItem{{Path: selected.path, IsDir: selected.isDir, UndoInfo: trashPath}}})
 					m.statusMsg = fmt.Sprintf("deleted: %s (press 'u' to undo)", selected.name)
 					m.statusExpiry = time.Now().Add(3 * time.Second)
 					if m.previousMode == modeSearch {
@@ -819,7 +955,7 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						m.mode = modeSearch
 						m.updatePreview()
 					} else {
-						m.loadFiles()
+						m.refreshPanes()
 						m.mode = modeNormal
 					}
 				}
@@ -843,6 +979,7 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					if err := m.renameFile(selected.path, newName); err != nil {
 						m.showError("RENAME FAILED", err.Error())
 					} else {
+						m.record(fileops.Op{Kind: fileops.OpRename, Moves: []fileops.RenameOp{{From: selected.path, To: filepath.Join(filepath.Dir(selected.path), newName)}}})
 						m.statusMsg = fmt.Sprintf("renamed to: %s", newName)
 						m.statusExpiry = time.Now().Add(2 * time.Second)
 						if m.previousMode == modeSearch {
@@ -851,7 +988,7 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 							m.filteredFiles[m.cursor].name = newName
 							m.filteredFiles[m.cursor].path = newPath
 						} else {
-							m.loadFiles()
+							m.refreshPanes()
 						}
 					}
 				}
@@ -863,6 +1000,54 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, cmd
 			}
 
+		case modeFollowPattern:
+			switch msg.String() {
+			case "ctrl+c", "esc":
+				m.mode = m.previousMode
+				m.textInput.SetValue("")
+				return m, nil
+			case "enter":
+				m.mode = m.previousMode
+				cmd = m.startFollow(m.textInput.Value())
+				m.textInput.SetValue("")
+				return m, cmd
+			default:
+				m.textInput, cmd = m.textInput.Update(msg)
+				return m, cmd
+			}
+
+		case modePreviewLine:
+			switch msg.String() {
+			case "ctrl+c", "esc":
+				m.mode = m.previousMode
+				m.textInput.SetValue("")
+				return m, nil
+			case "enter":
+				m.mode = m.previousMode
+				m.applyPreviewLine(m.textInput.Value())
+				m.textInput.SetValue("")
+				return m, nil
+			default:
+				m.textInput, cmd = m.textInput.Update(msg)
+				return m, cmd
+			}
+
+		case modeSelectGlob:
+			switch msg.String() {
+			case "ctrl+c", "esc":
+				m.mode = m.previousMode
+				m.textInput.SetValue("")
+				return m, nil
+			case "enter":
+				m.applySelectGlob(m.textInput.Value())
+				m.mode = m.previousMode
+				m.textInput.SetValue("")
+				return m, nil
+			default:
+				m.textInput, cmd = m.textInput.Update(msg)
+				return m, cmd
+			}
+
 		case modeCreateFile:
 			switch msg.String() {
 			case "ctrl+c", "esc":
@@ -875,9 +1060,10 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					if err := m.createFile(name); err != nil {
 						m.showError("CREATE FILE FAILED", err.Error())
 					} else {
+						m.record(fileops.Op{Kind: fileops.OpCreate, Created: []string{filepath.Join(m.currentDir, name)}})
 						m.statusMsg = fmt.Sprintf("created file: %s", name)
 						m.statusExpiry = time.Now().Add(2 * time.Second)
-						m.loadFiles()
+						m.refreshPanes()
 					}
 				}
 				m.mode = m.previousMode
@@ -900,9 +1086,10 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					if err := m.createDir(name); err != nil {
 						m.showError("CREATE DIRECTORY FAILED", err.Error())
 					} else {
+						m.record(fileops.Op{Kind: fileops.OpCreate, Created: []string{filepath.Join(m.currentDir, name)}})
 						m.statusMsg = fmt.Sprintf("created directory: %s", name)
 						m.statusExpiry = time.Now().Add(2 * time.Second)
-						m.loadFiles()
+						m.refreshPanes()
 					}
 				}
 				m.mode = m.previousMode
@@ -964,6 +1151,10 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.updatePreview()
 				return m, nil
 
+			case "ctrl+r":
+				// Rebuild the persistent filename index for the current search scope
+				return m, m.rebuildIndex()
+
 			case "S":
 				// Cycle through sort modes if locked, otherwise allow typing in search
 				if m.searchResultsLocked {
@@ -971,11 +1162,14 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					// Re-sort the filtered files
 					m.sortSearchResults()
 					sortNames := map[sortMode]string{
-						sortByName: "name",
+						sortByName: "relevance",
 						sortBySize: "size",
 						sortByDate: "date",
 						sortByType: "type",
 					}
+					if m.currentSearchType == searchContent {
+						sortNames[sortByName] = "name"
+					}
 					m.statusMsg = fmt.Sprintf("sorted by: %s", sortNames[m.sortBy])
 					m.statusExpiry = time.Now().Add(2 * time.Second)
 					return m, nil
@@ -1280,34 +1474,22 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 			case "alt+up":
 				// Scroll preview up (only when results are locked)
-				if m.searchResultsLocked && m.showPreview && m.previewScroll > 0 {
-					m.previewScroll--
+				if m.searchResultsLocked {
+					m.scrollPreview(-1)
 				}
 				return m, nil
 
 			case "alt+down":
 				// Scroll preview down (only when results are locked)
-				if m.searchResultsLocked && m.showPreview && len(m.previewLines) > 0 {
-					availableHeight := m.height - uiOverhead
-					if availableHeight < 3 {
-						availableHeight = 3
-					}
-					contentHeight := availableHeight - 1
-					if contentHeight < 1 {
-						contentHeight = 1
-					}
-					if m.previewScroll < len(m.previewLines)-contentHeight {
-						m.previewScroll++
-					}
+				if m.searchResultsLocked {
+					m.scrollPreview(1)
 				}
 				return m, nil
 
 			case "w":
 				// Scroll preview up if locked, otherwise allow typing in search
 				if m.searchResultsLocked {
-					if m.showPreview && m.previewScroll > 0 {
-						m.previewScroll--
-					}
+					m.scrollPreview(-1)
 					return m, nil
 				}
 				// Allow typing 'w' in search
@@ -1324,19 +1506,7 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case "s":
 				// Scroll preview down if locked, otherwise allow typing in search
 				if m.searchResultsLocked {
-					if m.showPreview && len(m.previewLines) > 0 {
-						availableHeight := m.height - uiOverhead
-						if availableHeight < 3 {
-							availableHeight = 3
-						}
-						contentHeight := availableHeight - 1
-						if contentHeight < 1 {
-							contentHeight = 1
-						}
-						if m.previewScroll < len(m.previewLines)-contentHeight {
-							m.previewScroll++
-						}
-					}
+					m.scrollPreview(1)
 					return m, nil
 				}
 				// Allow typing 's' in search
@@ -1532,6 +1702,37 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.updatePreview()
 				return m, cmd
 
+			case "ctrl+t":
+				// Cycle the filename matcher: fuzzy -> substring -> glob -> regex
+				if m.searchResultsLocked {
+					return m, nil
+				}
+				m.searchMatchMode = m.searchMatchMode.Next()
+				m.statusMsg = "match: " + m.searchMatchMode.String()
+				if m.currentSearchType == searchContent {
+					m.statusMsg += " (filename modes only; content search is always regex)"
+				}
+				m.statusExpiry = time.Now().Add(2 * time.Second)
+				cmd = m.updateFilter()
+				m.updatePreview()
+				return m, cmd
+
+			case "ctrl+o":
+				// Toggle whether .gitignore/.ignore'd files show up in recursive searches
+				if m.searchResultsLocked {
+					return m, nil
+				}
+				m.searchNoIgnore = !m.searchNoIgnore
+				if m.searchNoIgnore {
+					m.statusMsg = "search: including ignored files"
+				} else {
+					m.statusMsg = "search: honouring .gitignore/.ignore"
+				}
+				m.statusExpiry = time.Now().Add(2 * time.Second)
+				cmd = m.updateFilter()
+				m.updatePreview()
+				return m, cmd
+
 			case "ctrl+g":
 				// Exit and cd to the selected directory (or containing/current directory)
 				if m.searchResultsLocked && len(m.filteredFiles) > 0 && m.cursor < len(m.filteredFiles) {
@@ -1788,22 +1989,7 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						} else {
 							pasteDir = m.currentDir
 						}
-						var err error
-						if m.clipboardOp == opCopy {
-							err = fileops.CopyMultiple(m.clipboard, pasteDir)
-						} else if m.clipboardOp == opCut {
-							err = fileops.MoveMultiple(m.clipboard, pasteDir)
-							if err == nil {
-								m.clipboard = []string{}
-								m.clipboardOp = opNone
-							}
-						}
-						if err != nil {
-							m.showError("PASTE FAILED", err.Error())
-						} else {
-							m.statusMsg = "pasted successfully"
-							m.statusExpiry = time.Now().Add(2 * time.Second)
-						}
+						return m, m.submitPaste(pasteDir)
 					}
 					return m, nil
 				}
@@ -1820,21 +2006,7 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case "u":
 				// Undo when locked, otherwise type
 				if m.searchResultsLocked {
-					if len(m.undoStack) > 0 {
-						lastUndo := m.undoStack[len(m.undoStack)-1]
-						m.undoStack = m.undoStack[:len(m.undoStack)-1]
-						if lastUndo.operation == "delete" && lastUndo.trashPath != "" {
-							if err := fileops.RestoreFromTrash(lastUndo.trashPath, lastUndo.path); err != nil {
-								m.showError("UNDO FAILED", fmt.Sprintf("could not restore %s: %v", filepath.Base(lastUndo.path), err))
-							} else {
-								m.statusMsg = fmt.Sprintf("restored: %s", filepath.Base(lastUndo.path))
-								m.statusExpiry = time.Now().Add(2 * time.Second)
-							}
-						}
-					} else {
-						m.statusMsg = "nothing to undo"
-						m.statusExpiry = time.Now().Add(2 * time.Second)
-					}
+					m.undoLast()
 					return m, nil
 				}
 				m.searchInput, cmd = m.searchInput.Update(msg)
@@ -1985,7 +2157,7 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 			switch keyStr {
 			case "ctrl+c", "q":
-				return m, tea.Quit
+				return m, m.quitOrShowJobs()
 
 			case "ctrl+g":
 				// Exit and cd to selected/current directory (requires shell integration, see ? help)
@@ -2062,25 +2234,35 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 			case "w", "alt+up":
 				// Scroll preview up
-				if m.showPreview && m.previewScroll > 0 {
-					m.previewScroll--
-				}
+				m.scrollPreview(-1)
 
 			case "s", "alt+down":
 				// Scroll preview down
-				if m.showPreview && len(m.previewLines) > 0 {
-					availableHeight := m.height - uiOverhead
-					if availableHeight < 3 {
-						availableHeight = 3
-					}
-					contentHeight := availableHeight - 1
-					if contentHeight < 1 {
-						contentHeight = 1
-					}
-					if m.previewScroll < len(m.previewLines)-contentHeight {
-						m.previewScroll++
-					}
-				}
+				m.scrollPreview(1)
+
+			case "t":
+				// Preview: jump to the end of the file and back
+				m.togglePreviewEnd()
+
+			case ":":
+				// Preview: go to a line
+				return m, m.openPreviewLineDialog()
+
+			case "F":
+				// Preview: follow the file as it grows, or stop
+				return m, m.openFollowDialog()
+
+			case "m":
+				// Markdown and data file previews: rendered or source
+				m.togglePreviewSource()
+
+			case "<":
+				// Data preview: fold a level of the tree or outline
+				m.foldPreview(-1)
+
+			case ">":
+				// Data preview: unfold a level
+				m.foldPreview(1)
 
 			case "g":
 				return m, m.moveCursor(0)
@@ -2101,6 +2283,9 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						m.previewScroll = 0
 						m.loadFiles()
 						m.refreshGitStatus()
+					} else if archive.Detect(selected.path) != archive.Unknown {
+						// Archives open read-only like a directory
+						return m, m.openArchive(selected.path)
 					} else {
 						// Files: smart fallback (try editor, then system default)
 						if m.currentSearchType == searchContent {
@@ -2112,6 +2297,17 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 
 			case "esc", "h", "left":
+				// esc leaves visual mode, then clears the selection, before it goes up a level
+				if keyStr == "esc" && m.visualActive {
+					m.visualActive = false
+					m.visualAnchor = ""
+					break
+				}
+				if keyStr == "esc" && m.clearSelection() {
+					m.statusMsg = "selection cleared"
+					m.statusExpiry = time.Now().Add(2 * time.Second)
+					break
+				}
 				parentDir := filepath.Dir(m.currentDir)
 
 				// Check if we can go up (respect root path and filesystem root)
@@ -2127,20 +2323,26 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.gitBranch = git.GetBranch(m.currentDir)
 				}
 
-			case "/", "tab":
-				m.mode = modeSearch
-				m.currentSearchType = searchFilename
-				m.recursiveSearch = false     // Always start in current directory mode
-				m.searchResultsLocked = false // Clear any locked results
-				m.searchInput.SetValue("")
-				m.searchInput.Placeholder = "Search..."
-				m.searchInput.Focus()
-				// Clear any previous search results
-				m.filteredFiles = m.files
-				m.searchMatches = [][]int{}
-				m.cursor = 0
-				m.scrollOffset = 0
-				return m, textinput.Blink
+			case "tab":
+				// Dual-pane: switch active pane. Single pane: start search (same as /)
+				if m.dualPane {
+					m.switchPane()
+					return m, nil
+				}
+				return m, m.enterSearchMode()
+
+			case "/":
+				return m, m.enterSearchMode()
+
+			case "|":
+				// Toggle dual-pane commander layout
+				m.toggleDualPane()
+				if m.dualPane {
+					m.statusMsg = "dual pane (tab: switch pane)"
+				} else {
+					m.statusMsg = "single pane"
+				}
+				m.statusExpiry = time.Now().Add(2 * time.Second)
 
 			case ".":
 				m.showHidden = !m.showHidden
@@ -2153,11 +2355,48 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.statusExpiry = time.Now().Add(2 * time.Second)
 
 			case "y":
-				if len(m.filteredFiles) > 0 && m.cursor < len(m.filteredFiles) {
+				if m.hasSelection() {
+					// Selection: one path per line
+					paths := m.targetPaths()
+					m.copyPath(strings.Join(paths, "\n"))
+					if !strings.HasPrefix(m.statusMsg, "failed") {
+						m.statusMsg = fmt.Sprintf("copied %d paths", len(paths))
+					}
+				} else if len(m.filteredFiles) > 0 && m.cursor < len(m.filteredFiles) {
 					selected := m.filteredFiles[m.cursor]
 					m.copyPath(selected.path)
 				}
 
+			case " ":
+				// Toggle the mark on the cursor item and move down
+				m.commitVisual()
+				m.toggleSelection()
+				m.statusMsg = fmt.Sprintf("%d selected", len(m.selected))
+				m.statusExpiry = time.Now().Add(2 * time.Second)
+				m.updatePreview()
+
+			case "V":
+				// Visual range mode: V starts it, V again keeps the range selected
+				if m.visualActive {
+					m.commitVisual()
+					m.statusMsg = fmt.Sprintf("%d selected", len(m.selected))
+				} else {
+					m.startVisual()
+					m.statusMsg = "-- VISUAL -- (V: keep range | esc: cancel)"
+				}
+				m.statusExpiry = time.Now().Add(2 * time.Second)
+
+			case "v":
+				// Invert the selection in the current listing
+				m.invertSelection()
+				m.statusMsg = fmt.Sprintf("%d selected", len(m.selected))
+				m.statusExpiry = time.Now().Add(2 * time.Second)
+
+			case "+", "-":
+				// Select (+) or deselect (-) listed items matching a glob
+				m.enterSelectGlob(keyStr == "+")
+				return m, textinput.Blink
+
 			case "f":
 				// F = Navigate to directory/parent in scout (like middle-click)
 				if len(m.filteredFiles) > 0 && m.cursor < len(m.filteredFiles) {
@@ -2183,6 +2422,14 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 			case "o":
 				// O = Open externally (prefer editor/VS Code, fall back to system default)
+				if m.hasSelection() {
+					// Selection: open everything in one editor invocation
+					paths := m.targetPaths()
+					if len(paths) > 0 {
+						return m, m.execEditorFiles(paths)
+					}
+					break
+				}
 				if len(m.filteredFiles) > 0 && m.cursor < len(m.filteredFiles) {
 					selected := m.filteredFiles[m.cursor]
 					if selected.name == ".." {
@@ -2208,7 +2455,7 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, m.openInEditor(m.currentDir)
 
 			case "r":
-				m.loadFiles()
+				m.refreshPanes()
 				m.refreshGitStatus()
 				m.gitBranch = git.GetBranch(m.currentDir)
 				m.statusMsg = "refreshed"
@@ -2275,12 +2522,10 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 			// File operations
 			case "D":
-				// Delete file/directory
-				if len(m.filteredFiles) > 0 && m.cursor < len(m.filteredFiles) {
-					selected := m.filteredFiles[m.cursor]
-					if selected.name != ".." {
-						m.mode = modeConfirmFileDelete
-					}
+				// Delete the selection, or the file/directory under the cursor
+				if len(m.targetItems()) > 0 {
+					m.previousMode = modeNormal
+					m.mode = modeConfirmFileDelete
 				}
 
 			case "R":
@@ -2295,6 +2540,14 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					}
 				}
 
+			case "E":
+				// Bulk rename the selection (or everything listed) in the editor
+				return m, m.startBulkRename()
+
+			case "ctrl+e":
+				// Pattern rename the selection (or everything listed)
+				return m, m.startBatchRename()
+
 			case "n":
 				// Next key will determine action (nf = new file, nd = new dir)
 				// For simplicity, let's make 'n' followed by 'f' or 'd'
@@ -2318,7 +2571,11 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, textinput.Blink
 
 			case "c":
-				// Copy current file to clipboard
+				// Copy current file (or the selection) to clipboard
+				if m.hasSelection() {
+					m.stageSelection(opCopy, false)
+					break
+				}
 				if len(m.filteredFiles) > 0 && m.cursor < len(m.filteredFiles) {
 					current := m.filteredFiles[m.cursor]
 					if current.name != ".." {
@@ -2330,7 +2587,11 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 
 			case "x":
-				// Cut current file to clipboard
+				// Cut current file (or the selection) to clipboard
+				if m.hasSelection() {
+					m.stageSelection(opCut, false)
+					break
+				}
 				if len(m.filteredFiles) > 0 && m.cursor < len(m.filteredFiles) {
 					current := m.filteredFiles[m.cursor]
 					if current.name != ".." {
@@ -2342,7 +2603,11 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 
 			case "C":
-				// Append current file to copy clipboard (multi-file)
+				// Append current file (or the selection) to copy clipboard (multi-file)
+				if m.hasSelection() {
+					m.stageSelection(opCopy, true)
+					break
+				}
 				if len(m.filteredFiles) > 0 && m.cursor < len(m.filteredFiles) {
 					current := m.filteredFiles[m.cursor]
 					if current.name != ".." {
@@ -2360,7 +2625,11 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 
 			case "X":
-				// Append current file to cut clipboard (multi-file)
+				// Append current file (or the selection) to cut clipboard (multi-file)
+				if m.hasSelection() {
+					m.stageSelection(opCut, true)
+					break
+				}
 				if len(m.filteredFiles) > 0 && m.cursor < len(m.filteredFiles) {
 					current := m.filteredFiles[m.cursor]
 					if current.name != ".." {
@@ -2378,48 +2647,47 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 
 			case "p":
-				// Paste files from clipboard
-				if len(m.clipboard) > 0 {
-					var err error
-					if m.clipboardOp == opCopy {
-						err = m.copyFiles()
-					} else if m.clipboardOp == opCut {
-						err = m.cutFiles()
-						if err == nil {
-							m.clipboard = []string{}
-							m.clipboardOp = opNone
-						}
-					}
-					if err != nil {
-						m.showError("PASTE FAILED", err.Error())
-					} else {
-						m.statusMsg = "pasted successfully"
-						m.statusExpiry = time.Now().Add(2 * time.Second)
-						m.loadFiles()
-					}
-				}
+				// Paste files from clipboard (into the other pane when staged from this one)
+				return m, m.pasteClipboard()
+
+			case "J":
+				// Show background copy/move jobs
+				m.openJobsPanel()
 
 			case "u":
-				// Undo last deletion
-				if len(m.undoStack) > 0 {
-					lastUndo := m.undoStack[len(m.undoStack)-1]
-					m.undoStack = m.undoStack[:len(m.undoStack)-1]
-
-					if lastUndo.operation == "delete" && lastUndo.trashPath != "" {
-						// Try to restore from trash
-						if err := fileops.RestoreFromTrash(lastUndo.trashPath, lastUndo.path); err != nil {
-							m.showError("UNDO FAILED", fmt.Sprintf("could not restore %s: %v", filepath.Base(lastUndo.path), err))
-						} else {
-							m.statusMsg = fmt.Sprintf("restored: %s", filepath.Base(lastUndo.path))
-							m.statusExpiry = time.Now().Add(2 * time.Second)
-							m.loadFiles()
-						}
-					}
-				} else {
-					m.statusMsg = "nothing to undo"
-					m.statusExpiry = time.Now().Add(2 * time.Second)
+				// Undo the last file operation
+				m.undoLast()
+				if m.mode != modeErrorDialog {
+					m.refreshPanes()
 				}
 
+			case "ctrl+r":
+				// Redo the last undone file operation
+				m.redoLast()
+				if m.mode != modeErrorDialog {
+					m.refreshPanes()
+				}
+
+			case "U":
+				// Show the undo/redo history
+				m.openHistoryPanel()
+
+			case "T":
+				// Browse the trash: restore, delete for good, empty
+				m.openTrashPanel()
+
+			case "H":
+				// Page through the file under the cursor in hex
+				m.openHexViewer()
+
+			case "Z":
+				// Extract the archive(s) under the cursor or selected
+				return m, m.openExtractDialog(m.extractTargets())
+
+			case "z":
+				// Compress the selection (or cursor item) into a new archive
+				return m, m.openCompressDialog()
+
 			case "S":
 				// Cycle through sort modes: Name → Size → Date → Type → Name...
 				m.sortBy = (m.sortBy + 1) % 4
@@ -9,12 +9,20 @@ import (
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
 
 	"github.com/LFroesch/scout/internal/config"
+	"github.com/LFroesch/scout/internal/fileops"
+	"github.com/LFroesch/scout/internal/index"
+	"github.com/LFroesch/scout/internal/jobs"
+	"github.com/LFroesch/scout/internal/search"
 )
 
 func testModelForUpdate(t *testing.T, currentDir string) model {
 	t.Helper()
+	// Keep trash, undo records and history out of the real home directory
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_DATA_HOME", "")
 
 	searchInput := textinput.New()
 	searchInput.Focus()
@@ -42,6 +50,8 @@ func testModelForUpdate(t *testing.T, currentDir string) model {
 		previewCache:         make(map[string]previewCacheEntry),
 		visitedDirs:          make(map[string]bool),
 		doubleClickThreshold: 400 * time.Millisecond,
+		jobs:                 jobs.NewManager(),
+		journal:              fileops.NewJournal(""),
 	}
 	m.config.RootPath = ""
 	return m
@@ -244,3 +254,188 @@ func TestPerformAsyncRecursiveSearchRespectsShowHidden(t *testing.T) {
 
 	t.Fatal("timed out waiting for async recursive search to finish")
 }
+
+func TestPerformAsyncRecursiveSearchUsesIndexPastScanCap(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a/match-1.txt", "b/match-2.txt", "c/match-3.txt", "d/match-4.txt"} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m := testModelForUpdate(t, dir)
+	m.currentSearchType = searchRecursive
+	m.config.MaxFilesScanned = 2 // The disk walker would stop long before finding all four
+	m.indexes = index.NewManager(t.TempDir(), nil)
+	m.indexes.Ensure(dir)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for m.indexes.Building() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for index build")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	m.performAsyncSearch("match")
+
+	for time.Now().Before(deadline) {
+		m.searchShared.mu.Lock()
+		done := m.searchShared.done
+		count := len(m.searchShared.files)
+		m.searchShared.mu.Unlock()
+
+		if done {
+			if count != 4 {
+				t.Fatalf("expected index-backed search to find all 4 matches, got %d", count)
+			}
+			if label := m.indexStatusLabel(); !strings.HasPrefix(label, "idx 8 ") {
+				t.Fatalf("expected header index label for 8 entries, got %q", label)
+			}
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatal("timed out waiting for async recursive search to finish")
+}
+
+func TestCurrentDirSearchIsFuzzyAndRankedByScore(t *testing.T) {
+	dir := t.TempDir()
+	m := testModelForUpdate(t, dir)
+	m.files = []fileItem{
+		{name: "image.png", path: filepath.Join(dir, "image.png")},
+		{name: "model.go", path: filepath.Join(dir, "model.go")},
+		{name: "readme.md", path: filepath.Join(dir, "readme.md")},
+	}
+
+	m.searchCurrentDir("mg")
+
+	if len(m.filteredFiles) != 2 {
+		t.Fatalf("expected fuzzy match on image.png and model.go, got %d results", len(m.filteredFiles))
+	}
+	if m.filteredFiles[0].name != "model.go" {
+		t.Fatalf("expected model.go ranked first, got %q", m.filteredFiles[0].name)
+	}
+	if len(m.searchMatches[0]) != 2 || m.searchMatches[0][0] != 0 {
+		t.Fatalf("expected highlight positions to follow the sorted results, got %v", m.searchMatches[0])
+	}
+}
+
+func TestCurrentDirSearchAppliesQueryFilters(t *testing.T) {
+	dir := t.TempDir()
+	m := testModelForUpdate(t, dir)
+	m.files = []fileItem{
+		{name: "..", path: filepath.Dir(dir), isDir: true},
+		{name: "cmd", path: filepath.Join(dir, "cmd"), isDir: true},
+		{name: "main.go", path: filepath.Join(dir, "main.go"), size: 200},
+		{name: "model.go", path: filepath.Join(dir, "model.go"), size: 50000},
+		{name: "notes.md", path: filepath.Join(dir, "notes.md"), size: 90000},
+	}
+
+	m.searchCurrentDir("ext:go size:>10K")
+	if len(m.filteredFiles) != 1 || m.filteredFiles[0].name != "model.go" {
+		t.Fatalf("expected only model.go, got %+v", m.filteredFiles)
+	}
+
+	m.searchCurrentDir("type:dir")
+	if len(m.filteredFiles) != 1 || m.filteredFiles[0].name != "cmd" {
+		t.Fatalf("expected only cmd (no \"..\"), got %+v", m.filteredFiles)
+	}
+
+	m.searchCurrentDir("mn ext:go")
+	if len(m.filteredFiles) != 1 || m.filteredFiles[0].name != "main.go" {
+		t.Fatalf("expected text and filters combined to match main.go, got %+v", m.filteredFiles)
+	}
+}
+
+func TestSearchMatcherToggleAndInvalidPattern(t *testing.T) {
+	dir := t.TempDir()
+	m := testModelForUpdate(t, dir)
+	m.files = []fileItem{
+		{name: "main.go", path: filepath.Join(dir, "main.go")},
+		{name: "main_test.go", path: filepath.Join(dir, "main_test.go")},
+		{name: "notes.md", path: filepath.Join(dir, "notes.md")},
+	}
+
+	// fuzzy -> substring -> glob
+	for i := 0; i < 2; i++ {
+		gotModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlT})
+		m = *gotModel.(*model)
+	}
+	if m.searchMatchMode != search.MatchGlob {
+		t.Fatalf("expected glob mode after two ctrl+t, got %s", m.searchMatchMode)
+	}
+	m.searchInput.SetValue("*_test.go")
+	m.updateFilter()
+	if len(m.filteredFiles) != 1 || m.filteredFiles[0].name != "main_test.go" {
+		t.Fatalf("expected glob to match only main_test.go, got %+v", m.filteredFiles)
+	}
+
+	// An invalid regex is reported and the previous results stay on screen
+	gotModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlT})
+	m = *gotModel.(*model)
+	m.searchInput.SetValue("main(")
+	m.updateFilter()
+	if m.searchPatternErr == "" {
+		t.Fatal("expected an inline error for an unbalanced regex")
+	}
+	if len(m.filteredFiles) != 1 {
+		t.Fatalf("expected previous results to be kept, got %d", len(m.filteredFiles))
+	}
+
+	m.searchInput.SetValue(`^main.*\.go$`)
+	m.updateFilter()
+	if m.searchPatternErr != "" || len(m.filteredFiles) != 2 {
+		t.Fatalf("expected regex to match both main files, err=%q results=%d", m.searchPatternErr, len(m.filteredFiles))
+	}
+}
+
+func TestSearchIgnoreToggle(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, ".ignore"), []byte("*.log\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "app.log"), []byte("x"), 0644)
+	os.WriteFile(filepath.Join(dir, "app.go"), []byte("x"), 0644)
+
+	m := testModelForUpdate(t, dir)
+	m.recursiveSearch = true
+	m.currentSearchType = searchRecursive
+
+	m.recursiveSearchFiles("app")
+	if len(m.filteredFiles) != 1 || m.filteredFiles[0].name != "app.go" {
+		t.Fatalf("expected .ignore to hide app.log, got %+v", m.filteredFiles)
+	}
+
+	gotModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlO})
+	m = *gotModel.(*model)
+	if !m.searchNoIgnore {
+		t.Fatal("expected ctrl+o to include ignored files")
+	}
+	m.cancelCurrentSearch()
+	m.recursiveSearchFiles("app")
+	if len(m.filteredFiles) != 2 {
+		t.Fatalf("expected both files with ignored included, got %+v", m.filteredFiles)
+	}
+}
+
+func TestUltraProgressShowsEveryDrive(t *testing.T) {
+	m := testModelForUpdate(t, t.TempDir())
+	m.currentSearchType = searchUltra
+	m.ultraDrives = []string{"/mnt/c", "/mnt/d"}
+
+	for _, msg := range []searchPartialMsg{{count: 2000, drive: "D:"}, {count: 5000, drive: "C:"}, {count: 3000, drive: "D:"}} {
+		gotModel, _ := m.Update(msg)
+		m = *gotModel.(*model)
+	}
+	if m.scannedFiles != 8000 {
+		t.Fatalf("expected per-drive counts to add up to 8000, got %d", m.scannedFiles)
+	}
+	status := ansi.Strip(m.statusMsg)
+	if c, d := strings.Index(status, "C: 5000"), strings.Index(status, "D: 3000"); c < 0 || d < c {
+		t.Fatalf("expected both drives in mount order, got %q", status)
+	}
+}
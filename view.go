@@ -2,11 +2,20 @@ package main
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"path"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
+	xansi "github.com/charmbracelet/x/ansi"
 
+	"github.com/LFroesch/scout/internal/archive"
+	"github.com/LFroesch/scout/internal/hexview"
+	"github.com/LFroesch/scout/internal/jobs"
+	"github.com/LFroesch/scout/internal/search"
 	"github.com/LFroesch/scout/internal/utils"
 )
 
@@ -41,13 +50,31 @@ func (m *model) View() string {
 		mainContent = m.renderBookmarksView()
 	case modeHelp:
 		mainContent = m.renderHelpView()
+	case modeHex:
+		mainContent = m.renderHexView()
 	default:
 		if m.dualPane {
-			// Dual pane mode
-			leftPane := m.renderFileList(m.width / 2)
-			// For now, right pane shows same directory
-			rightPane := m.renderFileList(m.width / 2)
-			mainContent = lipgloss.JoinHorizontal(lipgloss.Top, leftPane, rightPane)
+			// Dual pane mode: the active pane sits on the side given by activePane
+			availableHeight := m.height - uiOverhead
+			if availableHeight < 3 {
+				availableHeight = 3
+			}
+			panelHeight := availableHeight + 2
+
+			leftWidth := m.width / 2
+			rightWidth := m.width - leftWidth
+			var leftPane, rightPane string
+			if m.activePane == 0 {
+				leftPane = m.renderFileList(leftWidth, true)
+				rightPane = m.renderOtherPane(rightWidth)
+			} else {
+				leftPane = m.renderOtherPane(leftWidth)
+				rightPane = m.renderFileList(rightWidth, true)
+			}
+
+			leftStyled := lipgloss.NewStyle().Height(panelHeight).Render(leftPane)
+			rightStyled := lipgloss.NewStyle().Height(panelHeight).Render(rightPane)
+			mainContent = lipgloss.JoinHorizontal(lipgloss.Top, leftStyled, rightStyled)
 		} else if m.showPreview {
 			// Split view with preview - ensure both panels have same height
 			availableHeight := m.height - uiOverhead
@@ -56,7 +83,7 @@ func (m *model) View() string {
 			}
 			panelHeight := availableHeight + 2
 
-			fileList := m.renderFileList(m.width / 2)
+			fileList := m.renderFileList(m.width/2, true)
 			preview := m.renderPreview(m.width / 2)
 
 			// Force both panels to exact same height
@@ -66,7 +93,7 @@ func (m *model) View() string {
 			mainContent = lipgloss.JoinHorizontal(lipgloss.Top, fileListStyled, previewStyled)
 		} else {
 			// Full width file list
-			mainContent = m.renderFileList(m.width)
+			mainContent = m.renderFileList(m.width, true)
 		}
 	}
 
@@ -88,13 +115,37 @@ func (m *model) View() string {
 		content = placeOverlay(content, m.renderConfirmFileDeleteView())
 	case modeRename:
 		content = placeOverlay(content, m.renderRenameDialog())
+	case modeSelectGlob:
+		content = placeOverlay(content, m.renderSelectGlobDialog())
+	case modePreviewLine:
+		content = placeOverlay(content, m.renderPreviewLineDialog())
+	case modeFollowPattern:
+		content = placeOverlay(content, m.renderFollowDialog())
+	case modeConfirmBulkRename:
+		content = placeOverlay(content, m.renderBulkRenameDialog())
+	case modeBatchRename:
+		content = placeOverlay(content, m.renderBatchRenameDialog())
+	case modeJobs:
+		content = placeOverlay(content, m.renderJobsDialog())
+	case modePasteConflict:
+		content = placeOverlay(content, m.renderPasteConflictDialog())
+	case modeHistory:
+		content = placeOverlay(content, m.renderHistoryDialog())
+	case modeTrash:
+		content = placeOverlay(content, m.renderTrashDialog())
+	case modeArchive:
+		content = placeOverlay(content, m.renderArchiveDialog())
+	case modeExtract:
+		content = placeOverlay(content, m.renderExtractDialog())
+	case modeCompress:
+		content = placeOverlay(content, m.renderCompressDialog())
 	case modeCreateFile:
 		content = placeOverlay(content, m.renderCreateFileDialog())
 	case modeCreateDir:
 		content = placeOverlay(content, m.renderCreateDirDialog())
 	}
 
-	return content
+	return m.placeImage(content)
 }
 
 func (m model) renderHeader() string {
@@ -169,9 +220,24 @@ func (m model) renderHeader() string {
 			searchMode = "SEARCH"
 		}
 
-		// Add name/path indicator for recursive modes
+		// Add name/path and matcher indicators, e.g. "RECURSIVE SEARCH (name, glob)"
+		var searchTags []string
 		if m.searchNameOnly && (m.recursiveSearch || m.currentSearchType == searchUltra) {
-			searchMode += " (name)"
+			searchTags = append(searchTags, "name")
+		}
+		if m.searchMatchMode != search.MatchFuzzy && m.currentSearchType != searchContent {
+			searchTags = append(searchTags, m.searchMatchMode.String())
+		}
+		if m.searchNoIgnore && (m.recursiveSearch || m.currentSearchType == searchUltra || m.currentSearchType == searchContent) {
+			searchTags = append(searchTags, "+ignored")
+		}
+		if len(searchTags) > 0 {
+			searchMode += " (" + strings.Join(searchTags, ", ") + ")"
+		}
+
+		// Index status (entry count + age) for index-backed modes
+		if label := m.indexStatusLabel(); label != "" && m.width >= 80 {
+			searchMode += " [" + label + "]"
 		}
 
 		searchLabel := fmt.Sprintf("🔍 %s: ", searchMode)
@@ -201,6 +267,22 @@ func (m model) renderHeader() string {
 			displayRendered = grayStyle.Render(displayValue)
 		}
 
+		// Active query filters as chips, bad filter tokens and patterns in red
+		if parsed := search.ParseQuery(searchValue); len(parsed.Chips) > 0 || len(parsed.Errors) > 0 || m.searchPatternErr != "" {
+			chipStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("255")).Background(lipgloss.Color("57")).Inline(true)
+			errStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Background(lipgloss.Color("235")).Bold(true).Inline(true)
+			gap := lipgloss.NewStyle().Background(lipgloss.Color("235")).Render(" ")
+			for _, chip := range parsed.Chips {
+				displayRendered += gap + chipStyle.Render(" "+chip+" ")
+			}
+			for _, bad := range parsed.Errors {
+				displayRendered += gap + errStyle.Render("✗ "+bad)
+			}
+			if m.searchPatternErr != "" {
+				displayRendered += gap + errStyle.Render("✗ "+m.searchMatchMode.String()+": "+m.searchPatternErr)
+			}
+		}
+
 		// Pick hint based on width
 		var hint string
 		if m.searchResultsLocked {
@@ -270,6 +352,53 @@ func (m model) renderHeader() string {
 	return titleStyle.Render(title)
 }
 
+// indexStatusLabel describes the filename index behind recursive/ultra search,
+// e.g. "idx 12.3k · 4m", "idx building…" or "no idx"
+func (m *model) indexStatusLabel() string {
+	if m.indexes == nil {
+		return ""
+	}
+	var dirs []string
+	switch {
+	case m.currentSearchType == searchUltra:
+		dirs = m.ultraDrives
+	case m.recursiveSearch || m.currentSearchType == searchRecursive:
+		dirs = []string{m.currentDir}
+	}
+	if len(dirs) == 0 {
+		return ""
+	}
+
+	entries, ready, building := 0, 0, false
+	var oldest time.Time
+	for _, dir := range dirs {
+		st := m.indexes.Status(dir)
+		building = building || st.Building
+		if st.Ready {
+			ready++
+			entries += st.Entries
+			if oldest.IsZero() || st.BuiltAt.Before(oldest) {
+				oldest = st.BuiltAt
+			}
+		}
+	}
+	if ready == 0 {
+		if building {
+			return "idx building…"
+		}
+		return "no idx"
+	}
+
+	label := fmt.Sprintf("idx %s · %s", formatCount(entries), formatAge(time.Since(oldest)))
+	if ready < len(dirs) {
+		label += fmt.Sprintf(" %d/%d", ready, len(dirs))
+	}
+	if building {
+		label += " ↻"
+	}
+	return label
+}
+
 func (m *model) renderStatusBar() string {
 	// Normal status bar
 	statusStyle := lipgloss.NewStyle().
@@ -317,6 +446,23 @@ func (m *model) renderStatusBar() string {
 			statusText = fileCountInfo
 		}
 
+		// Active pane indicator (dual-pane mode)
+		if m.dualPane {
+			paneName := "left"
+			if m.activePane == 1 {
+				paneName = "right"
+			}
+			statusText += whiteStyle.Render(" | ") + purpleStyle.Render("pane:") + whiteStyle.Render(" "+paneName)
+		}
+
+		// Selection info
+		if m.visualActive {
+			statusText += whiteStyle.Render(" | ") + purpleStyle.Render("VISUAL")
+		}
+		if count := m.selectionCount(); count > 0 {
+			statusText += whiteStyle.Render(" | ") + purpleStyle.Render("sel:") + whiteStyle.Render(fmt.Sprintf(" %d", count))
+		}
+
 		// Git info
 		if m.gitBranch != "" {
 			statusText += whiteStyle.Render(" | ") + purpleStyle.Render("branch:") + whiteStyle.Render(" "+m.gitBranch)
@@ -339,6 +485,11 @@ func (m *model) renderStatusBar() string {
 			statusText += whiteStyle.Render(" | ") + purpleStyle.Render(clipInfo)
 		}
 
+		// Background copy/move progress
+		if progress := m.jobProgressText(); progress != "" {
+			statusText += whiteStyle.Render(" | ") + purpleStyle.Render(progress)
+		}
+
 		// Status message (shows drive info during loading or other temporary messages)
 		if m.statusMsg != "" {
 			statusText += whiteStyle.Render(" | " + m.statusMsg)
@@ -415,8 +566,9 @@ func (m *model) renderStatusBar() string {
 	return statusStyle.Render(statusText)
 }
 
-// renderFileList renders the file list panel with the given width
-func (m *model) renderFileList(width int) string {
+// renderFileList renders the file list panel with the given width.
+// focused is false only for the inactive side in dual-pane mode (dimmed cursor, no search state).
+func (m *model) renderFileList(width int, focused bool) string {
 	// Calculate available height for file list
 	availableHeight := m.height - uiOverhead // Account for header, status, borders, padding
 	if availableHeight < 3 {
@@ -435,8 +587,14 @@ func (m *model) renderFileList(width int) string {
 		dirName = "/"
 	}
 
+	// Cursor background: bright for the focused list, dim for the inactive pane
+	selBg := lipgloss.Color("57")
+	if !focused {
+		selBg = lipgloss.Color("238")
+	}
+
 	var searchModeIndicator string
-	if m.mode == modeSearch {
+	if m.mode == modeSearch && focused {
 		switch m.currentSearchType {
 		case searchUltra:
 			searchModeIndicator = " [ULTRA SEARCH]"
@@ -515,8 +673,13 @@ func (m *model) renderFileList(width int) string {
 		endIdx = len(m.filteredFiles)
 	}
 
+	// Selection markers get their own column, only while something in this pane is selected
+	visualLo, visualHi, inVisual := m.visualRange()
+	showMarks := len(m.selected) > 0 || inVisual
+
 	for i := startIdx; i < endIdx && i < len(m.filteredFiles); i++ {
 		item := m.filteredFiles[i]
+		marked := showMarks && m.isMarked(i, visualLo, visualHi, inVisual)
 
 		// Icon
 		icon := "📄"
@@ -537,14 +700,14 @@ func (m *model) renderFileList(width int) string {
 		if m.gitModified[item.path] {
 			modifiedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Bold(true)
 			if isSelected {
-				modifiedStyle = modifiedStyle.Background(lipgloss.Color("57"))
+				modifiedStyle = modifiedStyle.Background(selBg)
 			}
 			gitStatus = " " + modifiedStyle.Render("[M]")
 		}
 		if item.isSymlink {
 			symlinkStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("cyan"))
 			if isSelected {
-				symlinkStyle = symlinkStyle.Background(lipgloss.Color("57"))
+				symlinkStyle = symlinkStyle.Background(selBg)
 			}
 			gitStatus += " " + symlinkStyle.Render("[→]")
 		}
@@ -554,7 +717,7 @@ func (m *model) renderFileList(width int) string {
 		displayName := name
 
 		// Apply search highlighting if we have match positions
-		if m.mode == modeSearch && i < len(m.searchMatches) && len(m.searchMatches[i]) > 0 {
+		if m.mode == modeSearch && focused && i < len(m.searchMatches) && len(m.searchMatches[i]) > 0 {
 			displayName = utils.HighlightMatches(name, m.searchMatches[i])
 		}
 
@@ -578,7 +741,7 @@ func (m *model) renderFileList(width int) string {
 			sizeWidth = fixedSizeWidth
 		}
 		if isSelected && sizeStr != "" {
-			sizeStr = lipgloss.NewStyle().Background(lipgloss.Color("57")).Inline(true).Render(sizeStr)
+			sizeStr = lipgloss.NewStyle().Background(selBg).Inline(true).Render(sizeStr)
 		}
 
 		// Add modification date when there's enough space
@@ -597,6 +760,9 @@ func (m *model) renderFileList(width int) string {
 			rightColWidth += dateWidth + 2 // "  " separator
 		}
 		reservedSpace := 2 + 1 + 8 + rightColWidth + 10
+		if showMarks {
+			reservedSpace += 2 // Marker column
+		}
 		maxNameLen := totalWidth - reservedSpace
 
 		// Progressively drop columns if name would be too short
@@ -621,7 +787,7 @@ func (m *model) renderFileList(width int) string {
 
 		// Truncate name if needed
 		if lipgloss.Width(name) > maxNameLen {
-			if m.mode == modeSearch && strings.Contains(name, string(filepath.Separator)) {
+			if m.mode == modeSearch && focused && strings.Contains(name, string(filepath.Separator)) {
 				// Search results: left-truncate to preserve filename
 				runes := []rune(name)
 				truncated := ""
@@ -653,8 +819,19 @@ func (m *model) renderFileList(width int) string {
 			}
 		}
 
-		// Build left side: icon + name + gitStatus (which includes symlink indicator)
+		// Build left side: [mark] + icon + name + gitStatus (which includes symlink indicator)
 		leftSide := fmt.Sprintf("%s %s%s", icon, displayName, gitStatus)
+		if showMarks {
+			markStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Bold(true)
+			if isSelected {
+				markStyle = markStyle.Background(selBg)
+			}
+			mark := " "
+			if marked {
+				mark = "●"
+			}
+			leftSide = markStyle.Render(mark) + " " + leftSide
+		}
 		leftWidth := lipgloss.Width(leftSide)
 
 		// Build right side: size + date
@@ -663,19 +840,19 @@ func (m *model) renderFileList(width int) string {
 		if dateStr != "" && sizeStr != "" {
 			var dateStyled string
 			if isSelected {
-				dateStyled = lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Background(lipgloss.Color("57")).Inline(true).Render(dateStr)
+				dateStyled = lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Background(selBg).Inline(true).Render(dateStr)
 			} else {
 				dateStyled = lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Inline(true).Render(dateStr)
 			}
 			sep := "  "
 			if isSelected {
-				sep = lipgloss.NewStyle().Background(lipgloss.Color("57")).Render("  ")
+				sep = lipgloss.NewStyle().Background(selBg).Render("  ")
 			}
 			rightStr = sizeStr + sep + dateStyled
 			totalRightWidth = sizeWidth + 2 + dateWidth
 		} else if dateStr != "" {
 			if isSelected {
-				rightStr = lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Background(lipgloss.Color("57")).Inline(true).Render(dateStr)
+				rightStr = lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Background(selBg).Inline(true).Render(dateStr)
 			} else {
 				rightStr = lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Inline(true).Render(dateStr)
 			}
@@ -700,7 +877,7 @@ func (m *model) renderFileList(width int) string {
 		// Build the line with right side right-aligned
 		var paddingStr string
 		if isSelected {
-			paddingStr = lipgloss.NewStyle().Background(lipgloss.Color("57")).Render(strings.Repeat(" ", padding))
+			paddingStr = lipgloss.NewStyle().Background(selBg).Render(strings.Repeat(" ", padding))
 		} else {
 			paddingStr = strings.Repeat(" ", padding)
 		}
@@ -709,9 +886,13 @@ func (m *model) renderFileList(width int) string {
 		// Style based on selection (don't set Width here - we already calculated exact spacing)
 		if i == m.cursor {
 			selectedStyle := lipgloss.NewStyle().
-				Background(lipgloss.Color("57")).
+				Background(selBg).
 				Foreground(lipgloss.Color("230"))
 			line = selectedStyle.Render(line)
+		} else if marked {
+			markedStyle := lipgloss.NewStyle().
+				Foreground(lipgloss.Color("214"))
+			line = markedStyle.Render(line)
 		} else {
 			normalStyle := lipgloss.NewStyle().
 				Foreground(lipgloss.Color("252"))
@@ -731,10 +912,14 @@ func (m *model) renderFileList(width int) string {
 
 	fileList := listStyle.Render(strings.Join(items, "\n"))
 
-	// Combine header and file list with border
+	// Combine header and file list with border (highlight the active side in dual-pane mode)
+	borderColor := lipgloss.Color("240")
+	if m.dualPane && focused {
+		borderColor = lipgloss.Color("105")
+	}
 	borderStyle := lipgloss.NewStyle().
 		Border(lipgloss.NormalBorder()).
-		BorderForeground(lipgloss.Color("240")).
+		BorderForeground(borderColor).
 		Width(width - 2).
 		Height(availableHeight + 1)
 
@@ -807,20 +992,13 @@ func (m *model) renderPreview(width int) string {
 		// Word-wrap long lines to fit panel width without losing content
 		maxLineWidth := width - 6 // Account for borders and padding
 		for _, line := range m.previewLines[startIdx:endIdx] {
-			runes := []rune(line)
-			for len(runes) > maxLineWidth {
-				// Find last space within maxLineWidth for word break
-				breakAt := maxLineWidth
-				for i := maxLineWidth - 1; i > 0; i-- {
-					if runes[i] == ' ' || runes[i] == '\t' {
-						breakAt = i + 1
-						break
-					}
-				}
-				lines = append(lines, string(runes[:breakAt]))
-				runes = runes[breakAt:]
+			if lipgloss.Width(line) <= maxLineWidth {
+				lines = append(lines, line)
+				continue
 			}
-			lines = append(lines, string(runes))
+			// Break at the last space that fits, measuring display width so the colour
+			// codes of highlighted previews don't count (or get cut in half)
+			lines = append(lines, strings.Split(xansi.Wrap(line, maxLineWidth, " \t"), "\n")...)
 		}
 
 		if hasBottomIndicator {
@@ -1075,6 +1253,10 @@ func (m model) renderConfirmFileDeleteView() string {
 	}
 	dialogHeight := 8
 
+	if m.previousMode != modeSearch && m.hasSelection() {
+		return m.renderConfirmSelectionDeleteView(dialogWidth)
+	}
+
 	if len(m.filteredFiles) == 0 || m.cursor >= len(m.filteredFiles) {
 		return "Error: No file selected"
 	}
@@ -1150,7 +1332,79 @@ func (m model) renderRenameDialog() string {
 	return dialogStyle.Render(dialog)
 }
 
-func (m model) renderCreateFileDialog() string {
+// renderConfirmSelectionDeleteView is the delete confirmation for a multi-item selection
+func (m model) renderConfirmSelectionDeleteView(dialogWidth int) string {
+	items := m.targetItems()
+	const maxListed = 5
+
+	dialogStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("196")).
+		Background(lipgloss.Color("232")).
+		Padding(1, 2).
+		Width(dialogWidth)
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("196")).
+		Background(lipgloss.Color("232"))
+
+	contentStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("252")).
+		Padding(1, 0).
+		Background(lipgloss.Color("232"))
+
+	promptStyle := lipgloss.NewStyle().
+		Bold(true).
+		Padding(1, 0).
+		Background(lipgloss.Color("232"))
+
+	// Marks made in other directories are listed in full, by path, so nothing off screen
+	// is deleted unseen. Only a list too long for the terminal is cut short.
+	listed := make(map[string]bool, len(m.filteredFiles))
+	for _, f := range m.filteredFiles {
+		listed[f.path] = true
+	}
+	var here, elsewhere []string
+	for _, item := range items {
+		name := item.name
+		if item.isDir {
+			name += "/"
+		}
+		if listed[item.path] {
+			here = append(here, name)
+		} else {
+			// Cut long paths at the start, so the name being deleted stays readable
+			path := filepath.Join(filepath.Dir(item.path), name)
+			if over := xansi.StringWidth(path) - (dialogWidth - 6); over > 0 {
+				path = xansi.TruncateLeft(path, over+1, "…")
+			}
+			elsewhere = append(elsewhere, path)
+		}
+	}
+	if len(here) > maxListed {
+		here = append(here[:maxListed], fmt.Sprintf("... and %d more", len(here)-maxListed))
+	}
+	if room := max(m.height-20-len(here), 3); len(elsewhere) > room {
+		elsewhere = append(elsewhere[:room-1], fmt.Sprintf("... and %d more elsewhere", len(elsewhere)-room+1))
+	}
+	names := strings.Join(here, "\n")
+	if len(elsewhere) > 0 {
+		if names != "" {
+			names += "\n\n"
+		}
+		names += "in other directories:\n" + strings.Join(elsewhere, "\n")
+	}
+
+	title := titleStyle.Render(fmt.Sprintf("DELETE %d SELECTED ITEMS?", len(items)))
+	content := contentStyle.Render(fmt.Sprintf("are you sure you want to delete:\n\n%s\n\nthis will move them to trash if available.", names))
+	prompt := promptStyle.Render("press 'y' to confirm, 'n' or esc to cancel")
+
+	dialog := title + "\n" + content + "\n" + prompt
+	return dialogStyle.Render(dialog)
+}
+
+func (m model) renderSelectGlobDialog() string {
 	dialogWidth := 60
 	if m.width-4 < dialogWidth {
 		dialogWidth = m.width - 4
@@ -1159,7 +1413,7 @@ func (m model) renderCreateFileDialog() string {
 
 	dialogStyle := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("105")).
+		BorderForeground(lipgloss.Color("214")).
 		Background(lipgloss.Color("232")).
 		Padding(1, 2).
 		Width(dialogWidth).
@@ -1167,7 +1421,7 @@ func (m model) renderCreateFileDialog() string {
 
 	titleStyle := lipgloss.NewStyle().
 		Bold(true).
-		Foreground(lipgloss.Color("105")).
+		Foreground(lipgloss.Color("214")).
 		Background(lipgloss.Color("232"))
 
 	contentStyle := lipgloss.NewStyle().
@@ -1175,28 +1429,31 @@ func (m model) renderCreateFileDialog() string {
 		Padding(1, 0).
 		Background(lipgloss.Color("232"))
 
-	title := titleStyle.Render("📄 CREATE NEW FILE")
-	content := contentStyle.Render("enter filename:")
+	action := "SELECT"
+	if !m.selectGlobOn {
+		action = "DESELECT"
+	}
+	title := titleStyle.Render("● " + action + " BY PATTERN")
+	content := contentStyle.Render("glob matched against names in this listing:")
 	inputView := m.textInput.View()
 
 	dialog := title + "\n" + content + "\n" + inputView
 	return dialogStyle.Render(dialog)
 }
 
-func (m model) renderCreateDirDialog() string {
-	dialogWidth := 60
+// renderPreviewLineDialog asks for a line of the previewed file to go to
+func (m model) renderPreviewLineDialog() string {
+	dialogWidth := 50
 	if m.width-4 < dialogWidth {
 		dialogWidth = m.width - 4
 	}
-	dialogHeight := 8
 
 	dialogStyle := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
 		BorderForeground(lipgloss.Color("105")).
 		Background(lipgloss.Color("232")).
 		Padding(1, 2).
-		Width(dialogWidth).
-		Height(dialogHeight)
+		Width(dialogWidth)
 
 	titleStyle := lipgloss.NewStyle().
 		Bold(true).
@@ -1208,118 +1465,1045 @@ func (m model) renderCreateDirDialog() string {
 		Padding(1, 0).
 		Background(lipgloss.Color("232"))
 
-	title := titleStyle.Render("📁 CREATE NEW DIRECTORY")
-	content := contentStyle.Render("enter directory name:")
-	inputView := m.textInput.View()
-
-	dialog := title + "\n" + content + "\n" + inputView
-	return dialogStyle.Render(dialog)
+	name := ""
+	if m.cursor < len(m.filteredFiles) {
+		name = m.filteredFiles[m.cursor].name
+	}
+	title := titleStyle.Render(xansi.Truncate("↧  GO TO LINE in "+name, dialogWidth-6, "…"))
+	content := contentStyle.Render("preview from line:")
+	return dialogStyle.Render(title + "\n" + content + "\n" + m.textInput.View())
 }
 
-func (m *model) renderHelpView() string {
-	availableHeight := m.height - uiOverhead
-	if availableHeight < 3 {
-		availableHeight = 3
+// renderFollowDialog asks for the pattern to highlight in the followed file
+func (m model) renderFollowDialog() string {
+	dialogWidth := 50
+	if m.width-4 < dialogWidth {
+		dialogWidth = m.width - 4
 	}
 
-	// Reserve space for scroll indicators
-	contentHeight := availableHeight - 1
-	if contentHeight < 1 {
-		contentHeight = 1
-	}
+	dialogStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("105")).
+		Background(lipgloss.Color("232")).
+		Padding(1, 2).
+		Width(dialogWidth)
 
-	headerStyle := lipgloss.NewStyle().
+	titleStyle := lipgloss.NewStyle().
 		Bold(true).
 		Foreground(lipgloss.Color("105")).
-		Width(m.width - 4)
+		Background(lipgloss.Color("232"))
 
-	header := headerStyle.Render("❓ HELP")
+	contentStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("252")).
+		Padding(1, 0).
+		Background(lipgloss.Color("232"))
 
-	listStyle := lipgloss.NewStyle().
-		Width(m.width-4).
-		Padding(0, 1)
+	name := ""
+	if m.cursor < len(m.filteredFiles) {
+		name = m.filteredFiles[m.cursor].name
+	}
+	title := titleStyle.Render(xansi.Truncate("⇣  FOLLOW "+name, dialogWidth-6, "…"))
+	content := contentStyle.Render("highlight lines matching (regex, optional):")
+	return dialogStyle.Render(title + "\n" + content + "\n" + m.textInput.View())
+}
 
-	borderStyle := lipgloss.NewStyle().
-		Border(lipgloss.NormalBorder()).
-		BorderForeground(lipgloss.Color("240")).
-		Width(m.width - 2).
-		Height(availableHeight + 1)
+// renderBulkRenameDialog shows the plan read back from the editor as a scrollable diff
+func (m model) renderBulkRenameDialog() string {
+	br := m.bulkRename
+	if br == nil || br.plan == nil {
+		return "Error: No rename plan"
+	}
+	dialogWidth := 80
+	if m.width-4 < dialogWidth {
+		dialogWidth = m.width - 4
+	}
+	visible := m.height - 14
+	if visible > 15 {
+		visible = 15
+	}
+	if visible < 3 {
+		visible = 3
+	}
 
-	// Build help content
-	keyStyle := lipgloss.NewStyle().
+	dialogStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("105")).
+		Background(lipgloss.Color("232")).
+		Padding(1, 2).
+		Width(dialogWidth)
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
 		Foreground(lipgloss.Color("105")).
-		Bold(true)
+		Background(lipgloss.Color("232"))
 
-	sectionStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("214")).
-		Bold(true)
+	contentStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("252")).
+		Padding(1, 0).
+		Background(lipgloss.Color("232"))
 
-	var allHelpContent []string
+	renameStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("42")).Background(lipgloss.Color("232"))
+	deleteStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Background(lipgloss.Color("232"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245")).Background(lipgloss.Color("232"))
 
-	// helpLine pads the key to a fixed column width so descriptions align
-	const keyColWidth = 14
-	helpLine := func(key, desc string) string {
-		pad := keyColWidth - lipgloss.Width(key)
-		if pad < 1 {
-			pad = 1
+	promptStyle := lipgloss.NewStyle().
+		Bold(true).
+		Padding(1, 0).
+		Background(lipgloss.Color("232"))
+
+	plan := br.plan
+	var summary []string
+	if n := len(plan.Renames); n > 0 {
+		summary = append(summary, fmt.Sprintf("%d rename(s)", n))
+	}
+	if n := len(plan.Deletes); n > 0 {
+		summary = append(summary, fmt.Sprintf("%d to trash", n))
+	}
+	if plan.Cycles > 0 {
+		summary = append(summary, fmt.Sprintf("%d swap(s)/cycle(s) via a temporary name", plan.Cycles))
+	}
+
+	lines := br.planLines()
+	start := br.scroll
+	if start > len(lines)-visible {
+		start = len(lines) - visible
+	}
+	if start < 0 {
+		start = 0
+	}
+	end := start + visible
+	if end > len(lines) {
+		end = len(lines)
+	}
+	lineWidth := dialogWidth - 6
+	var diff []string
+	for i := start; i < end; i++ {
+		line := xansi.Truncate(lines[i], lineWidth, "…")
+		if i < len(plan.Renames) {
+			diff = append(diff, renameStyle.Render(line))
+		} else {
+			diff = append(diff, deleteStyle.Render(line))
 		}
-		return "  " + keyStyle.Render(key) + strings.Repeat(" ", pad) + desc
+	}
+	if len(lines) > visible {
+		diff = append(diff, dimStyle.Render(fmt.Sprintf("  (%d-%d of %d, j/k to scroll)", start+1, end, len(lines))))
 	}
 
-	// Navigation section
-	allHelpContent = append(allHelpContent, sectionStyle.Render("NAVIGATION:"))
-	allHelpContent = append(allHelpContent, helpLine("j / ↓", "move down"))
-	allHelpContent = append(allHelpContent, helpLine("k / ↑", "move up"))
-	allHelpContent = append(allHelpContent, helpLine("enter / l / →", "enter directory / open file"))
-	allHelpContent = append(allHelpContent, helpLine("esc / h / ←", "go to parent directory"))
-	allHelpContent = append(allHelpContent, helpLine("f", "navigate to dir/parent (exit search)"))
-	allHelpContent = append(allHelpContent, helpLine("middle-click", "navigate to clicked dir/parent"))
-	allHelpContent = append(allHelpContent, helpLine("g", "go to top"))
-	allHelpContent = append(allHelpContent, helpLine("G", "go to bottom"))
-	allHelpContent = append(allHelpContent, helpLine("ctrl+d", "half-page down"))
-	allHelpContent = append(allHelpContent, helpLine("ctrl+u", "half-page up"))
-	allHelpContent = append(allHelpContent, helpLine("ctrl+f", "full-page down"))
-	allHelpContent = append(allHelpContent, helpLine("ctrl+b", "full-page up"))
-	allHelpContent = append(allHelpContent, helpLine("~", "jump to home directory"))
-	allHelpContent = append(allHelpContent, helpLine("`", "jump to /mnt/c (wsl)"))
-	allHelpContent = append(allHelpContent, "")
+	title := titleStyle.Render("✏️  BULK RENAME")
+	content := contentStyle.Render(strings.Join(summary, ", ") + ":")
+	prompt := promptStyle.Render("press 'y' to apply, 'n' or esc to cancel")
 
-	// Preview Scrolling section
-	allHelpContent = append(allHelpContent, sectionStyle.Render("PREVIEW SCROLLING:"))
-	allHelpContent = append(allHelpContent, helpLine("s / alt+↓", "scroll preview down"))
-	allHelpContent = append(allHelpContent, helpLine("w / alt+↑", "scroll preview up"))
-	allHelpContent = append(allHelpContent, "")
+	dialog := title + "\n" + content + "\n" + strings.Join(diff, "\n") + "\n" + prompt
+	return dialogStyle.Render(dialog)
+}
 
-	// File Operations section
-	allHelpContent = append(allHelpContent, sectionStyle.Render("FILE OPERATIONS:"))
-	allHelpContent = append(allHelpContent, helpLine("o", "open selected in editor/vs code"))
-	allHelpContent = append(allHelpContent, helpLine("O", "open current directory in vs code"))
-	allHelpContent = append(allHelpContent, helpLine("R", "rename file/directory"))
-	allHelpContent = append(allHelpContent, helpLine("D", "delete file/directory"))
-	allHelpContent = append(allHelpContent, helpLine("N", "create new file"))
-	allHelpContent = append(allHelpContent, helpLine("M", "create new directory"))
-	allHelpContent = append(allHelpContent, helpLine("r", "refresh current view"))
-	allHelpContent = append(allHelpContent, "")
+// renderBatchRenameDialog shows the pattern rename fields above a live old → new preview
+func (m model) renderBatchRenameDialog() string {
+	br := m.batchRename
+	if br == nil {
+		return "Error: No rename in progress"
+	}
+	dialogWidth := 90
+	if m.width-4 < dialogWidth {
+		dialogWidth = m.width - 4
+	}
+	visible := m.height - 22
+	if visible > 12 {
+		visible = 12
+	}
+	if visible < 3 {
+		visible = 3
+	}
 
-	// Clipboard Operations section
-	allHelpContent = append(allHelpContent, sectionStyle.Render("CLIPBOARD:"))
-	allHelpContent = append(allHelpContent, helpLine("c", "copy file (replaces clipboard)"))
-	allHelpContent = append(allHelpContent, helpLine("x", "cut file (replaces clipboard)"))
-	allHelpContent = append(allHelpContent, helpLine("C", "append to copy clipboard (multi-file)"))
-	allHelpContent = append(allHelpContent, helpLine("X", "append to cut clipboard (multi-file)"))
-	allHelpContent = append(allHelpContent, helpLine("p", "paste files"))
-	allHelpContent = append(allHelpContent, helpLine("y", "copy path to clipboard"))
-	allHelpContent = append(allHelpContent, helpLine("u", "undo last deletion"))
+	dialogStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("105")).
+		Background(lipgloss.Color("232")).
+		Padding(1, 2).
+		Width(dialogWidth)
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("105")).
+		Background(lipgloss.Color("232"))
+
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("252")).Background(lipgloss.Color("232")).Width(9)
+	activeLabelStyle := labelStyle.Foreground(lipgloss.Color("105")).Bold(true)
+	oldStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245")).Background(lipgloss.Color("232"))
+	newStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("42")).Background(lipgloss.Color("232"))
+	conflictStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Background(lipgloss.Color("232"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Background(lipgloss.Color("232"))
+
+	promptStyle := lipgloss.NewStyle().
+		Bold(true).
+		Padding(1, 0, 0, 0).
+		Background(lipgloss.Color("232"))
+
+	var lines []string
+	lines = append(lines, titleStyle.Render("✏️  PATTERN RENAME"), "")
+	labels := [batchFieldCount]string{"find:", "replace:", "ext:"}
+	for i, label := range labels {
+		style := labelStyle
+		if i == br.focus {
+			style = activeLabelStyle
+		}
+		lines = append(lines, style.Render(label)+br.inputs[i].View())
+	}
+	lines = append(lines, labelStyle.Render("case:")+br.caseMode.String()+dimStyle.Render("  (ctrl+t)"))
+	lines = append(lines, dimStyle.Render("tokens: $1 ${name}  {n} {n:3} {n:3:0}  {date} {date:YYYYMMDD}  {name} {ext}"), "")
+
+	conflicts := br.conflicts()
+	summary := fmt.Sprintf("%d of %d item(s) change", len(br.rows), len(br.items))
+	if conflicts > 0 {
+		summary += conflictStyle.Render(fmt.Sprintf(", %d conflict(s)", conflicts))
+	}
+	lines = append(lines, summary)
+	if br.err != "" {
+		lines = append(lines, conflictStyle.Render(xansi.Truncate(br.err, dialogWidth-6, "…")))
+	}
+
+	// Preview table: old name, arrow, new name, conflict
+	innerWidth := dialogWidth - 6
+	oldWidth := 0
+	for _, r := range br.rows {
+		if w := lipgloss.Width(filepath.Base(r.From)); w > oldWidth {
+			oldWidth = w
+		}
+	}
+	if oldWidth > innerWidth*2/5 {
+		oldWidth = innerWidth * 2 / 5
+	}
+	start := br.scroll
+	if start > len(br.rows)-visible {
+		start = len(br.rows) - visible
+	}
+	if start < 0 {
+		start = 0
+	}
+	end := start + visible
+	if end > len(br.rows) {
+		end = len(br.rows)
+	}
+	for _, r := range br.rows[start:end] {
+		oldName := xansi.Truncate(filepath.Base(r.From), oldWidth, "…")
+		oldName += strings.Repeat(" ", oldWidth-lipgloss.Width(oldName))
+		note := ""
+		if r.Conflict != "" {
+			note = "  ✗ " + r.Conflict
+		}
+		newName := xansi.Truncate(filepath.Base(r.To), innerWidth-oldWidth-3-lipgloss.Width(note), "…")
+		row := oldStyle.Render(oldName) + " → "
+		if r.Conflict != "" {
+			row += conflictStyle.Render(newName + note)
+		} else {
+			row += newStyle.Render(newName)
+		}
+		lines = append(lines, row)
+	}
+	if len(br.rows) > visible {
+		lines = append(lines, dimStyle.Render(fmt.Sprintf("(%d-%d of %d, ↑/↓ to scroll)", start+1, end, len(br.rows))))
+	}
+
+	lines = append(lines, promptStyle.Render("enter to rename, tab next field, esc to cancel"))
+	return dialogStyle.Render(strings.Join(lines, "\n"))
+}
+
+// renderJobsDialog lists background copy/move jobs with their progress
+func (m model) renderJobsDialog() string {
+	dialogWidth := 80
+	if m.width-4 < dialogWidth {
+		dialogWidth = m.width - 4
+	}
+	innerWidth := dialogWidth - 6
+
+	dialogStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("105")).
+		Background(lipgloss.Color("232")).
+		Padding(1, 2).
+		Width(dialogWidth)
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("105")).
+		Background(lipgloss.Color("232"))
+
+	rowStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("252")).Background(lipgloss.Color("232"))
+	cursorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("105")).Background(lipgloss.Color("236")).Bold(true)
+	doneStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("42")).Background(lipgloss.Color("232"))
+	errStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Background(lipgloss.Color("232"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245")).Background(lipgloss.Color("232"))
+
+	promptStyle := lipgloss.NewStyle().
+		Bold(true).
+		Padding(1, 0, 0, 0).
+		Background(lipgloss.Color("232"))
+
+	lines := []string{titleStyle.Render("⇄  JOBS"), ""}
+	snaps := m.jobs.Snapshots()
+	if len(snaps) == 0 {
+		lines = append(lines, dimStyle.Render("no jobs: paste with 'p' to start one"))
+	}
+	// Each job takes up to two lines; keep the cursor's job in view
+	visible := max(3, (m.height-12)/2)
+	start := max(0, m.jobsCursor-visible+1)
+	end := min(len(snaps), start+visible)
+	for i := start; i < end; i++ {
+		s := snaps[i]
+		what := filepath.Base(s.Sources[0])
+		if len(s.Sources) > 1 {
+			what += fmt.Sprintf(" +%d", len(s.Sources)-1)
+		}
+		status := s.State.String()
+		if s.State == jobs.Running {
+			status = jobProgress(s)
+		}
+		row := fmt.Sprintf("%s %s → %s", s.Kind, what, filepath.Base(s.DestDir))
+		row = xansi.Truncate(row, innerWidth-lipgloss.Width(status)-3, "…")
+		row += strings.Repeat(" ", max(1, innerWidth-lipgloss.Width(row)-lipgloss.Width(status)-2))
+
+		switch {
+		case i == m.jobsCursor:
+			lines = append(lines, cursorStyle.Render("▸ "+row+status))
+		case s.State == jobs.Done:
+			lines = append(lines, rowStyle.Render("  "+row)+doneStyle.Render(status))
+		case s.State == jobs.Failed || s.State == jobs.Cancelled:
+			lines = append(lines, rowStyle.Render("  "+row)+errStyle.Render(status))
+		default:
+			lines = append(lines, rowStyle.Render("  "+row+status))
+		}
+
+		switch {
+		case s.State == jobs.Running && s.Current != "":
+			detail := fmt.Sprintf("%d/%d files  %s", s.FilesDone, s.FilesTotal, s.Current)
+			if s.FilesTotal == 0 {
+				// Compressed tars don't say how many entries they hold
+				detail = fmt.Sprintf("%d files  %s", s.FilesDone, s.Current)
+			}
+			lines = append(lines, dimStyle.Render("    "+xansi.Truncate(detail, innerWidth-4, "…")))
+		case s.Err != nil:
+			lines = append(lines, errStyle.Render("    "+xansi.Truncate(s.Err.Error(), innerWidth-4, "…")))
+		}
+	}
+
+	if len(snaps) > visible {
+		lines = append(lines, dimStyle.Render(fmt.Sprintf("(%d-%d of %d, j/k to scroll)", start+1, end, len(snaps))))
+	}
+
+	lines = append(lines, promptStyle.Render("x: cancel, C: clear finished, q: cancel all & quit, esc: close"))
+	return dialogStyle.Render(strings.Join(lines, "\n"))
+}
+
+// renderHistoryDialog lists the undo/redo journal, newest first. Undone entries are
+// dimmed: ctrl+r brings them back, and any new operation drops them.
+func (m model) renderHistoryDialog() string {
+	dialogWidth := 80
+	if m.width-4 < dialogWidth {
+		dialogWidth = m.width - 4
+	}
+	innerWidth := dialogWidth - 6
+
+	dialogStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("105")).
+		Background(lipgloss.Color("232")).
+		Padding(1, 2).
+		Width(dialogWidth)
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("105")).
+		Background(lipgloss.Color("232"))
+
+	rowStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("252")).Background(lipgloss.Color("232"))
+	cursorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("105")).Background(lipgloss.Color("236")).Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245")).Background(lipgloss.Color("232"))
+
+	promptStyle := lipgloss.NewStyle().
+		Bold(true).
+		Padding(1, 0, 0, 0).
+		Background(lipgloss.Color("232"))
+
+	lines := []string{titleStyle.Render("↶  HISTORY"), ""}
+	ops := m.journal.Ops
+	if len(ops) == 0 {
+		lines = append(lines, dimStyle.Render("no file operations yet"))
+	}
+	visible := max(3, m.height-12)
+	start := max(0, m.historyCursor-visible+1)
+	end := min(len(ops), start+visible)
+	today := time.Now().Format("2006-01-02")
+	for row := start; row < end; row++ {
+		i := len(ops) - 1 - row
+		op := ops[i]
+		when := op.Time.Format("Jan 02 15:04")
+		if op.Time.Format("2006-01-02") == today {
+			when = op.Time.Format("15:04:05")
+		}
+		status := ""
+		if i >= m.journal.Applied {
+			status = "undone"
+		}
+		text := fmt.Sprintf("%-12s %s", when, op.Describe())
+		text = xansi.Truncate(text, innerWidth-lipgloss.Width(status)-3, "…")
+		text += strings.Repeat(" ", max(1, innerWidth-lipgloss.Width(text)-lipgloss.Width(status)-2))
+
+		switch {
+		case row == m.historyCursor:
+			lines = append(lines, cursorStyle.Render("▸ "+text+status))
+		case status != "":
+			lines = append(lines, dimStyle.Render("  "+text+status))
+		default:
+			lines = append(lines, rowStyle.Render("  "+text))
+		}
+	}
+
+	if len(ops) > visible {
+		lines = append(lines, dimStyle.Render(fmt.Sprintf("(%d-%d of %d, j/k to scroll)", start+1, end, len(ops))))
+	}
+
+	lines = append(lines, promptStyle.Render("u: undo, ctrl+r: redo, esc: close"))
+	return dialogStyle.Render(strings.Join(lines, "\n"))
+}
+
+// renderTrashDialog lists the trash, most recently deleted first, with the selected
+// item's original path underneath
+func (m model) renderTrashDialog() string {
+	dialogWidth := 90
+	if m.width-4 < dialogWidth {
+		dialogWidth = m.width - 4
+	}
+	innerWidth := dialogWidth - 6
+
+	border := lipgloss.Color("105")
+	if m.trashConfirm != "" {
+		border = lipgloss.Color("196")
+	}
+	dialogStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(border).
+		Background(lipgloss.Color("232")).
+		Padding(1, 2).
+		Width(dialogWidth)
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("105")).
+		Background(lipgloss.Color("232"))
+
+	rowStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("252")).Background(lipgloss.Color("232"))
+	cursorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("105")).Background(lipgloss.Color("236")).Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245")).Background(lipgloss.Color("232"))
+	warnStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Background(lipgloss.Color("232")).Bold(true)
+
+	promptStyle := lipgloss.NewStyle().
+		Bold(true).
+		Padding(1, 0, 0, 0).
+		Background(lipgloss.Color("232"))
+
+	entries := m.trashEntries
+	lines := []string{titleStyle.Render(fmt.Sprintf("⌫  TRASH (%d)", len(entries))), ""}
+	if len(entries) == 0 {
+		lines = append(lines, dimStyle.Render("the trash is empty"))
+	}
+	visible := max(3, m.height-14)
+	start := max(0, m.trashCursor-visible+1)
+	end := min(len(entries), start+visible)
+	for i := start; i < end; i++ {
+		e := entries[i]
+		name := filepath.Base(e.OriginalPath)
+		size := utils.FormatFileSize(e.Size)
+		if e.IsDir {
+			name += "/"
+			size = "dir"
+		}
+		info := fmt.Sprintf("%8s  %s", size, e.DeletedAt.Format("2006-01-02 15:04"))
+		name = xansi.Truncate(name, innerWidth-lipgloss.Width(info)-3, "…")
+		row := name + strings.Repeat(" ", max(1, innerWidth-lipgloss.Width(name)-lipgloss.Width(info)-2)) + info
+		if i == m.trashCursor {
+			lines = append(lines, cursorStyle.Render("▸ "+row))
+		} else {
+			lines = append(lines, rowStyle.Render("  "+row))
+		}
+	}
+	if len(entries) > visible {
+		lines = append(lines, dimStyle.Render(fmt.Sprintf("(%d-%d of %d, j/k to scroll)", start+1, end, len(entries))))
+	}
+	if m.trashCursor < len(entries) {
+		from := "from " + entries[m.trashCursor].OriginalPath
+		lines = append(lines, "", dimStyle.Render(xansi.Truncate(from, innerWidth, "…")))
+	}
+
+	switch m.trashConfirm {
+	case "purge":
+		name := filepath.Base(entries[m.trashCursor].OriginalPath)
+		lines = append(lines, promptStyle.Inherit(warnStyle).Render(fmt.Sprintf("delete %s forever? y to confirm, any other key cancels", name)))
+	case "empty":
+		lines = append(lines, promptStyle.Inherit(warnStyle).Render(fmt.Sprintf("delete all %d item(s) forever? y to confirm, any other key cancels", len(entries))))
+	default:
+		lines = append(lines, promptStyle.Render("r/enter: restore, d: delete forever, E: empty trash, esc: close"))
+	}
+	return dialogStyle.Render(strings.Join(lines, "\n"))
+}
+
+// renderArchiveDialog is the read-only browser into an archive: one directory at a time,
+// directories first, with sizes
+func (m model) renderArchiveDialog() string {
+	b := m.archiveBrowser
+	if b == nil {
+		return "Error: No archive open"
+	}
+	dialogWidth := 90
+	if m.width-4 < dialogWidth {
+		dialogWidth = m.width - 4
+	}
+	innerWidth := dialogWidth - 6
+
+	dialogStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("105")).
+		Background(lipgloss.Color("232")).
+		Padding(1, 2).
+		Width(dialogWidth)
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("105")).
+		Background(lipgloss.Color("232"))
+
+	rowStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("252")).Background(lipgloss.Color("232"))
+	cursorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("105")).Background(lipgloss.Color("236")).Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245")).Background(lipgloss.Color("232"))
+	errStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Background(lipgloss.Color("232"))
+
+	promptStyle := lipgloss.NewStyle().
+		Bold(true).
+		Padding(1, 0, 0, 0).
+		Background(lipgloss.Color("232"))
+
+	title := "▤  " + filepath.Base(b.path) + ":/" + b.dir
+	lines := []string{titleStyle.Render(xansi.Truncate(title, innerWidth, "…")), ""}
+	switch {
+	case b.loading:
+		lines = append(lines, dimStyle.Render("reading archive..."))
+	case b.err != nil:
+		lines = append(lines, errStyle.Render(xansi.Truncate(fmt.Sprintf("cannot read archive: %v", b.err), innerWidth, "…")))
+	case len(b.rows) == 0:
+		lines = append(lines, dimStyle.Render("(empty)"))
+	}
+
+	visible := max(3, m.height-14)
+	start := max(0, b.cursor-visible+1)
+	end := min(len(b.rows), start+visible)
+	for i := start; i < end; i++ {
+		e := b.rows[i]
+		name := path.Base(e.Name)
+		info := utils.FormatFileSize(e.Size)
+		switch {
+		case e.IsDir:
+			name = "📁 " + name + "/"
+			info = "dir"
+		case e.Link != "":
+			name = "🔗 " + name + " → " + e.Link
+			info = "link"
+		default:
+			name = "📄 " + name
+			if e.Size < 0 {
+				info = ""
+			}
+		}
+		if !e.ModTime.IsZero() {
+			info = fmt.Sprintf("%8s  %s", info, e.ModTime.Format("2006-01-02 15:04"))
+		}
+		name = xansi.Truncate(name, innerWidth-lipgloss.Width(info)-3, "…")
+		row := name + strings.Repeat(" ", max(1, innerWidth-lipgloss.Width(name)-lipgloss.Width(info)-2)) + info
+		if i == b.cursor {
+			lines = append(lines, cursorStyle.Render("▸ "+row))
+		} else {
+			lines = append(lines, rowStyle.Render("  "+row))
+		}
+	}
+	if len(b.rows) > visible {
+		lines = append(lines, dimStyle.Render(fmt.Sprintf("(%d-%d of %d, j/k to scroll)", start+1, end, len(b.rows))))
+	}
+
+	lines = append(lines, promptStyle.Render("enter/l: open folder, h: up, x: extract, esc: close"))
+	return dialogStyle.Render(strings.Join(lines, "\n"))
+}
+
+// renderExtractDialog asks where to extract archives
+func (m model) renderExtractDialog() string {
+	st := m.extract
+	if st == nil {
+		return "Error: Nothing to extract"
+	}
+	dialogWidth := 70
+	if m.width-4 < dialogWidth {
+		dialogWidth = m.width - 4
+	}
+	innerWidth := dialogWidth - 6
+
+	dialogStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("105")).
+		Background(lipgloss.Color("232")).
+		Padding(1, 2).
+		Width(dialogWidth)
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("105")).
+		Background(lipgloss.Color("232"))
+
+	contentStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("252")).
+		Padding(1, 0).
+		Background(lipgloss.Color("232"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245")).Background(lipgloss.Color("232"))
+
+	promptStyle := lipgloss.NewStyle().
+		Bold(true).
+		Padding(1, 0, 0, 0).
+		Background(lipgloss.Color("232"))
+
+	what := filepath.Base(st.archives[0])
+	if len(st.archives) > 1 {
+		what = fmt.Sprintf("%d archives", len(st.archives))
+	}
+	into := "contents go straight into this directory"
+	if st.subfolder {
+		into = "into a new folder: " + archive.Stem(filepath.Base(st.archives[0])) + "/"
+		if len(st.archives) > 1 {
+			into = "each into a new folder named after it"
+		}
+	}
+
+	lines := []string{
+		titleStyle.Render(xansi.Truncate("⇣  EXTRACT "+what, innerWidth, "…")),
+		contentStyle.Render("extract to:"),
+		m.textInput.View(),
+		dimStyle.Render("→ " + xansi.Truncate(into, innerWidth-2, "…")),
+		promptStyle.Render("tab: next preset, ctrl+n: folder on/off, enter: extract, esc: cancel"),
+	}
+	return dialogStyle.Render(strings.Join(lines, "\n"))
+}
+
+// renderCompressDialog asks for the name of the archive to create
+func (m model) renderCompressDialog() string {
+	st := m.compress
+	if st == nil {
+		return "Error: Nothing to compress"
+	}
+	dialogWidth := 70
+	if m.width-4 < dialogWidth {
+		dialogWidth = m.width - 4
+	}
+	innerWidth := dialogWidth - 6
+
+	dialogStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("105")).
+		Background(lipgloss.Color("232")).
+		Padding(1, 2).
+		Width(dialogWidth)
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("105")).
+		Background(lipgloss.Color("232"))
+
+	contentStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("252")).
+		Padding(1, 0).
+		Background(lipgloss.Color("232"))
+
+	promptStyle := lipgloss.NewStyle().
+		Bold(true).
+		Padding(1, 0, 0, 0).
+		Background(lipgloss.Color("232"))
+
+	what := filepath.Base(st.sources[0])
+	if len(st.sources) > 1 {
+		what = fmt.Sprintf("%d items", len(st.sources))
+	}
+	lines := []string{
+		titleStyle.Render(xansi.Truncate("⇡  COMPRESS "+what, innerWidth, "…")),
+		contentStyle.Render("new archive in this directory:"),
+		m.textInput.View(),
+		promptStyle.Render("tab: .zip / .tar.gz, enter: create, esc: cancel"),
+	}
+	return dialogStyle.Render(strings.Join(lines, "\n"))
+}
+
+// renderHexView is the full-screen hex viewer: the rows from the offset, read from the
+// file as they're drawn, with the last match or jump target highlighted
+func (m model) renderHexView() string {
+	v := m.hexViewer
+	if v == nil {
+		return "Error: No file open"
+	}
+	availableHeight := m.height - uiOverhead
+	if availableHeight < 3 {
+		availableHeight = 3
+	}
+	perRow, rows := m.hexLayout()
+	innerWidth := m.width - 6
+
+	headerStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("105"))
+
+	listStyle := lipgloss.NewStyle().
+		Width(m.width-4).
+		Padding(0, 1)
+
+	borderStyle := lipgloss.NewStyle().
+		Border(lipgloss.NormalBorder()).
+		BorderForeground(lipgloss.Color("240")).
+		Width(m.width - 2).
+		Height(availableHeight + 1)
+
+	markStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("105")).Background(lipgloss.Color("236")).Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+	errStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+
+	offset := v.offset / int64(perRow) * int64(perRow) // Row width changes with the terminal's
+	buf := make([]byte, perRow*rows)
+	n, err := v.file.ReadAt(buf, offset)
+
+	percent := int64(100)
+	if v.size > 0 {
+		percent = (offset + int64(n)) * 100 / v.size
+	}
+	info := fmt.Sprintf("%s  0x%x  %d%%", utils.FormatFileSize(v.size), offset, percent)
+	title := xansi.Truncate("⬡ HEX  "+v.path, max(innerWidth-lipgloss.Width(info)-2, 1), "…")
+	header := headerStyle.Render(title) +
+		strings.Repeat(" ", max(1, innerWidth-lipgloss.Width(title)-lipgloss.Width(info))) +
+		dimStyle.Render(info)
+
+	mark := func(off int64, s string) string {
+		if v.mark >= 0 && off >= v.mark && off < v.mark+int64(v.markLen) {
+			return markStyle.Render(s)
+		}
+		return s
+	}
+	var lines []string
+	switch {
+	case err != nil && err != io.EOF:
+		lines = append(lines, errStyle.Render(xansi.Truncate(fmt.Sprintf("cannot read file: %v", err), innerWidth, "…")))
+	case v.size == 0:
+		lines = append(lines, dimStyle.Render("(empty file)"))
+	}
+	for i := 0; i < n; i += perRow {
+		lines = append(lines, hexview.Row(buf[i:min(i+perRow, n)], offset+int64(i), perRow, mark))
+	}
+	for len(lines) < rows {
+		lines = append(lines, "")
+	}
+
+	var footer string
+	switch {
+	case v.prompt == "goto":
+		footer = "goto " + m.textInput.View()
+	case v.prompt == "search":
+		footer = "search " + m.textInput.View()
+	case v.cancel != nil:
+		footer = dimStyle.Render(xansi.Truncate("searching for "+v.query+"... esc to stop", innerWidth, "…"))
+	default:
+		footer = dimStyle.Render(xansi.Truncate("j/k: scroll, space/ctrl+b: page, g/G: start/end, : goto, /: search, n/N: next/prev, q: close", innerWidth, "…"))
+	}
+
+	combined := header + "\n" + strings.Join(lines, "\n") + "\n" + footer
+	return borderStyle.Render(listStyle.Render(combined))
+}
+
+// renderPasteConflictDialog asks what to do about the first name clash of a paste
+func (m model) renderPasteConflictDialog() string {
+	plan := m.pastePlan
+	if plan == nil || len(plan.pending) == 0 {
+		return "Error: No paste conflict"
+	}
+	c := plan.pending[0]
+	dialogWidth := 70
+	if m.width-4 < dialogWidth {
+		dialogWidth = m.width - 4
+	}
+
+	dialogStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("214")).
+		Background(lipgloss.Color("232")).
+		Padding(1, 2).
+		Width(dialogWidth)
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("214")).
+		Background(lipgloss.Color("232"))
+
+	contentStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("252")).Background(lipgloss.Color("232"))
+	labelStyle := contentStyle.Width(10)
+	newerStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("42")).Background(lipgloss.Color("232"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245")).Background(lipgloss.Color("232"))
+	keyStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("105")).Background(lipgloss.Color("232")).Bold(true)
+
+	describe := func(info os.FileInfo, newer bool) string {
+		if info == nil {
+			return dimStyle.Render("another item in this paste")
+		}
+		text := info.ModTime().Format("2006-01-02 15:04")
+		if info.IsDir() {
+			text = "directory, " + text
+		} else {
+			text = utils.FormatFileSize(info.Size()) + ", " + text
+		}
+		if newer {
+			return newerStyle.Render(text + " (newer)")
+		}
+		return contentStyle.Render(text)
+	}
+	srcNewer := c.dstInfo != nil && c.srcInfo.ModTime().After(c.dstInfo.ModTime())
+	dstNewer := c.dstInfo != nil && c.dstInfo.ModTime().After(c.srcInfo.ModTime())
+
+	name := xansi.Truncate(filepath.Base(c.dst), dialogWidth-30, "…")
+	dir := xansi.Truncate(plan.destDir, dialogWidth-12, "…")
+	lines := []string{
+		titleStyle.Render("⚠  NAME CONFLICT"),
+		"",
+		contentStyle.Render(fmt.Sprintf("%q already exists in", name)),
+		contentStyle.Render(dir),
+		"",
+		labelStyle.Render(plan.kind.String()+":") + describe(c.srcInfo, srcNewer),
+		labelStyle.Render("existing:") + describe(c.dstInfo, dstNewer),
+	}
+	if c.sameFile {
+		lines = append(lines, dimStyle.Render("(same file: only keep both or skip make sense)"))
+	}
+	lines = append(lines, "")
+	if more := len(plan.pending) - 1; more > 0 {
+		lines = append(lines, contentStyle.Render(fmt.Sprintf("%d more conflict(s) after this one", more)))
+	}
+	applyAll := "off"
+	if plan.applyAll {
+		applyAll = "on"
+	}
+	lines = append(lines,
+		keyStyle.Render("a")+contentStyle.Render(" apply to all remaining: "+applyAll),
+		"",
+		keyStyle.Render("o")+contentStyle.Render(" overwrite  ")+
+			keyStyle.Render("s")+contentStyle.Render(" skip  ")+
+			keyStyle.Render("r/enter")+contentStyle.Render(" keep both  ")+
+			keyStyle.Render("n")+contentStyle.Render(" overwrite if newer"),
+		dimStyle.Render("esc cancels the whole paste"),
+	)
+	return dialogStyle.Render(strings.Join(lines, "\n"))
+}
+
+func (m model) renderCreateFileDialog() string {
+	dialogWidth := 60
+	if m.width-4 < dialogWidth {
+		dialogWidth = m.width - 4
+	}
+	dialogHeight := 8
+
+	dialogStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("105")).
+		Background(lipgloss.Color("232")).
+		Padding(1, 2).
+		Width(dialogWidth).
+		Height(dialogHeight)
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("105")).
+		Background(lipgloss.Color("232"))
+
+	contentStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("252")).
+		Padding(1, 0).
+		Background(lipgloss.Color("232"))
+
+	title := titleStyle.Render("📄 CREATE NEW FILE")
+	content := contentStyle.Render("enter filename:")
+	inputView := m.textInput.View()
+
+	dialog := title + "\n" + content + "\n" + inputView
+	return dialogStyle.Render(dialog)
+}
+
+func (m model) renderCreateDirDialog() string {
+	dialogWidth := 60
+	if m.width-4 < dialogWidth {
+		dialogWidth = m.width - 4
+	}
+	dialogHeight := 8
+
+	dialogStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("105")).
+		Background(lipgloss.Color("232")).
+		Padding(1, 2).
+		Width(dialogWidth).
+		Height(dialogHeight)
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("105")).
+		Background(lipgloss.Color("232"))
+
+	contentStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("252")).
+		Padding(1, 0).
+		Background(lipgloss.Color("232"))
+
+	title := titleStyle.Render("📁 CREATE NEW DIRECTORY")
+	content := contentStyle.Render("enter directory name:")
+	inputView := m.textInput.View()
+
+	dialog := title + "\n" + content + "\n" + inputView
+	return dialogStyle.Render(dialog)
+}
+
+func (m *model) renderHelpView() string {
+	availableHeight := m.height - uiOverhead
+	if availableHeight < 3 {
+		availableHeight = 3
+	}
+
+	// Reserve space for scroll indicators
+	contentHeight := availableHeight - 1
+	if contentHeight < 1 {
+		contentHeight = 1
+	}
+
+	headerStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("105")).
+		Width(m.width - 4)
+
+	header := headerStyle.Render("❓ HELP")
+
+	listStyle := lipgloss.NewStyle().
+		Width(m.width-4).
+		Padding(0, 1)
+
+	borderStyle := lipgloss.NewStyle().
+		Border(lipgloss.NormalBorder()).
+		BorderForeground(lipgloss.Color("240")).
+		Width(m.width - 2).
+		Height(availableHeight + 1)
+
+	// Build help content
+	keyStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("105")).
+		Bold(true)
+
+	sectionStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("214")).
+		Bold(true)
+
+	var allHelpContent []string
+
+	// helpLine pads the key to a fixed column width so descriptions align
+	const keyColWidth = 14
+	helpLine := func(key, desc string) string {
+		pad := keyColWidth - lipgloss.Width(key)
+		if pad < 1 {
+			pad = 1
+		}
+		return "  " + keyStyle.Render(key) + strings.Repeat(" ", pad) + desc
+	}
+
+	// Navigation section
+	allHelpContent = append(allHelpContent, sectionStyle.Render("NAVIGATION:"))
+	allHelpContent = append(allHelpContent, helpLine("j / ↓", "move down"))
+	allHelpContent = append(allHelpContent, helpLine("k / ↑", "move up"))
+	allHelpContent = append(allHelpContent, helpLine("enter / l / →", "enter directory / open file"))
+	allHelpContent = append(allHelpContent, helpLine("esc / h / ←", "go to parent directory"))
+	allHelpContent = append(allHelpContent, helpLine("f", "navigate to dir/parent (exit search)"))
+	allHelpContent = append(allHelpContent, helpLine("middle-click", "navigate to clicked dir/parent"))
+	allHelpContent = append(allHelpContent, helpLine("g", "go to top"))
+	allHelpContent = append(allHelpContent, helpLine("G", "go to bottom"))
+	allHelpContent = append(allHelpContent, helpLine("ctrl+d", "half-page down"))
+	allHelpContent = append(allHelpContent, helpLine("ctrl+u", "half-page up"))
+	allHelpContent = append(allHelpContent, helpLine("ctrl+f", "full-page down"))
+	allHelpContent = append(allHelpContent, helpLine("ctrl+b", "full-page up"))
+	allHelpContent = append(allHelpContent, helpLine("~", "jump to home directory"))
+	allHelpContent = append(allHelpContent, helpLine("`", "jump to /mnt/c (wsl)"))
+	allHelpContent = append(allHelpContent, "")
+
+	// Preview Scrolling section
+	allHelpContent = append(allHelpContent, sectionStyle.Render("PREVIEW SCROLLING:"))
+	allHelpContent = append(allHelpContent, helpLine("s / alt+↓", "scroll preview down"))
+	allHelpContent = append(allHelpContent, helpLine("w / alt+↑", "scroll preview up"))
+	allHelpContent = append(allHelpContent, helpLine("m", "markdown & data preview: rendered / source"))
+	allHelpContent = append(allHelpContent, helpLine("< / >", "data preview: fold / unfold a level"))
+	allHelpContent = append(allHelpContent, helpLine("t", "preview: jump to end of file / back"))
+	allHelpContent = append(allHelpContent, helpLine(":", "preview: go to line"))
+	allHelpContent = append(allHelpContent, helpLine("F", "preview: follow file as it grows / stop"))
+	allHelpContent = append(allHelpContent, helpLine("H", "hex viewer: : goto offset, / find bytes"))
+	allHelpContent = append(allHelpContent, "")
+
+	// File Operations section
+	allHelpContent = append(allHelpContent, sectionStyle.Render("FILE OPERATIONS:"))
+	allHelpContent = append(allHelpContent, helpLine("o", "open selected in editor/vs code"))
+	allHelpContent = append(allHelpContent, helpLine("O", "open current directory in vs code"))
+	allHelpContent = append(allHelpContent, helpLine("R", "rename file/directory"))
+	allHelpContent = append(allHelpContent, helpLine("E", "bulk rename selection (or all) in editor"))
+	allHelpContent = append(allHelpContent, helpLine("ctrl+e", "pattern rename with live preview"))
+	allHelpContent = append(allHelpContent, helpLine("D", "delete file/directory"))
+	allHelpContent = append(allHelpContent, helpLine("N", "create new file"))
+	allHelpContent = append(allHelpContent, helpLine("M", "create new directory"))
+	allHelpContent = append(allHelpContent, helpLine("r", "refresh current view"))
+	allHelpContent = append(allHelpContent, "")
+
+	// Clipboard Operations section
+	allHelpContent = append(allHelpContent, sectionStyle.Render("CLIPBOARD:"))
+	allHelpContent = append(allHelpContent, helpLine("c", "copy file (replaces clipboard)"))
+	allHelpContent = append(allHelpContent, helpLine("x", "cut file (replaces clipboard)"))
+	allHelpContent = append(allHelpContent, helpLine("C", "append to copy clipboard (multi-file)"))
+	allHelpContent = append(allHelpContent, helpLine("X", "append to cut clipboard (multi-file)"))
+	allHelpContent = append(allHelpContent, helpLine("p", "paste files (runs in the background)"))
+	allHelpContent = append(allHelpContent, helpLine("J", "jobs: progress, x cancel, C clear"))
+	allHelpContent = append(allHelpContent, helpLine("y", "copy path to clipboard"))
+	allHelpContent = append(allHelpContent, helpLine("u", "undo last file operation"))
+	allHelpContent = append(allHelpContent, helpLine("ctrl+r", "redo what was undone"))
+	allHelpContent = append(allHelpContent, helpLine("U", "history: every undoable operation"))
+	allHelpContent = append(allHelpContent, helpLine("T", "trash: restore, delete forever, empty"))
+	allHelpContent = append(allHelpContent, helpLine("enter (archive)", "browse zip/tar contents, x to extract"))
+	allHelpContent = append(allHelpContent, helpLine("Z", "extract archive(s): tab picks where"))
+	allHelpContent = append(allHelpContent, helpLine("z", "compress selection into .zip/.tar.gz"))
+	allHelpContent = append(allHelpContent, "")
+
+	// Selection section
+	allHelpContent = append(allHelpContent, sectionStyle.Render("SELECTION:"))
+	allHelpContent = append(allHelpContent, helpLine("space", "toggle mark and move down"))
+	allHelpContent = append(allHelpContent, helpLine("V", "visual range (V again: keep, esc: cancel)"))
+	allHelpContent = append(allHelpContent, helpLine("v", "invert selection"))
+	allHelpContent = append(allHelpContent, helpLine("+ / -", "select / deselect by glob"))
+	allHelpContent = append(allHelpContent, helpLine("esc", "clear selection"))
+	allHelpContent = append(allHelpContent, helpLine("D y c x C X o", "act on the selection when there is one"))
+	allHelpContent = append(allHelpContent, "")
+
+	// Dual Pane section
+	allHelpContent = append(allHelpContent, sectionStyle.Render("DUAL PANE:"))
+	allHelpContent = append(allHelpContent, helpLine("|", "toggle dual-pane layout"))
+	allHelpContent = append(allHelpContent, helpLine("tab", "switch active pane (dual-pane)"))
+	allHelpContent = append(allHelpContent, helpLine("p", "paste into other pane (if copied here)"))
 	allHelpContent = append(allHelpContent, "")
 
 	// Search & Filter section
 	allHelpContent = append(allHelpContent, sectionStyle.Render("SEARCH & FILTER:"))
-	allHelpContent = append(allHelpContent, helpLine("/", "start search (file/recursive/content)"))
+	allHelpContent = append(allHelpContent, helpLine("/ / tab", "start search (tab: single-pane only)"))
 	allHelpContent = append(allHelpContent, helpLine("tab", "cycle search modes (while searching)"))
 	allHelpContent = append(allHelpContent, helpLine("↑/↓", "navigate results (while searching)"))
 	allHelpContent = append(allHelpContent, helpLine("enter", "lock results / go to file or dir"))
 	allHelpContent = append(allHelpContent, helpLine("ctrl+p", "toggle preview panel (while searching)"))
 	allHelpContent = append(allHelpContent, helpLine("ctrl+n", "toggle name-only / full-path search"))
+	allHelpContent = append(allHelpContent, helpLine("ctrl+t", "cycle matcher: fuzzy/substring/glob/regex"))
+	allHelpContent = append(allHelpContent, helpLine("ctrl+o", "include .gitignore'd files in recursive search"))
+	allHelpContent = append(allHelpContent, helpLine("ctrl+r", "rebuild filename index (while searching)"))
 	allHelpContent = append(allHelpContent, helpLine("s", "cycle sort mode"))
 	allHelpContent = append(allHelpContent, helpLine(".", "toggle hidden files"))
 	allHelpContent = append(allHelpContent, "")
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// dirChangedMsg is sent when the watcher reports a (debounced) change in a watched directory
+type dirChangedMsg struct{ dir string }
+
+// dirRefreshRetryMsg re-checks queued directory refreshes once the user is back in normal mode
+type dirRefreshRetryMsg struct{}
+
+// waitForDirChange blocks on the watcher's next event
+func (m *model) waitForDirChange() tea.Cmd {
+	if m.watcher == nil {
+		return nil
+	}
+	events := m.watcher.Events()
+	return func() tea.Msg {
+		dir, ok := <-events
+		if !ok {
+			return nil
+		}
+		return dirChangedMsg{dir: dir}
+	}
+}
+
+// syncWatches points the watcher at the directories currently on screen
+func (m *model) syncWatches() {
+	if m.dualPane {
+		m.watcher.Watch(m.currentDir, m.otherPane.dir)
+	} else {
+		m.watcher.Watch(m.currentDir)
+	}
+}
+
+// queueDirRefresh records that dir changed on disk; flushDirRefresh applies it
+func (m *model) queueDirRefresh(dir string) {
+	if m.dirsChanged == nil {
+		m.dirsChanged = make(map[string]bool)
+	}
+	m.dirsChanged[dir] = true
+}
+
+// flushDirRefresh reloads any changed pane. Outside normal mode the list may be showing
+// search results or backing a dialog, so the reload waits until the user is back.
+func (m *model) flushDirRefresh() tea.Cmd {
+	if len(m.dirsChanged) == 0 {
+		return nil
+	}
+	if m.mode != modeNormal {
+		if m.dirRetryPending {
+			return nil
+		}
+		m.dirRetryPending = true
+		return tea.Tick(500*time.Millisecond, func(t time.Time) tea.Msg {
+			return dirRefreshRetryMsg{}
+		})
+	}
+
+	changed := m.dirsChanged
+	m.dirsChanged = nil
+	if m.dualPane && changed[m.otherPane.dir] {
+		m.exchangePaneState()
+		m.reloadKeepingCursor()
+		m.exchangePaneState()
+	}
+	if changed[m.currentDir] {
+		m.reloadKeepingCursor()
+		m.updatePreview()
+	}
+	m.syncWatches()
+	return nil
+}
+
+// reloadKeepingCursor re-reads the active directory after an outside change, keeping the
+// cursor on the same file name (or the same row if that file is gone). If the directory
+// itself was removed, it moves up to the nearest ancestor that still exists.
+func (m *model) reloadKeepingCursor() {
+	var selected string
+	if m.cursor < len(m.filteredFiles) {
+		selected = m.filteredFiles[m.cursor].name
+	}
+
+	files, err := m.readDirItems(m.currentDir, m.showHidden)
+	for err != nil && os.IsNotExist(err) {
+		parent := filepath.Dir(m.currentDir)
+		if parent == m.currentDir {
+			break
+		}
+		m.currentDir = parent
+		selected = ""
+		files, err = m.readDirItems(m.currentDir, m.showHidden)
+	}
+	if err != nil {
+		return
+	}
+
+	m.files = files
+	m.sortFiles()
+	m.filteredFiles = m.files
+
+	if selected != "" {
+		for i, f := range m.filteredFiles {
+			if f.name == selected {
+				m.cursor = i
+				break
+			}
+		}
+	}
+	m.ensureCursorInBounds()
+	m.refreshGitStatus()
+}
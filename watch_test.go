@@ -0,0 +1,110 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDirRefreshKeepsCursorOnSameName(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"b.txt", "d.txt", "f.txt"} {
+		os.WriteFile(filepath.Join(dir, name), []byte("x"), 0o644)
+	}
+
+	m := testModelForUpdate(t, dir)
+	m.mode = modeNormal
+	m.loadFiles()
+	for i, f := range m.filteredFiles {
+		if f.name == "d.txt" {
+			m.cursor = i
+		}
+	}
+
+	// New files sort in front of the selection; the cursor should follow d.txt
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("x"), 0o644)
+	os.WriteFile(filepath.Join(dir, "c.txt"), []byte("x"), 0o644)
+	m.queueDirRefresh(dir)
+	m.flushDirRefresh()
+
+	if got := m.filteredFiles[m.cursor].name; got != "d.txt" {
+		t.Fatalf("expected cursor to stay on d.txt, got %q", got)
+	}
+	if len(m.files) != 6 { // 5 files + ".."
+		t.Fatalf("expected reload to pick up new files, got %d entries", len(m.files))
+	}
+}
+
+func TestDirRefreshWaitsForNormalMode(t *testing.T) {
+	dir := t.TempDir()
+	m := testModelForUpdate(t, dir) // starts in modeSearch
+	m.filteredFiles = []fileItem{{name: "result.txt", path: filepath.Join(dir, "result.txt")}}
+
+	os.WriteFile(filepath.Join(dir, "new.txt"), []byte("x"), 0o644)
+	m.queueDirRefresh(dir)
+	if cmd := m.flushDirRefresh(); cmd == nil {
+		t.Fatal("expected a retry tick while searching")
+	}
+	if len(m.filteredFiles) != 1 || m.filteredFiles[0].name != "result.txt" {
+		t.Fatal("search results must not be replaced by a directory reload")
+	}
+
+	m.mode = modeNormal
+	gotModel, _ := m.Update(dirRefreshRetryMsg{})
+	got := gotModel.(*model)
+	found := false
+	for _, f := range got.files {
+		if f.name == "new.txt" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatal("expected queued refresh to apply once back in normal mode")
+	}
+}
+
+func TestDirRefreshMovesUpWhenDirRemoved(t *testing.T) {
+	parent := t.TempDir()
+	child := filepath.Join(parent, "child", "grandchild")
+	os.MkdirAll(child, 0o755)
+
+	m := testModelForUpdate(t, child)
+	m.mode = modeNormal
+	m.loadFiles()
+
+	os.RemoveAll(filepath.Join(parent, "child"))
+	m.queueDirRefresh(child)
+	m.flushDirRefresh()
+
+	if m.currentDir != parent {
+		t.Fatalf("expected to move up to %q, got %q", parent, m.currentDir)
+	}
+}
+
+func TestDirRefreshReloadsOtherPane(t *testing.T) {
+	left := t.TempDir()
+	right := t.TempDir()
+
+	m := testModelForUpdate(t, left)
+	m.mode = modeNormal
+	m.loadFiles()
+	m.otherPane = paneState{dir: right}
+	m.toggleDualPane()
+
+	os.WriteFile(filepath.Join(right, "arrived.txt"), []byte("x"), 0o644)
+	m.queueDirRefresh(right)
+	m.flushDirRefresh()
+
+	found := false
+	for _, f := range m.otherPane.files {
+		if f.name == "arrived.txt" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatal("expected other pane to pick up the new file")
+	}
+	if m.currentDir != left {
+		t.Fatalf("active pane should be unchanged, got %q", m.currentDir)
+	}
+}